@@ -0,0 +1,139 @@
+package ast
+
+import "iter"
+
+// Get returns the value of the property with the given key.
+// When a key appears more than once, the last property wins.
+// Get uses the index when it has been built by Index.
+func (o *Object) Get(key string) (*Value, bool) {
+	if o.index != nil {
+		i, ok := o.index[key]
+		if !ok {
+			return nil, false
+		}
+		return asValue(o.Children[i].Value), true
+	}
+	for i := len(o.Children) - 1; i >= 0; i-- {
+		if o.Children[i].Identifier.Value == key {
+			return asValue(o.Children[i].Value), true
+		}
+	}
+	return nil, false
+}
+
+// Index returns a map from each key to the position of its property in
+// Children, the last one when a key appears more than once. The map is
+// built on first use and kept until a mutation method such as Set or Delete
+// is called; callers modifying Children directly must call Reindex.
+// The returned map must not be modified.
+func (o *Object) Index() map[string]int {
+	if o.index == nil {
+		o.index = make(map[string]int, len(o.Children))
+		for i, prop := range o.Children {
+			o.index[prop.Identifier.Value] = i
+		}
+	}
+	return o.index
+}
+
+// Reindex drops the index built by Index. The index of a frozen object
+// is kept.
+func (o *Object) Reindex() {
+	if !o.frozen {
+		o.index = nil
+	}
+}
+
+// Set sets the value of the property with the given key, replacing the
+// last property with that key or appending a new one with Insert.
+// It returns ErrFrozen when the object is frozen.
+func (o *Object) Set(key string, v *Value) error {
+	if o.frozen {
+		return ErrFrozen
+	}
+	for i := len(o.Children) - 1; i >= 0; i-- {
+		if o.Children[i].Identifier.Value == key {
+			o.Children[i].Value = v
+			return nil
+		}
+	}
+	return o.Insert(len(o.Children), key, v)
+}
+
+// Delete removes every property with the given key and reports whether
+// any was removed. It returns ErrFrozen when the object is frozen.
+func (o *Object) Delete(key string) (bool, error) {
+	if o.frozen {
+		return false, ErrFrozen
+	}
+	children := o.Children[:0]
+	for _, prop := range o.Children {
+		if prop.Identifier.Value != key {
+			children = append(children, prop)
+		}
+	}
+	removed := len(children) != len(o.Children)
+	clear(o.Children[len(children):])
+	o.Children = children
+	o.index = nil
+	return removed, nil
+}
+
+// Keys returns the property keys in document order.
+func (o *Object) Keys() []string {
+	keys := make([]string, 0, len(o.Children))
+	for _, prop := range o.Children {
+		keys = append(keys, prop.Identifier.Value)
+	}
+	return keys
+}
+
+// Len returns the number of properties.
+func (o *Object) Len() int {
+	return len(o.Children)
+}
+
+// Properties returns an iterator over the keys and values of the
+// properties in document order, duplicate keys included.
+func (o *Object) Properties() iter.Seq2[string, *Value] {
+	return func(yield func(string, *Value) bool) {
+		for _, prop := range o.Children {
+			if !yield(prop.Identifier.Value, asValue(prop.Value)) {
+				return
+			}
+		}
+	}
+}
+
+// At returns the i-th item of the array.
+func (a *Array) At(i int) (*Value, bool) {
+	if i < 0 || i >= len(a.Children) {
+		return nil, false
+	}
+	return asValue(a.Children[i].Value), true
+}
+
+// Len returns the number of items.
+func (a *Array) Len() int {
+	return len(a.Children)
+}
+
+// Values returns an iterator over the items in order.
+func (a *Array) Values() iter.Seq[*Value] {
+	return func(yield func(*Value) bool) {
+		for _, item := range a.Children {
+			if !yield(asValue(item.Value)) {
+				return
+			}
+		}
+	}
+}
+
+// asValue returns node as a *Value, wrapping bare
+// *Object, *Array and *Literal nodes.
+func asValue(node any) *Value {
+	if v, ok := node.(*Value); ok {
+		return v
+	}
+	return &Value{Value: node}
+}
@@ -0,0 +1,102 @@
+package ast_test
+
+import (
+	"testing"
+
+	"github.com/pohedev/gj.git/ast"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObject_Get(t *testing.T) {
+	root := parse(t, `{"a": 1, "b": [true, "x"], "a": 2}`)
+	obj := root.Value.Value.(*ast.Object)
+
+	a, ok := obj.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, int64(2), a.ToGo())
+
+	_, ok = obj.Get("missing")
+	assert.False(t, ok)
+
+	assert.Equal(t, []string{"a", "b", "a"}, obj.Keys())
+	assert.Equal(t, 3, obj.Len())
+}
+
+func TestArray_At(t *testing.T) {
+	root := parse(t, `{"b": [true, "x"]}`)
+	b, _ := root.Value.Value.(*ast.Object).Get("b")
+	array := b.Value.(*ast.Array)
+
+	item, ok := array.At(1)
+	assert.True(t, ok)
+	assert.Equal(t, "x", item.ToGo())
+
+	_, ok = array.At(2)
+	assert.False(t, ok)
+	_, ok = array.At(-1)
+	assert.False(t, ok)
+	assert.Equal(t, 2, array.Len())
+}
+
+func TestObject_Index(t *testing.T) {
+	root := parse(t, `{"a": 1, "b": 2, "a": 3}`)
+	obj := root.Value.Value.(*ast.Object)
+
+	assert.Equal(t, map[string]int{"a": 2, "b": 1}, obj.Index())
+
+	obj.Set("c", &ast.Value{Value: ast.Number(4)})
+	assert.Equal(t, map[string]int{"a": 2, "b": 1, "c": 3}, obj.Index())
+
+	obj.Set("b", &ast.Value{Value: ast.Number(5)})
+	b, ok := obj.Get("b")
+	assert.True(t, ok)
+	assert.Equal(t, int64(5), b.ToGo())
+
+	removed, err := obj.Delete("a")
+	assert.Nil(t, err)
+	assert.True(t, removed)
+	removed, err = obj.Delete("a")
+	assert.Nil(t, err)
+	assert.False(t, removed)
+	assert.Equal(t, map[string]int{"b": 0, "c": 1}, obj.Index())
+	_, ok = obj.Get("a")
+	assert.False(t, ok)
+	assert.Equal(t, []string{"b", "c"}, obj.Keys())
+}
+
+func TestObject_Properties(t *testing.T) {
+	root := parse(t, `{"a": 1, "b": "x", "a": 2}`)
+	obj := root.Value.Value.(*ast.Object)
+
+	var keys []string
+	var values []any
+	for key, v := range obj.Properties() {
+		keys = append(keys, key)
+		values = append(values, v.ToGo())
+	}
+	assert.Equal(t, []string{"a", "b", "a"}, keys)
+	assert.Equal(t, []any{int64(1), "x", int64(2)}, values)
+
+	for key := range obj.Properties() {
+		assert.Equal(t, "a", key)
+		break
+	}
+}
+
+func TestArray_Values(t *testing.T) {
+	root := parse(t, `[1, [true], null]`)
+	array := root.Value.Value.(*ast.Array)
+
+	var values []any
+	for v := range array.Values() {
+		values = append(values, v.ToGo())
+	}
+	assert.Equal(t, []any{int64(1), []any{true}, nil}, values)
+
+	n := 0
+	for range array.Values() {
+		n++
+		break
+	}
+	assert.Equal(t, 1, n)
+}
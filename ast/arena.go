@@ -0,0 +1,81 @@
+package ast
+
+// Arena allocates the nodes of documents in large blocks instead of one
+// by one, see parser.WithArena. Services parsing and discarding many
+// documents make far fewer allocations, and Reset releases all the
+// nodes of an arena at once for reuse. The zero value is ready to use.
+//
+// The child slices of objects and arrays are not allocated by the arena.
+// An Arena must not be used concurrently.
+type Arena struct {
+	values    slab[Value]
+	objects   slab[Object]
+	arrays    slab[Array]
+	literals  slab[Literal]
+	rawValues slab[RawValue]
+}
+
+// Value returns a new zero Value.
+func (a *Arena) Value() *Value {
+	return a.values.alloc()
+}
+
+// Object returns a new zero Object.
+func (a *Arena) Object() *Object {
+	return a.objects.alloc()
+}
+
+// Array returns a new zero Array.
+func (a *Arena) Array() *Array {
+	return a.arrays.alloc()
+}
+
+// Literal returns a new zero Literal.
+func (a *Arena) Literal() *Literal {
+	return a.literals.alloc()
+}
+
+// RawValue returns a new zero RawValue.
+func (a *Arena) RawValue() *RawValue {
+	return a.rawValues.alloc()
+}
+
+// Reset zeroes all the nodes allocated so far and makes their memory
+// available to later allocations. Nodes allocated before Reset, and
+// documents holding them, must not be used afterwards.
+func (a *Arena) Reset() {
+	a.values.reset()
+	a.objects.reset()
+	a.arrays.reset()
+	a.literals.reset()
+	a.rawValues.reset()
+}
+
+// slabSize is the number of nodes of a block.
+const slabSize = 256
+
+// slab allocates values of type T from blocks of slabSize values.
+type slab[T any] struct {
+	blocks [][]T
+	next   int // Index in blocks of the block to allocate from.
+}
+
+func (s *slab[T]) alloc() *T {
+	for s.next < len(s.blocks) && len(s.blocks[s.next]) == cap(s.blocks[s.next]) {
+		s.next++
+	}
+	if s.next == len(s.blocks) {
+		s.blocks = append(s.blocks, make([]T, 0, slabSize))
+	}
+	block := &s.blocks[s.next]
+	*block = (*block)[:len(*block)+1]
+	return &(*block)[len(*block)-1]
+}
+
+func (s *slab[T]) reset() {
+	for i, block := range s.blocks {
+		clear(block)
+		s.blocks[i] = block[:0]
+	}
+	s.next = 0
+}
@@ -0,0 +1,32 @@
+package ast_test
+
+import (
+	"testing"
+
+	"github.com/pohedev/gj.git/ast"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArena(t *testing.T) {
+	var a ast.Arena
+	first := a.Literal()
+	*first = *ast.String("x")
+	seen := map[*ast.Literal]bool{first: true}
+	for i := 0; i < 1000; i++ {
+		lit := a.Literal()
+		assert.Equal(t, &ast.Literal{}, lit)
+		assert.False(t, seen[lit])
+		seen[lit] = true
+	}
+
+	a.Reset()
+	assert.Equal(t, &ast.Literal{}, first)
+	assert.Same(t, first, a.Literal(), "memory is reused after Reset")
+
+	obj := a.Object()
+	obj.Children = append(obj.Children, ast.Property{Identifier: ast.Identifier{Value: "k"}, Value: &ast.Value{Value: ast.Null()}})
+	assert.Equal(t, &ast.Value{Value: obj}, &ast.Value{Value: obj})
+	assert.NotSame(t, a.Value(), a.Value())
+	assert.NotSame(t, a.Array(), a.Array())
+	assert.NotSame(t, a.RawValue(), a.RawValue())
+}
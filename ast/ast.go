@@ -4,14 +4,20 @@ package ast
 type RootNodeType int
 
 const (
-	RootNodeTypeObject RootNodeType = iota + 1 // object
-	RootNodeTypeArray                          // array
+	RootNodeTypeObject  RootNodeType = iota + 1 // object
+	RootNodeTypeArray                           // array
+	RootNodeTypeLiteral                         // string, number, boolean or null
 )
 
 // RootNode represents a what JSON starts every parsed AST.
 type RootNode struct {
 	RootNodeType
 	*Value
+
+	Before string // Trivia before the root value, see parser.Lossless.
+	After  string // Trivia after the root value.
+
+	frozen bool // See Freeze.
 }
 
 // LiteralType identifies the type of JSON Literal.
@@ -29,6 +35,10 @@ const (
 type Literal struct {
 	LiteralType
 	Val any
+	Raw string // Original source of a string or number literal, quotes and escapes included; empty if not parsed from source.
+
+	Kind  string // Kind given by the recognizer of the literal, see parser.Registry; empty if not recognized.
+	Typed any    // Domain-specific value given by the recognizer, such as a time.Time; Val is kept.
 }
 
 // State identifies the type of parsing JSON state.
@@ -56,17 +66,38 @@ type Object struct {
 	Children []Property
 	Start    int
 	End      int
+	Trivia   string // Trivia before the closing brace, after the line of the last property, see parser.Lossless.
+
+	index  map[string]int // Lazily built by Index, see there.
+	frozen bool           // See RootNode.Freeze.
 }
 
 // Property represents a JSON object property.
 type Property struct {
 	Identifier
-	Value any
+	Value  any
+	Trivia PropertyTrivia
+}
+
+// PropertyTrivia holds the trivia around the tokens of a property, that is
+// the source text between them, recorded by parser.Lossless. Trivia after
+// the comma preceding a property is its BeforeKey. The last property of an
+// object keeps the trivia up to the end of its line as AfterValue, the
+// rest belongs to the object, so that properties can be moved along with
+// their trivia, see Object.Move.
+type PropertyTrivia struct {
+	BeforeKey   string
+	BeforeColon string
+	BeforeValue string
+	AfterValue  string
 }
 
 // Identifier represents a key identifier of JSON object property.
 type Identifier struct {
 	Value string
+	Start int    // Byte offset of the opening quote of the key.
+	End   int    // Byte offset just after the closing quote of the key.
+	Raw   string // Source of the key, quotes and escapes included; set by parser.Lossless.
 }
 
 // Array represents a JSON array.
@@ -74,11 +105,24 @@ type Array struct {
 	Children []ArrayItem
 	Start    int
 	End      int
+	Trivia   string // Trivia before the closing bracket, after the line of the last item, see parser.Lossless.
+
+	frozen bool // See RootNode.Freeze.
 }
 
 // ArrayItem represents a value of JSON array.
 type ArrayItem struct {
-	Value any
+	Value  any
+	Before string // Trivia before the value, see parser.Lossless.
+	After  string // Trivia after the value, before the following comma or up to the end of the line.
+}
+
+// RawValue represents an object or array kept as unparsed source text
+// by lazy parsing, see parser.Lazy and parser.ParseRaw.
+type RawValue struct {
+	Raw   string // Source text of the value.
+	Start int    // Byte offset of the value in the input.
+	End   int    // Byte offset just after the value in the input.
 }
 
 // Value represents a value of JSON value
@@ -0,0 +1,32 @@
+package ast
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// AsBytes returns the base64-decoded value of a string literal. Both the
+// standard and the URL-safe alphabets are accepted, with or without
+// padding. The error of an invalid string reports the offset of the
+// first invalid byte in the string; literals do not record where they sit
+// in the document, so the error cannot tell, but gj.Decode reports the
+// JSON Pointer of the literal in its *TypeError.
+func (l *Literal) AsBytes() ([]byte, error) {
+	s, ok := l.AsString()
+	if !ok {
+		return nil, fmt.Errorf("failed to decode base64: %s is not a string", describe(l))
+	}
+	enc := base64.StdEncoding
+	if strings.ContainsAny(s, "-_") {
+		enc = base64.URLEncoding
+	}
+	if !strings.HasSuffix(s, "=") {
+		enc = enc.WithPadding(base64.NoPadding)
+	}
+	b, err := enc.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode base64: %w", err)
+	}
+	return b, nil
+}
@@ -0,0 +1,39 @@
+package ast
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLiteral_AsBytes(t *testing.T) {
+	data := []byte{0xfb, 0xff, 0xbf, 'g', 'j'}
+	tests := []struct {
+		name string
+		lit  *Literal
+		want []byte
+		err  string
+	}{
+		{"std", String(base64.StdEncoding.EncodeToString(data)), data, ""},
+		{"std raw", String(base64.RawStdEncoding.EncodeToString(data)), data, ""},
+		{"url", String(base64.URLEncoding.EncodeToString(data)), data, ""},
+		{"url raw", String(base64.RawURLEncoding.EncodeToString(data)), data, ""},
+		{"empty", String(""), []byte{}, ""},
+		{"invalid", String("aGVs*G8="), nil, "failed to decode base64: illegal base64 data at input byte 4"},
+		{"mixed alphabets", String("+/-_"), nil, "failed to decode base64: illegal base64 data at input byte 0"},
+		{"number", Number(1), nil, "failed to decode base64: Number 1 is not a string"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.lit.AsBytes()
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
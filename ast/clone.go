@@ -0,0 +1,47 @@
+package ast
+
+// Clone returns a deep copy of the tree, trivia included. The copy is not
+// frozen, whether the tree is or not, and shares nothing with it but the
+// immutable values of literals.
+func (r *RootNode) Clone() *RootNode {
+	if r == nil {
+		return nil
+	}
+	c := &RootNode{RootNodeType: r.RootNodeType, Before: r.Before, After: r.After}
+	if r.Value != nil {
+		c.Value = &Value{Value: clone(r.Value.Value)}
+	}
+	return c
+}
+
+// clone returns a deep copy of node.
+func clone(node any) any {
+	switch n := node.(type) {
+	case *Value:
+		if n == nil {
+			return n
+		}
+		return &Value{Value: clone(n.Value)}
+	case *Object:
+		c := &Object{Children: make([]Property, len(n.Children)), Start: n.Start, End: n.End, Trivia: n.Trivia}
+		for i, prop := range n.Children {
+			prop.Value = clone(prop.Value)
+			c.Children[i] = prop
+		}
+		return c
+	case *Array:
+		c := &Array{Children: make([]ArrayItem, len(n.Children)), Start: n.Start, End: n.End, Trivia: n.Trivia}
+		for i, item := range n.Children {
+			item.Value = clone(item.Value)
+			c.Children[i] = item
+		}
+		return c
+	case *Literal:
+		l := *n
+		return &l
+	case *RawValue:
+		raw := *n
+		return &raw
+	}
+	return node
+}
@@ -0,0 +1,21 @@
+package ast_test
+
+import (
+	"testing"
+
+	"github.com/pohedev/gj.git/ast"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRootNode_Clone(t *testing.T) {
+	root := parseLossless(t, "{\"a\": [ 1, {\"b\": \"x\"} ]\n}")
+	root.Freeze()
+	c := root.Clone()
+	assert.Equal(t, printLossless(t, root), printLossless(t, c))
+	assert.False(t, c.Frozen())
+
+	obj := c.Value.Value.(*ast.Object)
+	assert.Nil(t, obj.Set("a", &ast.Value{Value: ast.Null()}))
+	assert.Equal(t, map[string]any{"a": []any{int64(1), map[string]any{"b": "x"}}}, root.Value.ToGo())
+	assert.Nil(t, (*ast.RootNode)(nil).Clone())
+}
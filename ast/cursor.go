@@ -0,0 +1,133 @@
+package ast
+
+import "strconv"
+
+// Cursor walks a tree of nodes iteratively, without recursion or type
+// switches. A Cursor is positioned on a value; it moves to the children
+// of objects and arrays with Enter, between siblings with Next and back
+// to the parent with Up.
+type Cursor struct {
+	root    *Value
+	parents []cursorFrame // enclosing containers, innermost last.
+}
+
+// cursorFrame is a container enclosing the current value
+// and the position of the value among its children.
+type cursorFrame struct {
+	node  any // *Object or *Array.
+	index int
+}
+
+// NewCursor returns a Cursor positioned on root.
+func NewCursor(root *Value) *Cursor {
+	return &Cursor{root: root}
+}
+
+// Value returns the current value.
+func (c *Cursor) Value() *Value {
+	if len(c.parents) == 0 {
+		return c.root
+	}
+	f := c.parents[len(c.parents)-1]
+	switch n := f.node.(type) {
+	case *Object:
+		return asValue(n.Children[f.index].Value)
+	case *Array:
+		return asValue(n.Children[f.index].Value)
+	}
+	return nil
+}
+
+// Key returns the key of the current value when its parent is an object.
+func (c *Cursor) Key() (string, bool) {
+	if len(c.parents) == 0 {
+		return "", false
+	}
+	f := c.parents[len(c.parents)-1]
+	if o, ok := f.node.(*Object); ok {
+		return o.Children[f.index].Identifier.Value, true
+	}
+	return "", false
+}
+
+// Index returns the position of the current value among its siblings,
+// 0 for the root.
+func (c *Cursor) Index() int {
+	if len(c.parents) == 0 {
+		return 0
+	}
+	return c.parents[len(c.parents)-1].index
+}
+
+// Depth returns the number of containers enclosing the current value.
+func (c *Cursor) Depth() int {
+	return len(c.parents)
+}
+
+// Path returns the JSON Pointer of the current value.
+func (c *Cursor) Path() string {
+	path := ""
+	for _, f := range c.parents {
+		if o, ok := f.node.(*Object); ok {
+			path = appendPointer(path, o.Children[f.index].Identifier.Value)
+		} else {
+			path = appendPointer(path, strconv.Itoa(f.index))
+		}
+	}
+	return path
+}
+
+// Enter moves to the first child of the current object or array
+// and reports whether there was one.
+func (c *Cursor) Enter() bool {
+	v := c.Value()
+	if v == nil {
+		return false
+	}
+	switch n := unwrap(v.Value).(type) {
+	case *Object:
+		if len(n.Children) == 0 {
+			return false
+		}
+		c.parents = append(c.parents, cursorFrame{node: n})
+	case *Array:
+		if len(n.Children) == 0 {
+			return false
+		}
+		c.parents = append(c.parents, cursorFrame{node: n})
+	default:
+		return false
+	}
+	return true
+}
+
+// Next moves to the next sibling of the current value
+// and reports whether there was one.
+func (c *Cursor) Next() bool {
+	if len(c.parents) == 0 {
+		return false
+	}
+	f := &c.parents[len(c.parents)-1]
+	n := 0
+	switch node := f.node.(type) {
+	case *Object:
+		n = len(node.Children)
+	case *Array:
+		n = len(node.Children)
+	}
+	if f.index+1 >= n {
+		return false
+	}
+	f.index++
+	return true
+}
+
+// Up moves to the parent of the current value
+// and reports whether there was one.
+func (c *Cursor) Up() bool {
+	if len(c.parents) == 0 {
+		return false
+	}
+	c.parents = c.parents[:len(c.parents)-1]
+	return true
+}
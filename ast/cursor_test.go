@@ -0,0 +1,61 @@
+package ast_test
+
+import (
+	"testing"
+
+	"github.com/pohedev/gj.git/ast"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCursor(t *testing.T) {
+	root := parse(t, `{"a": {"b": [1, "x"]}, "c": 2.5, "d": []}`)
+	c := ast.NewCursor(root.Value)
+	assert.Equal(t, root.Value, c.Value())
+	_, ok := c.Key()
+	assert.False(t, ok)
+	assert.False(t, c.Next())
+	assert.False(t, c.Up())
+
+	assert.True(t, c.Enter())
+	key, _ := c.Key()
+	assert.Equal(t, "a", key)
+	assert.True(t, c.Enter())
+	assert.True(t, c.Enter())
+	assert.Equal(t, int64(1), c.Value().ToGo())
+	assert.True(t, c.Next())
+	assert.Equal(t, "x", c.Value().ToGo())
+	assert.Equal(t, 1, c.Index())
+	assert.Equal(t, 3, c.Depth())
+	assert.Equal(t, "/a/b/1", c.Path())
+	_, ok = c.Key()
+	assert.False(t, ok)
+	assert.False(t, c.Next())
+
+	assert.True(t, c.Up())
+	assert.True(t, c.Up())
+	assert.True(t, c.Next())
+	key, _ = c.Key()
+	assert.Equal(t, "c", key)
+	assert.False(t, c.Enter())
+	assert.True(t, c.Next())
+	assert.False(t, c.Enter())
+	assert.False(t, c.Next())
+}
+
+func TestCursor_Walk(t *testing.T) {
+	root := parse(t, `{"a": [1, {"b": null}], "c": true}`)
+	var paths []string
+	c := ast.NewCursor(root.Value)
+	for {
+		paths = append(paths, c.Path())
+		if c.Enter() {
+			continue
+		}
+		for !c.Next() {
+			if !c.Up() {
+				assert.Equal(t, []string{"", "/a", "/a/0", "/a/1", "/a/1/b", "/c"}, paths)
+				return
+			}
+		}
+	}
+}
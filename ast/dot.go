@@ -0,0 +1,85 @@
+package ast
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ToDot returns a Graphviz DOT graph of the tree at node, which is
+// a *RootNode or any node of a tree. Each node is labelled with its type,
+// its byte span when known and, for property values, its key.
+func ToDot(node any) string {
+	var b strings.Builder
+	b.WriteString("digraph ast {\n\tnode [shape=box, fontname=monospace];\n")
+	n := 0
+	var visit func(node any, key string) int
+	visit = func(node any, key string) int {
+		id := n
+		n++
+		label := describe(node)
+		if key != "" {
+			label = key + "\n" + label
+		}
+		fmt.Fprintf(&b, "\tn%d [label=%s];\n", id, dotQuote(label))
+
+		switch x := unwrap(node).(type) {
+		case *Object:
+			for _, prop := range x.Children {
+				key := fmt.Sprintf("%q [%d:%d]", prop.Identifier.Value, prop.Identifier.Start, prop.Identifier.End)
+				child := visit(prop.Value, key)
+				fmt.Fprintf(&b, "\tn%d -> n%d;\n", id, child)
+			}
+		case *Array:
+			for i, item := range x.Children {
+				child := visit(item.Value, "")
+				fmt.Fprintf(&b, "\tn%d -> n%d [label=\"%d\"];\n", id, child, i)
+			}
+		}
+		return id
+	}
+	if root, ok := node.(*RootNode); ok {
+		node = root.Value
+	}
+	visit(node, "")
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// describe returns the type of node followed by its span or value,
+// e.g. `Object[0:24]` or `String "Ford"`.
+func describe(node any) string {
+	switch n := unwrap(node).(type) {
+	case *Object:
+		return fmt.Sprintf("Object[%d:%d]", n.Start, n.End)
+	case *Array:
+		return fmt.Sprintf("Array[%d:%d]", n.Start, n.End)
+	case *RawValue:
+		return fmt.Sprintf("Raw[%d:%d] %s", n.Start, n.End, n.Raw)
+	case *Literal:
+		switch n.LiteralType {
+		case LiteralTypeString:
+			return "String " + strconv.Quote(fmt.Sprint(n.Val))
+		case LiteralTypeNumber:
+			return fmt.Sprintf("Number %v", n.Val)
+		case LiteralTypeTrue:
+			return "True"
+		case LiteralTypeFalse:
+			return "False"
+		case LiteralTypeNull:
+			return "Null"
+		}
+	case nil:
+		return "nil"
+	}
+	return fmt.Sprintf("%T", node)
+}
+
+// dotEscaper escapes the characters of DOT quoted strings;
+// line breaks become centered line breaks of labels.
+var dotEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// dotQuote returns s as a DOT quoted string.
+func dotQuote(s string) string {
+	return `"` + dotEscaper.Replace(s) + `"`
+}
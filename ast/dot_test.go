@@ -0,0 +1,27 @@
+package ast_test
+
+import (
+	"testing"
+
+	"github.com/pohedev/gj.git/ast"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToDot(t *testing.T) {
+	root := parse(t, `{"cars": ["Ford", 1.5], "ok": null}`)
+	assert.Equal(t, `digraph ast {
+	node [shape=box, fontname=monospace];
+	n0 [label="Object[0:35]"];
+	n1 [label="\"cars\" [1:7]\nArray[9:21]"];
+	n2 [label="String \"Ford\""];
+	n1 -> n2 [label="0"];
+	n3 [label="Number 1.5"];
+	n1 -> n3 [label="1"];
+	n0 -> n1;
+	n4 [label="\"ok\" [24:28]\nNull"];
+	n0 -> n4;
+}
+`, ast.ToDot(root))
+
+	assert.Equal(t, "digraph ast {\n\tnode [shape=box, fontname=monospace];\n\tn0 [label=\"True\"];\n}\n", ast.ToDot(ast.Bool(true)))
+}
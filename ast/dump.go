@@ -0,0 +1,38 @@
+package ast
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Dump returns an indented outline of the tree at node, which is
+// a *RootNode or any node of a tree, for debugging, e.g.
+//
+//	Object[0:24]
+//	  Property "cars" [1:7]
+//	    Array[9:21]
+//	      String "Ford"
+func Dump(node any) string {
+	var b strings.Builder
+	var visit func(node any, depth int)
+	visit = func(node any, depth int) {
+		indent := strings.Repeat("  ", depth)
+		b.WriteString(indent + describe(node) + "\n")
+		switch x := unwrap(node).(type) {
+		case *Object:
+			for _, prop := range x.Children {
+				fmt.Fprintf(&b, "%s  Property %q [%d:%d]\n", indent, prop.Identifier.Value, prop.Identifier.Start, prop.Identifier.End)
+				visit(prop.Value, depth+2)
+			}
+		case *Array:
+			for _, item := range x.Children {
+				visit(item.Value, depth+1)
+			}
+		}
+	}
+	if root, ok := node.(*RootNode); ok {
+		node = root.Value
+	}
+	visit(node, 0)
+	return b.String()
+}
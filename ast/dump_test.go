@@ -0,0 +1,28 @@
+package ast_test
+
+import (
+	"testing"
+
+	"github.com/pohedev/gj.git/ast"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDump(t *testing.T) {
+	root := parse(t, `{"cars": ["Ford", {"n": -2}, []], "ok": true, "x": "a\"b"}`)
+	assert.Equal(t, `Object[0:58]
+  Property "cars" [1:7]
+    Array[9:31]
+      String "Ford"
+      Object[18:27]
+        Property "n" [19:22]
+          Number -2
+      Array[29:30]
+  Property "ok" [34:38]
+    True
+  Property "x" [46:49]
+    String "a\"b"
+`, ast.Dump(root))
+
+	assert.Equal(t, "Null\n", ast.Dump(ast.Null()))
+	assert.Equal(t, "Raw[3:9] [1, 2]\n", ast.Dump(&ast.RawValue{Raw: "[1, 2]", Start: 3, End: 9}))
+}
@@ -0,0 +1,70 @@
+package ast
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Found represents a property matched by FindAll or FindFunc.
+type Found struct {
+	Path     string    // JSON Pointer of the property value.
+	Property *Property // Matched property.
+}
+
+// FindAll returns every property whose key equals key at any depth,
+// in document order.
+func FindAll(root *RootNode, key string) []Found {
+	return FindFunc(root, func(_ string, p *Property) bool {
+		return p.Identifier.Value == key
+	})
+}
+
+// FindFunc returns every property for which match reports true at any depth,
+// in document order. match receives the JSON Pointer of the property value.
+func FindFunc(root *RootNode, match func(path string, p *Property) bool) []Found {
+	if root == nil || root.Value == nil {
+		return nil
+	}
+	var found []Found
+	find(root.Value, "", match, &found)
+	return found
+}
+
+// find walks node recursively and appends matched properties to found.
+func find(node any, path string, match func(string, *Property) bool, found *[]Found) {
+	switch n := unwrap(node).(type) {
+	case *Object:
+		for i := range n.Children {
+			prop := &n.Children[i]
+			propPath := appendPointer(path, prop.Identifier.Value)
+			if match(propPath, prop) {
+				*found = append(*found, Found{Path: propPath, Property: prop})
+			}
+			find(prop.Value, propPath, match, found)
+		}
+	case *Array:
+		for i := range n.Children {
+			find(n.Children[i].Value, appendPointer(path, strconv.Itoa(i)), match, found)
+		}
+	}
+}
+
+// unwrap strips Value wrappers and returns the underlying
+// *Object, *Array or *Literal.
+func unwrap(node any) any {
+	for {
+		v, ok := node.(*Value)
+		if !ok || v == nil {
+			return node
+		}
+		node = v.Value
+	}
+}
+
+// pointerEscaper escapes a reference token of JSON Pointer (RFC 6901).
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// appendPointer appends an escaped reference token to JSON Pointer path.
+func appendPointer(path, token string) string {
+	return path + "/" + pointerEscaper.Replace(token)
+}
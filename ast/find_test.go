@@ -0,0 +1,45 @@
+package ast_test
+
+import (
+	"testing"
+
+	"github.com/pohedev/gj.git/ast"
+	"github.com/pohedev/gj.git/lexer"
+	"github.com/pohedev/gj.git/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+func parse(t *testing.T, input string) *ast.RootNode {
+	t.Helper()
+	root, err := parser.New(lexer.Lex(input)).Parse()
+	if err != nil {
+		t.Fatalf("failed to parse %q: %v", input, err)
+	}
+	return root
+}
+
+func TestFindAll(t *testing.T) {
+	root := parse(t, `{"id": 1, "items": [{"id": 2}, {"name": "x", "a/b": {"id": 3}}]}`)
+
+	found := ast.FindAll(root, "id")
+
+	var paths []string
+	for _, f := range found {
+		paths = append(paths, f.Path)
+		assert.Equal(t, "id", f.Property.Identifier.Value)
+	}
+	assert.Equal(t, []string{"/id", "/items/0/id", "/items/1/a~1b/id"}, paths)
+}
+
+func TestFindFunc(t *testing.T) {
+	root := parse(t, `[{"a": {"b": true}}, {"c": null}]`)
+
+	found := ast.FindFunc(root, func(path string, _ *ast.Property) bool {
+		return path == "/0/a/b" || path == "/1/c"
+	})
+
+	assert.Len(t, found, 2)
+	assert.Equal(t, "b", found[0].Property.Identifier.Value)
+	assert.Equal(t, "c", found[1].Property.Identifier.Value)
+	assert.Empty(t, ast.FindAll(root, "missing"))
+}
@@ -0,0 +1,44 @@
+package ast
+
+import "errors"
+
+// ErrFrozen is returned by the mutation methods of objects and arrays
+// of a tree marked read-only by RootNode.Freeze.
+var ErrFrozen = errors.New("tree is frozen")
+
+// Freeze marks the tree read-only: Object.Set, Object.Insert, Object.Move,
+// Object.Delete, Object.SortKeys, Array.Insert and Array.Move return
+// ErrFrozen, and the indexes used by Object.Get are built up front, so
+// that the tree can be shared by goroutines and cached without cloning.
+// The in-place transforms of package transform return ErrFrozen too.
+// Fields stay writable and must not be assigned. Freezing cannot be
+// undone.
+func (r *RootNode) Freeze() {
+	if r == nil {
+		return
+	}
+	r.frozen = true
+	freeze(r.Value)
+}
+
+// Frozen reports whether the tree has been frozen with Freeze.
+func (r *RootNode) Frozen() bool {
+	return r != nil && r.frozen
+}
+
+// freeze marks the objects and arrays of the tree at node read-only.
+func freeze(node any) {
+	switch n := unwrap(node).(type) {
+	case *Object:
+		n.Index()
+		n.frozen = true
+		for _, prop := range n.Children {
+			freeze(prop.Value)
+		}
+	case *Array:
+		n.frozen = true
+		for _, item := range n.Children {
+			freeze(item.Value)
+		}
+	}
+}
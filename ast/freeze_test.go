@@ -0,0 +1,53 @@
+package ast_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/pohedev/gj.git/ast"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRootNode_Freeze(t *testing.T) {
+	root := parse(t, `{"a": 1, "b": [{"c": true}]}`)
+	assert.False(t, root.Frozen())
+	root.Freeze()
+	assert.True(t, root.Frozen())
+
+	obj := root.Value.Value.(*ast.Object)
+	b, _ := obj.Get("b")
+	array := b.Value.(*ast.Array)
+	nested := array.Children[0].Value.(*ast.Object)
+
+	v := &ast.Value{Value: ast.Null()}
+	assert.ErrorIs(t, obj.Set("a", v), ast.ErrFrozen)
+	assert.ErrorIs(t, obj.Insert(0, "x", v), ast.ErrFrozen)
+	assert.ErrorIs(t, obj.Move(0, 1), ast.ErrFrozen)
+	removed, err := obj.Delete("a")
+	assert.ErrorIs(t, err, ast.ErrFrozen)
+	assert.False(t, removed)
+	assert.ErrorIs(t, nested.Set("d", v), ast.ErrFrozen)
+	assert.ErrorIs(t, array.Insert(0, v), ast.ErrFrozen)
+	assert.ErrorIs(t, array.Move(0, 0), ast.ErrFrozen)
+	obj.Reindex()
+	assert.Equal(t, map[string]any{"a": int64(1), "b": []any{map[string]any{"c": true}}}, root.Value.ToGo())
+
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 100 {
+				nested.Get("c")
+				obj.Get("a")
+			}
+		}()
+	}
+	wg.Wait()
+
+	unfrozen := parse(t, `{}`)
+	assert.Nil(t, unfrozen.Value.Value.(*ast.Object).Set("a", v))
+	var nilRoot *ast.RootNode
+	nilRoot.Freeze()
+	assert.False(t, nilRoot.Frozen())
+}
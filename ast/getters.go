@@ -0,0 +1,73 @@
+package ast
+
+import (
+	"strconv"
+
+	"github.com/pohedev/gj.git/path"
+)
+
+// GetValue returns the value at p, a dot/bracket path such as "a.b[0].c"
+// or a JSON Pointer, as accepted by path.Parse. The empty path is the root
+// value. When a key appears more than once, the last property wins. It
+// reports false when p is malformed, holds wildcards, or leads nowhere.
+func (r *RootNode) GetValue(p string) (*Value, bool) {
+	segments, err := path.Parse(p)
+	if err != nil || r == nil || r.Value == nil {
+		return nil, false
+	}
+	v := r.Value
+	for _, seg := range segments {
+		if seg.Wildcard {
+			return nil, false
+		}
+		var ok bool
+		switch n := unwrap(v).(type) {
+		case *Object:
+			v, ok = n.Get(seg.Key)
+		case *Array:
+			i, err := strconv.Atoi(seg.Key)
+			if err != nil {
+				return nil, false
+			}
+			v, ok = n.At(i)
+		}
+		if !ok {
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+// GetString returns the string at p, see GetValue.
+func (r *RootNode) GetString(p string) (string, bool) {
+	if lit, ok := r.getLiteral(p); ok {
+		return lit.AsString()
+	}
+	return "", false
+}
+
+// GetInt returns the integer at p, see GetValue.
+func (r *RootNode) GetInt(p string) (int64, bool) {
+	if lit, ok := r.getLiteral(p); ok {
+		return lit.AsInt()
+	}
+	return 0, false
+}
+
+// GetBool returns the boolean at p, see GetValue.
+func (r *RootNode) GetBool(p string) (bool, bool) {
+	if lit, ok := r.getLiteral(p); ok {
+		return lit.AsBool()
+	}
+	return false, false
+}
+
+// getLiteral returns the literal at p.
+func (r *RootNode) getLiteral(p string) (*Literal, bool) {
+	v, ok := r.GetValue(p)
+	if !ok {
+		return nil, false
+	}
+	lit, ok := unwrap(v).(*Literal)
+	return lit, ok
+}
@@ -0,0 +1,60 @@
+package ast_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRootNode_Get(t *testing.T) {
+	root := parse(t, `{"a": {"b": [{"c": "x"}, 2, true]}, "a.b": 1.5, "n": null}`)
+
+	s, ok := root.GetString("a.b[0].c")
+	assert.True(t, ok)
+	assert.Equal(t, "x", s)
+
+	i, ok := root.GetInt("$.a.b[1]")
+	assert.True(t, ok)
+	assert.Equal(t, int64(2), i)
+
+	b, ok := root.GetBool("/a/b/2")
+	assert.True(t, ok)
+	assert.True(t, b)
+
+	v, ok := root.GetValue("$['a.b']")
+	assert.True(t, ok)
+	assert.Equal(t, 1.5, v.ToGo())
+
+	v, ok = root.GetValue("")
+	assert.True(t, ok)
+	assert.Same(t, root.Value, v)
+
+	var tests = []struct {
+		name string
+		path string
+	}{
+		{"missing key", "a.x"},
+		{"index out of range", "a.b[3]"},
+		{"key in array", "a.b.c"},
+		{"key in literal", "n.x"},
+		{"wildcard", "a.b[*]"},
+		{"malformed", "a..b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, ok := root.GetValue(tt.path)
+			assert.False(t, ok)
+		})
+	}
+
+	_, ok = root.GetString("a.b[1]")
+	assert.False(t, ok, "wrong type")
+	_, ok = root.GetInt("$['a.b']")
+	assert.False(t, ok, "not an integer")
+	_, ok = root.GetBool("a")
+	assert.False(t, ok, "not a literal")
+
+	s, ok = parse(t, `[{"a": "x"}, {"a": "y"}]`).GetString("[1].a")
+	assert.True(t, ok)
+	assert.Equal(t, "y", s)
+}
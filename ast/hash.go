@@ -0,0 +1,151 @@
+package ast
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"hash"
+	"slices"
+	"strconv"
+
+	"github.com/pohedev/gj.git/internal/jsonnum"
+)
+
+// HashOption configures Hash.
+type HashOption func(*hasher)
+
+// UnorderedKeys makes Hash ignore the order of the properties of objects,
+// so that {"a": 1, "b": 2} and {"b": 2, "a": 1} have the same hash.
+func UnorderedKeys() HashOption {
+	return func(h *hasher) {
+		h.unorderedKeys = true
+	}
+}
+
+// CanonicalNumbers makes Hash compare numbers by decimal value instead
+// of source text, so that 100, 1e2 and 1.0E+2 have the same hash.
+func CanonicalNumbers() HashOption {
+	return func(h *hasher) {
+		h.canonicalNumbers = true
+	}
+}
+
+// hasher holds the options of Hash.
+type hasher struct {
+	unorderedKeys    bool
+	canonicalNumbers bool
+}
+
+// Hash returns the SHA-256 content hash of the tree at node, which is
+// a *RootNode or any node of a tree, for deduplicating and caching
+// documents without printing them. Positions are ignored; strings are
+// compared by decoded value and numbers by source text unless
+// CanonicalNumbers is given. Properties are hashed in order, duplicate
+// keys included, unless UnorderedKeys is given. RawValue nodes are
+// hashed by source text, and so differ from their parsed value.
+func Hash(node any, opts ...HashOption) [32]byte {
+	h := hasher{}
+	for _, opt := range opts {
+		opt(&h)
+	}
+	if root, ok := node.(*RootNode); ok {
+		node = root.Value
+	}
+	d := sha256.New()
+	h.write(d, node)
+	var sum [32]byte
+	d.Sum(sum[:0])
+	return sum
+}
+
+// write writes a tagged, length-prefixed encoding of node to d.
+func (h *hasher) write(d hash.Hash, node any) {
+	switch n := unwrap(node).(type) {
+	case *Object:
+		writeTag(d, 'o', len(n.Children))
+		if !h.unorderedKeys {
+			for _, prop := range n.Children {
+				writeString(d, prop.Identifier.Value)
+				h.write(d, prop.Value)
+			}
+			return
+		}
+		// Properties are hashed separately and written in sorted order.
+		sums := make([][sha256.Size]byte, len(n.Children))
+		for i, prop := range n.Children {
+			pd := sha256.New()
+			writeString(pd, prop.Identifier.Value)
+			h.write(pd, prop.Value)
+			pd.Sum(sums[i][:0])
+		}
+		slices.SortFunc(sums, func(a, b [sha256.Size]byte) int {
+			return bytes.Compare(a[:], b[:])
+		})
+		for _, sum := range sums {
+			d.Write(sum[:])
+		}
+
+	case *Array:
+		writeTag(d, 'a', len(n.Children))
+		for _, item := range n.Children {
+			h.write(d, item.Value)
+		}
+
+	case *RawValue:
+		writeTag(d, 'r', 0)
+		writeString(d, n.Raw)
+
+	case *Literal:
+		switch n.LiteralType {
+		case LiteralTypeString:
+			s, _ := n.AsString()
+			writeTag(d, 's', 0)
+			writeString(d, s)
+		case LiteralTypeNumber:
+			writeTag(d, 'n', 0)
+			writeString(d, h.number(n))
+		case LiteralTypeTrue:
+			writeTag(d, 't', 0)
+		case LiteralTypeFalse:
+			writeTag(d, 'f', 0)
+		default:
+			writeTag(d, 'z', 0)
+		}
+
+	default:
+		writeTag(d, 'z', 0)
+	}
+}
+
+// number returns the text hashed for number literal n.
+func (h *hasher) number(n *Literal) string {
+	if h.canonicalNumbers {
+		if s, ok := jsonnum.CanonicalValue(n.Raw, n.Val); ok {
+			return s
+		}
+	}
+	if n.Raw != "" {
+		return n.Raw
+	}
+	switch v := n.Val.(type) {
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+	return ""
+}
+
+// writeTag writes the type tag of a node and its number of children.
+func writeTag(d hash.Hash, tag byte, n int) {
+	var buf [1 + binary.MaxVarintLen64]byte
+	buf[0] = tag
+	d.Write(buf[:1+binary.PutUvarint(buf[1:], uint64(n))])
+}
+
+// writeString writes s prefixed with its length.
+func writeString(d hash.Hash, s string) {
+	var buf [binary.MaxVarintLen64]byte
+	d.Write(buf[:binary.PutUvarint(buf[:], uint64(len(s)))])
+	d.Write([]byte(s))
+}
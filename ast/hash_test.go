@@ -0,0 +1,44 @@
+package ast_test
+
+import (
+	"testing"
+
+	"github.com/pohedev/gj.git/ast"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHash(t *testing.T) {
+	var tests = []struct {
+		name  string
+		a, b  string
+		opts  []ast.HashOption
+		equal bool
+	}{
+		{"same document", `{"a": [1, "x", null]}`, `{ "a" : [ 1 , "x" , null ] }`, nil, true},
+		{"escaped strings", `["A"]`, `["\u0041"]`, nil, true},
+		{"different values", `{"a": 1}`, `{"a": 2}`, nil, false},
+		{"different types", `["1"]`, `[1]`, nil, false},
+		{"booleans", `[true]`, `[false]`, nil, false},
+		{"key order", `{"a": 1, "b": 2}`, `{"b": 2, "a": 1}`, nil, false},
+		{"unordered keys", `{"a": 1, "b": {"c": 3, "d": 4}}`, `{"b": {"d": 4, "c": 3}, "a": 1}`, []ast.HashOption{ast.UnorderedKeys()}, true},
+		{"unordered keys values", `{"a": 1, "b": 2}`, `{"a": 2, "b": 1}`, []ast.HashOption{ast.UnorderedKeys()}, false},
+		{"array order", `[1, 2]`, `[2, 1]`, []ast.HashOption{ast.UnorderedKeys()}, false},
+		{"number text", `[100]`, `[1.0E+2]`, nil, false},
+		{"canonical numbers", `[100, -0]`, `[1.0E+2, 0]`, []ast.HashOption{ast.CanonicalNumbers()}, true},
+		{"nesting", `[[1], 2]`, `[[1, 2]]`, nil, false},
+		{"keys and values", `{"ab": "c"}`, `{"a": "bc"}`, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := ast.Hash(parse(t, tt.a), tt.opts...)
+			b := ast.Hash(parse(t, tt.b), tt.opts...)
+			assert.Equal(t, tt.equal, a == b)
+		})
+	}
+
+	t.Run("constructed nodes", func(t *testing.T) {
+		obj := &ast.Object{}
+		obj.Set("n", &ast.Value{Value: ast.Number(100)})
+		assert.Equal(t, ast.Hash(parse(t, `{"n": 100}`)), ast.Hash(obj))
+	})
+}
@@ -0,0 +1,63 @@
+package ast
+
+// Null returns a null literal. Its Val is nil.
+func Null() *Literal {
+	return &Literal{LiteralType: LiteralTypeNull}
+}
+
+// Bool returns a true or false literal.
+func Bool(b bool) *Literal {
+	if b {
+		return &Literal{LiteralType: LiteralTypeTrue, Val: true}
+	}
+	return &Literal{LiteralType: LiteralTypeFalse, Val: false}
+}
+
+// Number returns a number literal. Integers are stored as int64,
+// floating point numbers as float64.
+func Number[T ~int | ~int32 | ~int64 | ~float32 | ~float64](n T) *Literal {
+	if one := T(1); one/2 == 0 {
+		return &Literal{LiteralType: LiteralTypeNumber, Val: int64(n)}
+	}
+	return &Literal{LiteralType: LiteralTypeNumber, Val: float64(n)}
+}
+
+// String returns a string literal.
+func String(s string) *Literal {
+	return &Literal{LiteralType: LiteralTypeString, Val: s}
+}
+
+// IsNull reports whether the literal is null.
+func (l *Literal) IsNull() bool {
+	return l.LiteralType == LiteralTypeNull
+}
+
+// AsString returns the value of a string literal.
+func (l *Literal) AsString() (string, bool) {
+	s, ok := l.Val.(string)
+	return s, ok && l.LiteralType == LiteralTypeString
+}
+
+// AsBool returns the value of a true or false literal.
+func (l *Literal) AsBool() (bool, bool) {
+	b, ok := l.Val.(bool)
+	return b, ok
+}
+
+// AsInt returns the value of a number literal holding an integer.
+func (l *Literal) AsInt() (int64, bool) {
+	i, ok := l.Val.(int64)
+	return i, ok
+}
+
+// AsFloat returns the value of a number literal as float64,
+// converting integers.
+func (l *Literal) AsFloat() (float64, bool) {
+	switch v := l.Val.(type) {
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
@@ -0,0 +1,42 @@
+package ast
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLiteralConstructors(t *testing.T) {
+	assert.Equal(t, &Literal{LiteralType: LiteralTypeNull}, Null())
+	assert.Equal(t, &Literal{LiteralType: LiteralTypeTrue, Val: true}, Bool(true))
+	assert.Equal(t, &Literal{LiteralType: LiteralTypeFalse, Val: false}, Bool(false))
+	assert.Equal(t, &Literal{LiteralType: LiteralTypeNumber, Val: int64(3)}, Number(3))
+	assert.Equal(t, &Literal{LiteralType: LiteralTypeNumber, Val: 1.5}, Number(1.5))
+	assert.Equal(t, &Literal{LiteralType: LiteralTypeString, Val: "x"}, String("x"))
+}
+
+func TestLiteralAccessors(t *testing.T) {
+	assert.True(t, Null().IsNull())
+
+	s, ok := String("x").AsString()
+	assert.True(t, ok)
+	assert.Equal(t, "x", s)
+
+	b, ok := Bool(false).AsBool()
+	assert.True(t, ok)
+	assert.False(t, b)
+
+	i, ok := Number(7).AsInt()
+	assert.True(t, ok)
+	assert.Equal(t, int64(7), i)
+
+	_, ok = Number(7.5).AsInt()
+	assert.False(t, ok)
+
+	f, ok := Number(7).AsFloat()
+	assert.True(t, ok)
+	assert.Equal(t, 7.0, f)
+
+	_, ok = Null().AsString()
+	assert.False(t, ok)
+}
@@ -0,0 +1,197 @@
+package ast
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/pohedev/gj.git/internal/jsonscan"
+	"github.com/pohedev/gj.git/internal/jsonstr"
+	"github.com/pohedev/gj.git/lexer"
+)
+
+// MarshalJSON implements json.Marshaler, so that documents can be
+// embedded in structs serialized by encoding/json, by value or pointer.
+// The output is the compact JSON text of the document, with numbers and
+// strings written from source when parsed from it. A nil value is null.
+func (r RootNode) MarshalJSON() ([]byte, error) {
+	b, err := appendJSON(nil, r.Value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal document: %w", err)
+	}
+	return b, nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler. It replaces r with the
+// document in data, with the positions of its nodes relative to data.
+// The End of objects and arrays is the offset of their closing bracket.
+func (r *RootNode) UnmarshalJSON(data []byte) error {
+	b := &builder{}
+	if err := jsonscan.Scan(lexer.Lex(string(data)), b); err != nil {
+		return fmt.Errorf("failed to unmarshal document: %w", err)
+	}
+	*r = RootNode{RootNodeType: RootNodeTypeLiteral, Value: &Value{Value: b.root}}
+	switch b.root.(type) {
+	case *Object:
+		r.RootNodeType = RootNodeTypeObject
+	case *Array:
+		r.RootNodeType = RootNodeTypeArray
+	}
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler like MarshalJSON.
+func (r RootNode) MarshalText() ([]byte, error) {
+	return r.MarshalJSON()
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler like UnmarshalJSON.
+func (r *RootNode) UnmarshalText(text []byte) error {
+	return r.UnmarshalJSON(text)
+}
+
+// appendJSON appends node to b as compact JSON.
+func appendJSON(b []byte, node any) ([]byte, error) {
+	switch n := node.(type) {
+	case *Value:
+		if n == nil {
+			return append(b, "null"...), nil
+		}
+		return appendJSON(b, n.Value)
+	case *Object:
+		b = append(b, '{')
+		for i, prop := range n.Children {
+			if i > 0 {
+				b = append(b, ',')
+			}
+			b = append(jsonstr.AppendQuote(b, prop.Identifier.Value, false, false), ':')
+			var err error
+			if b, err = appendJSON(b, prop.Value); err != nil {
+				return nil, err
+			}
+		}
+		return append(b, '}'), nil
+	case *Array:
+		b = append(b, '[')
+		for i, item := range n.Children {
+			if i > 0 {
+				b = append(b, ',')
+			}
+			var err error
+			if b, err = appendJSON(b, item.Value); err != nil {
+				return nil, err
+			}
+		}
+		return append(b, ']'), nil
+	case *RawValue:
+		return append(b, n.Raw...), nil
+	case *Literal:
+		return appendLiteral(b, n)
+	case nil:
+		return append(b, "null"...), nil
+	}
+	return nil, fmt.Errorf("unsupported node %T", node)
+}
+
+// appendLiteral appends lit to b as JSON.
+func appendLiteral(b []byte, lit *Literal) ([]byte, error) {
+	switch v := lit.Val.(type) {
+	case nil:
+		return append(b, "null"...), nil
+	case bool:
+		return strconv.AppendBool(b, v), nil
+	case string:
+		if lit.Raw != "" {
+			return append(b, lit.Raw...), nil
+		}
+		return jsonstr.AppendQuote(b, v, false, false), nil
+	case int64:
+		if lit.Raw != "" {
+			return append(b, lit.Raw...), nil
+		}
+		return strconv.AppendInt(b, v, 10), nil
+	case float64:
+		if lit.Raw != "" {
+			return append(b, lit.Raw...), nil
+		}
+		if math.IsInf(v, 0) || math.IsNaN(v) {
+			return nil, fmt.Errorf("unsupported number %v", v)
+		}
+		return strconv.AppendFloat(b, v, 'g', -1, 64), nil
+	}
+	return nil, fmt.Errorf("unsupported literal value %T", lit.Val)
+}
+
+// builder builds a tree from the values read by jsonscan.Scan, keeping
+// the source text of strings and numbers and the positions of nodes.
+type builder struct {
+	root  any
+	stack []any       // Open objects and arrays, innermost last.
+	key   *Identifier // Key of the next property of the innermost object.
+}
+
+// add adds node to the innermost container, or makes it the root.
+func (b *builder) add(node any) {
+	if len(b.stack) == 0 {
+		b.root = node
+		return
+	}
+	switch c := b.stack[len(b.stack)-1].(type) {
+	case *Object:
+		c.Children = append(c.Children, Property{Identifier: *b.key, Value: &Value{Value: node}})
+	case *Array:
+		c.Children = append(c.Children, ArrayItem{Value: node})
+	}
+}
+
+func (b *builder) Null(item lexer.Item) {
+	b.add(Null())
+}
+
+func (b *builder) Bool(item lexer.Item, v bool) {
+	b.add(Bool(v))
+}
+
+func (b *builder) Number(item lexer.Item) {
+	var lit *Literal
+	if i, err := strconv.ParseInt(item.Val, 10, 64); err == nil {
+		lit = Number(i)
+	} else {
+		f, _ := strconv.ParseFloat(item.Val, 64)
+		lit = Number(f)
+	}
+	lit.Raw = item.Val
+	b.add(lit)
+}
+
+func (b *builder) String(item lexer.Item, s string) {
+	lit := String(s)
+	lit.Raw = item.Val
+	b.add(lit)
+}
+
+func (b *builder) BeginObject(item lexer.Item) {
+	obj := &Object{Start: item.Pos}
+	b.add(obj)
+	b.stack = append(b.stack, obj)
+}
+
+func (b *builder) Key(item lexer.Item, s string) {
+	b.key = &Identifier{Value: s, Start: item.Pos, End: item.Pos + len(item.Val)}
+}
+
+func (b *builder) EndObject(item lexer.Item, n int) {
+	b.stack[len(b.stack)-1].(*Object).End = item.Pos
+	b.stack = b.stack[:len(b.stack)-1]
+}
+
+func (b *builder) BeginArray(item lexer.Item) {
+	array := &Array{Start: item.Pos}
+	b.add(array)
+	b.stack = append(b.stack, array)
+}
+
+func (b *builder) EndArray(item lexer.Item, n int) {
+	b.stack[len(b.stack)-1].(*Array).End = item.Pos
+	b.stack = b.stack[:len(b.stack)-1]
+}
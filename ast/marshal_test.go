@@ -0,0 +1,105 @@
+package ast_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/pohedev/gj.git/ast"
+	"github.com/pohedev/gj.git/lexer"
+	"github.com/pohedev/gj.git/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRootNode_MarshalJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		opts  []parser.Option
+		want  string
+	}{
+		{"object", `{"b": 1.50, "a": [true, null, "xA"], "b": {}}`, nil, `{"b":1.50,"a":[true,null,"xA"],"b":{}}`},
+		{"literal", `-1e3`, nil, `-1e3`},
+		{"lazy", `{"a": [1, 2]}`, []parser.Option{parser.LazyBelow(0)}, `{"a":[1, 2]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, err := parser.New(lexer.Lex(tt.input), tt.opts...).Parse()
+			require.NoError(t, err)
+			got, err := root.MarshalJSON()
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+
+	built := &ast.RootNode{RootNodeType: ast.RootNodeTypeArray, Value: &ast.Value{Value: &ast.Array{Children: []ast.ArrayItem{
+		{Value: ast.String("<a>\n")}, {Value: ast.Number(2.5)}, {Value: ast.Number(7)},
+	}}}}
+	got, err := built.MarshalText()
+	require.NoError(t, err)
+	assert.Equal(t, `["<a>\n",2.5,7]`, string(got))
+
+	got, err = ast.RootNode{}.MarshalJSON()
+	require.NoError(t, err)
+	assert.Equal(t, "null", string(got))
+}
+
+func TestRootNode_UnmarshalJSON(t *testing.T) {
+	inputs := []string{
+		`{"a": 1, "b": [1.5, "x\n", true, false, null, {}], "a": {"c": []}}`,
+		` [ 18446744073709551616 , -0 ] `,
+		`"s"`,
+		`null`,
+	}
+	for _, input := range inputs {
+		t.Run(input, func(t *testing.T) {
+			want, err := parser.New(lexer.Lex(input)).Parse()
+			require.NoError(t, err)
+			var got ast.RootNode
+			require.NoError(t, got.UnmarshalText([]byte(input)))
+			assert.Equal(t, want.RootNodeType, got.RootNodeType)
+			assert.Equal(t, want.ToGo(), got.ToGo())
+			assert.Equal(t, ast.Hash(want), ast.Hash(&got))
+		})
+	}
+
+	var doc ast.RootNode
+	require.NoError(t, doc.UnmarshalJSON([]byte(`{"a": [1, {}], "bc": "x"}`)))
+	obj := doc.Value.Value.(*ast.Object)
+	assert.Equal(t, []int{0, 24}, []int{obj.Start, obj.End})
+	assert.Equal(t, ast.Identifier{Value: "bc", Start: 15, End: 19}, obj.Children[1].Identifier)
+	array := obj.Children[0].Value.(*ast.Value).Value.(*ast.Array)
+	assert.Equal(t, []int{6, 12}, []int{array.Start, array.End})
+	assert.Equal(t, &ast.Object{Start: 10, End: 11}, array.Children[1].Value)
+	assert.Equal(t, &ast.Literal{LiteralType: ast.LiteralTypeString, Val: "x", Raw: `"x"`}, obj.Children[1].Value.(*ast.Value).Value)
+
+	var root ast.RootNode
+	assert.EqualError(t, root.UnmarshalText([]byte(`{"a": 1`)), "failed to unmarshal document: unexpected EOF, expected ',' or '}' at offset 7")
+	assert.EqualError(t, root.UnmarshalText([]byte(`1 2`)), "failed to unmarshal document: unexpected trailing content at offset 2")
+	assert.ErrorIs(t, root.UnmarshalText([]byte(`[1,]`)), parser.ErrTrailingComma)
+}
+
+func TestRootNode_EncodingJSON(t *testing.T) {
+	type envelope struct {
+		ID   int           `json:"id"`
+		Doc  *ast.RootNode `json:"doc"`
+		Meta ast.RootNode  `json:"meta"`
+	}
+	input := `{"id":1,"doc":{"z":1,"a":[1.0,"x"]},"meta":"m"}`
+	var e envelope
+	require.NoError(t, json.Unmarshal([]byte(input), &e))
+	assert.Equal(t, ast.RootNodeTypeObject, e.Doc.RootNodeType)
+	assert.Equal(t, "m", e.Meta.ToGo())
+
+	out, err := json.Marshal(&e)
+	require.NoError(t, err)
+	assert.Equal(t, input, string(out))
+
+	out, err = json.Marshal(e)
+	require.NoError(t, err)
+	assert.Equal(t, input, string(out), "marshaled by value")
+
+	out, err = json.Marshal(envelope{ID: 2})
+	require.NoError(t, err)
+	assert.Equal(t, `{"id":2,"doc":null,"meta":null}`, string(out))
+}
@@ -0,0 +1,200 @@
+package ast
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.com/pohedev/gj.git/path"
+)
+
+// Overlay is an edited variant of a base tree, leaving the base unchanged.
+// Edits copy only the objects and arrays on the way to the
+// changed value, the first time they are edited; the rest of the tree is
+// shared with the base, so that many variants of a large document cost
+// little more than their changes.
+type Overlay struct {
+	base  *RootNode
+	root  *Value       // Edited root value, nil before the first edit.
+	owned map[any]bool // Containers copied by the overlay.
+}
+
+// NewOverlay returns an overlay of base with no edits. base is frozen,
+// see RootNode.Freeze, as it is shared with the overlay.
+func NewOverlay(base *RootNode) *Overlay {
+	base.Freeze()
+	return &Overlay{base: base, owned: map[any]bool{}}
+}
+
+// Root returns the edited tree, or the base before the first edit. Parts
+// of the tree are shared with the base and other overlays: it must not be
+// modified other than through the overlay.
+func (o *Overlay) Root() *RootNode {
+	if o.root == nil {
+		return o.base
+	}
+	root := &RootNode{RootNodeType: RootNodeTypeLiteral, Value: o.root}
+	switch unwrap(o.root).(type) {
+	case *Object:
+		root.RootNodeType = RootNodeTypeObject
+	case *Array:
+		root.RootNodeType = RootNodeTypeArray
+	}
+	return root
+}
+
+// Get returns the value at p in the edited tree, see RootNode.GetValue.
+func (o *Overlay) Get(p string) (*Value, bool) {
+	return o.Root().GetValue(p)
+}
+
+// Set sets the value at p, a path as accepted by RootNode.GetValue, to v.
+// Like Object.Set, it replaces the last property with the key or adds one;
+// the index one past the end of an array, or "-" in a JSON Pointer,
+// appends to it. The containers on the way must exist.
+func (o *Overlay) Set(p string, v *Value) error {
+	segs, err := overlayPath(p)
+	if err != nil {
+		return err
+	}
+	if len(segs) == 0 {
+		o.root = v
+		return nil
+	}
+	parent, err := o.parent(p, segs)
+	if err != nil {
+		return err
+	}
+	key := segs[len(segs)-1].Key
+	switch n := parent.(type) {
+	case *Object:
+		return n.Set(key, v)
+	case *Array:
+		if key == "-" || key == strconv.Itoa(len(n.Children)) {
+			return n.Insert(len(n.Children), v)
+		}
+		i, ok := arrayIndex(n, key)
+		if !ok {
+			return fmt.Errorf("failed to set %q: no index %s in array", p, key)
+		}
+		n.Children[i].Value = v.Value
+		return nil
+	}
+	return fmt.Errorf("failed to set %q: %s is not a container", p, segs[:len(segs)-1].Pointer())
+}
+
+// Delete removes the value at p, every property with the key for objects.
+func (o *Overlay) Delete(p string) error {
+	segs, err := overlayPath(p)
+	if err != nil {
+		return err
+	}
+	if len(segs) == 0 {
+		return fmt.Errorf("failed to delete %q: cannot delete the root", p)
+	}
+	parent, err := o.parent(p, segs)
+	if err != nil {
+		return err
+	}
+	key := segs[len(segs)-1].Key
+	switch n := parent.(type) {
+	case *Object:
+		removed, err := n.Delete(key)
+		if err != nil {
+			return fmt.Errorf("failed to delete %q: %w", p, err)
+		}
+		if !removed {
+			return fmt.Errorf("failed to delete %q: no key %q", p, key)
+		}
+		return nil
+	case *Array:
+		i, ok := arrayIndex(n, key)
+		if !ok {
+			return fmt.Errorf("failed to delete %q: no index %s in array", p, key)
+		}
+		n.Children = slices.Delete(n.Children, i, i+1)
+		return nil
+	}
+	return fmt.Errorf("failed to delete %q: %s is not a container", p, segs[:len(segs)-1].Pointer())
+}
+
+// parent returns the owned copy of the container holding the value at
+// segs, copying the containers on the way.
+func (o *Overlay) parent(p string, segs path.Path) (any, error) {
+	if o.root == nil {
+		o.root = o.base.Value
+	}
+	node := o.own(unwrap(o.root))
+	o.root = &Value{Value: node}
+	for i, seg := range segs[:len(segs)-1] {
+		switch n := node.(type) {
+		case *Object:
+			j := lastIndex(n, seg.Key)
+			if j < 0 {
+				return nil, fmt.Errorf("failed to edit %q: no key %q at %q", p, seg.Key, segs[:i].Pointer())
+			}
+			node = o.own(unwrap(n.Children[j].Value))
+			n.Children[j].Value = &Value{Value: node}
+		case *Array:
+			j, ok := arrayIndex(n, seg.Key)
+			if !ok {
+				return nil, fmt.Errorf("failed to edit %q: no index %s at %q", p, seg.Key, segs[:i].Pointer())
+			}
+			node = o.own(unwrap(n.Children[j].Value))
+			n.Children[j].Value = node
+		default:
+			return nil, fmt.Errorf("failed to edit %q: %s is not a container", p, segs[:i].Pointer())
+		}
+	}
+	return node, nil
+}
+
+// own returns a copy of container node owned by the overlay, or node when
+// it is already owned or not a container.
+func (o *Overlay) own(node any) any {
+	if o.owned[node] {
+		return node
+	}
+	switch n := node.(type) {
+	case *Object:
+		c := &Object{Children: slices.Clone(n.Children), Start: n.Start, End: n.End, Trivia: n.Trivia}
+		o.owned[c] = true
+		return c
+	case *Array:
+		c := &Array{Children: slices.Clone(n.Children), Start: n.Start, End: n.End, Trivia: n.Trivia}
+		o.owned[c] = true
+		return c
+	}
+	return node
+}
+
+// overlayPath parses p, rejecting wildcards.
+func overlayPath(p string) (path.Path, error) {
+	segs, err := path.Parse(p)
+	if err != nil {
+		return nil, err
+	}
+	for _, seg := range segs {
+		if seg.Wildcard {
+			return nil, fmt.Errorf("failed to edit %q: wildcards are not supported", p)
+		}
+	}
+	return segs, nil
+}
+
+// lastIndex returns the position of the last property of obj with the
+// given key, or -1.
+func lastIndex(obj *Object, key string) int {
+	for i := len(obj.Children) - 1; i >= 0; i-- {
+		if obj.Children[i].Identifier.Value == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// arrayIndex returns key as an index of a.
+func arrayIndex(a *Array, key string) (int, bool) {
+	i, err := strconv.Atoi(key)
+	return i, err == nil && i >= 0 && i < len(a.Children)
+}
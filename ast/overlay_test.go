@@ -0,0 +1,64 @@
+package ast_test
+
+import (
+	"testing"
+
+	"github.com/pohedev/gj.git/ast"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOverlay(t *testing.T) {
+	base := parse(t, `{"a": {"b": 1, "c": [1, 2]}, "big": {"x": [true]}}`)
+	o1 := ast.NewOverlay(base)
+	o2 := ast.NewOverlay(base)
+	assert.True(t, base.Frozen())
+	assert.Same(t, base, o1.Root())
+
+	assert.Nil(t, o1.Set("a.b", &ast.Value{Value: ast.Number(2)}))
+	assert.Nil(t, o1.Set("a.c[2]", &ast.Value{Value: ast.Number(3)}))
+	assert.Nil(t, o1.Set("/a/c/0", &ast.Value{Value: ast.Null()}))
+	assert.Nil(t, o1.Set("a.d", &ast.Value{Value: ast.String("new")}))
+	assert.Nil(t, o2.Delete("a.c[0]"))
+	assert.Nil(t, o2.Delete("big"))
+
+	assert.Equal(t, map[string]any{
+		"a":   map[string]any{"b": int64(2), "c": []any{nil, int64(2), int64(3)}, "d": "new"},
+		"big": map[string]any{"x": []any{true}},
+	}, o1.Root().Value.ToGo())
+	assert.Equal(t, map[string]any{
+		"a": map[string]any{"b": int64(1), "c": []any{int64(2)}},
+	}, o2.Root().Value.ToGo())
+	assert.Equal(t, map[string]any{
+		"a":   map[string]any{"b": int64(1), "c": []any{int64(1), int64(2)}},
+		"big": map[string]any{"x": []any{true}},
+	}, base.Value.ToGo())
+
+	baseBig, _ := base.GetValue("big")
+	editedBig, _ := o1.Get("big")
+	assert.Same(t, baseBig.Value, editedBig.Value, "unchanged subtrees are shared")
+	a1, _ := o1.Get("a")
+	assert.Nil(t, o1.Set("a.b", &ast.Value{Value: ast.Number(4)}))
+	a2, _ := o1.Get("a")
+	assert.Same(t, a1.Value, a2.Value, "edited containers are copied once")
+
+	assert.Nil(t, o2.Set("", &ast.Value{Value: ast.Bool(true)}))
+	assert.Equal(t, ast.RootNodeTypeLiteral, o2.Root().RootNodeType)
+	assert.Equal(t, true, o2.Root().Value.ToGo())
+}
+
+func TestOverlay_Error(t *testing.T) {
+	base := parse(t, `{"a": {"b": 1, "c": [1]}}`)
+	o := ast.NewOverlay(base)
+	v := &ast.Value{Value: ast.Null()}
+
+	assert.EqualError(t, o.Set("x.y", v), `failed to edit "x.y": no key "x" at ""`)
+	assert.EqualError(t, o.Set("a.b.c", v), `failed to set "a.b.c": /a/b is not a container`)
+	assert.EqualError(t, o.Set("a.b.c.d", v), `failed to edit "a.b.c.d": /a/b is not a container`)
+	assert.EqualError(t, o.Set("a.c[5]", v), `failed to set "a.c[5]": no index 5 in array`)
+	assert.EqualError(t, o.Set("a.c[0][0]", v), `failed to set "a.c[0][0]": /a/c/0 is not a container`)
+	assert.EqualError(t, o.Set("a.*", v), `failed to edit "a.*": wildcards are not supported`)
+	assert.EqualError(t, o.Delete("a.x"), `failed to delete "a.x": no key "x"`)
+	assert.EqualError(t, o.Delete("a.c[1]"), `failed to delete "a.c[1]": no index 1 in array`)
+	assert.EqualError(t, o.Delete(""), `failed to delete "": cannot delete the root`)
+	assert.Error(t, o.Set("a..b", v))
+}
@@ -0,0 +1,75 @@
+package ast
+
+import (
+	"slices"
+	"strings"
+)
+
+// SortKeys sorts the properties of the object by key with cmp, or in
+// byte order when cmp is nil, keeping the order of properties comparing
+// equal, such as duplicates. Unlike printer.SortKeys, it changes the tree,
+// which can then be diffed, hashed or transformed in order. Properties
+// move along with their trivia, the trivia after the opening brace aside,
+// as with Move. It returns ErrFrozen when the object is frozen.
+func (o *Object) SortKeys(cmp func(a, b string) int) error {
+	if o.frozen {
+		return ErrFrozen
+	}
+	if cmp == nil {
+		cmp = strings.Compare
+	}
+	if len(o.Children) < 2 {
+		return nil
+	}
+	order := make([]int, len(o.Children))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(i, j int) int {
+		return cmp(o.Children[i].Identifier.Value, o.Children[j].Identifier.Value)
+	})
+	sorted := make([]Property, len(o.Children))
+	for i, j := range order {
+		sorted[i] = o.Children[j]
+	}
+	if first := slices.Index(order, 0); first > 0 {
+		// The trivia after the brace stays first, as with Move.
+		a, b := &sorted[0].Trivia, &sorted[first].Trivia
+		a.BeforeKey, b.BeforeKey = b.BeforeKey, a.BeforeKey
+	}
+	copy(o.Children, sorted)
+	o.index = nil
+	return nil
+}
+
+// SortKeys sorts the properties of every object of the tree with cmp, see
+// Object.SortKeys. Values kept unparsed by lazy parsing are left as is.
+// It returns ErrFrozen when the tree is frozen.
+func (r *RootNode) SortKeys(cmp func(a, b string) int) error {
+	if r.frozen {
+		return ErrFrozen
+	}
+	return sortKeys(r.Value, cmp)
+}
+
+// sortKeys sorts the objects of the tree at node.
+func sortKeys(node any, cmp func(a, b string) int) error {
+	switch n := unwrap(node).(type) {
+	case *Object:
+		if err := n.SortKeys(cmp); err != nil {
+			return err
+		}
+		for _, prop := range n.Children {
+			if err := sortKeys(prop.Value, cmp); err != nil {
+				return err
+			}
+		}
+	case *Array:
+		for _, item := range n.Children {
+			if err := sortKeys(item.Value, cmp); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
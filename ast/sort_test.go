@@ -0,0 +1,60 @@
+package ast_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pohedev/gj.git/ast"
+	"github.com/pohedev/gj.git/printer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObject_SortKeys(t *testing.T) {
+	var tests = []struct {
+		name  string
+		input string
+		cmp   func(a, b string) int
+		want  string
+	}{
+		{"byte order", `{"b": 1, "a": 2, "B": 3}`, nil, `{"B":3,"a":2,"b":1}`},
+		{"custom", `{"b": 1, "a": 2, "B": 3}`, func(a, b string) int {
+			return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+		}, `{"a":2,"b":1,"B":3}`},
+		{"reverse", `{"a": 1, "c": 2, "b": 3}`, func(a, b string) int { return strings.Compare(b, a) }, `{"c":2,"b":3,"a":1}`},
+		{"duplicates stable", `{"b": 1, "a": 2, "b": 3}`, nil, `{"a":2,"b":1,"b":3}`},
+		{"nested untouched", `{"b": {"d": 1, "c": 2}, "a": 3}`, nil, `{"a":3,"b":{"d":1,"c":2}}`},
+		{"empty", `{}`, nil, `{}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := parse(t, tt.input)
+			obj := root.Value.Value.(*ast.Object)
+			obj.Index()
+			assert.Nil(t, obj.SortKeys(tt.cmp))
+			got, err := printer.Sprint(root)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, got)
+			for key, i := range obj.Index() {
+				assert.Equal(t, key, obj.Children[i].Identifier.Value)
+			}
+		})
+	}
+}
+
+func TestObject_SortKeysTrivia(t *testing.T) {
+	root := parseLossless(t, "{ \"c\": 1,\n  \"a\":  2,\n  \"b\": 3\n}")
+	assert.Nil(t, root.Value.Value.(*ast.Object).SortKeys(nil))
+	assert.Equal(t, "{ \"a\":  2,\n  \"b\": 3,\n  \"c\": 1\n}", printLossless(t, root))
+}
+
+func TestRootNode_SortKeys(t *testing.T) {
+	root := parse(t, `[{"b": {"d": 1, "c": 2}, "a": [{"z": 1, "y": 2}]}, 1]`)
+	assert.Nil(t, root.SortKeys(nil))
+	got, err := printer.Sprint(root)
+	assert.Nil(t, err)
+	assert.Equal(t, `[{"a":[{"y":2,"z":1}],"b":{"c":2,"d":1}},1]`, got)
+
+	root.Freeze()
+	assert.ErrorIs(t, root.SortKeys(nil), ast.ErrFrozen)
+	assert.ErrorIs(t, root.Value.Value.(*ast.Array).Children[0].Value.(*ast.Object).SortKeys(nil), ast.ErrFrozen)
+}
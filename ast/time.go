@@ -0,0 +1,50 @@
+package ast
+
+import (
+	"math"
+	"time"
+)
+
+// epochMillisThreshold is the magnitude from which AsTime reads numbers
+// as milliseconds: 1e11 seconds is in the year 5138, 1e11 milliseconds
+// in 1973.
+const epochMillisThreshold = 1e11
+
+// AsTime returns the time held by a string or number literal. Strings are
+// parsed with each of layouts in turn, by default time.RFC3339, which also
+// accepts fractional seconds. Numbers are Unix times in seconds, or in
+// milliseconds when their magnitude is at least 1e11; fractions are kept.
+// Times read from numbers are in UTC.
+func (l *Literal) AsTime(layouts ...string) (time.Time, bool) {
+	if s, ok := l.AsString(); ok {
+		if len(layouts) == 0 {
+			layouts = []string{time.RFC3339}
+		}
+		for _, layout := range layouts {
+			if t, err := time.Parse(layout, s); err == nil {
+				return t, true
+			}
+		}
+		return time.Time{}, false
+	}
+
+	if i, ok := l.AsInt(); ok {
+		if i <= -epochMillisThreshold || i >= epochMillisThreshold {
+			return time.UnixMilli(i).UTC(), true
+		}
+		return time.Unix(i, 0).UTC(), true
+	}
+	f, ok := l.AsFloat()
+	if !ok || math.IsNaN(f) || math.IsInf(f, 0) {
+		return time.Time{}, false
+	}
+	unit := float64(time.Second)
+	if math.Abs(f) >= epochMillisThreshold {
+		unit = float64(time.Millisecond)
+	}
+	nanos := f * unit
+	if nanos < math.MinInt64 || nanos >= math.MaxInt64 {
+		return time.Time{}, false
+	}
+	return time.Unix(0, int64(nanos)).UTC(), true
+}
@@ -0,0 +1,38 @@
+package ast
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLiteral_AsTime(t *testing.T) {
+	tests := []struct {
+		name    string
+		lit     *Literal
+		layouts []string
+		want    time.Time
+		ok      bool
+	}{
+		{"rfc3339", String("2024-03-01T12:30:00Z"), nil, time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC), true},
+		{"rfc3339 fraction", String("2024-03-01T12:30:00.25Z"), nil, time.Date(2024, 3, 1, 12, 30, 0, 250e6, time.UTC), true},
+		{"rfc3339 offset", String("2024-03-01T14:30:00+02:00"), nil, time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC), true},
+		{"date only default", String("2024-03-01"), nil, time.Time{}, false},
+		{"layouts", String("2024-03-01"), []string{time.RFC3339, time.DateOnly}, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), true},
+		{"epoch seconds", Number(1709296200), nil, time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC), true},
+		{"epoch millis", Number(1709296200123), nil, time.Date(2024, 3, 1, 12, 30, 0, 123e6, time.UTC), true},
+		{"epoch float seconds", Number(1709296200.5), nil, time.Date(2024, 3, 1, 12, 30, 0, 500e6, time.UTC), true},
+		{"epoch negative", Number(-86400), nil, time.Date(1969, 12, 31, 0, 0, 0, 0, time.UTC), true},
+		{"bool", Bool(true), nil, time.Time{}, false},
+		{"null", Null(), nil, time.Time{}, false},
+		{"huge", Number(1e300), nil, time.Time{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.lit.AsTime(tt.layouts...)
+			assert.Equal(t, tt.ok, ok)
+			assert.True(t, tt.want.Equal(got), "got %v, want %v", got, tt.want)
+		})
+	}
+}
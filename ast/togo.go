@@ -0,0 +1,55 @@
+package ast
+
+// ToGo converts the value into plain Go values:
+// map[string]any, []any, string, int64, float64, bool or nil.
+// RawValue nodes are parsed first, see RootNode.UnmarshalJSON; those
+// holding invalid JSON, which lazy parsing does not detect, convert to nil.
+func (v *Value) ToGo() any {
+	if v == nil {
+		return nil
+	}
+	return toGo(v.Value)
+}
+
+// ToGo converts the object into a map[string]any. When a key appears
+// more than once, the last value wins.
+func (o *Object) ToGo() any {
+	m := make(map[string]any, len(o.Children))
+	for _, prop := range o.Children {
+		m[prop.Identifier.Value] = toGo(prop.Value)
+	}
+	return m
+}
+
+// ToGo converts the array into a []any.
+func (a *Array) ToGo() any {
+	s := make([]any, 0, len(a.Children))
+	for _, item := range a.Children {
+		s = append(s, toGo(item.Value))
+	}
+	return s
+}
+
+// ToGo returns the Go value of the literal, nil for null.
+func (l *Literal) ToGo() any {
+	return l.Val
+}
+
+// toGo converts any AST node into plain Go values.
+func toGo(node any) any {
+	switch n := unwrap(node).(type) {
+	case *Object:
+		return n.ToGo()
+	case *Array:
+		return n.ToGo()
+	case *Literal:
+		return n.ToGo()
+	case *RawValue:
+		var v RootNode
+		if err := v.UnmarshalJSON([]byte(n.Raw)); err != nil {
+			return nil
+		}
+		return toGo(v.Value)
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package ast_test
+
+import (
+	"testing"
+
+	"github.com/pohedev/gj.git/lexer"
+	"github.com/pohedev/gj.git/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValue_ToGo(t *testing.T) {
+	const input = `{"s": "x", "n": 1, "f": 1.5, "b": true, "z": null, "a": [false, {"k": [null]}]}`
+	want := map[string]any{
+		"s": "x",
+		"n": int64(1),
+		"f": 1.5,
+		"b": true,
+		"z": nil,
+		"a": []any{false, map[string]any{"k": []any{nil}}},
+	}
+	assert.Equal(t, want, parse(t, input).ToGo())
+
+	lazy, err := parser.New(lexer.Lex(input), parser.LazyBelow(0)).Parse()
+	assert.Nil(t, err)
+	assert.Equal(t, want, lazy.ToGo())
+
+	lazy, err = parser.New(lexer.Lex(`{"a": {"b" 1}}`), parser.LazyBelow(0)).Parse()
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]any{"a": nil}, lazy.ToGo())
+}
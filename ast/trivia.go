@@ -0,0 +1,110 @@
+package ast
+
+import (
+	"slices"
+	"strings"
+)
+
+// Insert inserts a property with the given key and value at position i
+// of Children, with 0 <= i <= Len. The property takes the layout of the
+// property before it, or of the first one when inserted first: the
+// whitespace recorded by parser.Lossless before its key, around its colon
+// and after its value, without blank lines, so that it lines up with the
+// other properties when printed with printer.Lossless. It returns
+// ErrFrozen when the object is frozen.
+func (o *Object) Insert(i int, key string, v *Value) error {
+	if o.frozen {
+		return ErrFrozen
+	}
+	prop := Property{Identifier: Identifier{Value: key}, Value: v}
+	if len(o.Children) > 0 {
+		t := o.Children[max(i-1, 0)].Trivia
+		prop.Trivia = PropertyTrivia{
+			BeforeKey:   layout(t.BeforeKey),
+			BeforeColon: layout(t.BeforeColon),
+			BeforeValue: layout(t.BeforeValue),
+			AfterValue:  layout(t.AfterValue),
+		}
+	}
+	o.Children = slices.Insert(o.Children, i, prop)
+	if i == 0 && len(o.Children) > 2 {
+		o.Children[1].Trivia.BeforeKey = layout(o.Children[2].Trivia.BeforeKey)
+	}
+	o.index = nil
+	return nil
+}
+
+// Move moves the property at position from of Children to position to,
+// along with its trivia. The trivia before the first key follows the
+// opening brace rather than the property: it is exchanged with the trivia
+// of the property taking or leaving the first position. It returns
+// ErrFrozen when the object is frozen.
+func (o *Object) Move(from, to int) error {
+	if o.frozen {
+		return ErrFrozen
+	}
+	if from == to {
+		return nil
+	}
+	prop := o.Children[from]
+	o.Children = slices.Insert(slices.Delete(o.Children, from, from+1), to, prop)
+	if from == 0 || to == 0 {
+		first, other := &o.Children[0].Trivia, &o.Children[max(to, 1)].Trivia
+		first.BeforeKey, other.BeforeKey = other.BeforeKey, first.BeforeKey
+	}
+	o.index = nil
+	return nil
+}
+
+// Insert inserts v at position i of Children, with 0 <= i <= Len, taking
+// the layout of the item before it like Object.Insert. It returns
+// ErrFrozen when the array is frozen.
+func (a *Array) Insert(i int, v *Value) error {
+	if a.frozen {
+		return ErrFrozen
+	}
+	item := ArrayItem{Value: v.Value}
+	if len(a.Children) > 0 {
+		neighbour := a.Children[max(i-1, 0)]
+		item.Before = layout(neighbour.Before)
+		item.After = layout(neighbour.After)
+	}
+	a.Children = slices.Insert(a.Children, i, item)
+	if i == 0 && len(a.Children) > 2 {
+		a.Children[1].Before = layout(a.Children[2].Before)
+	}
+	return nil
+}
+
+// Move moves the item at position from of Children to position to,
+// along with its trivia, like Object.Move. It returns ErrFrozen when
+// the array is frozen.
+func (a *Array) Move(from, to int) error {
+	if a.frozen {
+		return ErrFrozen
+	}
+	if from == to {
+		return nil
+	}
+	item := a.Children[from]
+	a.Children = slices.Insert(slices.Delete(a.Children, from, from+1), to, item)
+	if from == 0 || to == 0 {
+		first, other := &a.Children[0], &a.Children[max(to, 1)]
+		first.Before, other.Before = other.Before, first.Before
+	}
+	return nil
+}
+
+// layout returns the whitespace of trivia from its last line break,
+// dropping blank lines.
+func layout(trivia string) string {
+	if i := strings.LastIndexByte(trivia, '\n'); i > 0 && trivia[i-1] == '\r' {
+		trivia = trivia[i-1:]
+	} else if i >= 0 {
+		trivia = trivia[i:]
+	}
+	if strings.TrimLeft(trivia, " \t\r\n") != "" {
+		return ""
+	}
+	return trivia
+}
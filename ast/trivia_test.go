@@ -0,0 +1,110 @@
+package ast_test
+
+import (
+	"testing"
+
+	"github.com/pohedev/gj.git/ast"
+	"github.com/pohedev/gj.git/lexer"
+	"github.com/pohedev/gj.git/parser"
+	"github.com/pohedev/gj.git/printer"
+	"github.com/stretchr/testify/assert"
+)
+
+// parseLossless parses input with trivia or fails the test.
+func parseLossless(t *testing.T, input string) *ast.RootNode {
+	t.Helper()
+	root, err := parser.New(lexer.Lex(input), parser.Lossless()).Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return root
+}
+
+// printLossless prints root with its trivia or fails the test.
+func printLossless(t *testing.T, root *ast.RootNode) string {
+	t.Helper()
+	s, err := printer.Sprint(root, printer.Lossless())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestObject_Insert(t *testing.T) {
+	var tests = []struct {
+		name  string
+		input string
+		edit  func(obj *ast.Object)
+		want  string
+	}{
+		{
+			name:  "append",
+			input: "{\n  \"a\": 1,\n\n  \"b\": 2\n}",
+			edit:  func(obj *ast.Object) { obj.Set("c", &ast.Value{Value: ast.Number(3)}) },
+			want:  "{\n  \"a\": 1,\n\n  \"b\": 2,\n  \"c\": 3\n}",
+		},
+		{
+			name:  "first",
+			input: `{"a": 1, "b": 2}`,
+			edit:  func(obj *ast.Object) { obj.Insert(0, "z", &ast.Value{Value: ast.Null()}) },
+			want:  `{"z": null, "a": 1, "b": 2}`,
+		},
+		{
+			name:  "middle",
+			input: "{\r\n\t\"a\" : 1,\r\n\t\"b\" : 2\r\n}",
+			edit:  func(obj *ast.Object) { obj.Insert(1, "x", &ast.Value{Value: ast.Bool(true)}) },
+			want:  "{\r\n\t\"a\" : 1,\r\n\t\"x\" : true,\r\n\t\"b\" : 2\r\n}",
+		},
+		{
+			name:  "empty",
+			input: `{ }`,
+			edit:  func(obj *ast.Object) { obj.Insert(0, "a", &ast.Value{Value: ast.Number(1)}) },
+			want:  `{"a":1 }`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := parseLossless(t, tt.input)
+			obj := root.Value.Value.(*ast.Object)
+			tt.edit(obj)
+			assert.Equal(t, tt.want, printLossless(t, root))
+			_, ok := obj.Get(obj.Children[0].Identifier.Value)
+			assert.True(t, ok)
+		})
+	}
+}
+
+func TestObject_Move(t *testing.T) {
+	input := "{\"a\": 1,\n\n  \"b\": 2, \"c\": 3  \n}"
+	var tests = []struct {
+		from, to int
+		want     string
+	}{
+		{0, 2, "{\"b\": 2, \"c\": 3  ,\n\n  \"a\": 1\n}"},
+		{2, 0, "{\"c\": 3  , \"a\": 1,\n\n  \"b\": 2\n}"},
+		{1, 2, "{\"a\": 1, \"c\": 3  ,\n\n  \"b\": 2\n}"},
+		{1, 1, input},
+	}
+	for _, tt := range tests {
+		root := parseLossless(t, input)
+		obj := root.Value.Value.(*ast.Object)
+		obj.Move(tt.from, tt.to)
+		assert.Equal(t, tt.want, printLossless(t, root))
+	}
+}
+
+func TestArray_InsertMove(t *testing.T) {
+	root := parseLossless(t, "[\n  1,\n  2\n]")
+	array := root.Value.Value.(*ast.Array)
+	array.Insert(2, &ast.Value{Value: ast.Number(3)})
+	assert.Equal(t, "[\n  1,\n  2,\n  3\n]", printLossless(t, root))
+	array.Move(2, 0)
+	assert.Equal(t, "[\n  3,\n  1,\n  2\n]", printLossless(t, root))
+	array.Insert(0, &ast.Value{Value: ast.String("x")})
+	assert.Equal(t, []any{"x", int64(3), int64(1), int64(2)}, array.ToGo())
+
+	root = parseLossless(t, "[1, 2]")
+	array = root.Value.Value.(*ast.Array)
+	array.Move(0, 1)
+	assert.Equal(t, "[2, 1]", printLossless(t, root))
+}
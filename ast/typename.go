@@ -0,0 +1,33 @@
+package ast
+
+import "strings"
+
+// TypeName returns the JSON type of node, any node of a tree or a
+// *RootNode: "object", "array", "string", "number", "boolean" or "null".
+// A RawValue is an object or an array by its first byte.
+func TypeName(node any) string {
+	if r, ok := node.(*RootNode); ok && r != nil {
+		node = r.Value
+	}
+	switch n := unwrap(node).(type) {
+	case *Object:
+		return "object"
+	case *Array:
+		return "array"
+	case *RawValue:
+		if strings.HasPrefix(n.Raw, "[") {
+			return "array"
+		}
+		return "object"
+	case *Literal:
+		switch n.LiteralType {
+		case LiteralTypeString:
+			return "string"
+		case LiteralTypeNumber:
+			return "number"
+		case LiteralTypeTrue, LiteralTypeFalse:
+			return "boolean"
+		}
+	}
+	return "null"
+}
@@ -0,0 +1,30 @@
+package ast_test
+
+import (
+	"testing"
+
+	"github.com/pohedev/gj.git/ast"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTypeName(t *testing.T) {
+	tests := []struct {
+		node any
+		want string
+	}{
+		{&ast.Object{}, "object"},
+		{&ast.Value{Value: &ast.Value{Value: &ast.Array{}}}, "array"},
+		{&ast.RawValue{Raw: "[1]"}, "array"},
+		{&ast.RawValue{Raw: "{}"}, "object"},
+		{ast.String("x"), "string"},
+		{ast.Number(1.5), "number"},
+		{ast.Bool(false), "boolean"},
+		{ast.Null(), "null"},
+		{nil, "null"},
+		{(*ast.Value)(nil), "null"},
+		{&ast.RootNode{Value: &ast.Value{Value: ast.Bool(true)}}, "boolean"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, ast.TypeName(tt.node), "%#v", tt.node)
+	}
+}
@@ -0,0 +1,40 @@
+package ast
+
+import "strconv"
+
+// Walk calls fn for each node of the tree at root in depth-first order,
+// starting with the root value. node is the *Object, *Array, *Literal or
+// *RawValue, with Value wrappers stripped. The children of a node are
+// visited only when fn returns true.
+func Walk(root *RootNode, fn func(node any) bool) {
+	WalkWithPath(root, func(_ string, _ int, node any) bool {
+		return fn(node)
+	})
+}
+
+// WalkWithPath is like Walk but fn also receives the JSON Pointer of the
+// node and its depth, the number of containers enclosing it.
+func WalkWithPath(root *RootNode, fn func(path string, depth int, node any) bool) {
+	if root == nil || root.Value == nil {
+		return
+	}
+	walk(root.Value, "", 0, fn)
+}
+
+// walk visits node and, when fn returns true, its children recursively.
+func walk(node any, path string, depth int, fn func(string, int, any) bool) {
+	node = unwrap(node)
+	if !fn(path, depth, node) {
+		return
+	}
+	switch n := node.(type) {
+	case *Object:
+		for _, prop := range n.Children {
+			walk(prop.Value, appendPointer(path, prop.Identifier.Value), depth+1, fn)
+		}
+	case *Array:
+		for i, item := range n.Children {
+			walk(item.Value, appendPointer(path, strconv.Itoa(i)), depth+1, fn)
+		}
+	}
+}
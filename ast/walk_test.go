@@ -0,0 +1,36 @@
+package ast_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/pohedev/gj.git/ast"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWalk(t *testing.T) {
+	root := parse(t, `{"a": [1, {"b": null}], "c/d": "x"}`)
+
+	var types []string
+	ast.Walk(root, func(node any) bool {
+		types = append(types, fmt.Sprintf("%T", node))
+		return true
+	})
+	assert.Equal(t, []string{"*ast.Object", "*ast.Array", "*ast.Literal", "*ast.Object", "*ast.Literal", "*ast.Literal"}, types)
+
+	ast.Walk(nil, func(any) bool {
+		t.Fatal("called on nil root")
+		return true
+	})
+}
+
+func TestWalkWithPath(t *testing.T) {
+	root := parse(t, `{"a": [1, {"b": null}], "c/d": "x", "e": {"f": 2}}`)
+
+	var got []string
+	ast.WalkWithPath(root, func(path string, depth int, node any) bool {
+		got = append(got, fmt.Sprintf("%d %s", depth, path))
+		return path != "/e"
+	})
+	assert.Equal(t, []string{"0 ", "1 /a", "2 /a/0", "2 /a/1", "3 /a/1/b", "1 /c~1d", "1 /e"}, got)
+}
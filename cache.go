@@ -0,0 +1,78 @@
+package gj
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"sync"
+
+	"github.com/pohedev/gj.git/ast"
+	"github.com/pohedev/gj.git/lexer"
+	"github.com/pohedev/gj.git/parser"
+)
+
+// ParseCache parses documents, returning the same tree for inputs seen
+// recently, such as the payloads of webhooks rendered from a template.
+// Inputs are identified by their SHA-256 hash, and the trees of the least
+// recently used ones are evicted beyond the capacity of the cache. Trees
+// are frozen, see ast.RootNode.Freeze, as they are shared by the callers.
+// A ParseCache is safe for concurrent use.
+type ParseCache struct {
+	size int
+	opts []parser.Option
+
+	mu      sync.Mutex
+	entries map[[sha256.Size]byte]*list.Element
+	order   list.List // Of *cacheEntry, most recently used first.
+}
+
+// cacheEntry is a tree held by a ParseCache.
+type cacheEntry struct {
+	key  [sha256.Size]byte
+	root *ast.RootNode
+}
+
+// NewParseCache returns a cache holding up to size trees, parsed with opts.
+func NewParseCache(size int, opts ...parser.Option) *ParseCache {
+	return &ParseCache{size: max(size, 1), opts: opts, entries: map[[sha256.Size]byte]*list.Element{}}
+}
+
+// Parse returns the frozen tree of input, parsing it unless it is cached.
+// Failed parses are not cached.
+func (c *ParseCache) Parse(input string) (*ast.RootNode, error) {
+	key := sha256.Sum256([]byte(input))
+	c.mu.Lock()
+	if e, ok := c.entries[key]; ok {
+		c.order.MoveToFront(e)
+		c.mu.Unlock()
+		return e.Value.(*cacheEntry).root, nil
+	}
+	c.mu.Unlock()
+
+	root, err := parser.New(lexer.Lex(input), c.opts...).Parse()
+	if err != nil {
+		return nil, err
+	}
+	root.Freeze()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[key]; ok {
+		// Parsed meanwhile by another goroutine.
+		c.order.MoveToFront(e)
+		return e.Value.(*cacheEntry).root, nil
+	}
+	c.entries[key] = c.order.PushFront(&cacheEntry{key: key, root: root})
+	if c.order.Len() > c.size {
+		last := c.order.Back()
+		c.order.Remove(last)
+		delete(c.entries, last.Value.(*cacheEntry).key)
+	}
+	return root, nil
+}
+
+// Len returns the number of trees held by the cache.
+func (c *ParseCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
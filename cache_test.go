@@ -0,0 +1,61 @@
+package gj_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/pohedev/gj.git"
+	"github.com/pohedev/gj.git/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCache(t *testing.T) {
+	c := gj.NewParseCache(2)
+	a, err := c.Parse(`{"event": "push"}`)
+	require.NoError(t, err)
+	assert.True(t, a.Frozen())
+
+	again, err := c.Parse(`{"event": "push"}`)
+	require.NoError(t, err)
+	assert.Same(t, a, again)
+
+	b, _ := c.Parse(`{"event": "pull"}`)
+	assert.NotSame(t, a, b)
+	c.Parse(`{"event": "push"}`) // a is now the most recently used.
+	c.Parse(`[]`)                // Evicts b.
+	assert.Equal(t, 2, c.Len())
+
+	again, _ = c.Parse(`{"event": "push"}`)
+	assert.Same(t, a, again)
+	again, _ = c.Parse(`{"event": "pull"}`)
+	assert.NotSame(t, b, again)
+
+	_, err = c.Parse(`{`)
+	assert.Error(t, err)
+	assert.Equal(t, 2, c.Len(), "errors are not cached")
+
+	strict := gj.NewParseCache(1, parser.DisallowDuplicateKeys())
+	_, err = strict.Parse(`{"a": 1, "a": 2}`)
+	assert.ErrorIs(t, err, parser.ErrDuplicateKey)
+}
+
+func TestParseCache_Concurrent(t *testing.T) {
+	c := gj.NewParseCache(4)
+	inputs := []string{`1`, `2`, `3`, `[1]`, `{"a": 1}`, `"x"`}
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range 100 {
+				root, err := c.Parse(inputs[(i+j)%len(inputs)])
+				if assert.Nil(t, err) {
+					root.Value.ToGo()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 4, c.Len())
+}
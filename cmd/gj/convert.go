@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/pohedev/gj.git/ast"
+	"github.com/pohedev/gj.git/convert"
+	"github.com/pohedev/gj.git/hjson"
+	"github.com/pohedev/gj.git/lexer"
+	"github.com/pohedev/gj.git/parser"
+	"github.com/pohedev/gj.git/printer"
+)
+
+const convertUsage = "convert [-from format] [-to format] [-at pointer] [-infer] [-indent s] [-compact] [in [out]]"
+
+// Formats read and written by "gj convert".
+var (
+	convertFrom = []string{"json", "hjson", "csv", "tsv"}
+	convertTo   = []string{"json", "yaml", "toml", "cbor", "msgpack", "csv", "tsv"}
+)
+
+// runConvert implements "gj convert": it reads a document in one format
+// and writes it in another, from in to out, stdin and stdout by default.
+// Formats default to the extensions of the files, then to json. CSV and
+// TSV hold an array of objects with a header row, see convert.ToCSV;
+// YAML, TOML, CBOR and MessagePack are only written.
+func runConvert(env *env, args []string) error {
+	fs := newFlagSet(env, convertUsage)
+	from := fs.String("from", "", "input format: "+strings.Join(convertFrom, ", "))
+	to := fs.String("to", "", "output format: "+strings.Join(convertTo, ", "))
+	at := fs.String("at", "", "JSON Pointer of the array to write as CSV or TSV")
+	infer := fs.Bool("infer", false, "read CSV and TSV numbers and booleans as such rather than strings")
+	indent := fs.String("indent", "  ", "indentation of nested values in JSON")
+	compact := fs.Bool("compact", false, "write compact JSON on one line")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 2 {
+		fs.Usage()
+		return errUsage
+	}
+	in, out := fs.Arg(0), fs.Arg(1)
+	if *from == "" {
+		*from = formatOf(in)
+	}
+	if *to == "" {
+		*to = formatOf(out)
+	}
+	if !slices.Contains(convertFrom, *from) {
+		return fmt.Errorf("unsupported input format %q, want one of %s", *from, strings.Join(convertFrom, ", "))
+	}
+	if !slices.Contains(convertTo, *to) {
+		return fmt.Errorf("unsupported output format %q, want one of %s", *to, strings.Join(convertTo, ", "))
+	}
+
+	input, err := readInput(env, in)
+	if err != nil {
+		return err
+	}
+	root, err := decodeFormat(*from, input, *infer)
+	if err != nil {
+		if in != "" && in != "-" {
+			return fmt.Errorf("%s: %w", in, err)
+		}
+		return err
+	}
+	var text string
+	var data []byte
+	switch *to {
+	case "json":
+		text, err = printer.Sprint(root, printerOptions(*indent, *compact, false, false)...)
+		if err == nil {
+			text += "\n"
+		}
+	case "yaml":
+		text, err = convert.ToYAML(root)
+	case "toml":
+		text, err = convert.ToTOML(root)
+	case "cbor":
+		data, err = convert.ToCBOR(root)
+	case "msgpack":
+		data, err = convert.ToMsgpack(root)
+	case "csv", "tsv":
+		text, err = convert.ToCSV(root, convert.Comma(comma(*to)), convert.At(*at))
+	}
+	if err != nil {
+		return err
+	}
+	if data == nil {
+		data = []byte(text)
+	}
+	if out == "" || out == "-" {
+		_, err = env.stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(out, data, 0o644)
+}
+
+// decodeFormat parses input in the given format.
+func decodeFormat(format, input string, infer bool) (*ast.RootNode, error) {
+	switch format {
+	case "hjson":
+		return hjson.Parse(input)
+	case "csv", "tsv":
+		opts := []convert.Option{convert.Comma(comma(format))}
+		if infer {
+			opts = append(opts, convert.InferNumbers(), convert.InferBools())
+		}
+		return convert.FromCSV(strings.NewReader(input), opts...)
+	}
+	return parser.New(lexer.Lex(input)).Parse()
+}
+
+// formatOf returns the format of the file at name by its extension, json
+// for stdin, stdout and unknown extensions.
+func formatOf(name string) string {
+	switch ext := strings.ToLower(filepath.Ext(name)); ext {
+	case ".hjson", ".csv", ".tsv", ".yaml", ".toml", ".cbor", ".msgpack":
+		return ext[1:]
+	case ".yml":
+		return "yaml"
+	}
+	return "json"
+}
+
+// comma returns the field delimiter of the CSV or TSV format.
+func comma(format string) rune {
+	if format == "tsv" {
+		return '\t'
+	}
+	return ','
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/pohedev/gj.git/ast"
+	"github.com/pohedev/gj.git/diff"
+	"github.com/pohedev/gj.git/printer"
+)
+
+const diffUsage = "diff [-patch] [-color auto|always|never] a.json b.json"
+
+// ANSI colors of the changes printed by "gj diff".
+const (
+	colorRemove  = "\x1b[31m"
+	colorAdd     = "\x1b[32m"
+	colorReplace = "\x1b[33m"
+	colorReset   = "\x1b[0m"
+)
+
+// runDiff implements "gj diff": it prints the changes turning
+// the first document into the second, one path per line, or as
+// an RFC 6902 JSON Patch with -patch.
+func runDiff(env *env, args []string) error {
+	fs := newFlagSet(env, diffUsage)
+	patch := fs.Bool("patch", false, "write an RFC 6902 JSON Patch")
+	color := colorFlag("auto")
+	fs.Var(&color, "color", "colorize output: auto, always or never")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return errUsage
+	}
+
+	a, err := readDocument(env, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	b, err := readDocument(env, fs.Arg(1))
+	if err != nil {
+		return err
+	}
+	changes := diff.Compare(a.Value, b.Value)
+	colored := color.enabled(env.stdout)
+
+	if *patch {
+		opts := printerOptions("  ", false, false, colored)
+		if err := printer.Fprint(env.stdout, diff.Patch(changes), opts...); err != nil {
+			return err
+		}
+		_, err := fmt.Fprintln(env.stdout)
+		return err
+	}
+
+	for _, c := range changes {
+		var line, lineColor string
+		switch c.Op {
+		case diff.OpAdd:
+			line, lineColor = fmt.Sprintf("+ %s: %s", displayPath(c.Path), compact(c.New)), colorAdd
+		case diff.OpRemove:
+			line, lineColor = fmt.Sprintf("- %s: %s", displayPath(c.Path), compact(c.Old)), colorRemove
+		case diff.OpReplace:
+			line, lineColor = fmt.Sprintf("~ %s: %s -> %s", displayPath(c.Path), compact(c.Old), compact(c.New)), colorReplace
+		}
+		if colored {
+			line = lineColor + line + colorReset
+		}
+		if _, err := fmt.Fprintln(env.stdout, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// displayPath returns JSON Pointer ptr for display.
+func displayPath(ptr string) string {
+	if ptr == "" {
+		return "(root)"
+	}
+	return ptr
+}
+
+// compact returns v as compact JSON.
+func compact(v *ast.Value) string {
+	s, err := printer.Sprint(v)
+	if err != nil {
+		return fmt.Sprintf("<%v>", err)
+	}
+	return s
+}
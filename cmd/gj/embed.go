@@ -0,0 +1,182 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/pohedev/gj.git/ast"
+)
+
+const embedUsage = "embed [-pkg name] [-var name] [-o file] file"
+
+// runEmbed implements "gj embed": it writes Go source declaring a
+// variable holding the parsed AST of a JSON file, so that programs can
+// embed documents without parsing them at startup. It is meant to be
+// run by go generate, e.g.
+//
+//	//go:generate go run github.com/pohedev/gj.git/cmd/gj embed -var Defaults -o defaults_gen.go defaults.json
+func runEmbed(env *env, args []string) error {
+	fs := newFlagSet(env, embedUsage)
+	pkg := fs.String("pkg", os.Getenv("GOPACKAGE"), "package name, $GOPACKAGE by default")
+	name := fs.String("var", "", "variable name, derived from the file name by default")
+	out := fs.String("o", "", "output file instead of stdout")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 || *pkg == "" {
+		fs.Usage()
+		return errUsage
+	}
+	file := fs.Arg(0)
+	if *name == "" {
+		*name = varName(file)
+	}
+	if !token.IsIdentifier(*name) {
+		return fmt.Errorf("invalid variable name %q", *name)
+	}
+
+	root, err := readDocument(env, file)
+	if err != nil {
+		return err
+	}
+	src, err := generate(*pkg, *name, filepath.Base(file), root)
+	if err != nil {
+		return err
+	}
+	if *out == "" {
+		_, err = env.stdout.Write(src)
+		return err
+	}
+	return os.WriteFile(*out, src, 0o644)
+}
+
+// generate returns the formatted source of a file of package pkg
+// declaring variable name holding root, parsed from file.
+func generate(pkg, name, file string, root *ast.RootNode) ([]byte, error) {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by gj embed; DO NOT EDIT.\n\npackage %s\n\n", pkg)
+	fmt.Fprintf(&b, "import %q\n\n", "github.com/pohedev/gj.git/ast")
+	fmt.Fprintf(&b, "// %s is the document parsed from %s.\n", name, file)
+	fmt.Fprintf(&b, "var %s = &ast.RootNode{\nRootNodeType: %s,\nValue: ", name, rootNodeTypes[root.RootNodeType])
+	if err := writeNode(&b, root.Value); err != nil {
+		return nil, err
+	}
+	b.WriteString(",\n}\n")
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to format generated code: %w", err)
+	}
+	return src, nil
+}
+
+var rootNodeTypes = map[ast.RootNodeType]string{
+	ast.RootNodeTypeObject:  "ast.RootNodeTypeObject",
+	ast.RootNodeTypeArray:   "ast.RootNodeTypeArray",
+	ast.RootNodeTypeLiteral: "ast.RootNodeTypeLiteral",
+}
+
+// writeNode writes a Go expression constructing node to b.
+func writeNode(b *bytes.Buffer, node any) error {
+	switch n := node.(type) {
+	case *ast.Value:
+		b.WriteString("&ast.Value{Value: ")
+		if err := writeNode(b, n.Value); err != nil {
+			return err
+		}
+		b.WriteString("}")
+	case *ast.Object:
+		b.WriteString("&ast.Object{")
+		if len(n.Children) > 0 {
+			b.WriteString("Children: []ast.Property{\n")
+			for _, prop := range n.Children {
+				fmt.Fprintf(b, "{Identifier: ast.Identifier{Value: %s, Start: %d, End: %d}, Value: ",
+					strconv.Quote(prop.Identifier.Value), prop.Identifier.Start, prop.Identifier.End)
+				if err := writeNode(b, prop.Value); err != nil {
+					return err
+				}
+				b.WriteString("},\n")
+			}
+			b.WriteString("}, ")
+		}
+		fmt.Fprintf(b, "Start: %d, End: %d}", n.Start, n.End)
+	case *ast.Array:
+		b.WriteString("&ast.Array{")
+		if len(n.Children) > 0 {
+			b.WriteString("Children: []ast.ArrayItem{\n")
+			for _, item := range n.Children {
+				b.WriteString("{Value: ")
+				if err := writeNode(b, item.Value); err != nil {
+					return err
+				}
+				b.WriteString("},\n")
+			}
+			b.WriteString("}, ")
+		}
+		fmt.Fprintf(b, "Start: %d, End: %d}", n.Start, n.End)
+	case *ast.Literal:
+		return writeLiteral(b, n)
+	default:
+		return fmt.Errorf("unsupported node %T", node)
+	}
+	return nil
+}
+
+// writeLiteral writes a Go expression constructing lit to b.
+func writeLiteral(b *bytes.Buffer, lit *ast.Literal) error {
+	var typ, val string
+	switch v := lit.Val.(type) {
+	case nil:
+		b.WriteString("&ast.Literal{LiteralType: ast.LiteralTypeNull}")
+		return nil
+	case bool:
+		typ, val = "ast.LiteralTypeFalse", "false"
+		if v {
+			typ, val = "ast.LiteralTypeTrue", "true"
+		}
+	case string:
+		typ, val = "ast.LiteralTypeString", strconv.Quote(v)
+	case int64:
+		typ, val = "ast.LiteralTypeNumber", "int64("+strconv.FormatInt(v, 10)+")"
+	case float64:
+		typ, val = "ast.LiteralTypeNumber", "float64("+strconv.FormatFloat(v, 'g', -1, 64)+")"
+	default:
+		return fmt.Errorf("unsupported literal value %T", lit.Val)
+	}
+	fmt.Fprintf(b, "&ast.Literal{LiteralType: %s, Val: %s", typ, val)
+	if lit.Raw != "" {
+		fmt.Fprintf(b, ", Raw: %s", strconv.Quote(lit.Raw))
+	}
+	b.WriteString("}")
+	return nil
+}
+
+// varName returns a variable name derived from the name of file,
+// e.g. "appConfig" for "app-config.json".
+func varName(file string) string {
+	base := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+	var b strings.Builder
+	upper := false
+	for _, r := range base {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			upper = b.Len() > 0
+		case upper:
+			b.WriteRune(unicode.ToUpper(r))
+			upper = false
+		default:
+			b.WriteRune(r)
+		}
+	}
+	name := b.String()
+	if name == "" || unicode.IsDigit([]rune(name)[0]) || token.IsKeyword(name) {
+		name = "doc" + strings.ToUpper(name[:min(len(name), 1)]) + name[min(len(name), 1):]
+	}
+	return name
+}
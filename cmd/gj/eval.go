@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/pohedev/gj.git/ast"
+	"github.com/pohedev/gj.git/eval"
+	"github.com/pohedev/gj.git/printer"
+)
+
+const evalUsage = "eval [-raw] [-indent s] [-compact] [-escape-html] [-color auto|always|never] expr [file]"
+
+// runEval implements "gj eval": it evaluates a jq-like expression on
+// a document and prints each output on its own line, see package eval.
+// -raw prints strings without quotes.
+func runEval(env *env, args []string) error {
+	fs := newFlagSet(env, evalUsage)
+	raw := fs.Bool("raw", false, "print strings without quotes")
+	indent := fs.String("indent", "  ", "indentation of nested values")
+	compact := fs.Bool("compact", false, "write compact output on one line")
+	escapeHTML := fs.Bool("escape-html", false, "escape <, > and & in strings")
+	color := colorFlag("auto")
+	fs.Var(&color, "color", "colorize output: auto, always or never")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() < 1 || fs.NArg() > 2 {
+		fs.Usage()
+		return errUsage
+	}
+	q, err := eval.Compile(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	root, err := readDocument(env, fs.Arg(1))
+	if err != nil {
+		return err
+	}
+	out, err := q.Run(root.Value)
+	if err != nil {
+		return err
+	}
+	p := printer.New(printerOptions(*indent, *compact, *escapeHTML, color.enabled(env.stdout))...)
+	for _, v := range out {
+		if lit, ok := v.Value.(*ast.Literal); ok && *raw {
+			if s, ok := lit.AsString(); ok {
+				if _, err := fmt.Fprintln(env.stdout, s); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if err := p.Fprint(env.stdout, v); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintln(env.stdout); err != nil {
+			return err
+		}
+	}
+	return nil
+}
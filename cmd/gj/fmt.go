@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/pohedev/gj.git/printer"
+)
+
+const fmtUsage = "fmt [-indent s] [-compact] [-escape-html] [-color auto|always|never] [file]\n" +
+	"       gj fmt -watch [-interval d] [-indent s] [-compact] [-escape-html] path..."
+
+// runFmt implements "gj fmt": it reformats a document, indented
+// by default and colored when writing to a terminal. With -watch, it
+// reformats files in place as they change until interrupted, see watcher.
+func runFmt(env *env, args []string) error {
+	fs := newFlagSet(env, fmtUsage)
+	indent := fs.String("indent", "  ", "indentation of nested values")
+	compact := fs.Bool("compact", false, "write compact output on one line")
+	escapeHTML := fs.Bool("escape-html", false, "escape <, > and & in strings")
+	color := colorFlag("auto")
+	fs.Var(&color, "color", "colorize output: auto, always or never")
+	watch := fs.Bool("watch", false, "reformat the files and directories given as they change")
+	interval := fs.Duration("interval", 500*time.Millisecond, "time between checks for changes with -watch")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *watch {
+		if fs.NArg() == 0 || *interval <= 0 {
+			fs.Usage()
+			return errUsage
+		}
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		return newWatcher(env, fs.Args(), printerOptions(*indent, *compact, *escapeHTML, false)).run(ctx, *interval)
+	}
+	if fs.NArg() > 1 {
+		fs.Usage()
+		return errUsage
+	}
+
+	root, err := readDocument(env, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	opts := printerOptions(*indent, *compact, *escapeHTML, color.enabled(env.stdout))
+	if err := printer.Fprint(env.stdout, root, opts...); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(env.stdout)
+	return err
+}
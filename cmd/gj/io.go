@@ -0,0 +1,117 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/pohedev/gj.git/ast"
+	"github.com/pohedev/gj.git/hjson"
+	"github.com/pohedev/gj.git/lexer"
+	"github.com/pohedev/gj.git/parser"
+	"github.com/pohedev/gj.git/printer"
+)
+
+// newFlagSet returns a flag set for the command with the given usage line,
+// printing errors and usage to env.stderr.
+func newFlagSet(env *env, usage string) *flag.FlagSet {
+	fs := flag.NewFlagSet(usage, flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(env.stderr, "usage: gj %s\n", usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseFlags parses args into fs, returning errUsage on failure.
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	return nil
+}
+
+// readInput reads the file at name, or stdin when name is "" or "-".
+func readInput(env *env, name string) (string, error) {
+	if name == "" || name == "-" {
+		data, err := io.ReadAll(env.stdin)
+		return string(data), err
+	}
+	data, err := os.ReadFile(name)
+	return string(data), err
+}
+
+// readDocument parses the file at name, or stdin when name is "" or "-".
+// Files with the .hjson extension are parsed as HJSON.
+func readDocument(env *env, name string) (*ast.RootNode, error) {
+	input, err := readInput(env, name)
+	if err != nil {
+		return nil, err
+	}
+	var root *ast.RootNode
+	if filepath.Ext(name) == ".hjson" {
+		root, err = hjson.Parse(input)
+	} else {
+		root, err = parser.New(lexer.Lex(input)).Parse()
+	}
+	if err != nil && name != "" && name != "-" {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return root, err
+}
+
+// colorFlag is the value of a -color flag.
+type colorFlag string
+
+func (c *colorFlag) String() string {
+	return string(*c)
+}
+
+func (c *colorFlag) Set(s string) error {
+	switch s {
+	case "auto", "always", "never":
+		*c = colorFlag(s)
+		return nil
+	}
+	return fmt.Errorf("must be auto, always or never")
+}
+
+// enabled reports whether to color output written to w. In auto mode,
+// output is colored when w is a terminal and NO_COLOR is not set.
+func (c colorFlag) enabled(w io.Writer) bool {
+	switch c {
+	case "always":
+		return true
+	case "never":
+		return false
+	}
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	f, ok := w.(*os.File)
+	return ok && isTerminal(f)
+}
+
+// isTerminal reports whether f is a character device such as a terminal.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// printerOptions returns the printer options for the common output flags.
+func printerOptions(indent string, compact, escapeHTML bool, color bool) []printer.Option {
+	var opts []printer.Option
+	if !compact {
+		opts = append(opts, printer.Indent(indent))
+	}
+	if escapeHTML {
+		opts = append(opts, printer.EscapeHTML())
+	}
+	if color {
+		opts = append(opts, printer.WithColors(printer.DefaultColors))
+	}
+	return opts
+}
@@ -0,0 +1,90 @@
+// Command gj processes JSON documents.
+//
+// Usage:
+//
+//	gj <command> [flags] [arguments]
+//
+// Run "gj help" for the list of commands.
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// command is a subcommand of gj.
+type command struct {
+	usage string // Usage line, without the program name.
+	short string // One-line description.
+	run   func(env *env, args []string) error
+}
+
+// commands maps subcommand names to their implementation.
+var commands = map[string]command{
+	"convert":    {convertUsage, "convert a document to and from JSON, HJSON, CSV and TSV, or to YAML, TOML, CBOR and MessagePack", runConvert},
+	"diff":       {diffUsage, "print the changes between two documents", runDiff},
+	"embed":      {embedUsage, "generate Go code constructing the AST of a document", runEmbed},
+	"eval":       {evalUsage, "evaluate a jq-like expression on a document", runEval},
+	"fmt":        {fmtUsage, "reformat a document", runFmt},
+	"keys":       {keysUsage, "list the object keys of a document", runKeys},
+	"merge":      {mergeUsage, "deep-merge documents, later ones overriding earlier ones", runMerge},
+	"paths":      {pathsUsage, "list the leaf paths of a document", runPaths},
+	"sourcemap":  {sourcemapUsage, "print the byte range of each leaf of a document", runSourcemap},
+	"typescript": {typescriptUsage, "generate TypeScript types of documents", runTypescript},
+}
+
+// env holds the standard streams of a command.
+type env struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// errUsage reports invalid arguments; the usage is printed by the flag set.
+var errUsage = errors.New("invalid usage")
+
+func main() {
+	os.Exit(run(os.Args[1:], &env{stdin: os.Stdin, stdout: os.Stdout, stderr: os.Stderr}))
+}
+
+// run executes the command in args and returns the exit code.
+func run(args []string, env *env) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		usage(env.stderr)
+		return 2
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(env.stderr, "gj: unknown command %q\n", args[0])
+		usage(env.stderr)
+		return 2
+	}
+	if err := cmd.run(env, args[1:]); err != nil {
+		if errors.Is(err, errUsage) {
+			return 2
+		}
+		fmt.Fprintf(env.stderr, "gj %s: %v\n", args[0], err)
+		return 1
+	}
+	return 0
+}
+
+// usage prints the list of commands.
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "usage: gj <command> [flags] [arguments]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "commands:")
+	names := make([]string, 0, len(commands))
+	width := 0
+	for name := range commands {
+		names = append(names, name)
+		width = max(width, len(name))
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-*s %s\n", width, name, commands[name].short)
+	}
+}
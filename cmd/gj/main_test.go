@@ -0,0 +1,354 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runGj runs gj with args and stdin, returning the exit code and outputs.
+func runGj(t *testing.T, stdin string, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := run(args, &env{stdin: strings.NewReader(stdin), stdout: &stdout, stderr: &stderr})
+	return code, stdout.String(), stderr.String()
+}
+
+// writeFile writes content to a new file in a temporary directory
+// and returns its path.
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRun_Usage(t *testing.T) {
+	code, _, stderr := runGj(t, "")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, "fmt")
+
+	code, _, stderr = runGj(t, "", "nope")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, `unknown command "nope"`)
+}
+
+func TestFmt(t *testing.T) {
+	var tests = []struct {
+		name  string
+		stdin string
+		args  []string
+		want  string
+	}{
+		{"indent", `{"a":[1,{}]}`, nil, "{\n  \"a\": [\n    1,\n    {}\n  ]\n}\n"},
+		{"compact", "{ \"a\" : [1, 2] }", []string{"-compact"}, "{\"a\":[1,2]}\n"},
+		{"tab", `[true]`, []string{"-indent", "\t"}, "[\n\ttrue\n]\n"},
+		{"color", `[1]`, []string{"-compact", "-color", "always"}, "\x1b[1m[\x1b[0m\x1b[36m1\x1b[0m\x1b[1m]\x1b[0m\n"},
+		{"no color on pipes", `[1]`, []string{"-compact", "-color", "auto"}, "[1]\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, stdout, stderr := runGj(t, tt.stdin, append([]string{"fmt"}, tt.args...)...)
+			assert.Equal(t, 0, code, stderr)
+			assert.Equal(t, tt.want, stdout)
+		})
+	}
+
+	path := writeFile(t, "a.json", `{"b": null}`)
+	code, stdout, _ := runGj(t, "", "fmt", "-compact", path)
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "{\"b\":null}\n", stdout)
+
+	path = writeFile(t, "a.hjson", "# config\nb: null\nc: hello world\n")
+	code, stdout, _ = runGj(t, "", "fmt", "-compact", path)
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "{\"b\":null,\"c\":\"hello world\"}\n", stdout)
+
+	code, _, stderr := runGj(t, "", "fmt", path+".missing")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "no such file")
+
+	code, _, stderr = runGj(t, "[1,", "fmt")
+	assert.Equal(t, 1, code)
+	assert.Equal(t, "gj fmt: failed to parse: missing closing bracket at offset 3\n", stderr)
+
+	code, _, _ = runGj(t, "", "fmt", "-color", "sometimes")
+	assert.Equal(t, 2, code)
+}
+
+func TestFmt_Watch(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.json")
+	b := filepath.Join(dir, "sub", "b.hjson")
+	require.NoError(t, os.WriteFile(a, []byte(`{"x":[1]}`), 0o600))
+	require.NoError(t, os.MkdirAll(filepath.Dir(b), 0o700))
+	require.NoError(t, os.WriteFile(b, []byte("x: 1\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("{"), 0o600))
+
+	var stdout, stderr bytes.Buffer
+	w := newWatcher(&env{stdout: &stdout, stderr: &stderr}, []string{dir}, printerOptions("  ", true, false, false))
+	require.NoError(t, w.scan())
+	assert.Equal(t, "formatted "+a+"\n", stdout.String())
+	assert.Empty(t, stderr.String())
+	data, _ := os.ReadFile(a)
+	assert.Equal(t, "{\"x\":[1]}\n", string(data))
+
+	stdout.Reset()
+	require.NoError(t, w.scan())
+	assert.Empty(t, stdout.String(), "unchanged files are skipped")
+
+	require.NoError(t, os.WriteFile(a, []byte(`{"x": [1,`), 0o600))
+	require.NoError(t, os.WriteFile(b, []byte("{x: 1,"), 0o600))
+	require.NoError(t, w.scan())
+	assert.Empty(t, stdout.String())
+	assert.Contains(t, stderr.String(), a+": failed to parse: ")
+	assert.Contains(t, stderr.String(), b+": ")
+
+	stderr.Reset()
+	require.NoError(t, os.WriteFile(a, []byte(`[ true ]`), 0o600))
+	require.NoError(t, w.scan())
+	assert.Equal(t, "formatted "+a+"\n", stdout.String())
+	assert.Empty(t, stderr.String())
+
+	stdout.Reset()
+	require.NoError(t, os.RemoveAll(dir))
+	require.NoError(t, w.scan(), "paths vanishing after the first scan are reported")
+	assert.Contains(t, stderr.String(), dir)
+	assert.Empty(t, w.seen)
+
+	w = newWatcher(&env{stdout: &stdout, stderr: &stderr}, []string{filepath.Join(dir, "missing")}, nil)
+	assert.Error(t, w.scan())
+
+	code, _, _ := runGj(t, "", "fmt", "-watch")
+	assert.Equal(t, 2, code)
+}
+
+func TestDiff(t *testing.T) {
+	a := writeFile(t, "a.json", `{"a": 1, "b": [1, 2], "c": {"d": true}}`)
+	b := writeFile(t, "b.json", `{"a": 2, "b": [1], "c": {"d": true}, "e": "x"}`)
+
+	code, stdout, stderr := runGj(t, "", "diff", a, b)
+	assert.Equal(t, 0, code, stderr)
+	assert.Equal(t, "~ /a: 1 -> 2\n- /b/1: 2\n+ /e: \"x\"\n", stdout)
+
+	code, stdout, _ = runGj(t, "", "diff", "-color", "always", a, b)
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "\x1b[33m~ /a: 1 -> 2\x1b[0m\n\x1b[31m- /b/1: 2\x1b[0m\n\x1b[32m+ /e: \"x\"\x1b[0m\n", stdout)
+
+	code, stdout, _ = runGj(t, "", "diff", "-patch", a, b)
+	assert.Equal(t, 0, code)
+	assert.Equal(t, `[
+  {
+    "op": "replace",
+    "path": "/a",
+    "value": 2
+  },
+  {
+    "op": "remove",
+    "path": "/b/1"
+  },
+  {
+    "op": "add",
+    "path": "/e",
+    "value": "x"
+  }
+]
+`, stdout)
+
+	code, stdout, _ = runGj(t, `[1]`, "diff", "-", writeFile(t, "c.json", `{}`))
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "~ (root): [1] -> {}\n", stdout)
+
+	code, _, _ = runGj(t, "", "diff", a)
+	assert.Equal(t, 2, code)
+}
+
+const pathsInput = `{"users": [{"name": "ann", "age": 30}, {"name": "bob", "age": null, "tags": []}], "a/b": {"name": 1}}`
+
+func TestKeys(t *testing.T) {
+	code, stdout, stderr := runGj(t, pathsInput, "keys")
+	assert.Equal(t, 0, code, stderr)
+	assert.Equal(t, "users\nname\nage\ntags\na/b\n", stdout)
+
+	code, stdout, _ = runGj(t, pathsInput, "keys", "-count")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "users\t1\nname\t3\nage\t2\ntags\t1\na/b\t1\n", stdout)
+}
+
+func TestPaths(t *testing.T) {
+	code, stdout, stderr := runGj(t, pathsInput, "paths")
+	assert.Equal(t, 0, code, stderr)
+	assert.Equal(t, "/users/0/name\n/users/0/age\n/users/1/name\n/users/1/age\n/users/1/tags\n/a~1b/name\n", stdout)
+
+	code, stdout, _ = runGj(t, pathsInput, "paths", "-types")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "/users/0/name\tstring\n/users/0/age\tnumber\n/users/1/name\tstring\n/users/1/age\tnull\n/users/1/tags\tarray\n/a~1b/name\tnumber\n", stdout)
+
+	code, stdout, _ = runGj(t, pathsInput, "paths", "-types", "-count")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "/users/*/name\tstring\t2\n/users/*/age\tnumber|null\t2\n/users/*/tags\tarray\t1\n/a~1b/name\tnumber\t1\n", stdout)
+
+	code, stdout, _ = runGj(t, `3`, "paths", "-count")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "(root)\t1\n", stdout)
+}
+
+func TestSourcemap(t *testing.T) {
+	code, stdout, stderr := runGj(t, `{"a": [1, "x"], "b": {}}`, "sourcemap", "-compact")
+	assert.Equal(t, 0, code, stderr)
+	assert.Equal(t, `{"/a/0":{"start":7,"end":8},"/a/1":{"start":10,"end":13},"/b":{"start":21,"end":23}}`+"\n", stdout)
+
+	file := writeFile(t, "bad.json", `[1,]`)
+	code, _, stderr = runGj(t, "", "sourcemap", file)
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "bad.json: ")
+	assert.Contains(t, stderr, "trailing comma")
+}
+
+func TestTypescript(t *testing.T) {
+	a := writeFile(t, "a.json", `{"id": 1, "tags": ["x"]}`)
+	b := writeFile(t, "b.json", `{"id": 2, "tags": [], "owner": null}`)
+	code, stdout, stderr := runGj(t, "", "typescript", "-name", "item", a, b)
+	assert.Equal(t, 0, code, stderr)
+	assert.Equal(t, `export interface Item {
+  id: number;
+  tags: string[];
+  owner?: null;
+}
+`, stdout)
+
+	code, stdout, stderr = runGj(t, `[1, "x"]`, "typescript")
+	assert.Equal(t, 0, code, stderr)
+	assert.Equal(t, "export type Root = (string | number)[];\n", stdout)
+}
+
+func TestEmbed(t *testing.T) {
+	file := writeFile(t, "app-config.json", `{"a": [1, 2.5, "x"], "b": {"c": null, "d": false}}`)
+	want := `// Code generated by gj embed; DO NOT EDIT.
+
+package config
+
+import "github.com/pohedev/gj.git/ast"
+
+// appConfig is the document parsed from app-config.json.
+var appConfig = &ast.RootNode{
+	RootNodeType: ast.RootNodeTypeObject,
+	Value: &ast.Value{Value: &ast.Object{Children: []ast.Property{
+		{Identifier: ast.Identifier{Value: "a", Start: 1, End: 4}, Value: &ast.Value{Value: &ast.Array{Children: []ast.ArrayItem{
+			{Value: &ast.Literal{LiteralType: ast.LiteralTypeNumber, Val: int64(1), Raw: "1"}},
+			{Value: &ast.Literal{LiteralType: ast.LiteralTypeNumber, Val: float64(2.5), Raw: "2.5"}},
+			{Value: &ast.Literal{LiteralType: ast.LiteralTypeString, Val: "x", Raw: "\"x\""}},
+		}, Start: 6, End: 18}}},
+		{Identifier: ast.Identifier{Value: "b", Start: 21, End: 24}, Value: &ast.Value{Value: &ast.Object{Children: []ast.Property{
+			{Identifier: ast.Identifier{Value: "c", Start: 27, End: 30}, Value: &ast.Value{Value: &ast.Literal{LiteralType: ast.LiteralTypeNull}}},
+			{Identifier: ast.Identifier{Value: "d", Start: 38, End: 41}, Value: &ast.Value{Value: &ast.Literal{LiteralType: ast.LiteralTypeFalse, Val: false}}},
+		}, Start: 26, End: 49}}},
+	}, Start: 0, End: 50}},
+}
+`
+	code, stdout, stderr := runGj(t, "", "embed", "-pkg", "config", file)
+	assert.Equal(t, 0, code, stderr)
+	assert.Equal(t, want, stdout)
+
+	out := filepath.Join(t.TempDir(), "config_gen.go")
+	t.Setenv("GOPACKAGE", "config")
+	code, stdout, stderr = runGj(t, "", "embed", "-var", "Defaults", "-o", out, file)
+	assert.Equal(t, 0, code, stderr)
+	assert.Empty(t, stdout)
+	src, err := os.ReadFile(out)
+	if assert.Nil(t, err) {
+		assert.Contains(t, string(src), "var Defaults = &ast.RootNode{")
+	}
+
+	code, _, stderr = runGj(t, "", "embed", "-var", "no-go", file)
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, `invalid variable name "no-go"`)
+
+	assert.Equal(t, "doc2024", varName("2024.json"))
+	assert.Equal(t, "docType", varName("type.json"))
+	assert.Equal(t, "myDataV2", varName("dir/my_data.v2.json"))
+}
+
+func TestEval(t *testing.T) {
+	code, stdout, stderr := runGj(t, pathsInput, "eval", "-compact", ".users[] | select(.age) | {name}")
+	assert.Equal(t, 0, code, stderr)
+	assert.Equal(t, "{\"name\":\"ann\"}\n", stdout)
+
+	code, stdout, stderr = runGj(t, pathsInput, "eval", "-raw", ".users[].name")
+	assert.Equal(t, 0, code, stderr)
+	assert.Equal(t, "ann\nbob\n", stdout)
+
+	code, _, stderr = runGj(t, pathsInput, "eval", ".users[")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "failed to compile")
+
+	code, _, _ = runGj(t, pathsInput, "eval")
+	assert.Equal(t, 2, code)
+}
+
+func TestConvert(t *testing.T) {
+	in := writeFile(t, "people.csv", "name,age,admin\nAda,36,true\nBob,,false\n")
+	code, stdout, stderr := runGj(t, "", "convert", "-infer", "-compact", in)
+	assert.Equal(t, 0, code, stderr)
+	assert.Equal(t, `[{"name":"Ada","age":36,"admin":true},{"name":"Bob","age":"","admin":false}]`+"\n", stdout)
+
+	out := filepath.Join(t.TempDir(), "people.tsv")
+	code, _, stderr = runGj(t, `{"people": [{"name": "Ada", "tags": ["x"]}, {"name": "Bob"}]}`, "convert", "-at", "/people", "-", out)
+	assert.Equal(t, 0, code, stderr)
+	data, err := os.ReadFile(out)
+	require.NoError(t, err)
+	assert.Equal(t, "name\ttags\nAda\t\"[\"\"x\"\"]\"\nBob\t\n", string(data))
+
+	code, stdout, stderr = runGj(t, "a: 1\n", "convert", "-from", "hjson", "-to", "json")
+	assert.Equal(t, 0, code, stderr)
+	assert.Equal(t, "{\n  \"a\": 1\n}\n", stdout)
+
+	code, stdout, stderr = runGj(t, `{"a": [1, {"b": null}]}`, "convert", "--to", "yaml")
+	assert.Equal(t, 0, code, stderr)
+	assert.Equal(t, "a:\n  - 1\n  - b: null\n", stdout)
+
+	out = filepath.Join(t.TempDir(), "config.toml")
+	code, _, stderr = runGj(t, `{"a": {"b": "c"}}`, "convert", "-", out)
+	assert.Equal(t, 0, code, stderr)
+	data, err = os.ReadFile(out)
+	require.NoError(t, err)
+	assert.Equal(t, "[a]\nb = \"c\"\n", string(data))
+
+	code, stdout, stderr = runGj(t, `{"a": [true]}`, "convert", "-to", "cbor")
+	assert.Equal(t, 0, code, stderr)
+	assert.Equal(t, "\xa1\x61a\x81\xf5", stdout)
+
+	code, stdout, stderr = runGj(t, `{"a": [true]}`, "convert", "-to", "msgpack")
+	assert.Equal(t, 0, code, stderr)
+	assert.Equal(t, "\x81\xa1a\x91\xc3", stdout)
+
+	code, _, stderr = runGj(t, "{}", "convert", "--to", "xml")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, `unsupported output format "xml", want one of json, yaml, toml, cbor, msgpack, csv, tsv`)
+
+	code, _, stderr = runGj(t, "{}", "convert", "-to", "csv")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "failed to convert to CSV")
+}
+
+func TestMerge(t *testing.T) {
+	base := writeFile(t, "base.json", `{"db": {"host": "localhost", "port": 5432}, "tags": ["a"], "plugins": ["auth"]}`)
+	env := writeFile(t, "prod.json", `{"db": {"host": "db.internal"}, "tags": ["b", "a"], "plugins": ["metrics"]}`)
+	code, stdout, stderr := runGj(t, "", "merge", "-compact", "-union", "/tags", "-append", "$.plugins", base, env)
+	assert.Equal(t, 0, code, stderr)
+	assert.Equal(t, `{"db":{"host":"db.internal","port":5432},"tags":["a","b"],"plugins":["auth","metrics"]}`+"\n", stdout)
+
+	code, _, stderr = runGj(t, "", "merge", base, filepath.Join(t.TempDir(), "missing.json"))
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "gj merge: failed to merge: ")
+
+	code, _, _ = runGj(t, "", "merge")
+	assert.Equal(t, 2, code)
+}
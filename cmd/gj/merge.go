@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pohedev/gj.git/merge"
+	"github.com/pohedev/gj.git/printer"
+)
+
+const mergeUsage = "merge [-append pattern] [-union pattern] [-replace pattern] [-indent s] [-compact] file..."
+
+// runMerge implements "gj merge": it deep-merges the documents in the
+// files left to right, later files overriding earlier ones, and prints
+// the result. Arrays are replaced unless a pattern selects another
+// strategy, see merge.Deep.
+func runMerge(env *env, args []string) error {
+	fs := newFlagSet(env, mergeUsage)
+	var opts strategyFlags
+	fs.Var(opts.flag(merge.Append), "append", "append the items of arrays at `pattern`; may be repeated")
+	fs.Var(opts.flag(merge.Union), "union", "append the missing items of arrays at `pattern`; may be repeated")
+	fs.Var(opts.flag(merge.Replace), "replace", "replace the values at `pattern` as a whole; may be repeated")
+	indent := fs.String("indent", "  ", "indentation of nested values")
+	compact := fs.Bool("compact", false, "write compact output on one line")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errUsage
+	}
+
+	root, err := merge.Files(fs.Args(), opts...)
+	if err != nil {
+		return err
+	}
+	if err := printer.Fprint(env.stdout, root, printerOptions(*indent, *compact, false, false)...); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(env.stdout)
+	return err
+}
+
+// strategyFlags collects the merge strategies given by flags, in order.
+type strategyFlags []merge.Option
+
+// flag returns a flag adding the patterns it is given with strategy s.
+func (f *strategyFlags) flag(s merge.Strategy) *strategyFlag {
+	return &strategyFlag{opts: f, strategy: s}
+}
+
+// strategyFlag is the value of a flag selecting a merge strategy.
+type strategyFlag struct {
+	opts     *strategyFlags
+	strategy merge.Strategy
+	patterns []string
+}
+
+func (f *strategyFlag) String() string {
+	if f == nil {
+		return ""
+	}
+	return strings.Join(f.patterns, ",")
+}
+
+func (f *strategyFlag) Set(s string) error {
+	f.patterns = append(f.patterns, s)
+	*f.opts = append(*f.opts, merge.At(s, f.strategy))
+	return nil
+}
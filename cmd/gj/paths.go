@@ -0,0 +1,172 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pohedev/gj.git/ast"
+	"github.com/pohedev/gj.git/path"
+)
+
+const (
+	keysUsage  = "keys [-count] [file]"
+	pathsUsage = "paths [-types] [-count] [file]"
+)
+
+// runKeys implements "gj keys": it lists the distinct object keys of
+// a document at any depth in order of first appearance, with -count
+// followed by their number of occurrences.
+func runKeys(env *env, args []string) error {
+	fs := newFlagSet(env, keysUsage)
+	count := fs.Bool("count", false, "print the number of occurrences of each key")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 1 {
+		fs.Usage()
+		return errUsage
+	}
+	root, err := readDocument(env, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	var keys tally
+	walk(root.Value, func(c *ast.Cursor) {
+		if key, ok := c.Key(); ok {
+			keys.add(key, "")
+		}
+	})
+	for _, key := range keys.order {
+		line := key
+		if *count {
+			line = fmt.Sprintf("%s\t%d", key, keys.counts[key])
+		}
+		if _, err := fmt.Fprintln(env.stdout, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runPaths implements "gj paths": it lists the JSON Pointers of the
+// leaves of a document, that is literals and empty containers. -types
+// adds their type; -count replaces array indexes by "*" and adds the
+// number of leaves sharing each path.
+func runPaths(env *env, args []string) error {
+	fs := newFlagSet(env, pathsUsage)
+	types := fs.Bool("types", false, "print the type of each leaf")
+	count := fs.Bool("count", false, "merge array indexes into * and print the number of leaves per path")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 1 {
+		fs.Usage()
+		return errUsage
+	}
+	root, err := readDocument(env, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	var paths tally
+	walk(root.Value, func(c *ast.Cursor) {
+		node := c.Value().Value
+		if !isLeaf(node) {
+			return
+		}
+		ptr := c.Path()
+		if *count {
+			ptr = wildcardPath(root.Value, ptr)
+		}
+		paths.add(ptr, ast.TypeName(node))
+	})
+	for _, ptr := range paths.order {
+		line := displayPath(ptr)
+		if *types {
+			line += "\t" + strings.Join(paths.types[ptr], "|")
+		}
+		if *count {
+			line += fmt.Sprintf("\t%d", paths.counts[ptr])
+		}
+		if _, err := fmt.Fprintln(env.stdout, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tally counts distinct strings in order of first appearance,
+// along with the distinct types seen for each.
+type tally struct {
+	order  []string
+	counts map[string]int
+	types  map[string][]string
+}
+
+// add records an occurrence of s with type typ.
+func (t *tally) add(s, typ string) {
+	if t.counts == nil {
+		t.counts = map[string]int{}
+		t.types = map[string][]string{}
+	}
+	if t.counts[s] == 0 {
+		t.order = append(t.order, s)
+	}
+	t.counts[s]++
+	for _, seen := range t.types[s] {
+		if seen == typ {
+			return
+		}
+	}
+	t.types[s] = append(t.types[s], typ)
+}
+
+// walk calls fn for every value of the tree at root in document order.
+func walk(root *ast.Value, fn func(c *ast.Cursor)) {
+	c := ast.NewCursor(root)
+	for {
+		fn(c)
+		if c.Enter() {
+			continue
+		}
+		for !c.Next() {
+			if !c.Up() {
+				return
+			}
+		}
+	}
+}
+
+// wildcardPath returns JSON Pointer ptr of a value in root
+// with array indexes replaced by "*".
+func wildcardPath(root *ast.Value, ptr string) string {
+	if ptr == "" {
+		return ""
+	}
+	tokens := strings.Split(ptr, "/")[1:]
+	v := root
+	for i, token := range tokens {
+		switch n := v.Value.(type) {
+		case *ast.Object:
+			v, _ = n.Get(path.Unescape(token))
+		case *ast.Array:
+			index, _ := strconv.Atoi(token)
+			v, _ = n.At(index)
+			tokens[i] = "*"
+		}
+	}
+	return "/" + strings.Join(tokens, "/")
+}
+
+// isLeaf reports whether node is a literal or an empty container.
+func isLeaf(node any) bool {
+	switch n := node.(type) {
+	case *ast.Object:
+		return len(n.Children) == 0
+	case *ast.Array:
+		return len(n.Children) == 0
+	}
+	return true
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/pohedev/gj.git"
+)
+
+const sourcemapUsage = "sourcemap [-compact] [file]"
+
+// runSourcemap implements "gj sourcemap": it prints a JSON object mapping
+// the JSON Pointer of each leaf of a document to its byte range in the
+// input, as {"start": offset, "end": offset} with end excluded.
+func runSourcemap(env *env, args []string) error {
+	fs := newFlagSet(env, sourcemapUsage)
+	compact := fs.Bool("compact", false, "print the map on one line")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 1 {
+		fs.Usage()
+		return errUsage
+	}
+	name := fs.Arg(0)
+	input, err := readInput(env, name)
+	if err != nil {
+		return err
+	}
+	spans, err := gj.SourceMap(input)
+	if err != nil {
+		if name != "" && name != "-" {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		return err
+	}
+
+	var out []byte
+	if *compact {
+		out, err = json.Marshal(spans)
+	} else {
+		out, err = json.MarshalIndent(spans, "", "  ")
+	}
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(env.stdout, "%s\n", out)
+	return err
+}
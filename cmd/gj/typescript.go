@@ -0,0 +1,35 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/pohedev/gj.git/ast"
+	"github.com/pohedev/gj.git/typegen"
+)
+
+const typescriptUsage = "typescript [-name name] [file...]"
+
+// runTypescript implements "gj typescript": it prints TypeScript
+// declarations of the type inferred from the documents in the files, or
+// stdin when none is given, merged as samples of the same payload.
+func runTypescript(env *env, args []string) error {
+	fs := newFlagSet(env, typescriptUsage)
+	name := fs.String("name", "Root", "name of the root type")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	names := fs.Args()
+	if len(names) == 0 {
+		names = []string{""}
+	}
+	roots := make([]*ast.RootNode, 0, len(names))
+	for _, file := range names {
+		root, err := readDocument(env, file)
+		if err != nil {
+			return err
+		}
+		roots = append(roots, root)
+	}
+	_, err := fmt.Fprint(env.stdout, typegen.TypeScript(*name, roots...))
+	return err
+}
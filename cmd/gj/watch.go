@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pohedev/gj.git/printer"
+)
+
+// watcher reformats files as they change, for "gj fmt -watch". Files are
+// polled, comparing their modification time and size with the last scan.
+type watcher struct {
+	env   *env
+	paths []string // Files and directories to watch.
+	opts  []printer.Option
+	seen  map[string]fileStamp
+	ready bool // Whether a scan has completed.
+}
+
+// fileStamp identifies a version of a file.
+type fileStamp struct {
+	mod  time.Time
+	size int64
+}
+
+// newWatcher returns a watcher of paths writing files with opts.
+func newWatcher(env *env, paths []string, opts []printer.Option) *watcher {
+	return &watcher{env: env, paths: paths, opts: opts, seen: map[string]fileStamp{}}
+}
+
+// run scans the files every interval until ctx is done.
+func (w *watcher) run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := w.scan(); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// scan handles the files changed since the last scan, or all of them on
+// the first one. Files given explicitly are watched whatever their name;
+// directories are searched for .json and .hjson files. JSON files are
+// rewritten in place when their formatting differs, HJSON files are only
+// checked. Syntax errors and files vanishing between listing and stat,
+// as with atomic saves, are reported on stderr without stopping; the
+// error is only non-nil when a path given cannot be read on the first
+// scan.
+func (w *watcher) scan() error {
+	current := map[string]bool{}
+	for _, root := range w.paths {
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if path == root && !w.ready {
+					return err
+				}
+				fmt.Fprintln(w.env.stderr, err)
+				return nil
+			}
+			if d.IsDir() {
+				return nil
+			}
+			if path != root {
+				switch filepath.Ext(path) {
+				case ".json", ".hjson":
+				default:
+					return nil
+				}
+			}
+			info, err := d.Info()
+			if err != nil {
+				fmt.Fprintln(w.env.stderr, err)
+				return nil
+			}
+			current[path] = true
+			stamp := fileStamp{mod: info.ModTime(), size: info.Size()}
+			if w.seen[path] == stamp {
+				return nil
+			}
+			w.seen[path] = stamp
+			w.format(path, info.Mode())
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	for path := range w.seen {
+		if !current[path] {
+			delete(w.seen, path)
+		}
+	}
+	w.ready = true
+	return nil
+}
+
+// format reformats the file at path, or only checks it for HJSON files,
+// reporting the outcome.
+func (w *watcher) format(path string, mode fs.FileMode) {
+	root, err := readDocument(w.env, path)
+	if err != nil {
+		fmt.Fprintln(w.env.stderr, err)
+		return
+	}
+	if filepath.Ext(path) == ".hjson" {
+		return
+	}
+	out, err := printer.Sprint(root, w.opts...)
+	if err != nil {
+		fmt.Fprintf(w.env.stderr, "%s: %v\n", path, err)
+		return
+	}
+	out += "\n"
+	data, err := os.ReadFile(path)
+	if err == nil && string(data) == out {
+		return
+	}
+	if err := os.WriteFile(path, []byte(out), mode.Perm()); err != nil {
+		fmt.Fprintln(w.env.stderr, err)
+		return
+	}
+	if info, err := os.Stat(path); err == nil {
+		w.seen[path] = fileStamp{mod: info.ModTime(), size: info.Size()}
+	}
+	fmt.Fprintf(w.env.stdout, "formatted %s\n", path)
+}
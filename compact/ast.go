@@ -0,0 +1,60 @@
+package compact
+
+import (
+	"strconv"
+
+	"github.com/pohedev/gj.git/ast"
+)
+
+// AST converts the document into an ast tree for APIs working on
+// package ast. Numbers keep their source text; other positions and the
+// source text of strings are not kept.
+func (d *Document) AST() *ast.RootNode {
+	root := &ast.RootNode{RootNodeType: ast.RootNodeTypeLiteral, Value: &ast.Value{Value: d.node(0)}}
+	switch d.Kind(0) {
+	case Object:
+		root.RootNodeType = ast.RootNodeTypeObject
+	case Array:
+		root.RootNodeType = ast.RootNodeTypeArray
+	}
+	return root
+}
+
+// node returns the ast node of id.
+func (d *Document) node(id NodeID) any {
+	n := d.Nodes[id]
+	switch n.Kind {
+	case Object:
+		obj := &ast.Object{Children: make([]ast.Property, n.Len), Start: int(n.Start)}
+		for i := range obj.Children {
+			key, v := d.Property(id, i)
+			obj.Children[i] = ast.Property{Identifier: ast.Identifier{Value: key}, Value: &ast.Value{Value: d.node(v)}}
+		}
+		return obj
+	case Array:
+		array := &ast.Array{Children: make([]ast.ArrayItem, n.Len), Start: int(n.Start)}
+		for i := range array.Children {
+			v, _ := d.At(id, i)
+			array.Children[i].Value = d.node(v)
+		}
+		return array
+	case String:
+		return ast.String(d.text(id))
+	case Number:
+		raw := d.text(id)
+		var lit *ast.Literal
+		if i, err := strconv.ParseInt(raw, 10, 64); err == nil {
+			lit = ast.Number(i)
+		} else {
+			f, _ := strconv.ParseFloat(raw, 64)
+			lit = ast.Number(f)
+		}
+		lit.Raw = raw
+		return lit
+	case True:
+		return ast.Bool(true)
+	case False:
+		return ast.Bool(false)
+	}
+	return ast.Null()
+}
@@ -0,0 +1,194 @@
+// Package compact provides an index-based representation of JSON documents.
+//
+// A Document stores all its nodes in one slice and refers to them by
+// NodeID. The children of each object or array are a contiguous run of
+// the Children slice, and strings, keys and the source text of numbers
+// share one byte buffer. Parsing makes a handful of allocations instead
+// of several per value, and nodes are not boxed in interfaces, which
+// makes a Document much smaller than the equivalent ast tree. Unlike
+// package tape, children are reached by index in constant time.
+//
+// Offsets are stored as uint32, limiting documents to 4 GiB.
+package compact
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/pohedev/gj.git/lexer"
+)
+
+// Kind identifies the type of a Node.
+type Kind uint8
+
+const (
+	Null Kind = iota
+	True
+	False
+	Number
+	String
+	Key
+	Object
+	Array
+)
+
+var kindNames = [...]string{
+	Null:   "null",
+	True:   "true",
+	False:  "false",
+	Number: "number",
+	String: "string",
+	Key:    "key",
+	Object: "object",
+	Array:  "array",
+}
+
+func (k Kind) String() string {
+	if int(k) < len(kindNames) {
+		return kindNames[k]
+	}
+	return fmt.Sprintf("Kind(%d)", uint8(k))
+}
+
+// NodeID identifies a Node of a Document. The root value is 0.
+type NodeID uint32
+
+// Node is a value of a Document. The meaning of Off and Len depends on Kind:
+//
+//   - Object: Off is the index in Children of the first property, which
+//     takes two entries, the Key node and the value; Len is the number of
+//     properties.
+//   - Array: Off is the index in Children of the first item, Len the
+//     number of items.
+//   - String, Key: Off is the offset of the decoded text in Text, Len its
+//     length in bytes.
+//   - Number: Off is the offset of the source text in Text, Len its length.
+type Node struct {
+	Kind  Kind
+	Start uint32 // Byte offset of the value in the input.
+	Off   uint32
+	Len   uint32
+}
+
+// Document is an index-based representation of a JSON document.
+type Document struct {
+	Nodes    []Node   // Nodes in document order, the root value first.
+	Children []NodeID // Children of objects and arrays.
+	Text     []byte   // Decoded strings and keys, source text of numbers.
+
+	lex   *lexer.Lexer // Reused by Parse.
+	open  []NodeID     // Open containers while parsing, innermost last.
+	stack []NodeID     // Children of the open containers while parsing.
+}
+
+// Reset empties the Document, keeping its allocations.
+func (d *Document) Reset() {
+	d.Nodes = d.Nodes[:0]
+	d.Children = d.Children[:0]
+	d.Text = d.Text[:0]
+	d.open = d.open[:0]
+	d.stack = d.stack[:0]
+}
+
+// Kind returns the kind of node id.
+func (d *Document) Kind(id NodeID) Kind {
+	return d.Nodes[id].Kind
+}
+
+// Len returns the number of properties or items of the object or array
+// id, 0 for other values.
+func (d *Document) Len(id NodeID) int {
+	switch n := d.Nodes[id]; n.Kind {
+	case Object, Array:
+		return int(n.Len)
+	}
+	return 0
+}
+
+// Property returns the key and the value of the i-th property of
+// object id.
+func (d *Document) Property(id NodeID, i int) (string, NodeID) {
+	n := d.Nodes[id]
+	if n.Kind != Object || i < 0 || i >= int(n.Len) {
+		panic(fmt.Sprintf("compact: no property %d in %v node %d", i, n.Kind, id))
+	}
+	off := int(n.Off) + 2*i
+	return d.text(d.Children[off]), d.Children[off+1]
+}
+
+// Get returns the value of key in object id. When a key appears more
+// than once, the last value wins.
+func (d *Document) Get(id NodeID, key string) (NodeID, bool) {
+	n := d.Nodes[id]
+	if n.Kind != Object {
+		return 0, false
+	}
+	for i := int(n.Len) - 1; i >= 0; i-- {
+		off := int(n.Off) + 2*i
+		if d.text(d.Children[off]) == key {
+			return d.Children[off+1], true
+		}
+	}
+	return 0, false
+}
+
+// At returns the i-th item of array id.
+func (d *Document) At(id NodeID, i int) (NodeID, bool) {
+	n := d.Nodes[id]
+	if n.Kind != Array || i < 0 || i >= int(n.Len) {
+		return 0, false
+	}
+	return d.Children[int(n.Off)+i], true
+}
+
+// AsString returns the value of string node id.
+func (d *Document) AsString(id NodeID) (string, bool) {
+	if d.Nodes[id].Kind != String {
+		return "", false
+	}
+	return d.text(id), true
+}
+
+// AsBool returns the value of boolean node id.
+func (d *Document) AsBool(id NodeID) (bool, bool) {
+	switch d.Nodes[id].Kind {
+	case True:
+		return true, true
+	case False:
+		return false, true
+	}
+	return false, false
+}
+
+// AsInt returns the value of number node id if it is an integer
+// fitting in an int64.
+func (d *Document) AsInt(id NodeID) (int64, bool) {
+	if d.Nodes[id].Kind != Number {
+		return 0, false
+	}
+	i, err := strconv.ParseInt(d.text(id), 10, 64)
+	return i, err == nil
+}
+
+// AsFloat returns the value of number node id as a float64.
+func (d *Document) AsFloat(id NodeID) (float64, bool) {
+	if d.Nodes[id].Kind != Number {
+		return 0, false
+	}
+	f, err := strconv.ParseFloat(d.text(id), 64)
+	return f, err == nil
+}
+
+// Raw returns the source text of number node id.
+func (d *Document) Raw(id NodeID) (string, bool) {
+	if d.Nodes[id].Kind != Number {
+		return "", false
+	}
+	return d.text(id), true
+}
+
+// text returns the text of the String, Key or Number node id.
+func (d *Document) text(id NodeID) string {
+	n := d.Nodes[id]
+	return string(d.Text[n.Off : n.Off+n.Len])
+}
@@ -0,0 +1,178 @@
+package compact
+
+import (
+	"testing"
+
+	"github.com/pohedev/gj.git/lexer"
+	"github.com/pohedev/gj.git/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	var tests = []struct {
+		name     string
+		input    string
+		nodes    []Node
+		children []NodeID
+		text     string
+	}{
+		{
+			name:  "literal",
+			input: `"a\nb"`,
+			nodes: []Node{{Kind: String, Len: 3}},
+			text:  "a\nb",
+		},
+		{
+			name:  "empty containers",
+			input: `[{}, []]`,
+			nodes: []Node{
+				{Kind: Array, Off: 0, Len: 2},
+				{Kind: Object, Start: 1},
+				{Kind: Array, Start: 5},
+			},
+			children: []NodeID{1, 2},
+		},
+		{
+			name:  "object",
+			input: `{"a": 1, "bc": [1.5, true, null], "d": false}`,
+			nodes: []Node{
+				{Kind: Object, Off: 3, Len: 3},
+				{Kind: Key, Start: 1, Off: 0, Len: 1},
+				{Kind: Number, Start: 6, Off: 1, Len: 1},
+				{Kind: Key, Start: 9, Off: 2, Len: 2},
+				{Kind: Array, Start: 15, Off: 0, Len: 3},
+				{Kind: Number, Start: 16, Off: 4, Len: 3},
+				{Kind: True, Start: 21},
+				{Kind: Null, Start: 27},
+				{Kind: Key, Start: 34, Off: 7, Len: 1},
+				{Kind: False, Start: 39},
+			},
+			children: []NodeID{5, 6, 7, 1, 2, 3, 4, 8, 9},
+			text:     "a1bc1.5d",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := Parse(tt.input)
+			if assert.Nil(t, err) {
+				assert.Equal(t, tt.nodes, d.Nodes)
+				assert.Equal(t, tt.children, d.Children)
+				assert.Equal(t, tt.text, string(d.Text))
+			}
+		})
+	}
+}
+
+func TestParse_Error(t *testing.T) {
+	var tests = []struct {
+		name   string
+		input  string
+		offset int
+		kind   error
+	}{
+		{"empty", ``, 0, parser.ErrUnexpectedEOF},
+		{"missing colon", `{"a" 1}`, 5, parser.ErrUnexpectedToken},
+		{"missing brace", `{"a": 1`, 7, parser.ErrUnexpectedEOF},
+		{"trailing comma", `[1, ]`, 4, parser.ErrTrailingComma},
+		{"trailing content", `[1] 2`, 4, parser.ErrTrailingContent},
+		{"bad number", `[01]`, 1, parser.ErrInvalidNumber},
+		{"bad escape", `["\x"]`, 2, parser.ErrInvalidString},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.input)
+			var syntaxErr *parser.SyntaxError
+			if assert.ErrorAs(t, err, &syntaxErr) {
+				assert.Equal(t, tt.offset, syntaxErr.Offset)
+			}
+			assert.ErrorIs(t, err, tt.kind)
+		})
+	}
+}
+
+func TestDocument_Access(t *testing.T) {
+	d, err := Parse(`{"a": {"b": [1, "x", 2.5e0]}, "c": true, "a": {"b": null}, "n": 9223372036854775808}`)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, Object, d.Kind(0))
+	assert.Equal(t, 4, d.Len(0))
+
+	key, v := d.Property(0, 1)
+	assert.Equal(t, "c", key)
+	b, ok := d.AsBool(v)
+	assert.True(t, ok)
+	assert.True(t, b)
+
+	a, ok := d.Get(0, "a")
+	assert.True(t, ok)
+	v, _ = d.Get(a, "b")
+	assert.Equal(t, Null, d.Kind(v), "the last duplicate key wins")
+	_, ok = d.Get(0, "z")
+	assert.False(t, ok)
+
+	_, first := d.Property(0, 0)
+	arr, _ := d.Get(first, "b")
+	assert.Equal(t, 3, d.Len(arr))
+	item, _ := d.At(arr, 0)
+	i, ok := d.AsInt(item)
+	assert.True(t, ok)
+	assert.Equal(t, int64(1), i)
+	item, _ = d.At(arr, 1)
+	s, ok := d.AsString(item)
+	assert.True(t, ok)
+	assert.Equal(t, "x", s)
+	item, _ = d.At(arr, 2)
+	f, _ := d.AsFloat(item)
+	assert.Equal(t, 2.5, f)
+	raw, _ := d.Raw(item)
+	assert.Equal(t, "2.5e0", raw)
+	_, ok = d.AsInt(item)
+	assert.False(t, ok)
+	_, ok = d.At(arr, 3)
+	assert.False(t, ok)
+	_, ok = d.At(0, 0)
+	assert.False(t, ok)
+
+	n, _ := d.Get(0, "n")
+	_, ok = d.AsInt(n)
+	assert.False(t, ok, "out of int64 range")
+	assert.Panics(t, func() { d.Property(arr, 0) })
+}
+
+func TestDocument_AST(t *testing.T) {
+	input := `{"a": [1, 2.5, "x"], "b": {"c": null, "d": false}, "e": 1e3}`
+	d, err := Parse(input)
+	if !assert.Nil(t, err) {
+		return
+	}
+	want, err := parser.New(lexer.Lex(input)).Parse()
+	if assert.Nil(t, err) {
+		assert.Equal(t, want.ToGo(), d.AST().ToGo())
+	}
+}
+
+func TestDocument_ParseReuse(t *testing.T) {
+	d := &Document{}
+	if !assert.Nil(t, d.Parse(benchInput)) {
+		return
+	}
+	allocs := testing.AllocsPerRun(10, func() {
+		if err := d.Parse(benchInput); err != nil {
+			t.Fatal(err)
+		}
+	})
+	assert.Zero(t, allocs)
+}
+
+const benchInput = `{"id": 1, "name": "gj", "tags": ["a", "b", "c"], "nested": {"x": 1.5, "y": [true, false, null]}}`
+
+func BenchmarkParse(b *testing.B) {
+	b.ReportAllocs()
+	d := &Document{}
+	for i := 0; i < b.N; i++ {
+		if err := d.Parse(benchInput); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
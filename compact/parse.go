@@ -0,0 +1,116 @@
+package compact
+
+import (
+	"github.com/pohedev/gj.git/internal/jsonscan"
+	"github.com/pohedev/gj.git/lexer"
+	"github.com/pohedev/gj.git/parser"
+)
+
+// Parse parses input into a new Document.
+// Errors are reported as *parser.SyntaxError.
+func Parse(input string) (*Document, error) {
+	d := &Document{}
+	if err := d.Parse(input); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Parse replaces the content of the Document with the document in input,
+// reusing its allocations.
+func (d *Document) Parse(input string) error {
+	d.Reset()
+	if d.lex == nil {
+		d.lex = lexer.Lex(input)
+	} else {
+		d.lex.Reset(input)
+	}
+	if err := jsonscan.Scan(d.lex, (*builder)(d)); err != nil {
+		return (*parser.SyntaxError)(err)
+	}
+	return nil
+}
+
+// builder appends the nodes of the values read by jsonscan.Scan to a
+// Document. The children of the open containers are pushed on the stack;
+// while open, the Off of a container is the length of the stack before
+// its children.
+type builder Document
+
+// add appends a node of kind at item and returns its id. The node is a
+// child of the innermost open container, if any.
+func (b *builder) add(kind Kind, item lexer.Item) NodeID {
+	id := NodeID(len(b.Nodes))
+	b.Nodes = append(b.Nodes, Node{Kind: kind, Start: uint32(item.Pos)})
+	if len(b.open) > 0 {
+		b.stack = append(b.stack, id)
+	}
+	return id
+}
+
+// addText appends a node of kind holding text.
+func (b *builder) addText(kind Kind, item lexer.Item, text string) {
+	id := b.add(kind, item)
+	b.Nodes[id].Off = uint32(len(b.Text))
+	b.Nodes[id].Len = uint32(len(text))
+	b.Text = append(b.Text, text...)
+}
+
+// begin appends a container node of kind.
+func (b *builder) begin(kind Kind, item lexer.Item) {
+	id := b.add(kind, item)
+	b.Nodes[id].Off = uint32(len(b.stack))
+	b.open = append(b.open, id)
+}
+
+// end moves the children of the innermost container from the stack to
+// Children.
+func (b *builder) end(n int) {
+	id := b.open[len(b.open)-1]
+	b.open = b.open[:len(b.open)-1]
+	base := int(b.Nodes[id].Off)
+	b.Nodes[id].Off = uint32(len(b.Children))
+	b.Nodes[id].Len = uint32(n)
+	b.Children = append(b.Children, b.stack[base:]...)
+	b.stack = b.stack[:base]
+}
+
+func (b *builder) Null(item lexer.Item) {
+	b.add(Null, item)
+}
+
+func (b *builder) Bool(item lexer.Item, v bool) {
+	if v {
+		b.add(True, item)
+	} else {
+		b.add(False, item)
+	}
+}
+
+func (b *builder) Number(item lexer.Item) {
+	b.addText(Number, item, item.Val)
+}
+
+func (b *builder) String(item lexer.Item, s string) {
+	b.addText(String, item, s)
+}
+
+func (b *builder) BeginObject(item lexer.Item) {
+	b.begin(Object, item)
+}
+
+func (b *builder) Key(item lexer.Item, s string) {
+	b.addText(Key, item, s)
+}
+
+func (b *builder) EndObject(item lexer.Item, n int) {
+	b.end(n)
+}
+
+func (b *builder) BeginArray(item lexer.Item) {
+	b.begin(Array, item)
+}
+
+func (b *builder) EndArray(item lexer.Item, n int) {
+	b.end(n)
+}
@@ -0,0 +1,96 @@
+package convert
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/pohedev/gj.git/ast"
+)
+
+// ToCBOR converts a document to CBOR (RFC 8949). node is an *ast.RootNode
+// or any node of a tree. Integers are written in their shortest form,
+// other numbers as 64-bit floats, objects as maps keeping key order.
+func ToCBOR(node any) ([]byte, error) {
+	b, err := appendCBOR(nil, node)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert to CBOR: %w", err)
+	}
+	return b, nil
+}
+
+// CBOR major types.
+const (
+	cborUint   = 0 << 5
+	cborNegint = 1 << 5
+	cborText   = 3 << 5
+	cborArray  = 4 << 5
+	cborMap    = 5 << 5
+	cborSimple = 7 << 5
+)
+
+// appendCBOR appends the CBOR encoding of node to b.
+func appendCBOR(b []byte, node any) ([]byte, error) {
+	node, err := resolve(node)
+	if err != nil {
+		return nil, err
+	}
+	switch n := node.(type) {
+	case *ast.Object:
+		b = appendCBORHead(b, cborMap, uint64(len(n.Children)))
+		for _, prop := range n.Children {
+			b = appendCBORHead(b, cborText, uint64(len(prop.Identifier.Value)))
+			b = append(b, prop.Identifier.Value...)
+			if b, err = appendCBOR(b, prop.Value); err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+	case *ast.Array:
+		b = appendCBORHead(b, cborArray, uint64(len(n.Children)))
+		for _, item := range n.Children {
+			if b, err = appendCBOR(b, item.Value); err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+	}
+	lit := node.(*ast.Literal)
+	switch v := lit.Val.(type) {
+	case nil:
+		return append(b, cborSimple|22), nil
+	case bool:
+		if v {
+			return append(b, cborSimple|21), nil
+		}
+		return append(b, cborSimple|20), nil
+	case string:
+		b = appendCBORHead(b, cborText, uint64(len(v)))
+		return append(b, v...), nil
+	case int64:
+		if v < 0 {
+			return appendCBORHead(b, cborNegint, uint64(-(v + 1))), nil
+		}
+		return appendCBORHead(b, cborUint, uint64(v)), nil
+	case float64:
+		b = append(b, cborSimple|27)
+		return binary.BigEndian.AppendUint64(b, math.Float64bits(v)), nil
+	}
+	return nil, fmt.Errorf("unsupported literal value %T", lit.Val)
+}
+
+// appendCBORHead appends the head of a data item of the major type with
+// argument n, a value, length or count, in its shortest form.
+func appendCBORHead(b []byte, major byte, n uint64) []byte {
+	switch {
+	case n < 24:
+		return append(b, major|byte(n))
+	case n <= math.MaxUint8:
+		return append(b, major|24, byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, major|25), uint16(n))
+	case n <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(b, major|26), uint32(n))
+	}
+	return binary.BigEndian.AppendUint64(append(b, major|27), n)
+}
@@ -0,0 +1,156 @@
+// Package convert converts documents to and from other formats.
+package convert
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/pohedev/gj.git/ast"
+	"github.com/pohedev/gj.git/parser"
+	"github.com/pohedev/gj.git/path"
+)
+
+// Nested identifies how ToCSV writes objects and arrays found in cells.
+type Nested int
+
+const (
+	NestedJSON    Nested = iota // Write them as compact JSON text.
+	NestedFlatten               // Flatten them into columns such as "a.b[0]".
+	NestedError                 // Fail.
+)
+
+// config holds the settings of a conversion.
+type config struct {
+	comma  rune
+	at     string
+	null   string
+	nested Nested
+
+	inferNumbers bool
+	inferBools   bool
+	nullTokens   []string
+}
+
+// Option configures a conversion.
+type Option func(*config)
+
+// Comma sets the field delimiter, e.g. '\t' for TSV. The default is ','.
+func Comma(r rune) Option {
+	return func(c *config) {
+		c.comma = r
+	}
+}
+
+// At selects the array to convert by its JSON Pointer in the document.
+// The default is the document itself.
+func At(ptr string) Option {
+	return func(c *config) {
+		c.at = ptr
+	}
+}
+
+// NullAs sets the text written for null values. The default is the empty
+// string, which is also written for missing keys.
+func NullAs(s string) Option {
+	return func(c *config) {
+		c.null = s
+	}
+}
+
+// WithNested sets how nested objects and arrays are written.
+// The default is NestedJSON.
+func WithNested(n Nested) Option {
+	return func(c *config) {
+		c.nested = n
+	}
+}
+
+// InferNumbers makes FromCSV read cells holding a JSON number as numbers.
+// By default every cell is read as a string.
+func InferNumbers() Option {
+	return func(c *config) {
+		c.inferNumbers = true
+	}
+}
+
+// InferBools makes FromCSV read cells holding true or false as booleans.
+func InferBools() Option {
+	return func(c *config) {
+		c.inferBools = true
+	}
+}
+
+// NullTokens makes FromCSV read cells equal to one of tokens as null,
+// e.g. "", "NULL" or "N/A".
+func NullTokens(tokens ...string) Option {
+	return func(c *config) {
+		c.nullTokens = tokens
+	}
+}
+
+func newConfig(opts []Option) config {
+	c := config{comma: ','}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c
+}
+
+// resolve returns node as an *ast.Object, *ast.Array or *ast.Literal,
+// stripping Value wrappers and parsing RawValue nodes.
+func resolve(node any) (any, error) {
+	switch n := node.(type) {
+	case *ast.RootNode:
+		if n == nil {
+			return ast.Null(), nil
+		}
+		return resolve(n.Value)
+	case *ast.Value:
+		if n == nil {
+			return ast.Null(), nil
+		}
+		return resolve(n.Value)
+	case *ast.RawValue:
+		v, err := parser.ParseRaw(n)
+		if err != nil {
+			return nil, err
+		}
+		return resolve(v)
+	case *ast.Object, *ast.Array, *ast.Literal:
+		return n, nil
+	case nil:
+		return ast.Null(), nil
+	}
+	return nil, fmt.Errorf("unsupported node %T", node)
+}
+
+// lookup returns the node at JSON Pointer ptr in node.
+func lookup(node any, ptr string) (any, error) {
+	p, err := path.Parse(ptr)
+	if err != nil {
+		return nil, err
+	}
+	if ptr != "" && ptr[0] != '/' {
+		return nil, fmt.Errorf("invalid JSON Pointer %q", ptr)
+	}
+	for _, seg := range p {
+		if node, err = resolve(node); err != nil {
+			return nil, err
+		}
+		var v *ast.Value
+		var ok bool
+		switch n := node.(type) {
+		case *ast.Object:
+			v, ok = n.Get(seg.Key)
+		case *ast.Array:
+			if i, err := strconv.Atoi(seg.Key); err == nil {
+				v, ok = n.At(i)
+			}
+		}
+		if !ok {
+			return nil, fmt.Errorf("no value at %q", ptr)
+		}
+		node = v
+	}
+	return resolve(node)
+}
@@ -0,0 +1,250 @@
+package convert_test
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/pohedev/gj.git/ast"
+	"github.com/pohedev/gj.git/convert"
+	"github.com/pohedev/gj.git/gjtest"
+	"github.com/pohedev/gj.git/printer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToCSV(t *testing.T) {
+	const doc = `[
+		{"name": "ann", "age": 31, "tags": ["a", "b"], "addr": {"city": "Oslo"}},
+		{"name": "bob, jr", "age": null, "admin": true, "age": 25.50},
+		{"name": "cy \"c\"", "admin": false, "addr": {}}
+	]`
+	var tests = []struct {
+		name  string
+		input string
+		opts  []convert.Option
+		want  string
+	}{
+		{
+			name:  "default",
+			input: doc,
+			want: "name,age,tags,addr,admin\n" +
+				"ann,31,\"[\"\"a\"\",\"\"b\"\"]\",\"{\"\"city\"\":\"\"Oslo\"\"}\",\n" +
+				"\"bob, jr\",25.50,,,true\n" +
+				"\"cy \"\"c\"\"\",,,{},false\n",
+		},
+		{
+			name:  "flatten",
+			input: doc,
+			opts:  []convert.Option{convert.WithNested(convert.NestedFlatten), convert.Comma('\t')},
+			want: "name\tage\ttags[0]\ttags[1]\taddr.city\tadmin\taddr\n" +
+				"ann\t31\ta\tb\tOslo\t\t\n" +
+				"bob, jr\t25.50\t\t\t\ttrue\t\n" +
+				"\"cy \"\"c\"\"\"\t\t\t\t\tfalse\t{}\n",
+		},
+		{
+			name:  "null",
+			input: `{"data": {"rows": [{"a": null, "b": 1}, {"b": 2}]}}`,
+			opts:  []convert.Option{convert.At("/data/rows"), convert.NullAs("NULL")},
+			want:  "a,b\nNULL,1\n,2\n",
+		},
+		{
+			name:  "empty",
+			input: `[]`,
+			want:  "\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := convert.ToCSV(gjtest.Parse(t, tt.input), tt.opts...)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestToCSV_Error(t *testing.T) {
+	var tests = []struct {
+		input string
+		opts  []convert.Option
+		want  string
+	}{
+		{`{"a": 1}`, nil, "an object is not an array"},
+		{`[{"a": 1}, 2]`, nil, "item 1 is a number, expected an object"},
+		{`[{"a": [1]}]`, []convert.Option{convert.WithNested(convert.NestedError)}, `item 0: "a" holds an array`},
+		{`{"a": []}`, []convert.Option{convert.At("/b")}, `no value at "/b"`},
+	}
+	for _, tt := range tests {
+		_, err := convert.ToCSV(gjtest.Parse(t, tt.input), tt.opts...)
+		if assert.Error(t, err, tt.input) {
+			assert.Contains(t, err.Error(), tt.want)
+		}
+	}
+}
+
+func TestFromCSV(t *testing.T) {
+	const input = "name,age,admin,note\nann,31,true,NULL\n\"bob, jr\",25.50,false,\ncy,007,yes,1e3\n"
+	var tests = []struct {
+		name string
+		opts []convert.Option
+		want string
+	}{
+		{
+			name: "strings",
+			want: `[{"name":"ann","age":"31","admin":"true","note":"NULL"},{"name":"bob, jr","age":"25.50","admin":"false","note":""},{"name":"cy","age":"007","admin":"yes","note":"1e3"}]`,
+		},
+		{
+			name: "infer",
+			opts: []convert.Option{convert.InferNumbers(), convert.InferBools(), convert.NullTokens("", "NULL")},
+			want: `[{"name":"ann","age":31,"admin":true,"note":null},{"name":"bob, jr","age":25.50,"admin":false,"note":null},{"name":"cy","age":"007","admin":"yes","note":1e3}]`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, err := convert.FromCSV(strings.NewReader(input), tt.opts...)
+			if !assert.Nil(t, err) {
+				return
+			}
+			got, err := printer.Sprint(root)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	root, err := convert.FromCSV(strings.NewReader("a\tb\n1\t2\n"), convert.Comma('\t'), convert.InferNumbers())
+	assert.Nil(t, err)
+	got, err := convert.ToCSV(root, convert.Comma('\t'))
+	assert.Nil(t, err)
+	assert.Equal(t, "a\tb\n1\t2\n", got)
+
+	_, err = convert.FromCSV(strings.NewReader(""))
+	assert.ErrorContains(t, err, "missing header")
+	_, err = convert.FromCSV(strings.NewReader("a,b\n1\n"))
+	assert.ErrorContains(t, err, "wrong number of fields")
+}
+
+func TestToYAML(t *testing.T) {
+	root := gjtest.Parse(t, `{"name": "gj", "version": 1.0, "tags": ["json", "true", "1"], "deps": {}, "meta": {"stars": 1e3, "license": null, "note": "a\nb"}, "empty": []}`)
+	got, err := convert.ToYAML(root)
+	assert.Nil(t, err)
+	assert.Equal(t, `name: gj
+version: 1
+tags:
+  - json
+  - "true"
+  - "1"
+deps: {}
+meta:
+  stars: 1000
+  license: null
+  note: |-
+    a
+    b
+empty: []
+`, got)
+
+	got, err = convert.ToYAML(ast.String("x"))
+	assert.Nil(t, err)
+	assert.Equal(t, "x\n", got)
+}
+
+func TestToTOML(t *testing.T) {
+	root := gjtest.Parse(t, `{"title": "gj \"x\"", "pi": 3.0, "ports": [80, 443], "point": {"x": 1, "y": [{"z": true}, 2]}, "owner": {"name": "ann", "a b": {"c": "d"}}, "servers": [{"name": "alpha"}, {"name": "beta", "tls": {"on": false}}]}`)
+	got, err := convert.ToTOML(root)
+	assert.Nil(t, err)
+	assert.Equal(t, `title = "gj \"x\""
+pi = 3.0
+ports = [80, 443]
+
+[point]
+x = 1
+y = [{ z = true }, 2]
+
+[owner]
+name = "ann"
+
+[owner."a b"]
+c = "d"
+
+[[servers]]
+name = "alpha"
+
+[[servers]]
+name = "beta"
+
+[servers.tls]
+on = false
+`, got)
+
+	for input, want := range map[string]string{
+		`[1]`:                "document is an array, expected an object",
+		`{"a": {"b": null}}`: "null at a.b has no TOML equivalent",
+		`{"a": 1, "a": 2}`:   `duplicate key "a"`,
+	} {
+		_, err := convert.ToTOML(gjtest.Parse(t, input))
+		assert.ErrorContains(t, err, want, input)
+	}
+}
+
+func TestToCBOR(t *testing.T) {
+	// Examples from RFC 8949, appendix A.
+	var tests = []struct {
+		input string
+		want  string
+	}{
+		{`0`, "00"},
+		{`23`, "17"},
+		{`24`, "1818"},
+		{`1000`, "1903e8"},
+		{`1000000`, "1a000f4240"},
+		{`1000000000000`, "1b000000e8d4a51000"},
+		{`-1`, "20"},
+		{`-1000`, "3903e7"},
+		{`1.5`, "fb3ff8000000000000"},
+		{`false`, "f4"},
+		{`true`, "f5"},
+		{`null`, "f6"},
+		{`"IETF"`, "6449455446"},
+		{`"ü"`, "62c3bc"},
+		{`[1, [2, 3], [4, 5]]`, "8301820203820405"},
+		{`{"a": 1, "b": [2, 3]}`, "a26161016162820203"},
+	}
+	for _, tt := range tests {
+		got, err := convert.ToCBOR(gjtest.Parse(t, tt.input))
+		assert.Nil(t, err)
+		assert.Equal(t, tt.want, hex.EncodeToString(got), tt.input)
+	}
+}
+
+func TestToMsgpack(t *testing.T) {
+	var tests = []struct {
+		input string
+		want  string
+	}{
+		{`0`, "00"},
+		{`127`, "7f"},
+		{`128`, "cc80"},
+		{`255`, "ccff"},
+		{`256`, "cd0100"},
+		{`65535`, "cdffff"},
+		{`70000`, "ce00011170"},
+		{`5000000000`, "cf000000012a05f200"},
+		{`-32`, "e0"},
+		{`-33`, "d0df"},
+		{`-129`, "d1ff7f"},
+		{`-40000`, "d2ffff63c0"},
+		{`-5000000000`, "d3fffffffed5fa0e00"},
+		{`1.5`, "cb3ff8000000000000"},
+		{`null`, "c0"},
+		{`false`, "c2"},
+		{`true`, "c3"},
+		{`"abc"`, "a3616263"},
+		{`"` + strings.Repeat("x", 32) + `"`, "d920" + strings.Repeat("78", 32)},
+		{`[1, [2]]`, "920191" + "02"},
+		{`{"a": 1}`, "81a16101"},
+	}
+	for _, tt := range tests {
+		got, err := convert.ToMsgpack(gjtest.Parse(t, tt.input))
+		assert.Nil(t, err)
+		assert.Equal(t, tt.want, hex.EncodeToString(got), tt.input)
+	}
+}
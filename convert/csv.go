@@ -0,0 +1,271 @@
+package convert
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pohedev/gj.git/ast"
+	"github.com/pohedev/gj.git/printer"
+)
+
+// ToCSV converts an array of objects to CSV text, one record per object.
+// node is an *ast.RootNode or any node of a tree; At selects an array
+// inside it. The header row lists the union of the keys of the objects in
+// order of first appearance. Strings are written as is, other literals as
+// JSON, and nested objects and arrays as set by WithNested.
+func ToCSV(node any, opts ...Option) (string, error) {
+	c := newConfig(opts)
+	rows, err := c.rows(node)
+	if err != nil {
+		return "", fmt.Errorf("failed to convert to CSV: %w", err)
+	}
+
+	var header []string
+	columns := map[string]int{}
+	for _, row := range rows {
+		for _, cell := range row {
+			if _, ok := columns[cell.key]; !ok {
+				columns[cell.key] = len(header)
+				header = append(header, cell.key)
+			}
+		}
+	}
+
+	var b strings.Builder
+	w := csv.NewWriter(&b)
+	w.Comma = c.comma
+	if err := w.Write(header); err != nil {
+		return "", fmt.Errorf("failed to convert to CSV: %w", err)
+	}
+	record := make([]string, len(header))
+	for _, row := range rows {
+		clear(record)
+		for _, cell := range row {
+			record[columns[cell.key]] = cell.text
+		}
+		if err := w.Write(record); err != nil {
+			return "", fmt.Errorf("failed to convert to CSV: %w", err)
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return "", fmt.Errorf("failed to convert to CSV: %w", err)
+	}
+	return b.String(), nil
+}
+
+// FromCSV reads CSV text with a header row into an array of objects, one
+// per record, keyed by the header. Cells are read as strings unless
+// InferNumbers, InferBools or NullTokens is given. Records must have as
+// many fields as the header.
+func FromCSV(r io.Reader, opts ...Option) (*ast.RootNode, error) {
+	c := newConfig(opts)
+	cr := csv.NewReader(r)
+	cr.Comma = c.comma
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("failed to convert from CSV: missing header")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert from CSV: %w", err)
+	}
+	header = slices.Clone(header)
+
+	array := &ast.Array{}
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert from CSV: %w", err)
+		}
+		obj := &ast.Object{Children: make([]ast.Property, len(header))}
+		for i, key := range header {
+			obj.Children[i] = ast.Property{
+				Identifier: ast.Identifier{Value: key},
+				Value:      &ast.Value{Value: c.infer(record[i])},
+			}
+		}
+		array.Children = append(array.Children, ast.ArrayItem{Value: &ast.Value{Value: obj}})
+	}
+	return &ast.RootNode{RootNodeType: ast.RootNodeTypeArray, Value: &ast.Value{Value: array}}, nil
+}
+
+// infer returns the literal read from the text of a cell.
+func (c config) infer(s string) *ast.Literal {
+	if slices.Contains(c.nullTokens, s) {
+		return ast.Null()
+	}
+	if c.inferBools && (s == "true" || s == "false") {
+		return ast.Bool(s == "true")
+	}
+	if c.inferNumbers && isNumber(s) {
+		if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return &ast.Literal{LiteralType: ast.LiteralTypeNumber, Val: i, Raw: s}
+		}
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return &ast.Literal{LiteralType: ast.LiteralTypeNumber, Val: f, Raw: s}
+		}
+	}
+	return ast.String(s)
+}
+
+// isNumber reports whether s follows the JSON number grammar.
+func isNumber(s string) bool {
+	i := 0
+	digits := func() bool {
+		start := i
+		for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+			i++
+		}
+		return i > start
+	}
+	if i < len(s) && s[i] == '-' {
+		i++
+	}
+	if i < len(s) && s[i] == '0' {
+		i++
+	} else if !digits() {
+		return false
+	}
+	if i < len(s) && s[i] == '.' {
+		i++
+		if !digits() {
+			return false
+		}
+	}
+	if i < len(s) && (s[i] == 'e' || s[i] == 'E') {
+		i++
+		if i < len(s) && (s[i] == '+' || s[i] == '-') {
+			i++
+		}
+		if !digits() {
+			return false
+		}
+	}
+	return i == len(s)
+}
+
+// cell is a column of a record.
+type cell struct {
+	key  string
+	text string
+}
+
+// rows returns the cells of each object of the selected array.
+func (c config) rows(node any) ([][]cell, error) {
+	node, err := lookup(node, c.at)
+	if err != nil {
+		return nil, err
+	}
+	array, ok := node.(*ast.Array)
+	if !ok {
+		return nil, fmt.Errorf("%s is not an array", describe(node))
+	}
+	rows := make([][]cell, len(array.Children))
+	for i, item := range array.Children {
+		n, err := resolve(item.Value)
+		if err != nil {
+			return nil, err
+		}
+		obj, ok := n.(*ast.Object)
+		if !ok {
+			return nil, fmt.Errorf("item %d is %s, expected an object", i, describe(n))
+		}
+		if rows[i], err = c.cells(nil, "", obj); err != nil {
+			return nil, fmt.Errorf("item %d: %w", i, err)
+		}
+	}
+	return rows, nil
+}
+
+// cells appends the cells of the properties of obj to row, with keys
+// starting with prefix. Duplicate keys keep the last value.
+func (c config) cells(row []cell, prefix string, obj *ast.Object) ([]cell, error) {
+	for _, i := range sortedIndex(obj) {
+		prop := obj.Children[i]
+		var err error
+		if row, err = c.cell(row, prefix+prop.Identifier.Value, prop.Value); err != nil {
+			return nil, err
+		}
+	}
+	return row, nil
+}
+
+// cell appends the cells of the value at key to row.
+func (c config) cell(row []cell, key string, node any) ([]cell, error) {
+	n, err := resolve(node)
+	if err != nil {
+		return nil, err
+	}
+	switch n := n.(type) {
+	case *ast.Literal:
+		return append(row, cell{key, c.literal(n)}), nil
+	case *ast.Object:
+		if c.nested == NestedFlatten && len(n.Children) > 0 {
+			return c.cells(row, key+".", n)
+		}
+	case *ast.Array:
+		if c.nested == NestedFlatten && len(n.Children) > 0 {
+			for i, item := range n.Children {
+				if row, err = c.cell(row, key+"["+strconv.Itoa(i)+"]", item.Value); err != nil {
+					return nil, err
+				}
+			}
+			return row, nil
+		}
+	}
+	if c.nested == NestedError {
+		return nil, fmt.Errorf("%q holds %s", key, describe(n))
+	}
+	s, err := printer.Sprint(n)
+	if err != nil {
+		return nil, err
+	}
+	return append(row, cell{key, s}), nil
+}
+
+// literal returns the text of lit in a cell.
+func (c config) literal(lit *ast.Literal) string {
+	switch v := lit.Val.(type) {
+	case nil:
+		return c.null
+	case string:
+		return v
+	}
+	s, err := printer.Sprint(lit)
+	if err != nil {
+		return fmt.Sprint(lit.Val)
+	}
+	return s
+}
+
+// sortedIndex returns the positions of the properties of obj in document
+// order, the last one of duplicate keys.
+func sortedIndex(obj *ast.Object) []int {
+	index := obj.Index()
+	positions := make([]int, 0, len(index))
+	for i, prop := range obj.Children {
+		if index[prop.Identifier.Value] == i {
+			positions = append(positions, i)
+		}
+	}
+	return positions
+}
+
+// describe returns the JSON type of node with an article.
+func describe(node any) string {
+	switch name := ast.TypeName(node); name {
+	case "null":
+		return name
+	case "object", "array":
+		return "an " + name
+	default:
+		return "a " + name
+	}
+}
@@ -0,0 +1,113 @@
+package convert
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/pohedev/gj.git/ast"
+)
+
+// ToMsgpack converts a document to MessagePack. node is an *ast.RootNode
+// or any node of a tree. Integers are written in their shortest form,
+// unsigned when not negative, other numbers as 64-bit floats, objects as
+// maps keeping key order.
+func ToMsgpack(node any) ([]byte, error) {
+	b, err := appendMsgpack(nil, node)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert to MessagePack: %w", err)
+	}
+	return b, nil
+}
+
+// appendMsgpack appends the MessagePack encoding of node to b.
+func appendMsgpack(b []byte, node any) ([]byte, error) {
+	node, err := resolve(node)
+	if err != nil {
+		return nil, err
+	}
+	switch n := node.(type) {
+	case *ast.Object:
+		b = appendMsgpackLen(b, 0x80, 0xde, len(n.Children), 16)
+		for _, prop := range n.Children {
+			b = appendMsgpackString(b, prop.Identifier.Value)
+			if b, err = appendMsgpack(b, prop.Value); err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+	case *ast.Array:
+		b = appendMsgpackLen(b, 0x90, 0xdc, len(n.Children), 16)
+		for _, item := range n.Children {
+			if b, err = appendMsgpack(b, item.Value); err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+	}
+	lit := node.(*ast.Literal)
+	switch v := lit.Val.(type) {
+	case nil:
+		return append(b, 0xc0), nil
+	case bool:
+		if v {
+			return append(b, 0xc3), nil
+		}
+		return append(b, 0xc2), nil
+	case string:
+		return appendMsgpackString(b, v), nil
+	case int64:
+		switch {
+		case -32 <= v && v <= math.MaxInt8:
+			return append(b, byte(v)), nil
+		case v > 0:
+			return appendMsgpackUint(b, uint64(v)), nil
+		case math.MinInt8 <= v:
+			return append(b, 0xd0, byte(v)), nil
+		case math.MinInt16 <= v:
+			return binary.BigEndian.AppendUint16(append(b, 0xd1), uint16(v)), nil
+		case math.MinInt32 <= v:
+			return binary.BigEndian.AppendUint32(append(b, 0xd2), uint32(v)), nil
+		}
+		return binary.BigEndian.AppendUint64(append(b, 0xd3), uint64(v)), nil
+	case float64:
+		return binary.BigEndian.AppendUint64(append(b, 0xcb), math.Float64bits(v)), nil
+	}
+	return nil, fmt.Errorf("unsupported literal value %T", lit.Val)
+}
+
+// appendMsgpackUint appends v as the shortest unsigned integer.
+func appendMsgpackUint(b []byte, v uint64) []byte {
+	switch {
+	case v <= math.MaxUint8:
+		return append(b, 0xcc, byte(v))
+	case v <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, 0xcd), uint16(v))
+	case v <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(b, 0xce), uint32(v))
+	}
+	return binary.BigEndian.AppendUint64(append(b, 0xcf), v)
+}
+
+// appendMsgpackString appends the str encoding of s to b.
+func appendMsgpackString(b []byte, s string) []byte {
+	if len(s) <= math.MaxUint8 && len(s) >= 32 {
+		b = append(b, 0xd9, byte(len(s)))
+	} else {
+		b = appendMsgpackLen(b, 0xa0, 0xda, len(s), 32)
+	}
+	return append(b, s...)
+}
+
+// appendMsgpackLen appends the header of a str, array or map of n
+// elements: fix|n for n below limit, else the 16-bit form code and the
+// 32-bit form code+1.
+func appendMsgpackLen(b []byte, fix, code byte, n, limit int) []byte {
+	switch {
+	case n < limit:
+		return append(b, fix|byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, code), uint16(n))
+	}
+	return binary.BigEndian.AppendUint32(append(b, code+1), uint32(n))
+}
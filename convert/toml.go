@@ -0,0 +1,230 @@
+package convert
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/pohedev/gj.git/ast"
+)
+
+// ToTOML converts an object to TOML text. node is an *ast.RootNode or any
+// node of a tree. Keys holding scalars and arrays come first, then nested
+// objects as [tables] and arrays of objects as [[arrays of tables]];
+// objects inside other arrays are written inline. TOML has no null, so
+// null values fail the conversion, as do duplicate keys.
+func ToTOML(node any) (string, error) {
+	node, err := resolve(node)
+	if err != nil {
+		return "", fmt.Errorf("failed to convert to TOML: %w", err)
+	}
+	obj, ok := node.(*ast.Object)
+	if !ok {
+		return "", fmt.Errorf("failed to convert to TOML: document is %s, expected an object", describe(node))
+	}
+	var b strings.Builder
+	if err := writeTOMLTable(&b, nil, obj); err != nil {
+		return "", fmt.Errorf("failed to convert to TOML: %w", err)
+	}
+	return b.String(), nil
+}
+
+// writeTOMLTable writes the properties of obj, the table at path.
+func writeTOMLTable(b *strings.Builder, path []string, obj *ast.Object) error {
+	type table struct {
+		path  []string
+		value any // *ast.Object or *ast.Array of objects.
+	}
+	var tables []table
+	seen := map[string]bool{}
+	for _, prop := range obj.Children {
+		key := prop.Identifier.Value
+		if seen[key] {
+			return fmt.Errorf("duplicate key %q", key)
+		}
+		seen[key] = true
+		v, err := resolve(prop.Value)
+		if err != nil {
+			return err
+		}
+		sub := append(path[:len(path):len(path)], tomlKey(key))
+		switch n := v.(type) {
+		case *ast.Object:
+			tables = append(tables, table{sub, n})
+			continue
+		case *ast.Array:
+			if isArrayOfTables(n) {
+				tables = append(tables, table{sub, n})
+				continue
+			}
+		}
+		b.WriteString(tomlKey(key))
+		b.WriteString(" = ")
+		if err := writeTOMLValue(b, sub, v); err != nil {
+			return err
+		}
+		b.WriteByte('\n')
+	}
+	for _, t := range tables {
+		name := strings.Join(t.path, ".")
+		switch n := t.value.(type) {
+		case *ast.Object:
+			writeTOMLHeader(b, "["+name+"]")
+			if err := writeTOMLTable(b, t.path, n); err != nil {
+				return err
+			}
+		case *ast.Array:
+			for _, item := range n.Children {
+				writeTOMLHeader(b, "[["+name+"]]")
+				v, _ := resolve(item.Value)
+				if err := writeTOMLTable(b, t.path, v.(*ast.Object)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// writeTOMLHeader writes a table header, after a blank line unless first.
+func writeTOMLHeader(b *strings.Builder, header string) {
+	if b.Len() > 0 {
+		b.WriteByte('\n')
+	}
+	b.WriteString(header)
+	b.WriteByte('\n')
+}
+
+// isArrayOfTables reports whether arr is a non-empty array of objects.
+func isArrayOfTables(arr *ast.Array) bool {
+	for _, item := range arr.Children {
+		if v, err := resolve(item.Value); err != nil {
+			return false
+		} else if _, ok := v.(*ast.Object); !ok {
+			return false
+		}
+	}
+	return len(arr.Children) > 0
+}
+
+// writeTOMLValue writes v, found at path, inline.
+func writeTOMLValue(b *strings.Builder, path []string, v any) error {
+	v, err := resolve(v)
+	if err != nil {
+		return err
+	}
+	switch n := v.(type) {
+	case *ast.Object:
+		b.WriteByte('{')
+		seen := map[string]bool{}
+		for i, prop := range n.Children {
+			key := prop.Identifier.Value
+			if seen[key] {
+				return fmt.Errorf("duplicate key %q in %s", key, strings.Join(path, "."))
+			}
+			seen[key] = true
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			b.WriteByte(' ')
+			b.WriteString(tomlKey(key))
+			b.WriteString(" = ")
+			if err := writeTOMLValue(b, append(path[:len(path):len(path)], tomlKey(key)), prop.Value); err != nil {
+				return err
+			}
+		}
+		if len(n.Children) > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteByte('}')
+		return nil
+	case *ast.Array:
+		b.WriteByte('[')
+		for i, item := range n.Children {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			if err := writeTOMLValue(b, path, item.Value); err != nil {
+				return err
+			}
+		}
+		b.WriteByte(']')
+		return nil
+	}
+	lit := v.(*ast.Literal)
+	switch v := lit.Val.(type) {
+	case nil:
+		return fmt.Errorf("null at %s has no TOML equivalent", strings.Join(path, "."))
+	case bool:
+		b.WriteString(strconv.FormatBool(v))
+	case string:
+		writeTOMLString(b, v)
+	case int64:
+		b.WriteString(strconv.FormatInt(v, 10))
+	case float64:
+		switch {
+		case math.IsNaN(v):
+			b.WriteString("nan")
+		case math.IsInf(v, 1):
+			b.WriteString("inf")
+		case math.IsInf(v, -1):
+			b.WriteString("-inf")
+		default:
+			s := strconv.FormatFloat(v, 'g', -1, 64)
+			b.WriteString(s)
+			if !strings.ContainsAny(s, ".e") {
+				// Without a fraction or exponent it would be an integer.
+				b.WriteString(".0")
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported literal value %T", lit.Val)
+	}
+	return nil
+}
+
+// tomlKey returns key as a bare key if possible, else quoted.
+func tomlKey(key string) string {
+	bare := key != ""
+	for i := 0; i < len(key) && bare; i++ {
+		c := key[i]
+		bare = 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || c == '_' || c == '-'
+	}
+	if bare {
+		return key
+	}
+	var b strings.Builder
+	writeTOMLString(&b, key)
+	return b.String()
+}
+
+// writeTOMLString writes s as a TOML basic string.
+func writeTOMLString(b *strings.Builder, s string) {
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '\r':
+			b.WriteString(`\r`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(b, `\u%04X`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+}
@@ -0,0 +1,91 @@
+package convert
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/pohedev/gj.git/ast"
+	"github.com/pohedev/gj.git/internal/jsonnum"
+	"gopkg.in/yaml.v3"
+)
+
+// ToYAML converts a document to YAML text in block style, indented by two
+// spaces. node is an *ast.RootNode or any node of a tree. Keys keep their
+// order; numbers are written in canonical form, see printer.CanonicalNumbers.
+func ToYAML(node any) (string, error) {
+	n, err := yamlNode(node)
+	if err != nil {
+		return "", fmt.Errorf("failed to convert to YAML: %w", err)
+	}
+	var b strings.Builder
+	enc := yaml.NewEncoder(&b)
+	enc.SetIndent(2)
+	if err := enc.Encode(n); err != nil {
+		return "", fmt.Errorf("failed to convert to YAML: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return "", fmt.Errorf("failed to convert to YAML: %w", err)
+	}
+	return b.String(), nil
+}
+
+// yamlNode returns the YAML node of node.
+func yamlNode(node any) (*yaml.Node, error) {
+	node, err := resolve(node)
+	if err != nil {
+		return nil, err
+	}
+	switch n := node.(type) {
+	case *ast.Object:
+		m := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		for _, prop := range n.Children {
+			v, err := yamlNode(prop.Value)
+			if err != nil {
+				return nil, err
+			}
+			k := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: prop.Identifier.Value}
+			m.Content = append(m.Content, k, v)
+		}
+		return m, nil
+	case *ast.Array:
+		s := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for _, item := range n.Children {
+			v, err := yamlNode(item.Value)
+			if err != nil {
+				return nil, err
+			}
+			s.Content = append(s.Content, v)
+		}
+		return s, nil
+	}
+	lit := node.(*ast.Literal)
+	switch v := lit.Val.(type) {
+	case nil:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}, nil
+	case bool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: strconv.FormatBool(v)}, nil
+	case string:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: v}, nil
+	case int64, float64:
+		s, ok := jsonnum.CanonicalValue(lit.Raw, v)
+		if !ok {
+			f := v.(float64)
+			switch {
+			case math.IsNaN(f):
+				s = ".nan"
+			case f > 0:
+				s = ".inf"
+			default:
+				s = "-.inf"
+			}
+		}
+		tag := "!!float"
+		if _, err := strconv.ParseInt(s, 10, 64); err == nil {
+			tag = "!!int"
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: s}, nil
+	}
+	return nil, fmt.Errorf("unsupported literal value %T", lit.Val)
+}
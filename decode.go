@@ -0,0 +1,488 @@
+package gj
+
+import (
+	"encoding"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+
+	"github.com/pohedev/gj.git/ast"
+	"github.com/pohedev/gj.git/lexer"
+	"github.com/pohedev/gj.git/parser"
+)
+
+// Unmarshaler is implemented by types that decode themselves from
+// a node of the document, such as time, identifier or money types.
+// UnmarshalGJ also receives null values.
+type Unmarshaler interface {
+	UnmarshalGJ(v *ast.Value) error
+}
+
+// DecodeOption configures the conversion of documents into Go values.
+type DecodeOption func(*decoder)
+
+// DisallowUnknownFields makes decoding fail with an *UnknownFieldError
+// when an object has a property matching no field of the target struct.
+func DisallowUnknownFields() DecodeOption {
+	return func(d *decoder) {
+		d.disallowUnknownFields = true
+	}
+}
+
+// UseNumber makes decoding into interface values produce a Number
+// holding the source text of numbers instead of an int64 or float64.
+func UseNumber() DecodeOption {
+	return func(d *decoder) {
+		d.useNumber = true
+	}
+}
+
+// Unmarshal parses input and stores the result in the value pointed to by v.
+// See Decode for the conversion rules.
+func Unmarshal(input string, v any, opts ...DecodeOption) error {
+	root, err := parser.New(lexer.Lex(input)).Parse()
+	if err != nil {
+		return err
+	}
+	return Decode(root, v, opts...)
+}
+
+// Decode stores the value of root in the value pointed to by v.
+//
+// Conversion follows encoding/json: objects decode into structs, using
+// the json tag of fields and matching keys case-insensitively when there
+// is no exact match, and into maps with string or integer keys; arrays
+// decode into slices and arrays, and base64 strings into []byte (see
+// ast.Literal.AsBytes); null sets pointers, interfaces, maps and slices
+// to nil and leaves other values unchanged. Types implementing
+// Unmarshaler decode themselves, and ast.RootNode receives the subtree
+// as is; types implementing encoding.TextUnmarshaler decode from strings
+// and object keys. Numbers convert to integer types only when integral
+// and in range. A failed conversion is reported as *TypeError.
+func Decode(root *ast.RootNode, v any, opts ...DecodeOption) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("failed to decode: non-nil pointer required, got %T", v)
+	}
+	d := newDecoder(opts)
+	if root == nil {
+		return nil
+	}
+	return d.decode(root.Value, rv.Elem(), "")
+}
+
+// decoder converts AST nodes into Go values.
+type decoder struct {
+	disallowUnknownFields bool // Fail on properties without struct field.
+	useNumber             bool // Decode numbers into interfaces as Number.
+	protoJSON             bool // Accept the protobuf JSON mapping.
+
+	enums map[reflect.Type]map[string]int32 // Names of enum values by type.
+}
+
+var (
+	numberType          = reflect.TypeFor[Number]()
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+// newDecoder returns a decoder configured by opts.
+func newDecoder(opts []DecodeOption) *decoder {
+	d := &decoder{}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
+
+// decode stores node into rv. ptr is the JSON Pointer of node,
+// used in errors.
+func (d *decoder) decode(node any, rv reflect.Value, ptr string) error {
+	node, err := resolve(node)
+	if err != nil {
+		return err
+	}
+
+	if rv.Kind() == reflect.Pointer {
+		if isNull(node) {
+			rv.Set(reflect.Zero(rv.Type()))
+			return nil
+		}
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		return d.decode(node, rv.Elem(), ptr)
+	}
+	if rv.CanAddr() {
+		switch u := rv.Addr().Interface().(type) {
+		case *ast.RootNode:
+			*u = ast.RootNode{RootNodeType: ast.RootNodeTypeLiteral, Value: &ast.Value{Value: node}}
+			switch node.(type) {
+			case *ast.Object:
+				u.RootNodeType = ast.RootNodeTypeObject
+			case *ast.Array:
+				u.RootNodeType = ast.RootNodeTypeArray
+			}
+			return nil
+		case Unmarshaler:
+			if err := u.UnmarshalGJ(&ast.Value{Value: node}); err != nil {
+				return &TypeError{Path: ptr, Value: describe(node), Type: rv.Type(), Msg: err.Error(), Err: err}
+			}
+			return nil
+		case encoding.TextUnmarshaler:
+			if isNull(node) {
+				return nil
+			}
+			lit, ok := node.(*ast.Literal)
+			if !ok || lit.LiteralType != ast.LiteralTypeString {
+				return d.typeError(node, rv.Type(), ptr, "")
+			}
+			if err := u.UnmarshalText([]byte(lit.Val.(string))); err != nil {
+				return &TypeError{Path: ptr, Value: describe(node), Type: rv.Type(), Msg: err.Error(), Err: err}
+			}
+			return nil
+		}
+	}
+	if rv.Kind() == reflect.Interface && rv.NumMethod() == 0 {
+		v, err := d.toGo(node, ptr)
+		if err != nil {
+			return err
+		}
+		if v == nil {
+			rv.Set(reflect.Zero(rv.Type()))
+		} else {
+			rv.Set(reflect.ValueOf(v))
+		}
+		return nil
+	}
+
+	switch n := node.(type) {
+	case *ast.Object:
+		return d.decodeObject(n, rv, ptr)
+	case *ast.Array:
+		return d.decodeArray(n, rv, ptr)
+	case *ast.Literal:
+		return d.decodeLiteral(n, rv, ptr)
+	}
+	return d.typeError(node, rv.Type(), ptr, "")
+}
+
+// decodeObject stores obj into a struct or map.
+func (d *decoder) decodeObject(obj *ast.Object, rv reflect.Value, ptr string) error {
+	switch rv.Kind() {
+	case reflect.Struct:
+		fields := cachedFields(rv.Type())
+		for _, prop := range obj.Children {
+			key := prop.Identifier.Value
+			f := findField(fields, key)
+			if f == nil && d.protoJSON {
+				f = findProtoField(fields, key)
+			}
+			if f == nil {
+				if d.disallowUnknownFields {
+					return &UnknownFieldError{Key: key, Path: ptr, Offset: prop.Identifier.Start}
+				}
+				continue
+			}
+			fv, err := fieldByIndex(rv, f.index)
+			if err != nil {
+				return d.typeError(obj, rv.Type(), ptr, err.Error())
+			}
+			if err := d.decode(prop.Value, fv, appendPointer(ptr, key)); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case reflect.Map:
+		t := rv.Type()
+		switch t.Key().Kind() {
+		case reflect.String,
+			reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		default:
+			if !reflect.PointerTo(t.Key()).Implements(textUnmarshalerType) {
+				return d.typeError(obj, t, ptr, "unsupported map key type")
+			}
+		}
+		if rv.IsNil() {
+			rv.Set(reflect.MakeMapWithSize(t, len(obj.Children)))
+		}
+		for _, prop := range obj.Children {
+			key := prop.Identifier.Value
+			propPtr := appendPointer(ptr, key)
+			kv := reflect.New(t.Key()).Elem()
+			if !setKey(kv, key) {
+				return &TypeError{Path: propPtr, Value: fmt.Sprintf("key %q", key), Type: t.Key()}
+			}
+			ev := reflect.New(t.Elem()).Elem()
+			if err := d.decode(prop.Value, ev, propPtr); err != nil {
+				return err
+			}
+			rv.SetMapIndex(kv, ev)
+		}
+		return nil
+	}
+	return d.typeError(obj, rv.Type(), ptr, "")
+}
+
+// decodeArray stores array into a slice or array. Like encoding/json,
+// extra items are dropped when decoding into a Go array, and missing
+// ones are zeroed.
+func (d *decoder) decodeArray(array *ast.Array, rv reflect.Value, ptr string) error {
+	n := len(array.Children)
+	switch rv.Kind() {
+	case reflect.Slice:
+		rv.Set(reflect.MakeSlice(rv.Type(), n, n))
+	case reflect.Array:
+		for i := n; i < rv.Len(); i++ {
+			rv.Index(i).SetZero()
+		}
+		n = min(n, rv.Len())
+	default:
+		return d.typeError(array, rv.Type(), ptr, "")
+	}
+	for i := 0; i < n; i++ {
+		if err := d.decode(array.Children[i].Value, rv.Index(i), appendPointer(ptr, strconv.Itoa(i))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeLiteral stores lit into rv. Like encoding/json, null sets maps
+// and slices to nil and leaves other values unchanged.
+func (d *decoder) decodeLiteral(lit *ast.Literal, rv reflect.Value, ptr string) error {
+	if lit.IsNull() {
+		switch rv.Kind() {
+		case reflect.Map, reflect.Slice:
+			rv.SetZero()
+		}
+		return nil
+	}
+
+	if lit.LiteralType == ast.LiteralTypeString && (d.protoJSON || d.enums != nil) {
+		if ok, err := d.decodeProtoString(lit, rv, ptr); ok {
+			return err
+		}
+	}
+
+	if rv.Type() == numberType && lit.LiteralType == ast.LiteralTypeNumber {
+		rv.SetString(string(numberOf(lit)))
+		return nil
+	}
+
+	switch rv.Kind() {
+	case reflect.String:
+		if s, ok := lit.AsString(); ok {
+			rv.SetString(s)
+			return nil
+		}
+
+	case reflect.Bool:
+		if b, ok := lit.AsBool(); ok {
+			rv.SetBool(b)
+			return nil
+		}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if _, ok := lit.AsFloat(); !ok {
+			break
+		}
+		i, ok := asInt(lit)
+		if !ok {
+			return d.typeError(lit, rv.Type(), ptr, "not an integer")
+		}
+		if rv.OverflowInt(i) {
+			return d.typeError(lit, rv.Type(), ptr, "overflows "+rv.Type().String())
+		}
+		rv.SetInt(i)
+		return nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if _, ok := lit.AsFloat(); !ok {
+			break
+		}
+		i, ok := asInt(lit)
+		if !ok {
+			return d.typeError(lit, rv.Type(), ptr, "not an integer")
+		}
+		if i < 0 || rv.OverflowUint(uint64(i)) {
+			return d.typeError(lit, rv.Type(), ptr, "overflows "+rv.Type().String())
+		}
+		rv.SetUint(uint64(i))
+		return nil
+
+	case reflect.Slice:
+		if rv.Type().Elem().Kind() != reflect.Uint8 || lit.LiteralType != ast.LiteralTypeString {
+			break
+		}
+		b, err := lit.AsBytes()
+		if err != nil {
+			return &TypeError{Path: ptr, Value: describe(lit), Type: rv.Type(), Msg: err.Error(), Err: err}
+		}
+		rv.SetBytes(b)
+		return nil
+
+	case reflect.Float32, reflect.Float64:
+		if f, ok := lit.AsFloat(); ok {
+			if rv.OverflowFloat(f) {
+				return d.typeError(lit, rv.Type(), ptr, "overflows "+rv.Type().String())
+			}
+			rv.SetFloat(f)
+			return nil
+		}
+	}
+	return d.typeError(lit, rv.Type(), ptr, "")
+}
+
+// toGo converts node into plain Go values like ast.Value.ToGo,
+// parsing raw values kept by lazy parsing.
+func (d *decoder) toGo(node any, ptr string) (any, error) {
+	node, err := resolve(node)
+	if err != nil {
+		return nil, err
+	}
+	switch n := node.(type) {
+	case *ast.Object:
+		m := make(map[string]any, len(n.Children))
+		for _, prop := range n.Children {
+			v, err := d.toGo(prop.Value, appendPointer(ptr, prop.Identifier.Value))
+			if err != nil {
+				return nil, err
+			}
+			m[prop.Identifier.Value] = v
+		}
+		return m, nil
+	case *ast.Array:
+		s := make([]any, 0, len(n.Children))
+		for i, item := range n.Children {
+			v, err := d.toGo(item.Value, appendPointer(ptr, strconv.Itoa(i)))
+			if err != nil {
+				return nil, err
+			}
+			s = append(s, v)
+		}
+		return s, nil
+	case *ast.Literal:
+		if d.useNumber && n.LiteralType == ast.LiteralTypeNumber {
+			return numberOf(n), nil
+		}
+		return n.Val, nil
+	}
+	return nil, nil
+}
+
+// typeError returns a *TypeError for node and type t.
+func (d *decoder) typeError(node any, t reflect.Type, ptr string, msg string) error {
+	return &TypeError{Path: ptr, Value: describe(node), Type: t, Msg: msg}
+}
+
+// resolve strips *ast.Value wrappers from node
+// and parses raw values kept by lazy parsing.
+func resolve(node any) (any, error) {
+	for {
+		switch n := node.(type) {
+		case *ast.Value:
+			if n == nil {
+				return nil, nil
+			}
+			node = n.Value
+		case *ast.RawValue:
+			v, err := parser.ParseRaw(n)
+			if err != nil {
+				return nil, err
+			}
+			node = v.Value
+		default:
+			return node, nil
+		}
+	}
+}
+
+// fieldByIndex returns the field of struct rv at index, allocating
+// nil pointers to embedded structs on the way.
+func fieldByIndex(rv reflect.Value, index []int) (reflect.Value, error) {
+	for i, x := range index {
+		if i > 0 && rv.Kind() == reflect.Pointer {
+			if rv.IsNil() {
+				if !rv.CanSet() {
+					return reflect.Value{}, fmt.Errorf("cannot set embedded pointer to unexported struct %v", rv.Type().Elem())
+				}
+				rv.Set(reflect.New(rv.Type().Elem()))
+			}
+			rv = rv.Elem()
+		}
+		rv = rv.Field(x)
+	}
+	return rv, nil
+}
+
+// setKey stores object key into map key kv of string or integer kind,
+// or implementing encoding.TextUnmarshaler, and reports whether it succeeded.
+func setKey(kv reflect.Value, key string) bool {
+	if u, ok := kv.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(key)) == nil
+	}
+	switch kv.Kind() {
+	case reflect.String:
+		kv.SetString(key)
+		return true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(key, 10, 64)
+		if err != nil || kv.OverflowInt(i) {
+			return false
+		}
+		kv.SetInt(i)
+		return true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u, err := strconv.ParseUint(key, 10, 64)
+		if err != nil || kv.OverflowUint(u) {
+			return false
+		}
+		kv.SetUint(u)
+		return true
+	}
+	return false
+}
+
+// numberOf returns the source text of number literal lit,
+// formatting its value when it was not parsed from source.
+func numberOf(lit *ast.Literal) Number {
+	if lit.Raw != "" {
+		return Number(lit.Raw)
+	}
+	switch v := lit.Val.(type) {
+	case int64:
+		return Number(strconv.FormatInt(v, 10))
+	case float64:
+		return Number(strconv.FormatFloat(v, 'g', -1, 64))
+	}
+	return ""
+}
+
+// isNull reports whether node is a null literal.
+func isNull(node any) bool {
+	lit, ok := node.(*ast.Literal)
+	return node == nil || ok && lit.IsNull()
+}
+
+// asInt returns the value of a number literal as int64 when it is integral.
+func asInt(lit *ast.Literal) (int64, bool) {
+	if i, ok := lit.AsInt(); ok {
+		return i, true
+	}
+	f, _ := lit.AsFloat()
+	if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+		return 0, false
+	}
+	return int64(f), true
+}
+
+// describe returns the kind of node for error messages.
+func describe(node any) string {
+	if lit, ok := node.(*ast.Literal); ok && lit.LiteralType == ast.LiteralTypeNumber {
+		return fmt.Sprintf("number %v", lit.Val)
+	}
+	return ast.TypeName(node)
+}
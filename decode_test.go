@@ -0,0 +1,334 @@
+package gj_test
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/pohedev/gj.git"
+	"github.com/pohedev/gj.git/ast"
+	"github.com/stretchr/testify/assert"
+)
+
+type Base struct {
+	ID      int    `json:"id"`
+	Comment string `json:"comment"`
+}
+
+type Server struct {
+	Base
+	Host    string            `json:"host"`
+	Port    uint16            `json:"port"`
+	Tags    []string          `json:"tags"`
+	Labels  map[string]string `json:"labels"`
+	Weights map[int]float64   `json:"weights"`
+	Backup  *Server           `json:"backup"`
+	Coords  [2]int            `json:"coords"`
+	Extra   any               `json:"extra"`
+	Ignored string            `json:"-"`
+	Default string
+	private string
+}
+
+func TestUnmarshal(t *testing.T) {
+	input := `{
+		"id": 7,
+		"host": "localhost",
+		"PORT": 8080,
+		"tags": ["a", "b"],
+		"labels": {"env": "prod"},
+		"weights": {"1": 0.5, "-2": 1},
+		"backup": {"host": "spare", "backup": null},
+		"coords": [1, 2, 3],
+		"extra": {"x": [true, null]},
+		"Ignored": "no",
+		"default": "yes"
+	}`
+	var got Server
+	err := gj.Unmarshal(input, &got)
+	assert.Nil(t, err)
+	assert.Equal(t, Server{
+		Base:    Base{ID: 7},
+		Host:    "localhost",
+		Port:    8080,
+		Tags:    []string{"a", "b"},
+		Labels:  map[string]string{"env": "prod"},
+		Weights: map[int]float64{1: 0.5, -2: 1},
+		Backup:  &Server{Host: "spare"},
+		Coords:  [2]int{1, 2},
+		Extra:   map[string]any{"x": []any{true, nil}},
+		Default: "yes",
+	}, got)
+}
+
+func TestUnmarshal_Null(t *testing.T) {
+	got := Server{Host: "kept", Tags: []string{"x"}, Backup: &Server{}}
+	err := gj.Unmarshal(`{"host": null, "tags": null, "backup": null}`, &got)
+	assert.Nil(t, err)
+	assert.Equal(t, Server{Host: "kept"}, got)
+}
+
+func TestUnmarshal_Error(t *testing.T) {
+	var tests = []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "nested type",
+			input: `{"backup": {"tags": [1]}}`,
+			want:  `failed to convert number 1 at "/backup/tags/0" to string`,
+		},
+		{
+			name:  "map key",
+			input: `{"weights": {"x": 1}}`,
+			want:  `failed to convert key "x" at "/weights/x" to int`,
+		},
+		{
+			name:  "syntax",
+			input: `{"host": }`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Server
+			err := gj.Unmarshal(tt.input, &got)
+			if assert.Error(t, err) && tt.want != "" {
+				assert.Equal(t, tt.want, err.Error())
+			}
+		})
+	}
+
+	var got Server
+	assert.Error(t, gj.Unmarshal(`{}`, got))
+}
+
+func TestDisallowUnknownFields(t *testing.T) {
+	input := `{"host": "a", "backup": {"hots": "b"}}`
+
+	var got Server
+	assert.Nil(t, gj.Unmarshal(input, &got))
+
+	err := gj.Unmarshal(input, &got, gj.DisallowUnknownFields())
+	var unknownErr *gj.UnknownFieldError
+	if assert.ErrorAs(t, err, &unknownErr) {
+		assert.Equal(t, "hots", unknownErr.Key)
+		assert.Equal(t, "/backup", unknownErr.Path)
+		assert.Equal(t, 25, unknownErr.Offset)
+		assert.Equal(t, `failed to decode object at "/backup": unknown field "hots" at offset 25`, err.Error())
+	}
+
+	var m map[string]any
+	assert.Nil(t, gj.Unmarshal(input, &m, gj.DisallowUnknownFields()))
+}
+
+func TestUseNumber(t *testing.T) {
+	input := `{"big": 12345678901234567890, "f": 1.50, "n": [-3]}`
+
+	var plain any
+	assert.Nil(t, gj.Unmarshal(input, &plain))
+	assert.Equal(t, map[string]any{"big": 1.2345678901234567e19, "f": 1.5, "n": []any{int64(-3)}}, plain)
+
+	var numbers any
+	assert.Nil(t, gj.Unmarshal(input, &numbers, gj.UseNumber()))
+	assert.Equal(t, map[string]any{"big": gj.Number("12345678901234567890"), "f": gj.Number("1.50"), "n": []any{gj.Number("-3")}}, numbers)
+
+	big := numbers.(map[string]any)["big"].(gj.Number)
+	_, err := big.Int64()
+	assert.EqualError(t, err, "failed to convert number 12345678901234567890 to int64: value out of range")
+	f, err := big.Float64()
+	assert.Nil(t, err)
+	assert.Equal(t, 1.2345678901234567e19, f)
+
+	var s struct {
+		Big gj.Number `json:"big"`
+	}
+	assert.Nil(t, gj.Unmarshal(input, &s))
+	assert.Equal(t, gj.Number("12345678901234567890"), s.Big)
+	i, err := gj.Number("-3").Int64()
+	assert.Nil(t, err)
+	assert.Equal(t, int64(-3), i)
+}
+
+// Status is a protobuf enum.
+type Status int32
+
+var Status_value = map[string]int32{"STATUS_UNSPECIFIED": 0, "STATUS_ACTIVE": 1}
+
+type Account struct {
+	UserID    int64             `json:"user_id,omitempty"`
+	Quota     uint64            `json:"quota,omitempty"`
+	Ratio     float64           `json:"ratio,omitempty"`
+	Status    Status            `json:"status,omitempty"`
+	Timeout   time.Duration     `json:"timeout,omitempty"`
+	CreatedAt time.Time         `json:"created_at,omitempty"`
+	Metadata  map[string]any    `json:"metadata,omitempty"`
+	Limits    map[int64]float64 `json:"limits,omitempty"`
+}
+
+func TestProtoJSON(t *testing.T) {
+	input := `{
+		"userId": "9007199254740993",
+		"quota": "18446744073709551615",
+		"ratio": "Infinity",
+		"status": "STATUS_ACTIVE",
+		"timeout": "1.500s",
+		"createdAt": "2024-05-01T10:00:00.5Z",
+		"metadata": {"a": [1, "x"]},
+		"limits": {"1": "2.5e3"}
+	}`
+	var got Account
+	err := gj.Unmarshal(input, &got, gj.ProtoJSON(), gj.ProtoEnum[Status](Status_value))
+	assert.Nil(t, err)
+	assert.Equal(t, Account{
+		UserID:    9007199254740993,
+		Quota:     math.MaxUint64,
+		Ratio:     math.Inf(1),
+		Status:    1,
+		Timeout:   1500 * time.Millisecond,
+		CreatedAt: time.Date(2024, 5, 1, 10, 0, 0, 5e8, time.UTC),
+		Metadata:  map[string]any{"a": []any{int64(1), "x"}},
+		Limits:    map[int64]float64{1: 2500},
+	}, got)
+
+	got = Account{}
+	assert.Nil(t, gj.Unmarshal(`{"user_id": 1, "status": 1}`, &got, gj.ProtoJSON()))
+	assert.Equal(t, Account{UserID: 1, Status: 1}, got)
+
+	var tests = []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"unknown enum", `{"status": "STATUS_GONE"}`, `failed to convert string at "/status" to gj_test.Status: unknown enum value "STATUS_GONE"`},
+		{"invalid duration", `{"timeout": "1m"}`, `failed to convert string at "/timeout" to time.Duration: invalid duration "1m"`},
+		{"not a number", `{"userId": "12a"}`, `failed to convert string at "/userId" to int64`},
+		{"fraction", `{"userId": "1.5"}`, `failed to convert number 1.5 at "/userId" to int64: not an integer`},
+		{"overflow", `{"quota": "-1"}`, `failed to convert number -1 at "/quota" to uint64: overflows uint64`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Account
+			err := gj.Unmarshal(tt.input, &got, gj.ProtoJSON(), gj.ProtoEnum[Status](Status_value))
+			assert.EqualError(t, err, tt.want)
+		})
+	}
+
+	got = Account{}
+	assert.Error(t, gj.Unmarshal(`{"user_id": "1"}`, &got), "quoted numbers need ProtoJSON")
+}
+
+// Cents decodes amounts given as numbers or as strings like "12.34".
+type Cents int64
+
+func (c *Cents) UnmarshalGJ(v *ast.Value) error {
+	lit, ok := v.Value.(*ast.Literal)
+	if !ok {
+		return errNotAmount
+	}
+	if f, ok := lit.AsFloat(); ok {
+		*c = Cents(math.Round(f * 100))
+		return nil
+	}
+	if s, ok := lit.AsString(); ok {
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return errNotAmount
+		}
+		*c = Cents(math.Round(f * 100))
+		return nil
+	}
+	if lit.IsNull() {
+		*c = -1
+		return nil
+	}
+	return errNotAmount
+}
+
+var errNotAmount = errors.New("not an amount")
+
+// Level decodes from its name.
+type Level int
+
+func (l *Level) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "low":
+		*l = 1
+	case "high":
+		*l = 2
+	default:
+		return fmt.Errorf("unknown level %q", text)
+	}
+	return nil
+}
+
+func TestUnmarshal_RootNode(t *testing.T) {
+	var got struct {
+		Kind string        `json:"kind"`
+		Spec ast.RootNode  `json:"spec"`
+		Tags *ast.RootNode `json:"tags"`
+		None *ast.RootNode `json:"none"`
+	}
+	err := gj.Unmarshal(`{"kind": "job", "spec": {"n": 1}, "tags": ["a"], "none": null}`, &got)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, ast.RootNodeTypeObject, got.Spec.RootNodeType)
+	assert.Equal(t, map[string]any{"n": int64(1)}, got.Spec.ToGo())
+	assert.Equal(t, ast.RootNodeTypeArray, got.Tags.RootNodeType)
+	assert.Equal(t, []any{"a"}, got.Tags.ToGo())
+	assert.Nil(t, got.None)
+}
+
+func TestUnmarshal_Unmarshaler(t *testing.T) {
+	var got struct {
+		Price   Cents             `json:"price"`
+		Fee     *Cents            `json:"fee"`
+		Missing Cents             `json:"missing"`
+		At      time.Time         `json:"at"`
+		Level   Level             `json:"level"`
+		ByLevel map[Level]string  `json:"by_level"`
+		Times   []time.Time       `json:"times"`
+		Amounts map[string]Cents  `json:"amounts"`
+		Ignored map[string]string `json:"ignored"`
+	}
+	input := `{
+		"price": "12.34",
+		"fee": 0.5,
+		"missing": null,
+		"at": "2024-05-01T10:00:00Z",
+		"level": "high",
+		"by_level": {"low": "a"},
+		"times": ["2024-01-01T00:00:00Z"],
+		"amounts": {"x": 1}
+	}`
+	err := gj.Unmarshal(input, &got)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, Cents(1234), got.Price)
+	assert.Equal(t, Cents(50), *got.Fee)
+	assert.Equal(t, Cents(-1), got.Missing)
+	assert.Equal(t, time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC), got.At)
+	assert.Equal(t, Level(2), got.Level)
+	assert.Equal(t, map[Level]string{1: "a"}, got.ByLevel)
+	assert.Equal(t, []time.Time{time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}, got.Times)
+	assert.Equal(t, map[string]Cents{"x": 100}, got.Amounts)
+
+	var typeErr *gj.TypeError
+	err = gj.Unmarshal(`{"price": [1]}`, &got)
+	assert.ErrorIs(t, err, errNotAmount)
+	if assert.ErrorAs(t, err, &typeErr) {
+		assert.Equal(t, `failed to convert array at "/price" to gj_test.Cents: not an amount`, err.Error())
+	}
+
+	err = gj.Unmarshal(`{"level": "mid"}`, &got)
+	assert.EqualError(t, err, `failed to convert string at "/level" to gj_test.Level: unknown level "mid"`)
+	err = gj.Unmarshal(`{"level": 1}`, &got)
+	assert.EqualError(t, err, `failed to convert number 1 at "/level" to gj_test.Level`)
+	err = gj.Unmarshal(`{"by_level": {"mid": "a"}}`, &got)
+	assert.EqualError(t, err, `failed to convert key "mid" at "/by_level/mid" to gj_test.Level`)
+}
@@ -0,0 +1,158 @@
+// Package diff computes structural differences between JSON documents.
+package diff
+
+import (
+	"strconv"
+
+	"github.com/pohedev/gj.git/ast"
+	"github.com/pohedev/gj.git/path"
+)
+
+// Op identifies the kind of a Change.
+type Op int
+
+const (
+	OpAdd     Op = iota + 1 // value added
+	OpRemove                // value removed
+	OpReplace               // value replaced
+)
+
+func (op Op) String() string {
+	switch op {
+	case OpAdd:
+		return "add"
+	case OpRemove:
+		return "remove"
+	case OpReplace:
+		return "replace"
+	}
+	return "Op(" + strconv.Itoa(int(op)) + ")"
+}
+
+// Change is a difference between two documents.
+type Change struct {
+	Op   Op
+	Path string     // JSON Pointer of the value.
+	Old  *ast.Value // Value in the first document; nil for OpAdd.
+	New  *ast.Value // Value in the second document; nil for OpRemove.
+}
+
+// Compare returns the changes turning a into b. Objects are compared
+// by key, the last property winning when a key appears more than once;
+// arrays are compared by index. Numbers compare by value, so 1 and 1.0
+// are equal. Changes are ordered so that applying them in order as
+// RFC 6902 operations turns a into b.
+func Compare(a, b *ast.Value) []Change {
+	var changes []Change
+	compare(a, b, "", &changes)
+	return changes
+}
+
+// compare appends the changes turning a into b, located at ptr.
+func compare(a, b *ast.Value, ptr string, changes *[]Change) {
+	switch x := unwrap(a).(type) {
+	case *ast.Object:
+		if y, ok := unwrap(b).(*ast.Object); ok {
+			compareObjects(x, y, ptr, changes)
+			return
+		}
+	case *ast.Array:
+		if y, ok := unwrap(b).(*ast.Array); ok {
+			compareArrays(x, y, ptr, changes)
+			return
+		}
+	}
+	if !equalScalar(unwrap(a), unwrap(b)) {
+		*changes = append(*changes, Change{Op: OpReplace, Path: ptr, Old: a, New: b})
+	}
+}
+
+// compareObjects appends the changes turning object x into object y.
+func compareObjects(x, y *ast.Object, ptr string, changes *[]Change) {
+	seen := make(map[string]bool, len(x.Children))
+	for _, key := range x.Keys() {
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		old, _ := x.Get(key)
+		keyPtr := appendPointer(ptr, key)
+		if v, ok := y.Get(key); ok {
+			compare(old, v, keyPtr, changes)
+		} else {
+			*changes = append(*changes, Change{Op: OpRemove, Path: keyPtr, Old: old})
+		}
+	}
+	for _, key := range y.Keys() {
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		v, _ := y.Get(key)
+		*changes = append(*changes, Change{Op: OpAdd, Path: appendPointer(ptr, key), New: v})
+	}
+}
+
+// compareArrays appends the changes turning array x into array y.
+// Extra items are removed from the end first so that indexes stay valid.
+func compareArrays(x, y *ast.Array, ptr string, changes *[]Change) {
+	n := min(x.Len(), y.Len())
+	for i := 0; i < n; i++ {
+		old, _ := x.At(i)
+		v, _ := y.At(i)
+		compare(old, v, appendPointer(ptr, strconv.Itoa(i)), changes)
+	}
+	for i := x.Len() - 1; i >= n; i-- {
+		old, _ := x.At(i)
+		*changes = append(*changes, Change{Op: OpRemove, Path: appendPointer(ptr, strconv.Itoa(i)), Old: old})
+	}
+	for i := n; i < y.Len(); i++ {
+		v, _ := y.At(i)
+		*changes = append(*changes, Change{Op: OpAdd, Path: appendPointer(ptr, strconv.Itoa(i)), New: v})
+	}
+}
+
+// Equal reports whether a and b hold the same JSON value, see Compare.
+func Equal(a, b *ast.Value) bool {
+	return len(Compare(a, b)) == 0
+}
+
+// equalScalar reports whether nodes x and y, which are not both objects
+// or both arrays, are equal.
+func equalScalar(x, y any) bool {
+	switch x := x.(type) {
+	case *ast.Literal:
+		y, ok := y.(*ast.Literal)
+		if !ok {
+			return false
+		}
+		if xi, ok := x.AsInt(); ok {
+			if yi, ok := y.AsInt(); ok {
+				return xi == yi
+			}
+		}
+		if xf, ok := x.AsFloat(); ok {
+			yf, ok := y.AsFloat()
+			return ok && xf == yf
+		}
+		return x.LiteralType == y.LiteralType && x.Val == y.Val
+	}
+	return x == nil && y == nil
+}
+
+// unwrap returns the node held by v.
+func unwrap(v *ast.Value) any {
+	for v != nil {
+		if inner, ok := v.Value.(*ast.Value); ok {
+			v = inner
+			continue
+		}
+		return v.Value
+	}
+	return nil
+}
+
+// appendPointer appends token to JSON Pointer ptr.
+func appendPointer(ptr, token string) string {
+	return ptr + "/" + path.Escape(token)
+}
@@ -0,0 +1,74 @@
+package diff_test
+
+import (
+	"testing"
+
+	"github.com/pohedev/gj.git/diff"
+	"github.com/pohedev/gj.git/gjtest"
+	"github.com/pohedev/gj.git/printer"
+	"github.com/stretchr/testify/assert"
+)
+
+// change is a Change with values as Go values.
+type change struct {
+	op  diff.Op
+	ptr string
+	old any
+	new any
+}
+
+func TestCompare(t *testing.T) {
+	var tests = []struct {
+		name string
+		a, b string
+		want []change
+	}{
+		{"equal", `{"a": [1, {"b": null}]}`, `{"a": [1.0, {"b": null}]}`, nil},
+		{"root", `1`, `"x"`, []change{{diff.OpReplace, "", int64(1), "x"}}},
+		{
+			name: "object",
+			a:    `{"a": 1, "b": {"c": true}, "d": "x", "a/b": 1}`,
+			b:    `{"b": {"c": false}, "d": "x", "e": [], "a/b": 2}`,
+			want: []change{
+				{diff.OpRemove, "/a", int64(1), nil},
+				{diff.OpReplace, "/b/c", true, false},
+				{diff.OpReplace, "/a~1b", int64(1), int64(2)},
+				{diff.OpAdd, "/e", nil, []any{}},
+			},
+		},
+		{
+			name: "array",
+			a:    `[1, 2, 3, 4]`,
+			b:    `[1, 5]`,
+			want: []change{
+				{diff.OpReplace, "/1", int64(2), int64(5)},
+				{diff.OpRemove, "/3", int64(4), nil},
+				{diff.OpRemove, "/2", int64(3), nil},
+			},
+		},
+		{"array grows", `[1]`, `[1, [2]]`, []change{{diff.OpAdd, "/1", nil, []any{int64(2)}}}},
+		{"type change", `{"a": [1]}`, `{"a": {"0": 1}}`, []change{{diff.OpReplace, "/a", []any{int64(1)}, map[string]any{"0": int64(1)}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []change
+			for _, c := range diff.Compare(gjtest.Parse(t, tt.a).Value, gjtest.Parse(t, tt.b).Value) {
+				got = append(got, change{c.Op, c.Path, c.Old.ToGo(), c.New.ToGo()})
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestEqual(t *testing.T) {
+	assert.True(t, diff.Equal(gjtest.Parse(t, `{"a": [1, "x"]}`).Value, gjtest.Parse(t, `{"a": [1e0, "x"]}`).Value))
+	assert.False(t, diff.Equal(gjtest.Parse(t, `{"a": [1, "x"]}`).Value, gjtest.Parse(t, `{"a": [1, "y"]}`).Value))
+	assert.False(t, diff.Equal(gjtest.Parse(t, `null`).Value, gjtest.Parse(t, `false`).Value))
+}
+
+func TestPatch(t *testing.T) {
+	changes := diff.Compare(gjtest.Parse(t, `{"a": 1, "b": [1, 2]}`).Value, gjtest.Parse(t, `{"a": 2, "b": [1], "c": null}`).Value)
+	got, err := printer.Sprint(diff.Patch(changes))
+	assert.Nil(t, err)
+	assert.Equal(t, `[{"op":"replace","path":"/a","value":2},{"op":"remove","path":"/b/1"},{"op":"add","path":"/c","value":null}]`, got)
+}
@@ -0,0 +1,18 @@
+package diff
+
+import "github.com/pohedev/gj.git/ast"
+
+// Patch returns changes as an RFC 6902 JSON Patch document.
+func Patch(changes []Change) *ast.Array {
+	patch := &ast.Array{Children: make([]ast.ArrayItem, 0, len(changes))}
+	for _, c := range changes {
+		op := &ast.Object{}
+		op.Set("op", &ast.Value{Value: ast.String(c.Op.String())})
+		op.Set("path", &ast.Value{Value: ast.String(c.Path)})
+		if c.Op != OpRemove {
+			op.Set("value", c.New)
+		}
+		patch.Children = append(patch.Children, ast.ArrayItem{Value: op})
+	}
+	return patch
+}
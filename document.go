@@ -0,0 +1,118 @@
+package gj
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+
+	"github.com/pohedev/gj.git/ast"
+	"github.com/pohedev/gj.git/edit"
+	"github.com/pohedev/gj.git/lexer"
+	"github.com/pohedev/gj.git/parser"
+	"github.com/pohedev/gj.git/printer"
+)
+
+// Document is a document safe for concurrent use, such as a configuration
+// read by many goroutines and updated by a few. It holds a frozen tree,
+// see ast.RootNode.Freeze, replaced as a whole by every update: Set copies
+// only the containers on the way to the value, like ast.Overlay, and
+// Transform works on a copy, so that readers never see a partial update.
+type Document struct {
+	update sync.Mutex   // Serializes updates.
+	mu     sync.RWMutex // Guards root.
+	root   *ast.RootNode
+}
+
+// NewDocument returns a document holding root, which is frozen.
+func NewDocument(root *ast.RootNode) *Document {
+	root.Freeze()
+	return &Document{root: root}
+}
+
+// ParseDocument parses input into a document.
+func ParseDocument(input string) (*Document, error) {
+	root, err := parser.New(lexer.Lex(input)).Parse()
+	if err != nil {
+		return nil, err
+	}
+	return NewDocument(root), nil
+}
+
+// Root returns the current tree. It is frozen and stays unchanged by later
+// updates, so that it can be read without holding the document.
+func (d *Document) Root() *ast.RootNode {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.root
+}
+
+// Get stores the value at path in the value pointed to by v, like GetAs.
+func (d *Document) Get(path string, v any, opts ...DecodeOption) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("failed to decode: non-nil pointer required, got %T", v)
+	}
+	node, ptr, err := lookup(d.Root(), path)
+	if err != nil {
+		return err
+	}
+	return newDecoder(opts).decode(node, rv.Elem(), ptr)
+}
+
+// Set sets the value at path to v, see ast.Overlay.Set for the paths
+// accepted. v becomes part of the frozen tree.
+func (d *Document) Set(path string, v *ast.Value) error {
+	d.update.Lock()
+	defer d.update.Unlock()
+	o := ast.NewOverlay(d.Root())
+	if err := o.Set(path, v); err != nil {
+		return err
+	}
+	d.replace(o.Root())
+	return nil
+}
+
+// Transform calls fn with a copy of the tree, which replaces the tree
+// unless fn fails. Other updates wait until fn returns, reads do not.
+func (d *Document) Transform(fn func(root *ast.RootNode) error) error {
+	d.update.Lock()
+	defer d.update.Unlock()
+	root := d.Root().Clone()
+	if err := fn(root); err != nil {
+		return err
+	}
+	d.replace(root)
+	return nil
+}
+
+// Edit calls fn to queue operations on a transaction on the current tree,
+// then commits it: the tree is replaced by the edited one, or unchanged
+// when any operation fails, see edit.Transaction.Commit. Other updates
+// wait until the transaction is committed, so that it applies to the tree
+// it was built on.
+func (d *Document) Edit(fn func(tx *edit.Transaction)) error {
+	d.update.Lock()
+	defer d.update.Unlock()
+	tx := edit.New(d.Root())
+	fn(tx)
+	root, err := tx.Commit()
+	if err != nil {
+		return err
+	}
+	d.replace(root)
+	return nil
+}
+
+// replace freezes root and makes it the current tree.
+func (d *Document) replace(root *ast.RootNode) {
+	root.Freeze()
+	d.mu.Lock()
+	d.root = root
+	d.mu.Unlock()
+}
+
+// Serialize writes the current tree to w, see printer.Fprint.
+func (d *Document) Serialize(w io.Writer, opts ...printer.Option) error {
+	return printer.Fprint(w, d.Root(), opts...)
+}
@@ -0,0 +1,110 @@
+package gj_test
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/pohedev/gj.git"
+	"github.com/pohedev/gj.git/ast"
+	"github.com/pohedev/gj.git/edit"
+	"github.com/pohedev/gj.git/transform"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDocument(t *testing.T) {
+	doc, err := gj.ParseDocument(`{"server": {"port": 80, "hosts": ["a"]}, "debug": false}`)
+	require.NoError(t, err)
+	before := doc.Root()
+	assert.True(t, before.Frozen())
+
+	var port int
+	assert.Nil(t, doc.Get("server.port", &port))
+	assert.Equal(t, 80, port)
+
+	assert.Nil(t, doc.Set("server.port", &ast.Value{Value: ast.Number(8080)}))
+	assert.Nil(t, doc.Set("server.hosts[1]", &ast.Value{Value: ast.String("b")}))
+	assert.Nil(t, doc.Get("server.port", &port))
+	assert.Equal(t, 8080, port)
+	assert.Error(t, doc.Set("missing.key", &ast.Value{Value: ast.Null()}))
+
+	err = doc.Transform(func(root *ast.RootNode) error {
+		if _, err := root.Value.Value.(*ast.Object).Delete("debug"); err != nil {
+			return err
+		}
+		return transform.Prune(root, transform.PruneNulls)
+	})
+	assert.Nil(t, err)
+	errStop := errors.New("stop")
+	err = doc.Transform(func(root *ast.RootNode) error {
+		if _, err := root.Value.Value.(*ast.Object).Delete("server"); err != nil {
+			return err
+		}
+		return errStop
+	})
+	assert.ErrorIs(t, err, errStop, "a failed transform changes nothing")
+
+	var b strings.Builder
+	assert.Nil(t, doc.Serialize(&b))
+	assert.Equal(t, `{"server":{"port":8080,"hosts":["a","b"]}}`, b.String())
+	assert.True(t, doc.Root().Frozen())
+	assert.Equal(t, map[string]any{"server": map[string]any{"port": int64(80), "hosts": []any{"a"}}, "debug": false}, before.Value.ToGo(), "earlier trees are unchanged")
+
+	var s string
+	assert.Error(t, doc.Get("debug", &s))
+	assert.EqualError(t, doc.Get("debug", s), "failed to decode: non-nil pointer required, got string")
+	_, err = gj.ParseDocument(`{`)
+	assert.Error(t, err)
+}
+
+func TestDocument_Edit(t *testing.T) {
+	doc, err := gj.ParseDocument(`{"server": {"port": 80}, "debug": false}`)
+	require.NoError(t, err)
+
+	err = doc.Edit(func(tx *edit.Transaction) {
+		tx.Replace("/server/port", &ast.Value{Value: ast.Number(8080)})
+		tx.Delete("/debug")
+	})
+	assert.Nil(t, err)
+	err = doc.Edit(func(tx *edit.Transaction) {
+		tx.Set("/server/host", &ast.Value{Value: ast.String("x")})
+		tx.Replace("/server/port", &ast.Value{Value: ast.String("80")})
+	})
+	assert.ErrorIs(t, err, edit.ErrTypeMismatch)
+
+	var b strings.Builder
+	assert.Nil(t, doc.Serialize(&b))
+	assert.Equal(t, `{"server":{"port":8080}}`, b.String(), "a failed transaction changes nothing")
+}
+
+func TestDocument_Concurrent(t *testing.T) {
+	doc, err := gj.ParseDocument(`{"n": 0}`)
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 50 {
+				if i%2 == 0 {
+					var n int
+					assert.Nil(t, doc.Get("n", &n))
+					continue
+				}
+				err := doc.Transform(func(root *ast.RootNode) error {
+					n, _ := root.GetInt("n")
+					return root.Value.Value.(*ast.Object).Set("n", &ast.Value{Value: ast.Number(n + 1)})
+				})
+				assert.Nil(t, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	var n int
+	assert.Nil(t, doc.Get("n", &n))
+	assert.Equal(t, 200, n)
+}
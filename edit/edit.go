@@ -0,0 +1,138 @@
+// Package edit applies batches of path-based edits to documents, all of
+// them or none.
+package edit
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/pohedev/gj.git/ast"
+	"github.com/pohedev/gj.git/diff"
+	"github.com/pohedev/gj.git/parser"
+)
+
+// Kinds of errors of operations, to be matched with errors.Is.
+var (
+	ErrNotFound     = parser.ErrNotFound
+	ErrTypeMismatch = errors.New("type mismatch")
+	ErrTestFailed   = errors.New("test failed")
+)
+
+// OpError reports an operation of a Transaction that cannot be applied.
+type OpError struct {
+	Index int    // Position of the operation in the transaction.
+	Op    string // Name of the operation: set, replace, delete or test.
+	Path  string // Path given to the operation.
+	Err   error
+}
+
+func (e *OpError) Error() string {
+	return fmt.Sprintf("failed to %s %q (operation %d): %v", e.Op, e.Path, e.Index, e.Err)
+}
+
+func (e *OpError) Unwrap() error {
+	return e.Err
+}
+
+// Transaction is a batch of edits of a tree, applied by Commit all
+// together or not at all. Paths are as accepted by ast.Overlay.Set, and
+// each operation sees the effect of the operations before it.
+type Transaction struct {
+	root *ast.RootNode
+	ops  []operation
+}
+
+// operation is an edit queued in a Transaction.
+type operation struct {
+	name  string
+	path  string
+	value *ast.Value
+}
+
+// New returns an empty transaction on root. Commit freezes root, see
+// ast.RootNode.Freeze: later in-place edits of it, such as Object.Set,
+// fail with ast.ErrFrozen. Pass root.Clone() to keep root mutable.
+func New(root *ast.RootNode) *Transaction {
+	return &Transaction{root: root}
+}
+
+// Set queues setting the value at path to v, adding it when missing. The
+// object or array holding it must exist.
+func (t *Transaction) Set(path string, v *ast.Value) *Transaction {
+	t.ops = append(t.ops, operation{"set", path, v})
+	return t
+}
+
+// Replace queues replacing the value at path, which must exist and be of
+// the same type as v: object, array, string, number, boolean or null.
+func (t *Transaction) Replace(path string, v *ast.Value) *Transaction {
+	t.ops = append(t.ops, operation{"replace", path, v})
+	return t
+}
+
+// Delete queues removing the value at path, which must exist.
+func (t *Transaction) Delete(path string) *Transaction {
+	t.ops = append(t.ops, operation{"delete", path, nil})
+	return t
+}
+
+// Test queues checking that the value at path equals v, see diff.Equal,
+// like the test operation of JSON Patch, so that the transaction only
+// applies to the expected state of the document.
+func (t *Transaction) Test(path string, v *ast.Value) *Transaction {
+	t.ops = append(t.ops, operation{"test", path, v})
+	return t
+}
+
+// Len returns the number of operations queued.
+func (t *Transaction) Len() int {
+	return len(t.ops)
+}
+
+// Commit applies the operations and returns the edited tree. When any
+// operation cannot be applied, it returns nil and a parser.ErrorList
+// holding an OpError for every failed operation, the others being
+// applied to check the following ones. The tree of the transaction is
+// frozen and left unchanged either way: edits are applied to an
+// ast.Overlay of it, which the returned tree shares the unedited values
+// with.
+func (t *Transaction) Commit() (*ast.RootNode, error) {
+	o := ast.NewOverlay(t.root)
+	var errs parser.ErrorList
+	for i, op := range t.ops {
+		if err := op.apply(o); err != nil {
+			errs = append(errs, &OpError{Index: i, Op: op.name, Path: op.path, Err: err})
+		}
+	}
+	if errs != nil {
+		return nil, errs
+	}
+	return o.Root(), nil
+}
+
+// apply applies op to o.
+func (op operation) apply(o *ast.Overlay) error {
+	switch op.name {
+	case "set":
+		return o.Set(op.path, op.value)
+	case "delete":
+		if _, ok := o.Get(op.path); !ok {
+			return ErrNotFound
+		}
+		return o.Delete(op.path)
+	}
+	old, ok := o.Get(op.path)
+	if !ok {
+		return ErrNotFound
+	}
+	if op.name == "test" {
+		if !diff.Equal(old, op.value) {
+			return ErrTestFailed
+		}
+		return nil
+	}
+	if from, to := ast.TypeName(old), ast.TypeName(op.value); from != to {
+		return fmt.Errorf("%w: cannot replace %s with %s", ErrTypeMismatch, from, to)
+	}
+	return o.Set(op.path, op.value)
+}
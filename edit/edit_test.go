@@ -0,0 +1,78 @@
+package edit_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/pohedev/gj.git/ast"
+	"github.com/pohedev/gj.git/edit"
+	"github.com/pohedev/gj.git/gjtest"
+	"github.com/pohedev/gj.git/parser"
+	"github.com/pohedev/gj.git/printer"
+	"github.com/stretchr/testify/assert"
+)
+
+// sprint prints root compactly or fails the test.
+func sprint(t *testing.T, root *ast.RootNode) string {
+	t.Helper()
+	s, err := printer.Sprint(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+const config = `{"db": {"host": "localhost", "port": 5432}, "features": ["a", "b"], "debug": false}`
+
+func TestTransaction_Commit(t *testing.T) {
+	root := gjtest.Parse(t, config)
+	tx := edit.New(root).
+		Test("/db/host", &ast.Value{Value: ast.String("localhost")}).
+		Replace("/db/port", &ast.Value{Value: ast.Number(6432)}).
+		Set("/db/user", &ast.Value{Value: ast.String("app")}).
+		Set("/features/-", &ast.Value{Value: ast.String("c")}).
+		Delete("/features/0").
+		Replace("$.debug", &ast.Value{Value: ast.Bool(true)})
+	assert.Equal(t, 6, tx.Len())
+
+	got, err := tx.Commit()
+	if assert.Nil(t, err) {
+		assert.Equal(t, `{"db":{"host":"localhost","port":6432,"user":"app"},"features":["b","c"],"debug":true}`, sprint(t, got))
+	}
+	assert.Equal(t, `{"db":{"host":"localhost","port":5432},"features":["a","b"],"debug":false}`, sprint(t, root))
+}
+
+func TestTransaction_CommitErrors(t *testing.T) {
+	root := gjtest.Parse(t, config)
+	got, err := edit.New(root).
+		Set("/db/port", &ast.Value{Value: ast.Number(1)}).
+		Replace("/db/port", &ast.Value{Value: ast.String("1")}).
+		Delete("/db/missing").
+		Set("/cache/size", &ast.Value{Value: ast.Number(1)}).
+		Test("/debug", &ast.Value{Value: ast.Bool(true)}).
+		Test("/db/port", &ast.Value{Value: ast.Number(1)}).
+		Commit()
+	assert.Nil(t, got)
+
+	var list parser.ErrorList
+	if !assert.True(t, errors.As(err, &list)) || !assert.Len(t, list, 4) {
+		return
+	}
+	var indexes []int
+	for _, err := range list {
+		var opErr *edit.OpError
+		if assert.True(t, errors.As(err, &opErr)) {
+			indexes = append(indexes, opErr.Index)
+		}
+	}
+	assert.Equal(t, []int{1, 2, 3, 4}, indexes)
+	assert.ErrorIs(t, list[0], edit.ErrTypeMismatch)
+	assert.EqualError(t, list[0], `failed to replace "/db/port" (operation 1): type mismatch: cannot replace number with string`)
+	assert.ErrorIs(t, list[1], edit.ErrNotFound)
+	assert.ErrorContains(t, list[2], `no key "cache"`)
+	assert.ErrorIs(t, list[3], edit.ErrTestFailed)
+	assert.ErrorIs(t, err, edit.ErrTestFailed)
+	assert.EqualError(t, err, list[0].Error()+" (and 3 more errors)")
+
+	assert.Equal(t, `{"db":{"host":"localhost","port":5432},"features":["a","b"],"debug":false}`, sprint(t, root))
+}
@@ -0,0 +1,49 @@
+package gj
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// PathError reports a path that cannot be resolved in a document.
+type PathError struct {
+	Path string // Path as given.
+	Msg  string // Reason of the failure.
+}
+
+func (e *PathError) Error() string {
+	return fmt.Sprintf("failed to resolve path %q: %s", e.Path, e.Msg)
+}
+
+// TypeError reports a JSON value that cannot be converted to a Go type.
+type TypeError struct {
+	Path  string       // JSON Pointer of the value.
+	Value string       // Kind of the JSON value, e.g. "string" or "number 300".
+	Type  reflect.Type // Requested Go type.
+	Msg   string       // Optional detail, e.g. "overflows uint8".
+	Err   error        // Error returned by an Unmarshaler, if any.
+}
+
+func (e *TypeError) Error() string {
+	msg := fmt.Sprintf("failed to convert %s at %q to %v", e.Value, e.Path, e.Type)
+	if e.Msg != "" {
+		msg += ": " + e.Msg
+	}
+	return msg
+}
+
+func (e *TypeError) Unwrap() error {
+	return e.Err
+}
+
+// UnknownFieldError reports a property matching no field of the target
+// struct, see DisallowUnknownFields.
+type UnknownFieldError struct {
+	Key    string // Key of the property.
+	Path   string // JSON Pointer of the object.
+	Offset int    // Byte offset of the key in the input.
+}
+
+func (e *UnknownFieldError) Error() string {
+	return fmt.Sprintf("failed to decode object at %q: unknown field %q at offset %d", e.Path, e.Key, e.Offset)
+}
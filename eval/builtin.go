@@ -0,0 +1,326 @@
+package eval
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pohedev/gj.git/ast"
+	"github.com/pohedev/gj.git/printer"
+)
+
+// builtin implements a function called with unevaluated arguments.
+type builtin func(in *ast.Value, args []expr) ([]*ast.Value, error)
+
+// builtinKey identifies a function by name and number of arguments.
+type builtinKey struct {
+	name  string
+	arity int
+}
+
+// builtins maps the supported functions to their implementation.
+var builtins = map[builtinKey]builtin{
+	{"empty", 0}:    func(*ast.Value, []expr) ([]*ast.Value, error) { return nil, nil },
+	{"not", 0}:      one(func(v *ast.Value) (*ast.Value, error) { return value(ast.Bool(!truthy(v))), nil }),
+	{"type", 0}:     one(func(v *ast.Value) (*ast.Value, error) { return value(ast.String(ast.TypeName(v))), nil }),
+	{"length", 0}:   one(length),
+	{"keys", 0}:     one(keys),
+	{"add", 0}:      one(add),
+	{"sort", 0}:     one(sortValues),
+	{"reverse", 0}:  one(reverse),
+	{"unique", 0}:   one(unique),
+	{"min", 0}:      one(extremum(-1)),
+	{"max", 0}:      one(extremum(1)),
+	{"first", 0}:    one(func(v *ast.Value) (*ast.Value, error) { return index(v, value(ast.Number(0))) }),
+	{"last", 0}:     one(func(v *ast.Value) (*ast.Value, error) { return index(v, value(ast.Number(-1))) }),
+	{"tostring", 0}: one(tostring),
+	{"tonumber", 0}: one(tonumber),
+	{"first", 1}:    first,
+	{"last", 1}:     last,
+	{"select", 1}:   selectValues,
+	{"map", 1}:      mapValues,
+	{"sort_by", 1}:  sortBy,
+	{"has", 1}:      has,
+	{"join", 1}:     join,
+}
+
+// one returns a builtin without arguments producing one output.
+func one(fn func(v *ast.Value) (*ast.Value, error)) builtin {
+	return func(in *ast.Value, _ []expr) ([]*ast.Value, error) {
+		v, err := fn(in)
+		if err != nil {
+			return nil, err
+		}
+		return []*ast.Value{v}, nil
+	}
+}
+
+// items returns the items of array v, or an error naming function name.
+func items(name string, v *ast.Value) ([]*ast.Value, error) {
+	if _, ok := v.Value.(*ast.Array); !ok {
+		return nil, fmt.Errorf("%s: %s is not an array", name, ast.TypeName(v))
+	}
+	return children(v)
+}
+
+// array returns an array of values.
+func array(values []*ast.Value) *ast.Value {
+	a := &ast.Array{Children: make([]ast.ArrayItem, len(values))}
+	for i, v := range values {
+		a.Children[i].Value = v
+	}
+	return value(a)
+}
+
+func length(v *ast.Value) (*ast.Value, error) {
+	switch n := v.Value.(type) {
+	case *ast.Object:
+		return value(ast.Number(len(n.Index()))), nil
+	case *ast.Array:
+		return value(ast.Number(len(n.Children))), nil
+	}
+	if s, ok := asString(v); ok {
+		return value(ast.Number(utf8.RuneCountInString(s))), nil
+	}
+	if i, f, isInt, ok := asNumber(v); ok {
+		if isInt && i != math.MinInt64 {
+			return value(ast.Number(max(i, -i))), nil
+		}
+		return value(ast.Number(math.Abs(f))), nil
+	}
+	if rank(v) == 0 {
+		return value(ast.Number(0)), nil
+	}
+	return nil, fmt.Errorf("length: %s has no length", ast.TypeName(v))
+}
+
+func keys(v *ast.Value) (*ast.Value, error) {
+	switch n := v.Value.(type) {
+	case *ast.Object:
+		var out []*ast.Value
+		for _, key := range sortedKeys(n) {
+			out = append(out, value(ast.String(key)))
+		}
+		return array(out), nil
+	case *ast.Array:
+		out := make([]*ast.Value, len(n.Children))
+		for i := range out {
+			out[i] = value(ast.Number(i))
+		}
+		return array(out), nil
+	}
+	return nil, fmt.Errorf("keys: %s has no keys", ast.TypeName(v))
+}
+
+func add(v *ast.Value) (*ast.Value, error) {
+	values, err := items("add", v)
+	if err != nil {
+		return nil, err
+	}
+	sum := value(ast.Null())
+	for _, item := range values {
+		if sum, err = arithmetic("+", sum, item); err != nil {
+			return nil, err
+		}
+	}
+	return sum, nil
+}
+
+func sortValues(v *ast.Value) (*ast.Value, error) {
+	values, err := items("sort", v)
+	if err != nil {
+		return nil, err
+	}
+	slices.SortStableFunc(values, compare)
+	return array(values), nil
+}
+
+func reverse(v *ast.Value) (*ast.Value, error) {
+	if s, ok := asString(v); ok {
+		runes := []rune(s)
+		slices.Reverse(runes)
+		return value(ast.String(string(runes))), nil
+	}
+	if rank(v) == 0 {
+		return array(nil), nil
+	}
+	values, err := items("reverse", v)
+	if err != nil {
+		return nil, err
+	}
+	slices.Reverse(values)
+	return array(values), nil
+}
+
+func unique(v *ast.Value) (*ast.Value, error) {
+	values, err := items("unique", v)
+	if err != nil {
+		return nil, err
+	}
+	slices.SortStableFunc(values, compare)
+	return array(slices.CompactFunc(values, func(a, b *ast.Value) bool { return compare(a, b) == 0 })), nil
+}
+
+// extremum returns min for sign -1 and max for sign 1.
+func extremum(sign int) func(v *ast.Value) (*ast.Value, error) {
+	return func(v *ast.Value) (*ast.Value, error) {
+		values, err := items("min/max", v)
+		if err != nil || len(values) == 0 {
+			return value(ast.Null()), err
+		}
+		best := values[0]
+		for _, item := range values[1:] {
+			if c := compare(item, best); c*sign > 0 || c == 0 && sign > 0 {
+				best = item
+			}
+		}
+		return best, nil
+	}
+}
+
+func tostring(v *ast.Value) (*ast.Value, error) {
+	if _, ok := asString(v); ok {
+		return v, nil
+	}
+	s, err := printer.Sprint(v)
+	if err != nil {
+		return nil, err
+	}
+	return value(ast.String(s)), nil
+}
+
+func tonumber(v *ast.Value) (*ast.Value, error) {
+	if _, _, _, ok := asNumber(v); ok {
+		return v, nil
+	}
+	s, ok := asString(v)
+	if !ok {
+		return nil, fmt.Errorf("tonumber: %s cannot be parsed as a number", ast.TypeName(v))
+	}
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return value(ast.Number(i)), nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsInf(f, 0) || math.IsNaN(f) {
+		return nil, fmt.Errorf("tonumber: %q cannot be parsed as a number", s)
+	}
+	return value(ast.Number(f)), nil
+}
+
+func first(in *ast.Value, args []expr) ([]*ast.Value, error) {
+	out, err := args[0].eval(in)
+	if len(out) > 0 {
+		return out[:1], nil
+	}
+	return nil, err
+}
+
+func last(in *ast.Value, args []expr) ([]*ast.Value, error) {
+	out, err := args[0].eval(in)
+	if err != nil || len(out) == 0 {
+		return nil, err
+	}
+	return out[len(out)-1:], nil
+}
+
+func selectValues(in *ast.Value, args []expr) ([]*ast.Value, error) {
+	conds, err := args[0].eval(in)
+	var out []*ast.Value
+	for _, c := range conds {
+		if truthy(c) {
+			out = append(out, in)
+		}
+	}
+	return out, err
+}
+
+func mapValues(in *ast.Value, args []expr) ([]*ast.Value, error) {
+	return (&arrayExpr{&pipeExpr{&iterateExpr{identityExpr{}}, args[0]}}).eval(in)
+}
+
+func sortBy(in *ast.Value, args []expr) ([]*ast.Value, error) {
+	values, err := items("sort_by", in)
+	if err != nil {
+		return nil, err
+	}
+	sortKeys := make([]*ast.Value, len(values))
+	for i, v := range values {
+		out, err := (&arrayExpr{args[0]}).eval(v)
+		if err != nil {
+			return nil, err
+		}
+		sortKeys[i] = out[0]
+	}
+	order := make([]int, len(values))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int { return compare(sortKeys[a], sortKeys[b]) })
+	sorted := make([]*ast.Value, len(values))
+	for i, j := range order {
+		sorted[i] = values[j]
+	}
+	return []*ast.Value{array(sorted)}, nil
+}
+
+func has(in *ast.Value, args []expr) ([]*ast.Value, error) {
+	keys, err := args[0].eval(in)
+	if err != nil {
+		return nil, err
+	}
+	var out []*ast.Value
+	for _, key := range keys {
+		switch n := in.Value.(type) {
+		case *ast.Object:
+			if s, ok := asString(key); ok {
+				_, found := n.Get(s)
+				out = append(out, value(ast.Bool(found)))
+				continue
+			}
+		case *ast.Array:
+			if _, f, _, ok := asNumber(key); ok {
+				out = append(out, value(ast.Bool(f >= 0 && f < float64(len(n.Children)))))
+				continue
+			}
+		}
+		return out, fmt.Errorf("has: cannot check whether %s has a %s key", ast.TypeName(in), ast.TypeName(key))
+	}
+	return out, nil
+}
+
+func join(in *ast.Value, args []expr) ([]*ast.Value, error) {
+	values, err := items("join", in)
+	if err != nil {
+		return nil, err
+	}
+	seps, err := args[0].eval(in)
+	if err != nil {
+		return nil, err
+	}
+	var out []*ast.Value
+	for _, sep := range seps {
+		s, ok := asString(sep)
+		if !ok {
+			return out, fmt.Errorf("join: separator must be a string, not %s", ast.TypeName(sep))
+		}
+		parts := make([]string, len(values))
+		for i, v := range values {
+			switch {
+			case rank(v) == 0:
+			case rank(v) >= 5:
+				return out, fmt.Errorf("join: cannot join %s", ast.TypeName(v))
+			default:
+				str, err := tostring(v)
+				if err != nil {
+					return out, err
+				}
+				parts[i], _ = asString(str)
+			}
+		}
+		out = append(out, value(ast.String(strings.Join(parts, s))))
+	}
+	return out, nil
+}
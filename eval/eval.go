@@ -0,0 +1,393 @@
+// Package eval evaluates jq-like expressions over the AST.
+//
+// The supported subset of jq covers:
+//   - identity ".", recursion "..", field access ".a", ."a" and .["a"],
+//     indexing .[0] and .[-1], slicing .[1:3], iteration .[] and "?";
+//   - pipes "|", commas ",", alternatives "//", "and", "or";
+//   - comparisons "==", "!=", "<", "<=", ">", ">=";
+//   - arithmetic "+", "-", "*", "/", "%" on numbers, and "+" on strings,
+//     arrays and objects, "-" on arrays;
+//   - literals, array construction [...] and object construction {...};
+//   - the functions length, keys, type, not, empty, add, sort, sort_by(f),
+//     reverse, unique, min, max, first, last, first(f), last(f), tostring,
+//     tonumber, select(f), map(f), has(k) and join(s).
+//
+// Like jq, an expression produces any number of outputs for an input.
+// Outputs share nodes with the input, which must not be modified while
+// they are in use.
+package eval
+
+import (
+	"fmt"
+
+	"github.com/pohedev/gj.git/ast"
+)
+
+// Query is a compiled expression.
+type Query struct {
+	source string
+	root   expr
+}
+
+// Compile parses expr into a Query.
+func Compile(expr string) (*Query, error) {
+	tokens, err := tokenize(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := &exprParser{expr: expr, tokens: tokens}
+	root, err := p.parsePipe()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokenEOF {
+		return nil, p.unexpected("end of expression")
+	}
+	return &Query{source: expr, root: root}, nil
+}
+
+// MustCompile is like Compile but panics if expr cannot be compiled.
+func MustCompile(expr string) *Query {
+	q, err := Compile(expr)
+	if err != nil {
+		panic(err)
+	}
+	return q
+}
+
+// String returns the source of the expression.
+func (q *Query) String() string {
+	return q.source
+}
+
+// Run evaluates the query with input as ".", returning its outputs.
+// A nil input is null.
+func (q *Query) Run(input *ast.Value) ([]*ast.Value, error) {
+	in, err := resolve(input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate %q: %w", q.source, err)
+	}
+	out, err := q.root.eval(in)
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate %q: %w", q.source, err)
+	}
+	return out, nil
+}
+
+// Eval compiles expr and runs it on the value of root.
+func Eval(root *ast.RootNode, expr string) ([]*ast.Value, error) {
+	q, err := Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	var input *ast.Value
+	if root != nil {
+		input = root.Value
+	}
+	return q.Run(input)
+}
+
+// expr is a node of a compiled expression. eval returns the outputs
+// for input, with the outputs produced before an error.
+type expr interface {
+	eval(input *ast.Value) ([]*ast.Value, error)
+}
+
+type (
+	identityExpr struct{}
+	recurseExpr  struct{}
+	literalExpr  struct{ value *ast.Value }
+	pipeExpr     struct{ left, right expr }
+	commaExpr    struct{ left, right expr }
+	tryExpr      struct{ body expr }
+	negateExpr   struct{ operand expr }
+	arrayExpr    struct{ body expr } // body is nil for [].
+	iterateExpr  struct{ target expr }
+
+	alternativeExpr struct{ left, right expr }
+
+	logicExpr struct {
+		or          bool
+		left, right expr
+	}
+	binaryExpr struct {
+		op          string
+		left, right expr
+	}
+	indexExpr struct {
+		target, key expr
+	}
+	sliceExpr struct {
+		target, from, to expr // from and to may be nil.
+	}
+	objectExpr struct {
+		entries []objectEntry
+	}
+	objectEntry struct {
+		key, value expr
+	}
+	callExpr struct {
+		name string
+		fn   builtin
+		args []expr
+	}
+)
+
+func (identityExpr) eval(in *ast.Value) ([]*ast.Value, error) {
+	return []*ast.Value{in}, nil
+}
+
+func (recurseExpr) eval(in *ast.Value) ([]*ast.Value, error) {
+	var out []*ast.Value
+	var visit func(v *ast.Value) error
+	visit = func(v *ast.Value) error {
+		out = append(out, v)
+		children, err := children(v)
+		if err != nil {
+			return err
+		}
+		for _, child := range children {
+			if err := visit(child); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	err := visit(in)
+	return out, err
+}
+
+func (e *literalExpr) eval(*ast.Value) ([]*ast.Value, error) {
+	return []*ast.Value{e.value}, nil
+}
+
+func (e *pipeExpr) eval(in *ast.Value) ([]*ast.Value, error) {
+	left, err := e.left.eval(in)
+	var out []*ast.Value
+	for _, v := range left {
+		right, err := e.right.eval(v)
+		out = append(out, right...)
+		if err != nil {
+			return out, err
+		}
+	}
+	return out, err
+}
+
+func (e *commaExpr) eval(in *ast.Value) ([]*ast.Value, error) {
+	left, err := e.left.eval(in)
+	if err != nil {
+		return left, err
+	}
+	right, err := e.right.eval(in)
+	return append(left, right...), err
+}
+
+func (e *tryExpr) eval(in *ast.Value) ([]*ast.Value, error) {
+	out, _ := e.body.eval(in)
+	return out, nil
+}
+
+func (e *negateExpr) eval(in *ast.Value) ([]*ast.Value, error) {
+	operands, err := e.operand.eval(in)
+	if err != nil {
+		return nil, err
+	}
+	var out []*ast.Value
+	for _, v := range operands {
+		n, err := arithmetic("-", value(ast.Number(0)), v)
+		if err != nil {
+			return out, fmt.Errorf("cannot negate %s", ast.TypeName(v))
+		}
+		out = append(out, n)
+	}
+	return out, nil
+}
+
+func (e *arrayExpr) eval(in *ast.Value) ([]*ast.Value, error) {
+	array := &ast.Array{}
+	if e.body != nil {
+		items, err := e.body.eval(in)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			array.Children = append(array.Children, ast.ArrayItem{Value: item})
+		}
+	}
+	return []*ast.Value{value(array)}, nil
+}
+
+func (e *iterateExpr) eval(in *ast.Value) ([]*ast.Value, error) {
+	targets, err := e.target.eval(in)
+	var out []*ast.Value
+	for _, t := range targets {
+		switch t.Value.(type) {
+		case *ast.Object, *ast.Array:
+		default:
+			return out, fmt.Errorf("cannot iterate over %s", ast.TypeName(t))
+		}
+		children, err := children(t)
+		out = append(out, children...)
+		if err != nil {
+			return out, err
+		}
+	}
+	return out, err
+}
+
+func (e *alternativeExpr) eval(in *ast.Value) ([]*ast.Value, error) {
+	left, _ := e.left.eval(in)
+	var out []*ast.Value
+	for _, v := range left {
+		if truthy(v) {
+			out = append(out, v)
+		}
+	}
+	if len(out) > 0 {
+		return out, nil
+	}
+	return e.right.eval(in)
+}
+
+func (e *logicExpr) eval(in *ast.Value) ([]*ast.Value, error) {
+	left, err := e.left.eval(in)
+	if err != nil {
+		return nil, err
+	}
+	var out []*ast.Value
+	for _, l := range left {
+		if truthy(l) == e.or {
+			out = append(out, value(ast.Bool(e.or)))
+			continue
+		}
+		right, err := e.right.eval(in)
+		if err != nil {
+			return out, err
+		}
+		for _, r := range right {
+			out = append(out, value(ast.Bool(truthy(r))))
+		}
+	}
+	return out, nil
+}
+
+// eval evaluates the operands against the input, the left one varying
+// fastest like jq does.
+func (e *binaryExpr) eval(in *ast.Value) ([]*ast.Value, error) {
+	right, err := e.right.eval(in)
+	if err != nil {
+		return nil, err
+	}
+	left, err := e.left.eval(in)
+	if err != nil {
+		return nil, err
+	}
+	var out []*ast.Value
+	for _, r := range right {
+		for _, l := range left {
+			v, err := binary(e.op, l, r)
+			if err != nil {
+				return out, err
+			}
+			out = append(out, v)
+		}
+	}
+	return out, nil
+}
+
+func (e *indexExpr) eval(in *ast.Value) ([]*ast.Value, error) {
+	targets, err := e.target.eval(in)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := e.key.eval(in)
+	if err != nil {
+		return nil, err
+	}
+	var out []*ast.Value
+	for _, t := range targets {
+		for _, k := range keys {
+			v, err := index(t, k)
+			if err != nil {
+				return out, err
+			}
+			out = append(out, v)
+		}
+	}
+	return out, nil
+}
+
+func (e *sliceExpr) eval(in *ast.Value) ([]*ast.Value, error) {
+	targets, err := e.target.eval(in)
+	if err != nil {
+		return nil, err
+	}
+	bounds := func(e expr) ([]*ast.Value, error) {
+		if e == nil {
+			return []*ast.Value{value(ast.Null())}, nil
+		}
+		return e.eval(in)
+	}
+	froms, err := bounds(e.from)
+	if err != nil {
+		return nil, err
+	}
+	tos, err := bounds(e.to)
+	if err != nil {
+		return nil, err
+	}
+	var out []*ast.Value
+	for _, t := range targets {
+		for _, from := range froms {
+			for _, to := range tos {
+				v, err := slice(t, from, to)
+				if err != nil {
+					return out, err
+				}
+				out = append(out, v)
+			}
+		}
+	}
+	return out, nil
+}
+
+// eval returns one object for each combination of the outputs of the
+// keys and values.
+func (e *objectExpr) eval(in *ast.Value) ([]*ast.Value, error) {
+	objects := []*ast.Object{{}}
+	for _, entry := range e.entries {
+		keys, err := entry.key.eval(in)
+		if err != nil {
+			return nil, err
+		}
+		values, err := entry.value.eval(in)
+		if err != nil {
+			return nil, err
+		}
+		var next []*ast.Object
+		for _, obj := range objects {
+			for _, k := range keys {
+				key, ok := asString(k)
+				if !ok {
+					return nil, fmt.Errorf("object keys must be strings, not %s", ast.TypeName(k))
+				}
+				for _, v := range values {
+					o := cloneObject(obj)
+					o.Set(key, v)
+					next = append(next, o)
+				}
+			}
+		}
+		objects = next
+	}
+	out := make([]*ast.Value, len(objects))
+	for i, obj := range objects {
+		out[i] = value(obj)
+	}
+	return out, nil
+}
+
+func (e *callExpr) eval(in *ast.Value) ([]*ast.Value, error) {
+	return e.fn(in, e.args)
+}
@@ -0,0 +1,137 @@
+package eval_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pohedev/gj.git/ast"
+	"github.com/pohedev/gj.git/eval"
+	"github.com/pohedev/gj.git/gjtest"
+	"github.com/pohedev/gj.git/parser"
+	"github.com/pohedev/gj.git/printer"
+	"github.com/stretchr/testify/assert"
+)
+
+// run evaluates expr on input and returns the outputs printed compactly,
+// separated by spaces.
+func run(t *testing.T, expr string, root *ast.RootNode) (string, error) {
+	t.Helper()
+	out, err := eval.Eval(root, expr)
+	if err != nil {
+		return "", err
+	}
+	var printed []string
+	for _, v := range out {
+		s, err := printer.Sprint(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		printed = append(printed, s)
+	}
+	return strings.Join(printed, " "), nil
+}
+
+func TestEval(t *testing.T) {
+	const doc = `{
+		"users": [
+			{"name": "ann", "age": 31, "tags": ["a", "b"]},
+			{"name": "bob", "age": 25, "tags": []},
+			{"name": "cy", "age": 40, "tags": ["b"], "admin": true}
+		],
+		"a.b": 1,
+		"n": null
+	}`
+	var tests = []struct {
+		expr string
+		want string
+	}{
+		{".", `{"users":[{"name":"ann","age":31,"tags":["a","b"]},{"name":"bob","age":25,"tags":[]},{"name":"cy","age":40,"tags":["b"],"admin":true}],"a.b":1,"n":null}`},
+		{".users[0].name", `"ann"`},
+		{`."a.b"`, `1`},
+		{`.["a.b"]`, `1`},
+		{".users[-1].name", `"cy"`},
+		{".users[5]", `null`},
+		{".missing.deep", `null`},
+		{".users[].name", `"ann" "bob" "cy"`},
+		{".users | .[1:] | map(.name)", `["bob","cy"]`},
+		{".users[0].name[1:]", `"nn"`},
+		{".users[] | select(.age > 30) | .name", `"ann" "cy"`},
+		{"[.users[] | select(.admin) | .name]", `["cy"]`},
+		{"([.[]?] | length), (.n | .[]?)", `3`},
+		{".users | map(.age * 2 + 1)", `[63,51,81]`},
+		{".users | map(.age) | add / length", `32`},
+		{"[.users[].tags[]] | unique", `["a","b"]`},
+		{".users | sort_by(.age) | map(.name) | join(\", \")", `"bob, ann, cy"`},
+		{".users | map(.age) | min, max", `25 40`},
+		{"{name: .users[0].name, count: (.users | length)}", `{"name":"ann","count":3}`},
+		{"{(.users[].name): 1}", `{"ann":1} {"bob":1} {"cy":1}`},
+		{".users[0] | {name}", `{"name":"ann"}`},
+		{".n // \"default\"", `"default"`},
+		{".users[0].age // 0", `31`},
+		{"(1, 2) + (10, 20)", `11 12 21 22`},
+		{"1 / 2, 7 % 3, -(.users[0].age), 1.5 * 2", `0.5 1 -31 3`},
+		{"\"x\" + \"y\", [1] + [2], {\"a\": 1} + {\"b\": 2}, null + 1", `"xy" [1,2] {"a":1,"b":2} 1`},
+		{"[1, 2, 3, 2] - [2]", `[1,3]`},
+		{"1 == 1.0, [1, 2] < [1, 3], \"a\" < 1, {} == {}", `true true false true`},
+		{"true and (1, null), false or false", `true false false`},
+		{".users[0] | has(\"age\"), has(\"nope\")", `true false`},
+		{".users[0] | keys", `["age","name","tags"]`},
+		{"[.[] | type]", `["array","number","null"]`},
+		{`[.. | select(type == "number")] | length`, `4`},
+		{"[..] | length", `20`},
+		{".users | first.name, last.name, first(.[].age)", `"ann" "cy" 31`},
+		{"[1, [2]] | tostring, (\"12\" | tonumber)", `"[1,[2]]" 12`},
+		{"empty, 1 | not", `false`},
+		{"[3, 1, 2] | sort, reverse", `[1,2,3] [2,1,3]`},
+		{"\"ab\" | length, reverse", `2 "ba"`},
+		{"9223372036854775807 + 1, 9223372036854775806 + 1", `9.223372036854776e+18 9223372036854775807`},
+		{".[\"users\"][1:2][0].tags", `[]`},
+		{"# comment\n.n", `null`},
+	}
+	root := gjtest.Parse(t, doc)
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := run(t, tt.expr, root)
+			if assert.Nil(t, err) {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestEval_Lazy(t *testing.T) {
+	root := gjtest.Parse(t, `{"a": {"b": [1, 2]}, "c": [{"d": 3}]}`, parser.LazyBelow(1))
+	got, err := run(t, ".a.b[1], .c[].d, (.a | length)", root)
+	assert.Nil(t, err)
+	assert.Equal(t, `2 3 1`, got)
+}
+
+func TestEval_Error(t *testing.T) {
+	var tests = []struct {
+		expr string
+		want string
+	}{
+		{".a[", "unexpected end of expression"},
+		{"foo", "unknown function foo/0"},
+		{"1 +", "unexpected end of expression, expected value"},
+		{".a )", `unexpected ")", expected end of expression`},
+		{`"abc`, "unterminated string"},
+		{"{1: 2}", "expected object key"},
+		{"@", "unexpected character"},
+		{".users.name", `cannot index array with "name"`},
+		{".n[]", "cannot iterate over null"},
+		{"{} - 1", `object and number cannot be combined with "-"`},
+		{"1 / 0", "divisor is zero"},
+		{".users | join(1)", "join: separator must be a string"},
+		{"{(.users[0].age): 1}", "object keys must be strings, not number"},
+	}
+	root := gjtest.Parse(t, `{"users": [{"age": 3}], "n": null}`)
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := run(t, tt.expr, root)
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tt.want)
+			}
+		})
+	}
+}
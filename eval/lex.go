@@ -0,0 +1,151 @@
+package eval
+
+import (
+	"fmt"
+	"strings"
+)
+
+// tokenKind identifies the kind of a token of an expression.
+type tokenKind int
+
+const (
+	tokenEOF    tokenKind = iota
+	tokenIdent            // Name of a function or keyword, e.g. "select".
+	tokenField            // ".name" field access; text holds the name.
+	tokenNumber           // Number literal.
+	tokenString           // String literal, quotes and escapes included.
+	tokenPunct            // Operator or punctuation, e.g. "|" or "==".
+)
+
+// exprToken is a token of an expression.
+type exprToken struct {
+	kind tokenKind
+	text string
+	pos  int // Byte offset of the token in the expression.
+}
+
+func (t exprToken) String() string {
+	switch t.kind {
+	case tokenEOF:
+		return "end of expression"
+	case tokenField:
+		return fmt.Sprintf("%q", "."+t.text)
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+// puncts lists the operators and punctuation, longest first.
+var puncts = []string{
+	"..", "//", "==", "!=", "<=", ">=",
+	".", "|", ",", "(", ")", "[", "]", "{", "}", ":", ";", "?",
+	"+", "-", "*", "/", "%", "<", ">",
+}
+
+// tokenize splits expr into tokens, ending with a tokenEOF token.
+func tokenize(expr string) ([]exprToken, error) {
+	var tokens []exprToken
+	for i := 0; i < len(expr); {
+		c := expr[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+			continue
+
+		case c == '#':
+			for i < len(expr) && expr[i] != '\n' {
+				i++
+			}
+			continue
+
+		case c == '.' && i+1 < len(expr) && isIdentStart(expr[i+1]):
+			end := identEnd(expr, i+1)
+			tokens = append(tokens, exprToken{tokenField, expr[i+1 : end], i})
+			i = end
+			continue
+
+		case isIdentStart(c):
+			end := identEnd(expr, i)
+			tokens = append(tokens, exprToken{tokenIdent, expr[i:end], i})
+			i = end
+			continue
+
+		case c >= '0' && c <= '9':
+			end := numberEnd(expr, i)
+			tokens = append(tokens, exprToken{tokenNumber, expr[i:end], i})
+			i = end
+			continue
+
+		case c == '"':
+			end := i + 1
+			for end < len(expr) && expr[end] != '"' {
+				if expr[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(expr) {
+				return nil, syntaxError(expr, i, "unterminated string")
+			}
+			tokens = append(tokens, exprToken{tokenString, expr[i : end+1], i})
+			i = end + 1
+			continue
+		}
+
+		found := false
+		for _, p := range puncts {
+			if strings.HasPrefix(expr[i:], p) {
+				tokens = append(tokens, exprToken{tokenPunct, p, i})
+				i += len(p)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, syntaxError(expr, i, fmt.Sprintf("unexpected character %q", c))
+		}
+	}
+	return append(tokens, exprToken{kind: tokenEOF, pos: len(expr)}), nil
+}
+
+// isIdentStart reports whether c starts an identifier.
+func isIdentStart(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// identEnd returns the offset just after the identifier starting at i.
+func identEnd(s string, i int) int {
+	for i < len(s) && (isIdentStart(s[i]) || s[i] >= '0' && s[i] <= '9') {
+		i++
+	}
+	return i
+}
+
+// numberEnd returns the offset just after the number starting at i.
+func numberEnd(s string, i int) int {
+	digits := func() {
+		for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+			i++
+		}
+	}
+	digits()
+	if i+1 < len(s) && s[i] == '.' && s[i+1] >= '0' && s[i+1] <= '9' {
+		i++
+		digits()
+	}
+	if i < len(s) && (s[i] == 'e' || s[i] == 'E') {
+		j := i + 1
+		if j < len(s) && (s[j] == '+' || s[j] == '-') {
+			j++
+		}
+		if j < len(s) && s[j] >= '0' && s[j] <= '9' {
+			i = j
+			digits()
+		}
+	}
+	return i
+}
+
+// syntaxError returns the error for expr at offset pos.
+func syntaxError(expr string, pos int, msg string) error {
+	return fmt.Errorf("failed to compile %q: %s at offset %d", expr, msg, pos)
+}
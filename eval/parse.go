@@ -0,0 +1,436 @@
+package eval
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/pohedev/gj.git/ast"
+	"github.com/pohedev/gj.git/internal/jsonstr"
+)
+
+// exprParser parses the tokens of an expression by recursive descent,
+// from the lowest precedence to the highest: "|", ",", "//", "or", "and",
+// comparisons, "+" and "-", "*", "/" and "%", unary "-", then postfix
+// field access, indexing and "?".
+type exprParser struct {
+	expr   string
+	tokens []exprToken
+	pos    int
+}
+
+// peek returns the current token.
+func (p *exprParser) peek() exprToken {
+	return p.tokens[p.pos]
+}
+
+// next returns the current token and moves to the next one.
+func (p *exprParser) next() exprToken {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+// is reports whether the current token is punctuation or identifier text.
+func (p *exprParser) is(text string) bool {
+	t := p.peek()
+	return (t.kind == tokenPunct || t.kind == tokenIdent) && t.text == text
+}
+
+// accept consumes the current token when it is text.
+func (p *exprParser) accept(text string) bool {
+	if p.is(text) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// expect consumes the current token, which must be text.
+func (p *exprParser) expect(text string) error {
+	if !p.accept(text) {
+		return p.unexpected(fmt.Sprintf("%q", text))
+	}
+	return nil
+}
+
+// unexpected returns the error for the current token when expected was.
+func (p *exprParser) unexpected(expected string) error {
+	t := p.peek()
+	return syntaxError(p.expr, t.pos, fmt.Sprintf("unexpected %v, expected %s", t, expected))
+}
+
+// parsePipe parses "a | b".
+func (p *exprParser) parsePipe() (expr, error) {
+	left, err := p.parseComma()
+	if err != nil {
+		return nil, err
+	}
+	if !p.accept("|") {
+		return left, nil
+	}
+	right, err := p.parsePipe()
+	if err != nil {
+		return nil, err
+	}
+	return &pipeExpr{left, right}, nil
+}
+
+// parseComma parses "a, b".
+func (p *exprParser) parseComma() (expr, error) {
+	left, err := p.parseAlternative()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept(",") {
+		right, err := p.parseAlternative()
+		if err != nil {
+			return nil, err
+		}
+		left = &commaExpr{left, right}
+	}
+	return left, nil
+}
+
+// parseAlternative parses "a // b".
+func (p *exprParser) parseAlternative() (expr, error) {
+	left, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if !p.accept("//") {
+		return left, nil
+	}
+	right, err := p.parseAlternative()
+	if err != nil {
+		return nil, err
+	}
+	return &alternativeExpr{left, right}, nil
+}
+
+// parseOr parses "a or b".
+func (p *exprParser) parseOr() (expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("or") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicExpr{or: true, left: left, right: right}
+	}
+	return left, nil
+}
+
+// parseAnd parses "a and b".
+func (p *exprParser) parseAnd() (expr, error) {
+	left, err := p.parseComparison()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("and") {
+		right, err := p.parseComparison()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicExpr{left: left, right: right}
+	}
+	return left, nil
+}
+
+// parseComparison parses "a == b" and the other comparisons,
+// which do not chain.
+func (p *exprParser) parseComparison() (expr, error) {
+	left, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if p.accept(op) {
+			right, err := p.parseAdditive()
+			if err != nil {
+				return nil, err
+			}
+			return &binaryExpr{op, left, right}, nil
+		}
+	}
+	return left, nil
+}
+
+// parseAdditive parses "a + b" and "a - b".
+func (p *exprParser) parseAdditive() (expr, error) {
+	left, err := p.parseMultiplicative()
+	if err != nil {
+		return nil, err
+	}
+	for p.is("+") || p.is("-") {
+		op := p.next().text
+		right, err := p.parseMultiplicative()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryExpr{op, left, right}
+	}
+	return left, nil
+}
+
+// parseMultiplicative parses "a * b", "a / b" and "a % b".
+func (p *exprParser) parseMultiplicative() (expr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.is("*") || p.is("/") || p.is("%") {
+		op := p.next().text
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryExpr{op, left, right}
+	}
+	return left, nil
+}
+
+// parseUnary parses "-a".
+func (p *exprParser) parseUnary() (expr, error) {
+	if p.accept("-") {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &negateExpr{operand}, nil
+	}
+	return p.parsePostfix()
+}
+
+// parsePostfix parses a primary expression followed by field accesses,
+// indexes, slices, iterations and "?".
+func (p *exprParser) parsePostfix() (expr, error) {
+	e, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		switch {
+		case t.kind == tokenField:
+			p.next()
+			e = &indexExpr{target: e, key: &literalExpr{value(ast.String(t.text))}}
+		case p.is(".") && p.tokens[p.pos+1].kind == tokenString:
+			p.next()
+			key, err := p.parseString()
+			if err != nil {
+				return nil, err
+			}
+			e = &indexExpr{target: e, key: key}
+		case p.is(".") && p.tokens[p.pos+1].kind == tokenPunct && p.tokens[p.pos+1].text == "[":
+			p.next()
+		case p.is("["):
+			if e, err = p.parseBracket(e); err != nil {
+				return nil, err
+			}
+		case p.accept("?"):
+			e = &tryExpr{e}
+		default:
+			return e, nil
+		}
+	}
+}
+
+// parseBracket parses "[]", "[i]" or "[from:to]" applied to target.
+func (p *exprParser) parseBracket(target expr) (expr, error) {
+	p.next()
+	if p.accept("]") {
+		return &iterateExpr{target}, nil
+	}
+	var from, to expr
+	var err error
+	if !p.is(":") {
+		if from, err = p.parsePipe(); err != nil {
+			return nil, err
+		}
+		if p.accept("]") {
+			return &indexExpr{target: target, key: from}, nil
+		}
+	}
+	if err := p.expect(":"); err != nil {
+		return nil, err
+	}
+	if !p.is("]") {
+		if to, err = p.parsePipe(); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.expect("]"); err != nil {
+		return nil, err
+	}
+	return &sliceExpr{target: target, from: from, to: to}, nil
+}
+
+// parsePrimary parses literals, ".", "..", field accesses, parentheses,
+// array and object construction, and function calls.
+func (p *exprParser) parsePrimary() (expr, error) {
+	t := p.peek()
+	switch t.kind {
+	case tokenField:
+		p.next()
+		return &indexExpr{target: identityExpr{}, key: &literalExpr{value(ast.String(t.text))}}, nil
+
+	case tokenNumber:
+		p.next()
+		if i, err := strconv.ParseInt(t.text, 10, 64); err == nil {
+			return &literalExpr{value(ast.Number(i))}, nil
+		}
+		f, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, syntaxError(p.expr, t.pos, "invalid number "+t.text)
+		}
+		return &literalExpr{value(ast.Number(f))}, nil
+
+	case tokenString:
+		return p.parseString()
+
+	case tokenIdent:
+		return p.parseCall()
+	}
+
+	switch {
+	case p.accept("."):
+		if p.peek().kind == tokenString {
+			key, err := p.parseString()
+			if err != nil {
+				return nil, err
+			}
+			return &indexExpr{target: identityExpr{}, key: key}, nil
+		}
+		return identityExpr{}, nil
+
+	case p.accept(".."):
+		return recurseExpr{}, nil
+
+	case p.accept("("):
+		e, err := p.parsePipe()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return e, nil
+
+	case p.accept("["):
+		if p.accept("]") {
+			return &arrayExpr{}, nil
+		}
+		e, err := p.parsePipe()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+		return &arrayExpr{e}, nil
+
+	case p.accept("{"):
+		return p.parseObject()
+	}
+	return nil, p.unexpected("value")
+}
+
+// parseString parses a string literal.
+func (p *exprParser) parseString() (expr, error) {
+	t := p.next()
+	s, offset, err := jsonstr.Unquote(t.text)
+	if err != nil {
+		return nil, syntaxError(p.expr, t.pos+offset, err.Error())
+	}
+	return &literalExpr{value(ast.String(s))}, nil
+}
+
+// parseCall parses true, false, null and function calls with arguments
+// separated by ";".
+func (p *exprParser) parseCall() (expr, error) {
+	t := p.next()
+	switch t.text {
+	case "true", "false":
+		return &literalExpr{value(ast.Bool(t.text == "true"))}, nil
+	case "null":
+		return &literalExpr{value(ast.Null())}, nil
+	}
+
+	var args []expr
+	if p.accept("(") {
+		for {
+			arg, err := p.parsePipe()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if !p.accept(";") {
+				break
+			}
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+	}
+	fn, ok := builtins[builtinKey{t.text, len(args)}]
+	if !ok {
+		return nil, syntaxError(p.expr, t.pos, fmt.Sprintf("unknown function %s/%d", t.text, len(args)))
+	}
+	return &callExpr{name: t.text, fn: fn, args: args}, nil
+}
+
+// parseObject parses the entries of an object construction after "{".
+// Keys are identifiers, strings or parenthesized expressions; "{a}" is
+// short for "{a: .a}". Values may not contain "," or "|" unless
+// parenthesized.
+func (p *exprParser) parseObject() (expr, error) {
+	obj := &objectExpr{}
+	if p.accept("}") {
+		return obj, nil
+	}
+	for {
+		var key expr
+		t := p.peek()
+		switch {
+		case t.kind == tokenIdent:
+			p.next()
+			key = &literalExpr{value(ast.String(t.text))}
+		case t.kind == tokenString:
+			var err error
+			if key, err = p.parseString(); err != nil {
+				return nil, err
+			}
+		case p.accept("("):
+			var err error
+			if key, err = p.parsePipe(); err != nil {
+				return nil, err
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, p.unexpected("object key")
+		}
+
+		var val expr = &indexExpr{target: identityExpr{}, key: key}
+		if p.accept(":") {
+			var err error
+			if val, err = p.parseAlternative(); err != nil {
+				return nil, err
+			}
+		}
+		obj.entries = append(obj.entries, objectEntry{key, val})
+
+		if p.accept("}") {
+			return obj, nil
+		}
+		if err := p.expect(","); err != nil {
+			return nil, err
+		}
+	}
+}
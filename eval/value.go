@@ -0,0 +1,414 @@
+package eval
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pohedev/gj.git/ast"
+	"github.com/pohedev/gj.git/parser"
+)
+
+// value wraps node in a Value.
+func value(node any) *ast.Value {
+	return &ast.Value{Value: node}
+}
+
+// resolve returns node as a Value holding an *ast.Object, *ast.Array or
+// *ast.Literal, parsing RawValue nodes kept by lazy parsing. nil is null.
+func resolve(node any) (*ast.Value, error) {
+	base := node
+	for {
+		v, ok := base.(*ast.Value)
+		if !ok || v == nil {
+			break
+		}
+		base = v.Value
+	}
+	switch n := base.(type) {
+	case *ast.Object, *ast.Array, *ast.Literal:
+		if v, ok := node.(*ast.Value); ok && v.Value == base {
+			return v, nil
+		}
+		return value(n), nil
+	case *ast.RawValue:
+		v, err := parser.ParseRaw(n)
+		if err != nil {
+			return nil, err
+		}
+		return resolve(v)
+	case nil, *ast.Value:
+		return value(ast.Null()), nil
+	}
+	return nil, fmt.Errorf("unsupported node %T", base)
+}
+
+// children returns the property values of an object or the items of an
+// array, and nothing for other values.
+func children(v *ast.Value) ([]*ast.Value, error) {
+	var out []*ast.Value
+	switch n := v.Value.(type) {
+	case *ast.Object:
+		for _, prop := range n.Children {
+			child, err := resolve(prop.Value)
+			if err != nil {
+				return out, err
+			}
+			out = append(out, child)
+		}
+	case *ast.Array:
+		for _, item := range n.Children {
+			child, err := resolve(item.Value)
+			if err != nil {
+				return out, err
+			}
+			out = append(out, child)
+		}
+	}
+	return out, nil
+}
+
+// truthy reports whether v is neither false nor null.
+func truthy(v *ast.Value) bool {
+	lit, ok := v.Value.(*ast.Literal)
+	return !ok || lit.LiteralType != ast.LiteralTypeFalse && lit.LiteralType != ast.LiteralTypeNull
+}
+
+// asString returns the value of a string.
+func asString(v *ast.Value) (string, bool) {
+	if lit, ok := v.Value.(*ast.Literal); ok {
+		return lit.AsString()
+	}
+	return "", false
+}
+
+// asNumber returns the value of a number as int64 when it is an integer,
+// and as float64.
+func asNumber(v *ast.Value) (i int64, f float64, isInt, ok bool) {
+	lit, isLit := v.Value.(*ast.Literal)
+	if !isLit {
+		return 0, 0, false, false
+	}
+	switch n := lit.Val.(type) {
+	case int64:
+		return n, float64(n), true, true
+	case float64:
+		return 0, n, false, true
+	}
+	return 0, 0, false, false
+}
+
+// rank orders the jq types: null < false < true < numbers < strings
+// < arrays < objects.
+func rank(v *ast.Value) int {
+	switch n := v.Value.(type) {
+	case *ast.Object:
+		return 6
+	case *ast.Array:
+		return 5
+	case *ast.Literal:
+		switch n.LiteralType {
+		case ast.LiteralTypeString:
+			return 4
+		case ast.LiteralTypeNumber:
+			return 3
+		case ast.LiteralTypeTrue:
+			return 2
+		case ast.LiteralTypeFalse:
+			return 1
+		}
+	}
+	return 0
+}
+
+// compare orders a and b like jq: by type, then by value. Arrays compare
+// item by item; objects compare their sorted keys, then their values.
+func compare(a, b *ast.Value) int {
+	if c := cmp.Compare(rank(a), rank(b)); c != 0 {
+		return c
+	}
+	switch x := a.Value.(type) {
+	case *ast.Object:
+		y := b.Value.(*ast.Object)
+		xKeys, yKeys := sortedKeys(x), sortedKeys(y)
+		if c := slices.Compare(xKeys, yKeys); c != 0 {
+			return c
+		}
+		for _, key := range xKeys {
+			xv, _ := x.Get(key)
+			yv, _ := y.Get(key)
+			if c := compareNodes(xv, yv); c != 0 {
+				return c
+			}
+		}
+		return 0
+	case *ast.Array:
+		y := b.Value.(*ast.Array)
+		for i := range min(len(x.Children), len(y.Children)) {
+			if c := compareNodes(x.Children[i].Value, y.Children[i].Value); c != 0 {
+				return c
+			}
+		}
+		return cmp.Compare(len(x.Children), len(y.Children))
+	}
+	if s, ok := asString(a); ok {
+		t, _ := asString(b)
+		return strings.Compare(s, t)
+	}
+	if i, f, isInt, ok := asNumber(a); ok {
+		j, g, isIntB, _ := asNumber(b)
+		if isInt && isIntB {
+			return cmp.Compare(i, j)
+		}
+		return cmp.Compare(f, g)
+	}
+	return 0
+}
+
+// compareNodes compares child nodes, which may be unresolved. Nodes that
+// cannot be resolved compare as null.
+func compareNodes(a, b any) int {
+	x, err := resolve(a)
+	if err != nil {
+		x = value(ast.Null())
+	}
+	y, err := resolve(b)
+	if err != nil {
+		y = value(ast.Null())
+	}
+	return compare(x, y)
+}
+
+// sortedKeys returns the distinct keys of obj in sorted order.
+func sortedKeys(obj *ast.Object) []string {
+	keys := make([]string, 0, len(obj.Children))
+	for key := range obj.Index() {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// cloneObject returns a shallow copy of obj.
+func cloneObject(obj *ast.Object) *ast.Object {
+	return &ast.Object{Children: slices.Clone(obj.Children)}
+}
+
+// binary applies operator op to l and r.
+func binary(op string, l, r *ast.Value) (*ast.Value, error) {
+	switch op {
+	case "==":
+		return value(ast.Bool(compare(l, r) == 0)), nil
+	case "!=":
+		return value(ast.Bool(compare(l, r) != 0)), nil
+	case "<":
+		return value(ast.Bool(compare(l, r) < 0)), nil
+	case "<=":
+		return value(ast.Bool(compare(l, r) <= 0)), nil
+	case ">":
+		return value(ast.Bool(compare(l, r) > 0)), nil
+	case ">=":
+		return value(ast.Bool(compare(l, r) >= 0)), nil
+	}
+	return arithmetic(op, l, r)
+}
+
+// arithmetic applies arithmetic operator op to l and r.
+func arithmetic(op string, l, r *ast.Value) (*ast.Value, error) {
+	if li, lf, lInt, ok := asNumber(l); ok {
+		if ri, rf, rInt, ok := asNumber(r); ok {
+			return numeric(op, li, lf, lInt && rInt, ri, rf)
+		}
+	}
+
+	if op == "+" {
+		switch {
+		case rank(l) == 0:
+			return r, nil
+		case rank(r) == 0:
+			return l, nil
+		}
+		if s, ok := asString(l); ok {
+			if t, ok := asString(r); ok {
+				return value(ast.String(s + t)), nil
+			}
+		}
+		switch x := l.Value.(type) {
+		case *ast.Array:
+			if y, ok := r.Value.(*ast.Array); ok {
+				return value(&ast.Array{Children: slices.Concat(x.Children, y.Children)}), nil
+			}
+		case *ast.Object:
+			if y, ok := r.Value.(*ast.Object); ok {
+				obj := cloneObject(x)
+				for _, prop := range y.Children {
+					v, err := resolve(prop.Value)
+					if err != nil {
+						return nil, err
+					}
+					obj.Set(prop.Identifier.Value, v)
+				}
+				return value(obj), nil
+			}
+		}
+	}
+
+	if op == "-" {
+		x, xOK := l.Value.(*ast.Array)
+		y, yOK := r.Value.(*ast.Array)
+		if xOK && yOK {
+			array := &ast.Array{}
+			for _, item := range x.Children {
+				if !slices.ContainsFunc(y.Children, func(other ast.ArrayItem) bool {
+					return compareNodes(item.Value, other.Value) == 0
+				}) {
+					array.Children = append(array.Children, item)
+				}
+			}
+			return value(array), nil
+		}
+	}
+	return nil, fmt.Errorf("%s and %s cannot be combined with %q", ast.TypeName(l), ast.TypeName(r), op)
+}
+
+// numeric applies arithmetic operator op to numbers, keeping integers
+// while the result is exact.
+func numeric(op string, li int64, lf float64, ints bool, ri int64, rf float64) (*ast.Value, error) {
+	switch op {
+	case "+":
+		if sum := li + ri; ints && (sum > li) == (ri > 0) {
+			return value(ast.Number(sum)), nil
+		}
+		return floatValue(lf + rf)
+	case "-":
+		if diff := li - ri; ints && (diff < li) == (ri > 0) {
+			return value(ast.Number(diff)), nil
+		}
+		return floatValue(lf - rf)
+	case "*":
+		if product := li * ri; ints && (li == 0 || product/li == ri && !(li == -1 && ri == math.MinInt64)) {
+			return value(ast.Number(product)), nil
+		}
+		return floatValue(lf * rf)
+	case "/":
+		if rf == 0 {
+			return nil, fmt.Errorf("%v and %v cannot be divided because the divisor is zero", lf, rf)
+		}
+		if ints && li%ri == 0 && !(li == math.MinInt64 && ri == -1) {
+			return value(ast.Number(li / ri)), nil
+		}
+		return floatValue(lf / rf)
+	case "%":
+		if !ints {
+			li, ri = int64(lf), int64(rf)
+		}
+		if ri == 0 {
+			return nil, fmt.Errorf("%v and %v cannot be divided because the divisor is zero", lf, rf)
+		}
+		if ri == -1 {
+			return value(ast.Number(0)), nil
+		}
+		return value(ast.Number(li % ri)), nil
+	}
+	return nil, fmt.Errorf("number and number cannot be combined with %q", op)
+}
+
+// floatValue returns a number holding f, which must be finite.
+func floatValue(f float64) (*ast.Value, error) {
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return nil, fmt.Errorf("number %v is out of range", f)
+	}
+	return value(ast.Number(f)), nil
+}
+
+// index returns the value of key in an object or the item at index key in
+// an array; null when there is none or when v is null.
+func index(v, key *ast.Value) (*ast.Value, error) {
+	switch n := v.Value.(type) {
+	case *ast.Object:
+		if s, ok := asString(key); ok {
+			child, ok := n.Get(s)
+			if !ok {
+				return value(ast.Null()), nil
+			}
+			return resolve(child)
+		}
+	case *ast.Array:
+		if _, f, _, ok := asNumber(key); ok {
+			i := int(math.Floor(f))
+			if i < 0 {
+				i += len(n.Children)
+			}
+			if i < 0 || i >= len(n.Children) {
+				return value(ast.Null()), nil
+			}
+			return resolve(n.Children[i].Value)
+		}
+	default:
+		if rank(v) == 0 {
+			switch rank(key) {
+			case 3, 4:
+				return v, nil
+			}
+		}
+	}
+	if s, ok := asString(key); ok {
+		return nil, fmt.Errorf("cannot index %s with %s", ast.TypeName(v), strconv.Quote(s))
+	}
+	return nil, fmt.Errorf("cannot index %s with %s", ast.TypeName(v), ast.TypeName(key))
+}
+
+// slice returns the items of an array or the characters of a string
+// between from and to, which may be null and negative.
+func slice(v, from, to *ast.Value) (*ast.Value, error) {
+	var n int
+	s, isString := asString(v)
+	switch x := v.Value.(type) {
+	case *ast.Array:
+		n = len(x.Children)
+	default:
+		switch {
+		case isString:
+			n = utf8.RuneCountInString(s)
+		case rank(v) == 0:
+			return v, nil
+		default:
+			return nil, fmt.Errorf("cannot slice %s", ast.TypeName(v))
+		}
+	}
+
+	bound := func(b *ast.Value, def int) (int, error) {
+		if rank(b) == 0 {
+			return def, nil
+		}
+		_, f, _, ok := asNumber(b)
+		if !ok {
+			return 0, fmt.Errorf("slice indices must be numbers, not %s", ast.TypeName(b))
+		}
+		i := int(math.Floor(f))
+		if i < 0 {
+			i += n
+		}
+		return min(max(i, 0), n), nil
+	}
+	start, err := bound(from, 0)
+	if err != nil {
+		return nil, err
+	}
+	end, err := bound(to, n)
+	if err != nil {
+		return nil, err
+	}
+	end = max(start, end)
+
+	if isString {
+		runes := []rune(s)
+		return value(ast.String(string(runes[start:end]))), nil
+	}
+	array := v.Value.(*ast.Array)
+	return value(&ast.Array{Children: slices.Clone(array.Children[start:end])}), nil
+}
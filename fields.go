@@ -0,0 +1,93 @@
+package gj
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// field describes a struct field decoded from an object property.
+type field struct {
+	name  string // Property key.
+	index []int  // Index sequence for reflect.Value.FieldByIndex.
+}
+
+// fieldCache maps struct types to their []field.
+var fieldCache sync.Map
+
+// cachedFields returns the decodable fields of struct type t.
+func cachedFields(t reflect.Type) []field {
+	if f, ok := fieldCache.Load(t); ok {
+		return f.([]field)
+	}
+	f, _ := fieldCache.LoadOrStore(t, typeFields(t, nil))
+	return f.([]field)
+}
+
+// typeFields returns the fields of struct type t, named by their json tag
+// like encoding/json. Fields of embedded structs without a tag are
+// promoted; fields of the outer struct win over promoted ones.
+func typeFields(t reflect.Type, index []int) []field {
+	var fields, promoted []field
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		fieldIndex := append(index[:len(index):len(index)], i)
+
+		if sf.Anonymous && name == "" && sf.Type.Kind() == reflect.Struct {
+			promoted = append(promoted, typeFields(sf.Type, fieldIndex)...)
+			continue
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		fields = append(fields, field{name: name, index: fieldIndex})
+	}
+
+	names := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		names[f.name] = true
+	}
+	for _, pf := range promoted {
+		if !names[pf.name] {
+			fields = append(fields, pf)
+		}
+	}
+	return fields
+}
+
+// findField returns the field named key, matching case-insensitively
+// when there is no exact match, or nil.
+func findField(fields []field, key string) *field {
+	for i := range fields {
+		if fields[i].name == key {
+			return &fields[i]
+		}
+	}
+	for i := range fields {
+		if strings.EqualFold(fields[i].name, key) {
+			return &fields[i]
+		}
+	}
+	return nil
+}
+
+// findProtoField returns the field whose name matches key ignoring case
+// and underscores, so that the lowerCamelCase keys of the protobuf JSON
+// mapping match snake_case names, or nil.
+func findProtoField(fields []field, key string) *field {
+	key = strings.ReplaceAll(key, "_", "")
+	for i := range fields {
+		if strings.EqualFold(strings.ReplaceAll(fields[i].name, "_", ""), key) {
+			return &fields[i]
+		}
+	}
+	return nil
+}
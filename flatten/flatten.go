@@ -0,0 +1,167 @@
+package flatten
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pohedev/gj.git/ast"
+)
+
+// ArrayStyle identifies how array indices are written in flattened keys.
+type ArrayStyle int
+
+const (
+	ArrayStyleBracket   ArrayStyle = iota + 1 // a.b[0].c
+	ArrayStyleSeparator                       // a.b.0.c
+)
+
+const defaultSeparator = "."
+
+// config holds the settings of Flatten.
+type config struct {
+	separator  string
+	arrayStyle ArrayStyle
+}
+
+// Option configures Flatten.
+type Option func(*config)
+
+// WithSeparator sets the separator written between object keys.
+// The default is ".".
+func WithSeparator(sep string) Option {
+	return func(c *config) {
+		c.separator = sep
+	}
+}
+
+// WithArrayStyle sets how array indices are written.
+// The default is ArrayStyleBracket.
+func WithArrayStyle(s ArrayStyle) Option {
+	return func(c *config) {
+		c.arrayStyle = s
+	}
+}
+
+func newConfig(opts []Option) config {
+	c := config{
+		separator:  defaultSeparator,
+		arrayStyle: ArrayStyleBracket,
+	}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c
+}
+
+// Flatten converts the document into a map of flattened keys to leaf values,
+// e.g. {"a": {"b": [{"c": 1}]}} becomes {"a.b[0].c": 1}.
+// Leaf values are string, int64, float64, bool or nil for null.
+// Empty objects and arrays are kept as map[string]any{} and []any{}, and
+// backslashes, separators and brackets in object keys are escaped with a
+// backslash, as are keys reading as indices with ArrayStyleSeparator, so
+// that no information is lost: {"a.b": 1} becomes {`a\.b`: 1}.
+// RawValue nodes are parsed first, see ast.RootNode.UnmarshalJSON; those
+// holding invalid JSON, which lazy parsing does not detect, become nil.
+func Flatten(root *ast.RootNode, opts ...Option) map[string]any {
+	out := map[string]any{}
+	if root == nil || root.Value == nil {
+		return out
+	}
+	c := newConfig(opts)
+	c.flatten(out, "", root.Value)
+	return out
+}
+
+// flatten writes node and its descendants into out under key prefix.
+func (c config) flatten(out map[string]any, prefix string, node any) {
+	switch n := node.(type) {
+	case *ast.Value:
+		c.flatten(out, prefix, n.Value)
+
+	case *ast.Object:
+		if len(n.Children) == 0 {
+			out[prefix] = map[string]any{}
+			return
+		}
+		for _, prop := range n.Children {
+			key := c.escape(prop.Identifier.Value)
+			if prefix != "" {
+				key = prefix + c.separator + key
+			}
+			c.flatten(out, key, prop.Value)
+		}
+
+	case *ast.Array:
+		if len(n.Children) == 0 {
+			out[prefix] = []any{}
+			return
+		}
+		for i, item := range n.Children {
+			c.flatten(out, c.indexKey(prefix, i), item.Value)
+		}
+
+	case *ast.Literal:
+		out[prefix] = n.ToGo()
+
+	case *ast.RawValue:
+		var v ast.RootNode
+		if err := v.UnmarshalJSON([]byte(n.Raw)); err != nil {
+			out[prefix] = nil
+			return
+		}
+		c.flatten(out, prefix, v.Value)
+	}
+}
+
+// indexKey appends array index i to prefix according to the array style.
+func (c config) indexKey(prefix string, i int) string {
+	index := strconv.Itoa(i)
+	if c.arrayStyle == ArrayStyleSeparator {
+		if prefix == "" {
+			return index
+		}
+		return prefix + c.separator + index
+	}
+	return prefix + "[" + index + "]"
+}
+
+// escape escapes the characters of object key k that Unflatten would
+// read as syntax.
+func (c config) escape(k string) string {
+	if c.arrayStyle == ArrayStyleSeparator && isIndex(k) {
+		return `\` + k
+	}
+	special := `\`
+	if c.arrayStyle == ArrayStyleBracket {
+		special += "[]"
+	}
+	if !strings.ContainsAny(k, special) && (c.separator == "" || !strings.Contains(k, c.separator)) {
+		return k
+	}
+	var b strings.Builder
+	for i := 0; i < len(k); {
+		switch {
+		case strings.IndexByte(special, k[i]) >= 0:
+			b.WriteByte('\\')
+			b.WriteByte(k[i])
+			i++
+		case c.separator != "" && strings.HasPrefix(k[i:], c.separator):
+			for j := 0; j < len(c.separator); j++ {
+				b.WriteByte('\\')
+				b.WriteByte(c.separator[j])
+			}
+			i += len(c.separator)
+		default:
+			b.WriteByte(k[i])
+			i++
+		}
+	}
+	return b.String()
+}
+
+// isIndex reports whether s reads as an array index with
+// ArrayStyleSeparator.
+func isIndex(s string) bool {
+	i, err := strconv.Atoi(s)
+	return err == nil && i >= 0
+}
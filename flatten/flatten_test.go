@@ -0,0 +1,186 @@
+package flatten
+
+import (
+	"testing"
+
+	"github.com/pohedev/gj.git/ast"
+	"github.com/pohedev/gj.git/lexer"
+	"github.com/pohedev/gj.git/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+type flattenTest struct {
+	name  string
+	input string
+	opts  []Option
+	want  map[string]any
+}
+
+func TestFlatten(t *testing.T) {
+	var tests = []flattenTest{
+		{
+			"nested object",
+			`{"a": {"b": [{"c": 1}, "x"], "d": null}, "e": true}`,
+			nil,
+			map[string]any{
+				"a.b[0].c": int64(1),
+				"a.b[1]":   "x",
+				"a.d":      nil,
+				"e":        true,
+			},
+		},
+		{
+			"custom separator and array style",
+			`{"a": {"b": [{"c": 1.5}]}}`,
+			[]Option{WithSeparator("/"), WithArrayStyle(ArrayStyleSeparator)},
+			map[string]any{
+				"a/b/0/c": 1.5,
+			},
+		},
+		{
+			"root array",
+			`[1, [2]]`,
+			nil,
+			map[string]any{
+				"[0]":    int64(1),
+				"[1][0]": int64(2),
+			},
+		},
+		{
+			"escaped keys",
+			`{"a": {"b": 1}, "a.b": 2, "c[0]": 3, "c": [4], "d\\": {"e]": 5}}`,
+			nil,
+			map[string]any{
+				"a.b":     int64(1),
+				`a\.b`:    int64(2),
+				`c\[0\]`:  int64(3),
+				"c[0]":    int64(4),
+				`d\\.e\]`: int64(5),
+			},
+		},
+		{
+			"escaped keys with custom separator and array style",
+			`{"a": {"0": 1, "1": [2]}, "a::b": 3}`,
+			[]Option{WithSeparator("::"), WithArrayStyle(ArrayStyleSeparator)},
+			map[string]any{
+				`a::\0`:    int64(1),
+				`a::\1::0`: int64(2),
+				`a\:\:b`:   int64(3),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, err := parser.New(lexer.Lex(tt.input)).Parse()
+			assert.Nil(t, err)
+			got := Flatten(root, tt.opts...)
+			assert.Equal(t, tt.want, got)
+
+			back, err := Unflatten(got, tt.opts...)
+			if assert.Nil(t, err) {
+				assert.Equal(t, got, Flatten(back, tt.opts...))
+			}
+		})
+	}
+}
+
+func TestFlatten_Lazy(t *testing.T) {
+	root, err := parser.New(lexer.Lex(`{"a": {"b": [1, {"c": "x"}]}, "d": [], "e": 1}`), parser.LazyBelow(0)).Parse()
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]any{
+		"a.b[0]":   int64(1),
+		"a.b[1].c": "x",
+		"d":        []any{},
+		"e":        int64(1),
+	}, Flatten(root))
+}
+
+func TestFlatten_EmptyContainers(t *testing.T) {
+	root := &ast.RootNode{
+		RootNodeType: ast.RootNodeTypeObject,
+		Value: &ast.Value{Value: &ast.Object{
+			Children: []ast.Property{
+				{Identifier: ast.Identifier{Value: "a"}, Value: &ast.Value{Value: &ast.Object{}}},
+				{Identifier: ast.Identifier{Value: "b"}, Value: &ast.Value{Value: &ast.Array{}}},
+			},
+		}},
+	}
+
+	want := map[string]any{
+		"a": map[string]any{},
+		"b": []any{},
+	}
+	assert.Equal(t, want, Flatten(root))
+}
+
+type unflattenTest struct {
+	name  string
+	input map[string]any
+	opts  []Option
+	want  string
+}
+
+func TestUnflatten(t *testing.T) {
+	var tests = []unflattenTest{
+		{
+			"nested object",
+			map[string]any{
+				"a.b[0].c": int64(1),
+				"a.b[1]":   "x",
+				"a.d":      nil,
+				"e":        true,
+			},
+			nil,
+			`{"a": {"b": [{"c": 1}, "x"], "d": null}, "e": true}`,
+		},
+		{
+			"custom separator and array style",
+			map[string]any{"a/b/0/c": 1.5},
+			[]Option{WithSeparator("/"), WithArrayStyle(ArrayStyleSeparator)},
+			`{"a": {"b": [{"c": 1.5}]}}`,
+		},
+		{
+			"root array",
+			map[string]any{"[0]": int64(1), "[1][0]": int64(2)},
+			nil,
+			`[1, [2]]`,
+		},
+		{
+			"missing indices",
+			map[string]any{"a[0]": int64(1), "a[2]": int64(3)},
+			nil,
+			`{"a": [1, null, 3]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, err := Unflatten(tt.input, tt.opts...)
+			assert.Nil(t, err)
+
+			want, err := parser.New(lexer.Lex(tt.want)).Parse()
+			assert.Nil(t, err)
+			assert.Equal(t, Flatten(want, tt.opts...), Flatten(root, tt.opts...))
+			assert.Equal(t, want.RootNodeType, root.RootNodeType)
+		})
+	}
+}
+
+func TestUnflatten_Error(t *testing.T) {
+	var tests = []struct {
+		name  string
+		input map[string]any
+	}{
+		{"leaf and object conflict", map[string]any{"a": int64(1), "a.b": int64(2)}},
+		{"index on object", map[string]any{"a.b": int64(1), "a[0]": int64(2)}},
+		{"invalid index", map[string]any{"a[x]": int64(1)}},
+		{"unsupported value", map[string]any{"a": struct{}{}}},
+		{"sparse index", map[string]any{"a[50000000]": int64(1)}},
+		{"sparse index after items", map[string]any{"a[0]": int64(1), "a[3]": int64(2)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Unflatten(tt.input)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,301 @@
+package flatten
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pohedev/gj.git/ast"
+)
+
+// segment represents a part of a flattened key,
+// either an object key or an array index.
+type segment struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+// tree is an intermediate node used to build the AST from flattened keys.
+type tree struct {
+	keys     []string // object keys in order of first appearance.
+	props    map[string]*tree
+	items    map[int]*tree
+	size     int    // array length, the largest index plus one.
+	sizeKey  string // flattened key holding the largest index.
+	isArray  bool
+	isObject bool
+	leaf     any
+	isLeaf   bool
+}
+
+// Unflatten is the inverse of Flatten: it builds nested objects and arrays
+// from a map of flattened keys to values. Numeric segments become array
+// indices; missing indices are filled with null, but an index larger than
+// the number of items given for its array is rejected, so that a single
+// key cannot allocate a huge array. A backslash escapes the character
+// after it, as written by Flatten. Keys are processed in sorted order,
+// so object properties are sorted as well.
+func Unflatten(m map[string]any, opts ...Option) (*ast.RootNode, error) {
+	c := newConfig(opts)
+
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var root tree
+	for _, k := range keys {
+		segs, err := c.split(k)
+		if err != nil {
+			return nil, err
+		}
+		if err := root.insert(k, segs, m[k]); err != nil {
+			return nil, err
+		}
+	}
+
+	val, err := root.build()
+	if err != nil {
+		return nil, err
+	}
+
+	node := ast.RootNode{Value: val}
+	switch val.Value.(type) {
+	case *ast.Object:
+		node.RootNodeType = ast.RootNodeTypeObject
+	case *ast.Array:
+		node.RootNodeType = ast.RootNodeTypeArray
+	default:
+		node.RootNodeType = ast.RootNodeTypeLiteral
+	}
+	return &node, nil
+}
+
+// split splits flattened key k into segments according to the config.
+func (c config) split(k string) ([]segment, error) {
+	if k == "" {
+		return nil, nil
+	}
+
+	var segs []segment
+	for _, part := range c.parts(k) {
+		if c.arrayStyle == ArrayStyleSeparator {
+			if isIndex(part) {
+				i, _ := strconv.Atoi(part)
+				segs = append(segs, segment{index: i, isIndex: true})
+			} else {
+				segs = append(segs, segment{key: unescape(part)})
+			}
+			continue
+		}
+
+		name, rest := part, ""
+		if i := indexUnescaped(part, '['); i >= 0 {
+			name, rest = part[:i], part[i:]
+		}
+		if name != "" || rest == "" {
+			segs = append(segs, segment{key: unescape(name)})
+		}
+		for rest != "" {
+			end := strings.IndexByte(rest, ']')
+			if rest[0] != '[' || end < 0 {
+				return nil, fmt.Errorf("failed to unflatten key %q: invalid array syntax %q", k, rest)
+			}
+			i, err := strconv.Atoi(rest[1:end])
+			if err != nil || i < 0 {
+				return nil, fmt.Errorf("failed to unflatten key %q: invalid array index %q", k, rest[1:end])
+			}
+			segs = append(segs, segment{index: i, isIndex: true})
+			rest = rest[end+1:]
+		}
+	}
+	return segs, nil
+}
+
+// parts splits flattened key k at the separators not escaped.
+func (c config) parts(k string) []string {
+	var parts []string
+	start := 0
+	for i := 0; i < len(k); {
+		switch {
+		case k[i] == '\\':
+			i += 2
+		case c.separator != "" && strings.HasPrefix(k[i:], c.separator):
+			parts = append(parts, k[start:i])
+			i += len(c.separator)
+			start = i
+		default:
+			i++
+		}
+	}
+	return append(parts, k[start:])
+}
+
+// indexUnescaped returns the index of the first b in s not escaped,
+// or -1.
+func indexUnescaped(s string, b byte) int {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case b:
+			return i
+		}
+	}
+	return -1
+}
+
+// unescape removes the backslashes escaping characters of s.
+func unescape(s string) string {
+	if strings.IndexByte(s, '\\') < 0 {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// insert stores val at segs below t.
+func (t *tree) insert(k string, segs []segment, val any) error {
+	if len(segs) == 0 {
+		if t.isLeaf || t.isObject || t.isArray {
+			return fmt.Errorf("failed to unflatten key %q: conflicts with another key", k)
+		}
+		t.leaf, t.isLeaf = val, true
+		return nil
+	}
+	if t.isLeaf {
+		return fmt.Errorf("failed to unflatten key %q: conflicts with another key", k)
+	}
+
+	seg := segs[0]
+	var child *tree
+	if seg.isIndex {
+		if t.isObject {
+			return fmt.Errorf("failed to unflatten key %q: array index used on object", k)
+		}
+		if t.items == nil {
+			t.items = map[int]*tree{}
+			t.isArray = true
+		}
+		if child = t.items[seg.index]; child == nil {
+			child = &tree{}
+			t.items[seg.index] = child
+		}
+		if seg.index >= t.size {
+			t.size, t.sizeKey = seg.index+1, k
+		}
+	} else {
+		if t.isArray {
+			return fmt.Errorf("failed to unflatten key %q: object key used on array", k)
+		}
+		if t.props == nil {
+			t.props = map[string]*tree{}
+			t.isObject = true
+		}
+		if child = t.props[seg.key]; child == nil {
+			child = &tree{}
+			t.props[seg.key] = child
+			t.keys = append(t.keys, seg.key)
+		}
+	}
+	return child.insert(k, segs[1:], val)
+}
+
+// build converts t into an AST value.
+func (t *tree) build() (*ast.Value, error) {
+	switch {
+	case t.isObject:
+		obj := ast.Object{}
+		for _, key := range t.keys {
+			val, err := t.props[key].build()
+			if err != nil {
+				return nil, err
+			}
+			obj.Children = append(obj.Children, ast.Property{
+				Identifier: ast.Identifier{Value: key},
+				Value:      val,
+			})
+		}
+		return &ast.Value{Value: &obj}, nil
+
+	case t.isArray:
+		if t.size-1 > len(t.items) {
+			return nil, fmt.Errorf("failed to unflatten key %q: array index %d exceeds the %d items given", t.sizeKey, t.size-1, len(t.items))
+		}
+		array := ast.Array{Children: make([]ast.ArrayItem, t.size)}
+		for i := range array.Children {
+			child, ok := t.items[i]
+			if !ok {
+				child = &tree{isLeaf: true}
+			}
+			val, err := child.build()
+			if err != nil {
+				return nil, err
+			}
+			array.Children[i].Value = val.Value
+		}
+		return &ast.Value{Value: &array}, nil
+
+	case t.isLeaf:
+		return fromGo(t.leaf)
+	}
+	return &ast.Value{Value: &ast.Object{}}, nil
+}
+
+// fromGo converts a Go value into an AST value.
+func fromGo(v any) (*ast.Value, error) {
+	switch val := v.(type) {
+	case nil:
+		return &ast.Value{Value: ast.Null()}, nil
+	case string:
+		return &ast.Value{Value: ast.String(val)}, nil
+	case bool:
+		return &ast.Value{Value: ast.Bool(val)}, nil
+	case int:
+		return &ast.Value{Value: ast.Number(val)}, nil
+	case int64:
+		return &ast.Value{Value: ast.Number(val)}, nil
+	case float64:
+		return &ast.Value{Value: ast.Number(val)}, nil
+
+	case map[string]any:
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		obj := ast.Object{}
+		for _, k := range keys {
+			child, err := fromGo(val[k])
+			if err != nil {
+				return nil, err
+			}
+			obj.Children = append(obj.Children, ast.Property{
+				Identifier: ast.Identifier{Value: k},
+				Value:      child,
+			})
+		}
+		return &ast.Value{Value: &obj}, nil
+
+	case []any:
+		array := ast.Array{}
+		for _, item := range val {
+			child, err := fromGo(item)
+			if err != nil {
+				return nil, err
+			}
+			array.Children = append(array.Children, ast.ArrayItem{Value: child.Value})
+		}
+		return &ast.Value{Value: &array}, nil
+	}
+	return nil, fmt.Errorf("failed to unflatten: unsupported value type %T", v)
+}
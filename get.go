@@ -0,0 +1,117 @@
+package gj
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/pohedev/gj.git/ast"
+	"github.com/pohedev/gj.git/parser"
+	"github.com/pohedev/gj.git/path"
+)
+
+// GetAs resolves path in root and converts the value found there to T.
+// path is a JSON Pointer or a dot/bracket path without wildcards, as
+// accepted by path.Parse, e.g. "/server/port" or "$.server.port".
+// Numbers convert to integer types only when integral and in range.
+// A failed lookup is reported as *PathError, a failed conversion
+// as *TypeError.
+func GetAs[T any](root *ast.RootNode, path string) (T, error) {
+	var out T
+	node, ptr, err := lookup(root, path)
+	if err != nil {
+		return out, err
+	}
+	d := decoder{}
+	err = d.decode(node, reflect.ValueOf(&out).Elem(), ptr)
+	return out, err
+}
+
+// Get returns the value at path in input, parsing only what is needed to
+// reach it: objects and arrays off the way are skipped without building
+// nodes, and input after the value is not read. path is as accepted by
+// GetAs. It suits reading a few fields of large documents; to read many,
+// parse the document once. When a key appears more than once, the first
+// value is returned. A missing value is reported as *PathError.
+func Get(input string, path string) (*ast.Value, error) {
+	ptr, err := pointer(path)
+	if err != nil {
+		return nil, err
+	}
+	v, err := parser.ParseUntil(input, ptr)
+	if errors.Is(err, parser.ErrNotFound) {
+		return nil, &PathError{Path: path, Msg: "no value"}
+	}
+	return v, err
+}
+
+// pointer returns path s as a JSON Pointer.
+func pointer(s string) (string, error) {
+	p, err := concretePath(s)
+	if err != nil {
+		return "", err
+	}
+	return p.Pointer(), nil
+}
+
+// concretePath parses path s, rejecting wildcards.
+func concretePath(s string) (path.Path, error) {
+	p, err := path.Parse(s)
+	if err != nil {
+		return nil, err
+	}
+	for _, seg := range p {
+		if seg.Wildcard {
+			return nil, &PathError{Path: s, Msg: "wildcards are not supported"}
+		}
+	}
+	return p, nil
+}
+
+// lookup returns the node located at s in root and its JSON Pointer.
+func lookup(root *ast.RootNode, s string) (any, string, error) {
+	p, err := path.Parse(s)
+	if err != nil {
+		return nil, "", err
+	}
+	if root == nil || root.Value == nil {
+		return nil, "", &PathError{Path: s, Msg: "empty document"}
+	}
+
+	var node any = root.Value
+	ptr := ""
+	for _, seg := range p {
+		if seg.Wildcard {
+			return nil, "", &PathError{Path: s, Msg: "wildcards are not supported"}
+		}
+		node, err = resolve(node)
+		if err != nil {
+			return nil, "", err
+		}
+		switch n := node.(type) {
+		case *ast.Object:
+			v, ok := n.Get(seg.Key)
+			if !ok {
+				return nil, "", &PathError{Path: s, Msg: fmt.Sprintf("no key %q in object at %q", seg.Key, ptr)}
+			}
+			node = v
+		case *ast.Array:
+			i, err := strconv.Atoi(seg.Key)
+			v, ok := n.At(i)
+			if err != nil || !ok {
+				return nil, "", &PathError{Path: s, Msg: fmt.Sprintf("no index %s in array at %q", seg.Key, ptr)}
+			}
+			node = v
+		default:
+			return nil, "", &PathError{Path: s, Msg: fmt.Sprintf("cannot index %s at %q", describe(node), ptr)}
+		}
+		ptr = appendPointer(ptr, seg.Key)
+	}
+	return node, ptr, nil
+}
+
+// appendPointer appends token to JSON Pointer ptr.
+func appendPointer(ptr, token string) string {
+	return ptr + "/" + path.Escape(token)
+}
@@ -0,0 +1,170 @@
+package gj_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/pohedev/gj.git"
+	"github.com/pohedev/gj.git/gjtest"
+	"github.com/pohedev/gj.git/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+const config = `{"server": {"host": "localhost", "port": 8080, "ratio": 0.5, "tls": true},
+	"users": [{"name": "ann"}, {"name": "bob"}], "limit": 300, "none": null}`
+
+func TestGetAs(t *testing.T) {
+	root := gjtest.Parse(t, config)
+
+	host, err := gj.GetAs[string](root, "/server/host")
+	assert.Nil(t, err)
+	assert.Equal(t, "localhost", host)
+
+	port, err := gj.GetAs[uint16](root, "$.server.port")
+	assert.Nil(t, err)
+	assert.Equal(t, uint16(8080), port)
+
+	ratio, err := gj.GetAs[float32](root, "server.ratio")
+	assert.Nil(t, err)
+	assert.Equal(t, float32(0.5), ratio)
+
+	tls, err := gj.GetAs[bool](root, "server.tls")
+	assert.Nil(t, err)
+	assert.True(t, tls)
+
+	name, err := gj.GetAs[string](root, "users[1].name")
+	assert.Nil(t, err)
+	assert.Equal(t, "bob", name)
+
+	none, err := gj.GetAs[*int](root, "none")
+	assert.Nil(t, err)
+	assert.Nil(t, none)
+
+	limit, err := gj.GetAs[*int](root, "limit")
+	if assert.Nil(t, err) {
+		assert.Equal(t, 300, *limit)
+	}
+
+	server, err := gj.GetAs[any](root, "server")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]any{"host": "localhost", "port": int64(8080), "ratio": 0.5, "tls": true}, server)
+
+	blob, err := gj.GetAs[[]byte](gjtest.Parse(t, `{"blob": "Z2pfAP8"}`), "blob")
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("gj_\x00\xff"), blob)
+}
+
+func TestGetAs_Lazy(t *testing.T) {
+	root := gjtest.Parse(t, config, parser.LazyBelow(0))
+
+	name, err := gj.GetAs[string](root, "/users/0/name")
+	assert.Nil(t, err)
+	assert.Equal(t, "ann", name)
+}
+
+func TestGetAs_PathError(t *testing.T) {
+	root := gjtest.Parse(t, config)
+	var tests = []struct {
+		path string
+		want string
+	}{
+		{"/server/missing", `failed to resolve path "/server/missing": no key "missing" in object at "/server"`},
+		{"users[2]", `failed to resolve path "users[2]": no index 2 in array at "/users"`},
+		{"limit.x", `failed to resolve path "limit.x": cannot index number 300 at "/limit"`},
+		{"users[*]", `failed to resolve path "users[*]": wildcards are not supported`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			_, err := gj.GetAs[int](root, tt.path)
+			var pathErr *gj.PathError
+			if assert.ErrorAs(t, err, &pathErr) {
+				assert.Equal(t, tt.want, err.Error())
+			}
+		})
+	}
+}
+
+func TestGet(t *testing.T) {
+	var tests = []struct {
+		path string
+		want any
+	}{
+		{"/server/host", "localhost"},
+		{"$.server.port", int64(8080)},
+		{"users[1]", map[string]any{"name": "bob"}},
+		{"none", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			v, err := gj.Get(config, tt.path)
+			if assert.Nil(t, err) {
+				assert.Equal(t, tt.want, v.ToGo())
+			}
+		})
+	}
+
+	v, err := gj.Get(`{"type": "ping", "payload": [1, 2, `, "type")
+	if assert.Nil(t, err, "input after the value is not read") {
+		assert.Equal(t, "ping", v.ToGo())
+	}
+
+	var pathErr *gj.PathError
+	_, err = gj.Get(config, "server.missing")
+	if assert.ErrorAs(t, err, &pathErr) {
+		assert.Equal(t, `failed to resolve path "server.missing": no value`, err.Error())
+	}
+	_, err = gj.Get(config, "users[*]")
+	assert.ErrorAs(t, err, &pathErr)
+	_, err = gj.Get(`{"a": }`, "a")
+	assert.ErrorIs(t, err, parser.ErrUnexpectedToken)
+}
+
+func TestGetAs_TypeError(t *testing.T) {
+	root := gjtest.Parse(t, config)
+	var tests = []struct {
+		name string
+		get  func() error
+		want string
+	}{
+		{
+			name: "overflow",
+			get:  func() error { _, err := gj.GetAs[int8](root, "limit"); return err },
+			want: `failed to convert number 300 at "/limit" to int8: overflows int8`,
+		},
+		{
+			name: "fraction",
+			get:  func() error { _, err := gj.GetAs[int](root, "server.ratio"); return err },
+			want: `failed to convert number 0.5 at "/server/ratio" to int: not an integer`,
+		},
+		{
+			name: "kind",
+			get:  func() error { _, err := gj.GetAs[int](root, "server.host"); return err },
+			want: `failed to convert string at "/server/host" to int`,
+		},
+		{
+			name: "container",
+			get:  func() error { _, err := gj.GetAs[string](root, "users"); return err },
+			want: `failed to convert array at "/users" to string`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.get()
+			var typeErr *gj.TypeError
+			if assert.ErrorAs(t, err, &typeErr) {
+				assert.Equal(t, tt.want, err.Error())
+			}
+		})
+	}
+
+	blob := gjtest.Parse(t, `{"blob": "aGVs*G8="}`)
+	_, err := gj.GetAs[[]byte](blob, "blob")
+	assert.EqualError(t, err, `failed to convert string at "/blob" to []uint8: failed to decode base64: illegal base64 data at input byte 4`)
+
+	_, err = gj.GetAs[uint](root, "limit")
+	assert.Nil(t, err)
+	var typeErr *gj.TypeError
+	if _, err := gj.GetAs[bool](root, "limit"); assert.ErrorAs(t, err, &typeErr) {
+		assert.Equal(t, reflect.TypeOf(true), typeErr.Type)
+	}
+}
@@ -0,0 +1,4 @@
+// Package gj provides high-level access to JSON documents parsed by
+// package parser: path lookups, conversion to Go values, and validation and
+// reformatting of documents without building the AST.
+package gj
@@ -0,0 +1,228 @@
+// Package gjtest provides assertions on JSON documents and ASTs for tests,
+// reporting the differences found rather than the whole values.
+package gjtest
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/pohedev/gj.git/ast"
+	"github.com/pohedev/gj.git/diff"
+	"github.com/pohedev/gj.git/lexer"
+	"github.com/pohedev/gj.git/parser"
+	"github.com/pohedev/gj.git/path"
+	"github.com/pohedev/gj.git/printer"
+)
+
+// TestingT is the subset of testing.TB used by the assertions.
+type TestingT interface {
+	Errorf(format string, args ...any)
+}
+
+// Option configures AssertASTEqual.
+type Option func(*config)
+
+// config holds the options of AssertASTEqual.
+type config struct {
+	ignorePositions bool
+}
+
+// IgnorePositions makes AssertASTEqual ignore the byte offsets of nodes,
+// so that expected trees can be written without them.
+func IgnorePositions() Option {
+	return func(c *config) {
+		c.ignorePositions = true
+	}
+}
+
+// AssertJSONEqual checks that the JSON documents want and got are equal
+// regardless of layout and of the order of keys, see diff.Equal, and
+// reports each value that differs by its JSON Pointer otherwise.
+func AssertJSONEqual(t TestingT, want, got string) bool {
+	if h, ok := t.(interface{ Helper() }); ok {
+		h.Helper()
+	}
+	wantRoot, err := parser.New(lexer.Lex(want)).Parse()
+	if err != nil {
+		t.Errorf("invalid expected JSON: %v", err)
+		return false
+	}
+	gotRoot, err := parser.New(lexer.Lex(got)).Parse()
+	if err != nil {
+		t.Errorf("invalid JSON: %v\n%s", err, got)
+		return false
+	}
+	changes := diff.Compare(wantRoot.Value, gotRoot.Value)
+	if len(changes) == 0 {
+		return true
+	}
+	var b strings.Builder
+	b.WriteString("JSON not equal:")
+	for _, c := range changes {
+		ptr := c.Path
+		if ptr == "" {
+			ptr = "(root)"
+		}
+		switch c.Op {
+		case diff.OpAdd:
+			fmt.Fprintf(&b, "\n  %s: unexpected %s", ptr, compact(c.New))
+		case diff.OpRemove:
+			fmt.Fprintf(&b, "\n  %s: missing %s", ptr, compact(c.Old))
+		default:
+			fmt.Fprintf(&b, "\n  %s: want %s, got %s", ptr, compact(c.Old), compact(c.New))
+		}
+	}
+	t.Errorf("%s", b.String())
+	return false
+}
+
+// AssertASTEqual checks that the trees want and got are equal: same nodes
+// in the same order, with the same values and, unless IgnorePositions is
+// given, the same byte offsets. Trivia are not compared. Differences are
+// reported as a diff of outlines of the trees, one line per node.
+func AssertASTEqual(t TestingT, want, got *ast.RootNode, opts ...Option) bool {
+	if h, ok := t.(interface{ Helper() }); ok {
+		h.Helper()
+	}
+	c := &config{}
+	for _, opt := range opts {
+		opt(c)
+	}
+	wantLines, gotLines := c.outline(want), c.outline(got)
+	lines := diffLines(wantLines, gotLines)
+	if lines == nil {
+		return true
+	}
+	t.Errorf("AST not equal (-want +got):\n%s", strings.Join(lines, "\n"))
+	return false
+}
+
+// Parse parses input with opts, stopping the test with t.Fatal
+// if it is not valid JSON.
+func Parse(t testing.TB, input string, opts ...parser.Option) *ast.RootNode {
+	t.Helper()
+	root, err := parser.New(lexer.Lex(input), opts...).Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return root
+}
+
+// outline returns a line per node of root: its JSON Pointer, then its
+// type and value or span, e.g. `/cars [1:7]: Array[9:21]`.
+func (c *config) outline(root *ast.RootNode) []string {
+	if root == nil {
+		return []string{"(nil)"}
+	}
+	var lines []string
+	var visit func(node any, ptr string)
+	visit = func(node any, ptr string) {
+		if v, ok := node.(*ast.Value); ok && v != nil {
+			node = v.Value
+		}
+		name := ptr
+		if name == "" {
+			name = "(root)"
+		}
+		lines = append(lines, name+": "+c.describe(node))
+		switch n := node.(type) {
+		case *ast.Object:
+			for _, prop := range n.Children {
+				key := ptr + "/" + path.Escape(prop.Identifier.Value)
+				if !c.ignorePositions {
+					key += c.span(prop.Identifier.Start, prop.Identifier.End)
+				}
+				visit(prop.Value, key)
+			}
+		case *ast.Array:
+			for i, item := range n.Children {
+				visit(item.Value, ptr+"/"+strconv.Itoa(i))
+			}
+		}
+	}
+	visit(root.Value, "")
+	return lines
+}
+
+// describe returns the type of node followed by its span or value.
+func (c *config) describe(node any) string {
+	switch n := node.(type) {
+	case *ast.Object:
+		return "Object" + c.span(n.Start, n.End)
+	case *ast.Array:
+		return "Array" + c.span(n.Start, n.End)
+	case *ast.RawValue:
+		return "Raw" + c.span(n.Start, n.End) + " " + n.Raw
+	case *ast.Literal:
+		switch n.LiteralType {
+		case ast.LiteralTypeString:
+			return fmt.Sprintf("String %q", n.Val)
+		case ast.LiteralTypeNumber:
+			return fmt.Sprintf("Number %T(%v)", n.Val, n.Val)
+		case ast.LiteralTypeTrue:
+			return "True"
+		case ast.LiteralTypeFalse:
+			return "False"
+		}
+		return "Null"
+	case *ast.Value, nil:
+		return "nil"
+	}
+	return fmt.Sprintf("%T", node)
+}
+
+// span returns the span of a node for outlines, or "" when ignored.
+func (c *config) span(start, end int) string {
+	if c.ignorePositions {
+		return ""
+	}
+	return fmt.Sprintf(" [%d:%d]", start, end)
+}
+
+// compact returns v as compact JSON for messages.
+func compact(v *ast.Value) string {
+	s, err := printer.Sprint(v)
+	if err != nil {
+		return fmt.Sprintf("<%v>", err)
+	}
+	return s
+}
+
+// diffLines returns the lines removed from a, prefixed with "- ", and
+// added to b, prefixed with "+ ", in order, or nil when a and b are equal.
+// It follows a longest common subsequence of the lines.
+func diffLines(a, b []string) []string {
+	// lcs[i][j] is the length of the longest common subsequence
+	// of a[i:] and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	var lines []string
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			i++
+			j++
+		case j == len(b) || i < len(a) && lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, "- "+a[i])
+			i++
+		default:
+			lines = append(lines, "+ "+b[j])
+			j++
+		}
+	}
+	return lines
+}
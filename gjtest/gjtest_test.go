@@ -0,0 +1,97 @@
+package gjtest
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/pohedev/gj.git/ast"
+	"github.com/pohedev/gj.git/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+// recorder records the errors reported by the assertions.
+type recorder struct {
+	errors []string
+}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestAssertJSONEqual(t *testing.T) {
+	var tests = []struct {
+		name      string
+		want, got string
+		err       string
+	}{
+		{"equal", `{"a": [1, {"b": null}], "c": "x"}`, "{\"c\":\"x\",\n\"a\":[1,{\"b\":null}]}", ""},
+		{
+			name: "different",
+			want: `{"a": [1, 2], "b": {"c": true}, "d": 1}`,
+			got:  `{"a": [1, 3, 4], "b": {"c": "true"}, "e": 1}`,
+			err: `JSON not equal:
+  /a/1: want 2, got 3
+  /a/2: unexpected 4
+  /b/c: want true, got "true"
+  /d: missing 1
+  /e: unexpected 1`,
+		},
+		{"root", `1`, `[]`, "JSON not equal:\n  (root): want 1, got []"},
+		{"invalid expected", `{`, `{}`, "invalid expected JSON: failed to parse: missing closing brace at offset 1"},
+		{"invalid", `{}`, `[1,]`, "invalid JSON: failed to parse: trailing comma in array at offset 2\n[1,]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &recorder{}
+			ok := AssertJSONEqual(r, tt.want, tt.got)
+			assert.Equal(t, tt.err == "", ok)
+			if tt.err == "" {
+				assert.Empty(t, r.errors)
+			} else {
+				assert.Equal(t, []string{tt.err}, r.errors)
+			}
+		})
+	}
+}
+
+func TestAssertASTEqual(t *testing.T) {
+	built := &ast.RootNode{
+		RootNodeType: ast.RootNodeTypeObject,
+		Value: &ast.Value{Value: &ast.Object{Children: []ast.Property{
+			{Identifier: ast.Identifier{Value: "a"}, Value: &ast.Value{Value: &ast.Array{Children: []ast.ArrayItem{
+				{Value: ast.Number(1)},
+				{Value: ast.String("x")},
+			}}}},
+		}}},
+	}
+
+	r := &recorder{}
+	assert.True(t, AssertASTEqual(r, built, Parse(t, `{"a": [1, "x"]}`), IgnorePositions()))
+	assert.True(t, AssertASTEqual(r, Parse(t, `{"a": [1, "x"]}`), Parse(t, `{"a": [1, "x"]}`)))
+	assert.Empty(t, r.errors)
+
+	assert.False(t, AssertASTEqual(r, built, Parse(t, `{"a": [1.0, "x", null]}`), IgnorePositions()))
+	assert.False(t, AssertASTEqual(r, Parse(t, `{"a": []}`), Parse(t, `{"a":[]}`)))
+	assert.False(t, AssertASTEqual(r, nil, Parse(t, `true`)))
+	assert.Equal(t, []string{
+		`AST not equal (-want +got):
+- /a/0: Number int64(1)
++ /a/0: Number float64(1)
++ /a/2: Null`,
+		`AST not equal (-want +got):
+- (root): Object [0:9]
+- /a [1:4]: Array [6:7]
++ (root): Object [0:8]
++ /a [1:4]: Array [5:6]`,
+		`AST not equal (-want +got):
+- (nil)
++ (root): True`,
+	}, r.errors)
+}
+
+func TestParse(t *testing.T) {
+	root := Parse(t, `{"a": {"b": [1]}}`, parser.LazyBelow(0))
+	assert.Equal(t, ast.RootNodeTypeObject, root.RootNodeType)
+	prop := root.Value.Value.(*ast.Object).Children[0]
+	assert.Equal(t, &ast.RawValue{Raw: `{"b": [1]}`, Start: 6, End: 16}, prop.Value.(*ast.Value).Value)
+}
@@ -0,0 +1,444 @@
+// Package hjson parses HJSON, a syntax for human-edited configuration
+// files extending JSON with comments, optional commas and quotes, and
+// multiline strings, see https://hjson.github.io.
+//
+// Documents are parsed into the same AST as JSON documents, so that they
+// can be diffed, validated, transformed and printed as JSON with the other
+// packages of gj. Comments are dropped.
+package hjson
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pohedev/gj.git/ast"
+	"github.com/pohedev/gj.git/internal/jsonstr"
+	"github.com/pohedev/gj.git/lexer"
+	"github.com/pohedev/gj.git/parser"
+	"github.com/pohedev/gj.git/token"
+)
+
+// Parse parses the HJSON document in input. The braces of a root object
+// may be omitted. Positions in the AST are byte offsets in input, and the
+// End of objects and arrays is the offset of their closing bracket.
+// Errors are reported as *parser.SyntaxError, matched with the parser.Err
+// variables.
+func Parse(input string) (*ast.RootNode, error) {
+	d := &decoder{input: input}
+	if _, err := d.skip(); err != nil {
+		return nil, err
+	}
+	if d.eof() {
+		return nil, d.errorf(parser.ErrUnexpectedEOF, d.pos, "unexpected EOF, expected value")
+	}
+
+	var (
+		v   *ast.Value
+		err error
+	)
+	if c := d.input[d.pos]; c != '{' && c != '[' && d.isKey() {
+		v, err = d.members(0)
+	} else {
+		v, err = d.value()
+	}
+	if err != nil {
+		return nil, err
+	}
+	if _, err := d.skip(); err != nil {
+		return nil, err
+	}
+	if !d.eof() {
+		return nil, d.errorf(parser.ErrTrailingContent, d.pos, "unexpected trailing content")
+	}
+
+	root := &ast.RootNode{RootNodeType: ast.RootNodeTypeLiteral, Value: v}
+	switch v.Value.(type) {
+	case *ast.Object:
+		root.RootNodeType = ast.RootNodeTypeObject
+	case *ast.Array:
+		root.RootNodeType = ast.RootNodeTypeArray
+	}
+	return root, nil
+}
+
+// decoder reads an HJSON document.
+type decoder struct {
+	input string
+	pos   int
+}
+
+// eof reports whether the whole input was read.
+func (d *decoder) eof() bool {
+	return d.pos >= len(d.input)
+}
+
+// errorf returns a *parser.SyntaxError of the given kind at offset.
+func (d *decoder) errorf(kind error, offset int, msg string) error {
+	return &parser.SyntaxError{Msg: msg, Offset: offset, End: offset, Err: kind}
+}
+
+// unexpected returns the error for the input at the current position
+// when expected was expected.
+func (d *decoder) unexpected(expected string) error {
+	if d.eof() {
+		return d.errorf(parser.ErrUnexpectedEOF, d.pos, "unexpected EOF, expected "+expected)
+	}
+	return d.errorf(parser.ErrUnexpectedToken, d.pos, "unexpected "+strconv.QuoteRune(rune(d.input[d.pos]))+", expected "+expected)
+}
+
+// skip skips whitespace and comments, and reports whether
+// it went past a line break.
+func (d *decoder) skip() (bool, error) {
+	newline := false
+	for !d.eof() {
+		switch rest := d.input[d.pos:]; {
+		case rest[0] == '\n':
+			newline = true
+			d.pos++
+		case rest[0] == ' ' || rest[0] == '\t' || rest[0] == '\r':
+			d.pos++
+		case rest[0] == '#' || strings.HasPrefix(rest, "//"):
+			end := strings.IndexByte(rest, '\n')
+			if end < 0 {
+				end = len(rest)
+			}
+			d.pos += end
+		case strings.HasPrefix(rest, "/*"):
+			end := strings.Index(rest[2:], "*/")
+			if end < 0 {
+				return newline, d.errorf(parser.ErrUnexpectedEOF, d.pos, "unterminated comment")
+			}
+			newline = newline || strings.Contains(rest[:end+2], "\n")
+			d.pos += end + 4
+		default:
+			return newline, nil
+		}
+	}
+	return newline, nil
+}
+
+// isKey reports whether the input at the current position is a key
+// followed by a colon, as in a root object without braces.
+func (d *decoder) isKey() bool {
+	pos := d.pos
+	defer func() { d.pos = pos }()
+	if _, err := d.key(); err != nil {
+		return false
+	}
+	if _, err := d.skip(); err != nil {
+		return false
+	}
+	return !d.eof() && d.input[d.pos] == ':'
+}
+
+// value reads the value at the current position.
+func (d *decoder) value() (*ast.Value, error) {
+	if d.eof() {
+		return nil, d.unexpected("value")
+	}
+	var (
+		node any
+		err  error
+	)
+	switch rest := d.input[d.pos:]; {
+	case rest[0] == '{':
+		node, err = d.object()
+	case rest[0] == '[':
+		node, err = d.array()
+	case strings.HasPrefix(rest, "'''"):
+		var s string
+		s, err = d.multiline()
+		node = ast.String(s)
+	case rest[0] == '"' || rest[0] == '\'':
+		var s string
+		s, err = d.quoted()
+		node = ast.String(s)
+	case strings.IndexByte(",:]}", rest[0]) >= 0:
+		return nil, d.unexpected("value")
+	default:
+		node, err = d.quoteless()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &ast.Value{Value: node}, nil
+}
+
+// object reads the object starting at the current '{'.
+func (d *decoder) object() (*ast.Object, error) {
+	start := d.pos
+	d.pos++
+	v, err := d.members('}')
+	if err != nil {
+		return nil, err
+	}
+	obj := v.Value.(*ast.Object)
+	obj.Start = start
+	return obj, nil
+}
+
+// members reads properties up to the closing brace, or up to EOF
+// for a root object without braces when closer is 0, and consumes
+// the closer.
+func (d *decoder) members(closer byte) (*ast.Value, error) {
+	obj := &ast.Object{Start: d.pos}
+	separated := true
+	for {
+		newline, err := d.skip()
+		if err != nil {
+			return nil, err
+		}
+		separated = separated || newline
+		switch {
+		case d.eof() && closer == 0:
+			obj.End = d.pos
+			return &ast.Value{Value: obj}, nil
+		case d.eof():
+			return nil, d.errorf(parser.ErrUnexpectedEOF, d.pos, "missing closing brace")
+		case d.input[d.pos] == closer:
+			obj.End = d.pos
+			d.pos++
+			return &ast.Value{Value: obj}, nil
+		case !separated:
+			return nil, d.unexpected("',', newline or '}'")
+		}
+
+		id, err := d.key()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := d.skip(); err != nil {
+			return nil, err
+		}
+		if d.eof() || d.input[d.pos] != ':' {
+			return nil, d.unexpected("':'")
+		}
+		d.pos++
+		if _, err := d.skip(); err != nil {
+			return nil, err
+		}
+		v, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		obj.Children = append(obj.Children, ast.Property{Identifier: id, Value: v})
+		separated, err = d.separator()
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// array reads the array starting at the current '['.
+func (d *decoder) array() (*ast.Array, error) {
+	array := &ast.Array{Start: d.pos}
+	d.pos++
+	separated := true
+	for {
+		newline, err := d.skip()
+		if err != nil {
+			return nil, err
+		}
+		separated = separated || newline
+		switch {
+		case d.eof():
+			return nil, d.errorf(parser.ErrUnexpectedEOF, d.pos, "missing closing bracket")
+		case d.input[d.pos] == ']':
+			array.End = d.pos
+			d.pos++
+			return array, nil
+		case !separated:
+			return nil, d.unexpected("',', newline or ']'")
+		}
+
+		v, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		array.Children = append(array.Children, ast.ArrayItem{Value: v.Value})
+		separated, err = d.separator()
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// separator skips the comma or line break following a value,
+// and reports whether there was one.
+func (d *decoder) separator() (bool, error) {
+	newline, err := d.skip()
+	if err != nil {
+		return false, err
+	}
+	if !d.eof() && d.input[d.pos] == ',' {
+		d.pos++
+		return true, nil
+	}
+	return newline, nil
+}
+
+// key reads a quoted or quoteless key.
+func (d *decoder) key() (ast.Identifier, error) {
+	start := d.pos
+	if !d.eof() && (d.input[d.pos] == '"' || d.input[d.pos] == '\'') {
+		s, err := d.quoted()
+		return ast.Identifier{Value: s, Start: start, End: d.pos}, err
+	}
+	for !d.eof() && strings.IndexByte(",:[]{} \t\r\n", d.input[d.pos]) < 0 {
+		d.pos++
+	}
+	if d.pos == start {
+		return ast.Identifier{}, d.unexpected("key")
+	}
+	return ast.Identifier{Value: d.input[start:d.pos], Start: start, End: d.pos}, nil
+}
+
+// quoted reads a string in double or single quotes.
+func (d *decoder) quoted() (string, error) {
+	start := d.pos
+	quote := d.input[d.pos]
+	d.pos++
+	for {
+		if d.eof() || d.input[d.pos] == '\n' {
+			return "", d.errorf(parser.ErrUnterminatedString, start, "unterminated quoted string")
+		}
+		c := d.input[d.pos]
+		d.pos++
+		if c == quote {
+			break
+		}
+		if c == '\\' && !d.eof() {
+			d.pos++
+		}
+	}
+
+	literal := d.input[start:d.pos]
+	if quote == '\'' {
+		literal = doubleQuoted(literal)
+	}
+	s, offset, err := jsonstr.Unquote(literal)
+	if err != nil {
+		if quote == '\'' {
+			// Offsets in the rewritten literal may not match the input.
+			offset = 0
+		}
+		return "", d.errorf(parser.ErrInvalidString, start+offset, err.Error())
+	}
+	return s, nil
+}
+
+// doubleQuoted rewrites single-quoted literal s as a JSON string literal.
+func doubleQuoted(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	inner := s[1 : len(s)-1]
+	for i := 0; i < len(inner); i++ {
+		switch c := inner[i]; {
+		case c == '\\' && i+1 < len(inner) && inner[i+1] == '\'':
+			b.WriteByte('\'')
+			i++
+		case c == '\\' && i+1 < len(inner):
+			b.WriteString(inner[i : i+2])
+			i++
+		case c == '"':
+			b.WriteString(`\"`)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// multiline reads a string between triple single quotes. Its lines are
+// stripped of the indentation of the opening quotes, and the line breaks
+// after the opening and before the closing quotes are dropped.
+func (d *decoder) multiline() (string, error) {
+	start := d.pos
+	indent := start - (strings.LastIndexByte(d.input[:start], '\n') + 1)
+	d.pos += 3
+	for !d.eof() && (d.input[d.pos] == ' ' || d.input[d.pos] == '\t' || d.input[d.pos] == '\r') {
+		d.pos++
+	}
+	dedentFirst := false
+	if !d.eof() && d.input[d.pos] == '\n' {
+		d.pos++
+		dedentFirst = true
+	}
+	end := strings.Index(d.input[d.pos:], "'''")
+	if end < 0 {
+		return "", d.errorf(parser.ErrUnterminatedString, start, "unterminated multiline string")
+	}
+	lines := strings.Split(d.input[d.pos:d.pos+end], "\n")
+	d.pos += end + 3
+
+	for i, line := range lines {
+		line = strings.TrimSuffix(line, "\r")
+		if i > 0 || dedentFirst {
+			n := 0
+			for n < indent && n < len(line) && (line[n] == ' ' || line[n] == '\t') {
+				n++
+			}
+			line = line[n:]
+		}
+		lines[i] = line
+	}
+	if last := lines[len(lines)-1]; len(lines) > 1 && strings.TrimLeft(last, " \t") == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// quoteless reads a literal, or a quoteless string running to the end
+// of the line. A number, true, false or null followed by the end of the
+// line, a comma, a closing bracket or a comment is a literal, anything
+// else is a string.
+func (d *decoder) quoteless() (*ast.Literal, error) {
+	start := d.pos
+	line := d.input[start:]
+	if i := strings.IndexByte(line, '\n'); i >= 0 {
+		line = line[:i]
+	}
+
+	n := 0
+	for n < len(line) && strings.IndexByte(",]} \t\r#", line[n]) < 0 && !strings.HasPrefix(line[n:], "//") && !strings.HasPrefix(line[n:], "/*") {
+		n++
+	}
+	rest := strings.TrimLeft(line[n:], " \t\r")
+	if rest == "" || strings.IndexByte(",]}#", rest[0]) >= 0 || strings.HasPrefix(rest, "//") || strings.HasPrefix(rest, "/*") {
+		if lit, ok, err := d.literal(line[:n]); ok || err != nil {
+			d.pos += n
+			return lit, err
+		}
+	}
+
+	s := strings.TrimRight(line, " \t\r")
+	d.pos += len(s)
+	return ast.String(s), nil
+}
+
+// literal returns the literal spelled s at the current position,
+// if s is a JSON number, true, false or null.
+func (d *decoder) literal(s string) (*ast.Literal, bool, error) {
+	switch s {
+	case "true":
+		return ast.Bool(true), true, nil
+	case "false":
+		return ast.Bool(false), true, nil
+	case "null":
+		return ast.Null(), true, nil
+	}
+	if item := lexer.Lex(s).NextItem(); item.Token != token.Number || item.Val != s {
+		return nil, false, nil
+	}
+	var lit *ast.Literal
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		lit = ast.Number(i)
+	} else if f, err := strconv.ParseFloat(s, 64); err == nil {
+		lit = ast.Number(f)
+	} else {
+		return nil, false, &parser.SyntaxError{Msg: "invalid number " + s, Offset: d.pos, End: d.pos + len(s), Err: parser.ErrInvalidNumber}
+	}
+	lit.Raw = s
+	return lit, true, nil
+}
@@ -0,0 +1,117 @@
+package hjson
+
+import (
+	"testing"
+
+	"github.com/pohedev/gj.git/ast"
+	"github.com/pohedev/gj.git/lexer"
+	"github.com/pohedev/gj.git/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	var tests = []struct {
+		name  string
+		input string
+		json  string
+	}{
+		{"json", `{"a": [1, 2.5, "x", true, false, null], "b": {}}`, `{"a": [1, 2.5, "x", true, false, null], "b": {}}`},
+		{
+			name: "comments and optional commas",
+			input: `{
+  # hash comment
+  a: 1 // line comment
+  /* block
+     comment */ b: [
+    1
+    2,
+  ]
+}`,
+			json: `{"a": 1, "b": [1, 2]}`,
+		},
+		{
+			name:  "root braces omitted",
+			input: "name: gj\nport: 8080\n",
+			json:  `{"name": "gj", "port": 8080}`,
+		},
+		{
+			name:  "quoteless strings",
+			input: "{\n  text: hello, world # not a comment \n  num: 1 apple\n  url: http://example.com\n  t: true\n}",
+			json:  `{"text": "hello, world # not a comment", "num": "1 apple", "url": "http://example.com", "t": true}`,
+		},
+		{
+			name:  "literals before separators",
+			input: `{a: 1, b: null} `,
+			json:  `{"a": 1, "b": null}`,
+		},
+		{
+			name:  "quoted strings",
+			input: `{"a b": 'it\'s "x"', c: "é\n"}`,
+			json:  `{"a b": "it's \"x\"", "c": "é\n"}`,
+		},
+		{
+			name:  "multiline string",
+			input: "{\n  text:\n    '''\n    first\n      second\n    '''\n}",
+			json:  `{"text": "first\n  second"}`,
+		},
+		{
+			name:  "literal root",
+			input: "# count\n42\n",
+			json:  `42`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.input)
+			want, wantErr := parser.New(lexer.Lex(tt.json)).Parse()
+			if assert.Nil(t, err) && assert.Nil(t, wantErr) {
+				assert.Equal(t, want.RootNodeType, got.RootNodeType)
+				assert.Equal(t, want.ToGo(), got.ToGo())
+			}
+		})
+	}
+}
+
+func TestParse_Positions(t *testing.T) {
+	got, err := Parse("a: [1]\n'b': {}")
+	if !assert.Nil(t, err) {
+		return
+	}
+	obj := got.Value.Value.(*ast.Object)
+	assert.Equal(t, ast.Identifier{Value: "a", Start: 0, End: 1}, obj.Children[0].Identifier)
+	assert.Equal(t, ast.Identifier{Value: "b", Start: 7, End: 10}, obj.Children[1].Identifier)
+	array := obj.Children[0].Value.(*ast.Value).Value.(*ast.Array)
+	assert.Equal(t, 3, array.Start)
+	assert.Equal(t, 5, array.End)
+	assert.Equal(t, "1", array.Children[0].Value.(*ast.Literal).Raw)
+}
+
+func TestParse_Error(t *testing.T) {
+	var tests = []struct {
+		name   string
+		input  string
+		offset int
+		kind   error
+	}{
+		{"empty", "# nothing\n", 10, parser.ErrUnexpectedEOF},
+		{"missing brace", "{a: 1", 5, parser.ErrUnexpectedEOF},
+		{"missing separator", `{a: "x" b: 2}`, 8, parser.ErrUnexpectedToken},
+		{"missing colon", "{a 1}", 3, parser.ErrUnexpectedToken},
+		{"quoteless runs to end of line", "[a, b]", 6, parser.ErrUnexpectedEOF},
+		{"unterminated string", "{a: \"x\n}", 4, parser.ErrUnterminatedString},
+		{"unterminated comment", "{a: 1 /* x", 6, parser.ErrUnexpectedEOF},
+		{"bad escape", `["\x"]`, 2, parser.ErrInvalidString},
+		{"trailing content", "[1] [2]", 4, parser.ErrTrailingContent},
+		{"number out of range", "[1e400]", 1, parser.ErrInvalidNumber},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.input)
+			var syntaxErr *parser.SyntaxError
+			if assert.ErrorAs(t, err, &syntaxErr) {
+				assert.Equal(t, tt.offset, syntaxErr.Offset)
+			}
+			assert.ErrorIs(t, err, tt.kind)
+		})
+	}
+}
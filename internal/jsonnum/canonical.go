@@ -0,0 +1,111 @@
+// Package jsonnum formats JSON numbers for the packages of gj.
+package jsonnum
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Canonical returns the canonical form of number literal s, holding the
+// same decimal value: no exponent marker, sign or zero that can be left
+// out, e.g. "1.0E+2" is "100", "-0" is "0" and "0.50" is "0.5".
+// Like JavaScript, numbers are written in exponent notation,
+// e.g. "1e+21" or "1.5e-7", only when very large or small.
+// Leading '+' and '.', trailing '.' and '_' separators are accepted.
+// It reports false when s is not a number.
+func Canonical(s string) (string, bool) {
+	s = strings.ReplaceAll(s, "_", "")
+	neg := false
+	switch {
+	case strings.HasPrefix(s, "-"):
+		neg = true
+		s = s[1:]
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	}
+
+	mantissa, exponent := s, 0
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		e, err := strconv.Atoi(strings.TrimPrefix(s[i+1:], "+"))
+		if err != nil {
+			return "", false
+		}
+		mantissa, exponent = s[:i], e
+	}
+	intPart, fracPart, _ := strings.Cut(mantissa, ".")
+	if intPart == "" && fracPart == "" || !isDigits(intPart) || !isDigits(fracPart) {
+		return "", false
+	}
+
+	// The value is digits × 10^exponent.
+	digits := strings.TrimLeft(intPart+fracPart, "0")
+	exponent -= len(fracPart)
+	trimmed := strings.TrimRight(digits, "0")
+	exponent += len(digits) - len(trimmed)
+	digits = trimmed
+	if digits == "" {
+		return "0", true
+	}
+
+	var b strings.Builder
+	if neg {
+		b.WriteByte('-')
+	}
+	// n is the position of the decimal point relative to digits.
+	n := len(digits) + exponent
+	switch {
+	case len(digits) <= n && n <= 21:
+		b.WriteString(digits)
+		b.WriteString(strings.Repeat("0", n-len(digits)))
+	case 0 < n && n <= 21:
+		b.WriteString(digits[:n])
+		b.WriteByte('.')
+		b.WriteString(digits[n:])
+	case -6 < n && n <= 0:
+		b.WriteString("0.")
+		b.WriteString(strings.Repeat("0", -n))
+		b.WriteString(digits)
+	default:
+		b.WriteString(digits[:1])
+		if len(digits) > 1 {
+			b.WriteByte('.')
+			b.WriteString(digits[1:])
+		}
+		b.WriteByte('e')
+		if n-1 >= 0 {
+			b.WriteByte('+')
+		}
+		b.WriteString(strconv.Itoa(n - 1))
+	}
+	return b.String(), true
+}
+
+// CanonicalValue returns the canonical form of a number literal holding
+// val, an int64 or float64, and written raw in the source, "" if not
+// parsed. It reports false for infinite and NaN values.
+func CanonicalValue(raw string, val any) (string, bool) {
+	if raw != "" {
+		return Canonical(raw)
+	}
+	switch v := val.(type) {
+	case int64:
+		return Canonical(strconv.FormatInt(v, 10))
+	case float64:
+		if math.IsInf(v, 0) || math.IsNaN(v) {
+			return "", false
+		}
+		return Canonical(strconv.FormatFloat(v, 'g', -1, 64))
+	}
+	return "", false
+}
+
+// isDigits reports whether s only holds decimal digits.
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,74 @@
+package jsonnum
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanonical(t *testing.T) {
+	var tests = []struct {
+		input string
+		want  string
+	}{
+		{"0", "0"},
+		{"-0", "0"},
+		{"-0.0e5", "0"},
+		{"100", "100"},
+		{"1.0E+2", "100"},
+		{"1e2", "100"},
+		{"0.50", "0.5"},
+		{"-12.340", "-12.34"},
+		{"123e-2", "1.23"},
+		{"007", "7"},
+		{"1e21", "1e+21"},
+		{"1e20", "100000000000000000000"},
+		{"12345678901234567890123", "1.2345678901234567890123e+22"},
+		{"0.000001", "0.000001"},
+		{"0.0000001", "1e-7"},
+		{"-1.5e-7", "-1.5e-7"},
+		{"+5", "5"},
+		{".5", "0.5"},
+		{"5.", "5"},
+		{"1_000.000_1", "1000.0001"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, ok := Canonical(tt.input)
+			assert.True(t, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	for _, input := range []string{"", "-", ".", "1e", "1x", "e5", "0x10"} {
+		t.Run(input, func(t *testing.T) {
+			_, ok := Canonical(input)
+			assert.False(t, ok)
+		})
+	}
+}
+
+func TestCanonicalValue(t *testing.T) {
+	var tests = []struct {
+		name string
+		raw  string
+		val  any
+		want string
+		ok   bool
+	}{
+		{"raw", "1.0E+2", float64(100), "100", true},
+		{"int", "", int64(-42), "-42", true},
+		{"float", "", 2.50, "2.5", true},
+		{"large float", "", 1e300, "1e+300", true},
+		{"infinity", "", math.Inf(1), "", false},
+		{"not a number", "", "x", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := CanonicalValue(tt.raw, tt.val)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,205 @@
+// Package jsonscan reads JSON documents from the items of a lexer for the
+// packages building their own representation of documents, such as tape,
+// compact and the unmarshaling of ast trees. It holds the grammar and the
+// error messages they share with package parser.
+package jsonscan
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/pohedev/gj.git/internal/jsonstr"
+	"github.com/pohedev/gj.git/lexer"
+	"github.com/pohedev/gj.git/token"
+)
+
+// Kinds of syntax errors, re-exported by package parser.
+var (
+	ErrUnexpectedEOF   = errors.New("unexpected EOF")
+	ErrUnexpectedToken = errors.New("unexpected token")
+	ErrInvalidString   = errors.New("invalid string")
+	ErrTrailingComma   = errors.New("trailing comma")
+	ErrTrailingContent = errors.New("trailing content")
+)
+
+// SyntaxError represents a syntax error at a byte offset of the input.
+// It has the fields of parser.SyntaxError, to which it converts.
+type SyntaxError struct {
+	Msg    string // Description of the error.
+	Offset int    // Byte offset in the input where the error occurred.
+	End    int    // Byte offset just after the offending token, or Offset if unknown.
+	Err    error  // Kind of the error, one of the Err variables or of package lexer.
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("%s at offset %d", e.Msg, e.Offset)
+}
+
+func (e *SyntaxError) Unwrap() error {
+	return e.Err
+}
+
+// Handler receives the values of a document in document order. The items
+// give the position and the source text of the values; the closing items
+// of containers come with their number of properties or items.
+type Handler interface {
+	Null(item lexer.Item)
+	Bool(item lexer.Item, v bool)
+	Number(item lexer.Item) // The source text parses as a float64.
+	String(item lexer.Item, s string)
+	BeginObject(item lexer.Item)
+	Key(item lexer.Item, s string)
+	EndObject(item lexer.Item, n int)
+	BeginArray(item lexer.Item)
+	EndArray(item lexer.Item, n int)
+}
+
+// Scan reads the document from lex, which holds exactly one value, and
+// reports its values to h. It returns nil on success.
+func Scan(lex *lexer.Lexer, h Handler) *SyntaxError {
+	s := scanner{lex: lex, h: h}
+	s.next()
+	if err := s.value(); err != nil {
+		return err
+	}
+	if s.item.Token != token.EOF {
+		return s.errorf(ErrTrailingContent, "unexpected trailing content")
+	}
+	return nil
+}
+
+// scanner walks the items of a document.
+type scanner struct {
+	lex  *lexer.Lexer
+	h    Handler
+	item lexer.Item
+}
+
+// next reads the next item.
+func (s *scanner) next() {
+	s.item = s.lex.NextItem()
+}
+
+// errorf returns a SyntaxError of the given kind at the current item.
+func (s *scanner) errorf(kind error, format string, args ...any) *SyntaxError {
+	end := s.item.Pos + len(s.item.Val)
+	if s.item.Token == token.Error {
+		end = s.item.Pos
+	}
+	return &SyntaxError{Msg: fmt.Sprintf(format, args...), Offset: s.item.Pos, End: end, Err: kind}
+}
+
+// unexpected returns the error for an item not allowed at this point.
+func (s *scanner) unexpected(expected string) *SyntaxError {
+	switch s.item.Token {
+	case token.Error:
+		return s.errorf(s.lex.Err(), "%s", s.item.Val)
+	case token.EOF:
+		return s.errorf(ErrUnexpectedEOF, "unexpected EOF, expected %s", expected)
+	}
+	return s.errorf(ErrUnexpectedToken, "unexpected %v, expected %s", s.item, expected)
+}
+
+// unquote decodes the current string item.
+func (s *scanner) unquote() (string, *SyntaxError) {
+	v, offset, err := jsonstr.Unquote(s.item.Val)
+	if err != nil {
+		return "", &SyntaxError{Msg: err.Error(), Offset: s.item.Pos + offset, End: s.item.Pos + len(s.item.Val), Err: ErrInvalidString}
+	}
+	return v, nil
+}
+
+// value reads the value starting at the current item.
+func (s *scanner) value() *SyntaxError {
+	switch s.item.Token {
+	case token.LeftBrace:
+		return s.object()
+	case token.LeftBracket:
+		return s.array()
+	case token.String:
+		v, err := s.unquote()
+		if err != nil {
+			return err
+		}
+		s.h.String(s.item, v)
+	case token.Number:
+		if _, err := strconv.ParseFloat(s.item.Val, 64); err != nil {
+			return s.errorf(lexer.ErrInvalidNumber, "invalid number %s", s.item.Val)
+		}
+		s.h.Number(s.item)
+	case token.True:
+		s.h.Bool(s.item, true)
+	case token.False:
+		s.h.Bool(s.item, false)
+	case token.Null:
+		s.h.Null(s.item)
+	default:
+		return s.unexpected("value")
+	}
+	s.next()
+	return nil
+}
+
+// object reads the object starting at the current item.
+func (s *scanner) object() *SyntaxError {
+	s.h.BeginObject(s.item)
+	s.next()
+	n := 0
+	for s.item.Token != token.RightBrace {
+		if n > 0 {
+			if s.item.Token != token.Comma {
+				return s.unexpected("',' or '}'")
+			}
+			s.next()
+			if s.item.Token == token.RightBrace {
+				return s.errorf(ErrTrailingComma, "trailing comma in object")
+			}
+		}
+		if s.item.Token != token.String {
+			return s.unexpected("string key")
+		}
+		key, err := s.unquote()
+		if err != nil {
+			return err
+		}
+		s.h.Key(s.item, key)
+		s.next()
+		if s.item.Token != token.Colon {
+			return s.unexpected("':'")
+		}
+		s.next()
+		if err := s.value(); err != nil {
+			return err
+		}
+		n++
+	}
+	s.h.EndObject(s.item, n)
+	s.next()
+	return nil
+}
+
+// array reads the array starting at the current item.
+func (s *scanner) array() *SyntaxError {
+	s.h.BeginArray(s.item)
+	s.next()
+	n := 0
+	for s.item.Token != token.RightBracket {
+		if n > 0 {
+			if s.item.Token != token.Comma {
+				return s.unexpected("',' or ']'")
+			}
+			s.next()
+			if s.item.Token == token.RightBracket {
+				return s.errorf(ErrTrailingComma, "trailing comma in array")
+			}
+		}
+		if err := s.value(); err != nil {
+			return err
+		}
+		n++
+	}
+	s.h.EndArray(s.item, n)
+	s.next()
+	return nil
+}
@@ -0,0 +1,64 @@
+package jsonscan
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/pohedev/gj.git/lexer"
+	"github.com/stretchr/testify/assert"
+)
+
+// recorder records the calls of Scan.
+type recorder []string
+
+func (r *recorder) add(format string, args ...any) {
+	*r = append(*r, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Null(item lexer.Item)             { r.add("null@%d", item.Pos) }
+func (r *recorder) Bool(item lexer.Item, v bool)     { r.add("%t@%d", v, item.Pos) }
+func (r *recorder) Number(item lexer.Item)           { r.add("%s@%d", item.Val, item.Pos) }
+func (r *recorder) String(item lexer.Item, s string) { r.add("%q@%d", s, item.Pos) }
+func (r *recorder) BeginObject(item lexer.Item)      { r.add("{@%d", item.Pos) }
+func (r *recorder) Key(item lexer.Item, s string)    { r.add("key %q@%d", s, item.Pos) }
+func (r *recorder) EndObject(item lexer.Item, n int) { r.add("}%d@%d", n, item.Pos) }
+func (r *recorder) BeginArray(item lexer.Item)       { r.add("[@%d", item.Pos) }
+func (r *recorder) EndArray(item lexer.Item, n int)  { r.add("]%d@%d", n, item.Pos) }
+
+func TestScan(t *testing.T) {
+	var r recorder
+	err := Scan(lexer.Lex(`{"a": [1.5, true, null], "b": "x"}`), &r)
+	assert.Nil(t, err)
+	assert.Equal(t, recorder{
+		"{@0", `key "a"@1`, "[@6", "1.5@7", "true@12", "null@18", "]3@22",
+		`key "b"@25`, `"x"@30`, "}2@33",
+	}, r)
+}
+
+func TestScan_Error(t *testing.T) {
+	var tests = []struct {
+		input  string
+		offset int
+		kind   error
+		msg    string
+	}{
+		{`[1,]`, 3, ErrTrailingComma, "trailing comma in array"},
+		{`{"a" 1}`, 5, ErrUnexpectedToken, `unexpected "1", expected ':'`},
+		{`{"a": 1`, 7, ErrUnexpectedEOF, "unexpected EOF, expected ',' or '}'"},
+		{`"\x"`, 1, ErrInvalidString, ""},
+		{`1e400`, 0, lexer.ErrInvalidNumber, "invalid number 1e400"},
+		{`1 2`, 2, ErrTrailingContent, "unexpected trailing content"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var r recorder
+			err := Scan(lexer.Lex(tt.input), &r)
+			if !assert.NotNil(t, err) {
+				return
+			}
+			assert.Equal(t, tt.offset, err.Offset)
+			assert.ErrorIs(t, err, tt.kind)
+			assert.Contains(t, err.Msg, tt.msg)
+		})
+	}
+}
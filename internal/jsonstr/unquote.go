@@ -0,0 +1,179 @@
+// Package jsonstr decodes and encodes JSON string literals for the
+// parsers and printers of gj.
+package jsonstr
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Unquote decodes JSON string literal s, including its quotes.
+// On failure, it returns the byte offset in s of the offending sequence.
+func Unquote(s string) (string, int, error) {
+	if len(s) < 2 || s[0] != '"' || s[len(s)-1] != '"' {
+		return "", 0, fmt.Errorf("invalid string literal %s", s)
+	}
+	inner := s[1 : len(s)-1]
+	if strings.IndexByte(inner, '\\') < 0 && !hasControl(inner) {
+		return inner, 0, nil
+	}
+
+	var b strings.Builder
+	b.Grow(len(inner))
+	for i := 0; i < len(inner); {
+		c := inner[i]
+		if c < 0x20 {
+			return "", i + 1, fmt.Errorf("invalid control character %q in string literal", c)
+		}
+		if c != '\\' {
+			r, size := utf8.DecodeRuneInString(inner[i:])
+			b.WriteRune(r)
+			i += size
+			continue
+		}
+
+		if i+1 >= len(inner) {
+			return "", i + 1, fmt.Errorf("invalid escape sequence in string literal")
+		}
+		switch inner[i+1] {
+		case '"', '\\', '/':
+			b.WriteByte(inner[i+1])
+		case 'b':
+			b.WriteByte('\b')
+		case 'f':
+			b.WriteByte('\f')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 't':
+			b.WriteByte('\t')
+		case 'u':
+			r, ok := decodeHex(inner[i+2:])
+			if !ok {
+				return "", i + 1, fmt.Errorf("invalid unicode escape sequence %q in string literal", truncate(inner[i:], 6))
+			}
+			i += 6
+			if utf16.IsSurrogate(r) {
+				if strings.HasPrefix(inner[i:], `\u`) {
+					low, ok := decodeHex(inner[i+2:])
+					if pair := utf16.DecodeRune(r, low); ok && pair != utf8.RuneError {
+						b.WriteRune(pair)
+						i += 6
+						continue
+					}
+				}
+				r = utf8.RuneError
+			}
+			b.WriteRune(r)
+			continue
+		default:
+			return "", i + 1, fmt.Errorf("invalid escape sequence %q in string literal", inner[i:i+2])
+		}
+		i += 2
+	}
+	return b.String(), 0, nil
+}
+
+// Check reports the error Unquote would return for s, without decoding s
+// nor allocating when s is valid.
+func Check(s string) (int, error) {
+	if len(s) < 2 || s[0] != '"' || s[len(s)-1] != '"' {
+		return failed(s)
+	}
+	inner := s[1 : len(s)-1]
+	for i := 0; i < len(inner); {
+		switch c := inner[i]; {
+		case c < 0x20:
+			return failed(s)
+		case c != '\\':
+			i++
+		case i+1 >= len(inner):
+			return failed(s)
+		case strings.IndexByte(`"\/bfnrt`, inner[i+1]) >= 0:
+			i += 2
+		case inner[i+1] == 'u':
+			if _, ok := decodeHex(inner[i+2:]); !ok {
+				return failed(s)
+			}
+			i += 6
+		default:
+			return failed(s)
+		}
+	}
+	return 0, nil
+}
+
+// DecodeEscape decodes the escape sequence at the start of s as Unquote
+// does, returning the character and the length of the sequence, or
+// utf8.RuneError for an invalid one.
+func DecodeEscape(s string) (rune, int) {
+	if len(s) < 2 || s[0] != '\\' {
+		return utf8.RuneError, min(len(s), 1)
+	}
+	if i := strings.IndexByte(`"\/bfnrt`, s[1]); i >= 0 {
+		return rune("\"\\/\b\f\n\r\t"[i]), 2
+	}
+	r, ok := decodeHex(s[2:])
+	if s[1] != 'u' || !ok {
+		return utf8.RuneError, 2
+	}
+	if !utf16.IsSurrogate(r) {
+		return r, 6
+	}
+	if strings.HasPrefix(s[6:], `\u`) {
+		low, ok := decodeHex(s[8:])
+		if pair := utf16.DecodeRune(r, low); ok && pair != utf8.RuneError {
+			return pair, 12
+		}
+	}
+	return utf8.RuneError, 6
+}
+
+// failed returns the error of Unquote for invalid s.
+func failed(s string) (int, error) {
+	_, offset, err := Unquote(s)
+	return offset, err
+}
+
+// decodeHex decodes the four hex digits at the start of s.
+func decodeHex(s string) (rune, bool) {
+	if len(s) < 4 {
+		return 0, false
+	}
+	var r rune
+	for _, c := range []byte(s[:4]) {
+		switch {
+		case '0' <= c && c <= '9':
+			c -= '0'
+		case 'a' <= c && c <= 'f':
+			c = c - 'a' + 10
+		case 'A' <= c && c <= 'F':
+			c = c - 'A' + 10
+		default:
+			return 0, false
+		}
+		r = r<<4 | rune(c)
+	}
+	return r, true
+}
+
+// hasControl reports whether s contains a control character.
+func hasControl(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < 0x20 {
+			return true
+		}
+	}
+	return false
+}
+
+// truncate returns at most n bytes of s.
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
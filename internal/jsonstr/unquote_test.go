@@ -0,0 +1,78 @@
+package jsonstr
+
+import (
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnquote(t *testing.T) {
+	var tests = []struct {
+		input string
+		want  string
+	}{
+		{`"plain"`, "plain"},
+		{`"a\"b\\c\/d"`, `a"b\c/d`},
+		{`"\b\f\n\r\t"`, "\b\f\n\r\t"},
+		{`"\u00e9é"`, "éé"},
+		{`"\ud83d\ude00"`, "😀"},
+		{`"\ud83d"`, "�"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			s, _, err := Unquote(tt.input)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, s)
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	var tests = []string{
+		`"plain"`,
+		`"a\"b\\c\/d\b\f\n\r\t"`,
+		`"\u00e9\ud83d"`,
+		`"\x"`,
+		`"\u12"`,
+		`"\u12G4"`,
+		`"a\"`,
+		"\"a\tb\"",
+		`x`,
+	}
+	for _, input := range tests {
+		t.Run(input, func(t *testing.T) {
+			_, wantOffset, wantErr := Unquote(input)
+			offset, err := Check(input)
+			assert.Equal(t, wantOffset, offset)
+			assert.Equal(t, wantErr, err)
+		})
+	}
+	assert.Zero(t, testing.AllocsPerRun(10, func() {
+		Check(`"a\nb\u00e9"`)
+	}))
+}
+
+func TestDecodeEscape(t *testing.T) {
+	var tests = []struct {
+		input string
+		r     rune
+		size  int
+	}{
+		{`\n`, '\n', 2},
+		{`\/x`, '/', 2},
+		{`\u00e9`, 'é', 6},
+		{`\ud83d\ude00`, '😀', 12},
+		{`\ud83dx`, utf8.RuneError, 6},
+		{`\u12G4`, utf8.RuneError, 2},
+		{`\x`, utf8.RuneError, 2},
+		{`x`, utf8.RuneError, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			r, size := DecodeEscape(tt.input)
+			assert.Equal(t, tt.r, r)
+			assert.Equal(t, tt.size, size)
+		})
+	}
+}
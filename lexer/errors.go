@@ -0,0 +1,10 @@
+package lexer
+
+import "errors"
+
+// Kinds of the errors reported by Error items, see Lexer.Err.
+var (
+	ErrInvalidNumber      = errors.New("invalid number")
+	ErrUnterminatedString = errors.New("unterminated string")
+	ErrInvalidLiteral     = errors.New("invalid literal")
+)
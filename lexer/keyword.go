@@ -0,0 +1,96 @@
+package lexer
+
+import (
+	"strings"
+
+	"github.com/pohedev/gj.git/token"
+)
+
+// keywords are the literals scanned by lexKeyword.
+var keywords = []struct {
+	word  string
+	token token.Token
+}{
+	{nullValue, token.Null},
+	{boolTrueValue, token.True},
+	{boolFalseValue, token.False},
+}
+
+// lexKeyword scans a run of letters, which must be true, false or null.
+// A truncated or misspelled keyword is reported with the intended one;
+// other words are left to lexToken as unknown characters.
+func lexKeyword(l *Lexer) stateFn {
+	for isAlphaNumeric(l.peek()) {
+		l.next()
+	}
+	word := l.input[l.start:l.pos]
+	for _, kw := range keywords {
+		if word == kw.word {
+			l.emit(kw.token)
+			return lexToken
+		}
+	}
+	for _, kw := range keywords {
+		if strings.HasPrefix(kw.word, word) {
+			return l.errorf(ErrInvalidLiteral, "truncated literal %q, expected %s", word, kw.word)
+		}
+	}
+	if kw := suggest(word); kw != "" {
+		return l.errorf(ErrInvalidLiteral, "invalid literal %q, did you mean %s?", word, kw)
+	}
+	if strings.ContainsRune("tfn", rune(word[0])) {
+		return l.errorf(ErrInvalidLiteral, "invalid literal %q", word)
+	}
+	// Not meant as a keyword, report its first character only.
+	l.pos = l.start
+	l.next()
+	l.emit(token.Unknown)
+	return lexToken
+}
+
+// suggest returns the keyword word is likely a misspelling of,
+// or "" if there is none.
+func suggest(word string) string {
+	maxDistance := 1
+	if len(word) >= 4 {
+		maxDistance = 2
+	}
+	best, bestDistance := "", maxDistance+1
+	for _, kw := range keywords {
+		if strings.EqualFold(word, kw.word) {
+			return kw.word
+		}
+		if d := distance(strings.ToLower(word), kw.word); d < bestDistance {
+			best, bestDistance = kw.word, d
+		}
+	}
+	return best
+}
+
+// distance returns the optimal string alignment distance between a and b:
+// the number of insertions, deletions, substitutions and transpositions
+// of adjacent characters turning a into b.
+func distance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(ra)][len(rb)]
+}
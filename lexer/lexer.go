@@ -2,9 +2,11 @@ package lexer
 
 import (
 	"fmt"
+	"iter"
 	"strings"
 	"unicode"
 	"unicode/utf8"
+	"unsafe"
 
 	"github.com/pohedev/gj.git/token"
 )
@@ -39,44 +41,121 @@ func (i Item) String() string {
 
 // Lexer holds the state of the scanner.
 type Lexer struct {
-	input string    // the string being scanned.
-	start int       // start position of this Item.
-	pos   int       // current position in the input.
-	width int       // width of last rune read from input.
-	items chan Item // channel of scanned items.
+	input string  // the string being scanned.
+	start int     // start position of this Item.
+	pos   int     // current position in the input.
+	width int     // width of last rune read from input.
+	items []Item  // scanned items not yet returned by NextItem.
+	head  int     // index of the next Item to return.
+	state stateFn // next state function, nil once scanning is done.
+
+	extensions      Extension // enabled non-standard syntaxes.
+	offset          int       // added to the positions of items.
+	continueOnError bool      // keep scanning after an Error item.
+	err             error     // kind of the error reported by the last Error item.
+
+	feeding bool // input is given in chunks by Feed.
+	ended   bool // End was called, no more chunks.
+	atEnd   bool // the state function read past the end of the input.
+	dropped int  // bytes of input dropped by Feed, included in offset.
 }
 
 // Lex creates a new lexer.
-func Lex(input string) *Lexer {
+func Lex(input string, opts ...Option) *Lexer {
 	l := &Lexer{
 		input: input,
-		items: make(chan Item),
+		state: lexToken,
+	}
+	for _, opt := range opts {
+		opt(l)
 	}
-	go l.run() // concurrently run state machine.
 	return l
 }
 
-// stateFn represents the state of the scanner
-// as a function that returns the next state.
-type stateFn func(*Lexer) stateFn
+// LexBytes is like Lex but scans input without copying it. Items, and ASTs
+// built from them, share the memory of input, which must not be modified
+// while they are in use.
+func LexBytes(input []byte, opts ...Option) *Lexer {
+	return Lex(bytesToString(input), opts...)
+}
 
-// run lexer the input by executing state functions until
-// the state is nil.
-func (l *Lexer) run() {
-	for state := lexToken; state != nil; {
-		state = state(l)
+// Reset prepares the Lexer to scan input, reusing its allocations.
+// Options given to Lex are kept.
+func (l *Lexer) Reset(input string) {
+	l.input = input
+	l.start = 0
+	l.pos = 0
+	l.width = 0
+	l.items = l.items[:0]
+	l.head = 0
+	l.state = lexToken
+	l.err = nil
+	l.feeding = false
+	l.ended = false
+	l.offset -= l.dropped
+	l.dropped = 0
+}
 
-	}
-	close(l.items) // No more tokens will be delivered.
+// Feed appends chunk to the input, for scanning input that arrives
+// incrementally. Items are read with TryNextItem until End is called.
+// Input before the items already scanned is released, so Slice can
+// only be used on the part of the input not scanned yet.
+func (l *Lexer) Feed(chunk []byte) {
+	l.feeding = true
+	l.input = l.input[l.start:] + string(chunk)
+	l.offset += l.start
+	l.dropped += l.start
+	l.pos -= l.start
+	l.start = 0
+}
+
+// End marks the end of the input given by Feed; the last item,
+// which may have been waiting for more input, can then be scanned.
+func (l *Lexer) End() {
+	l.feeding = true
+	l.ended = true
+}
+
+// ResetBytes is like Reset but scans input without copying it,
+// with the same restrictions as LexBytes.
+func (l *Lexer) ResetBytes(input []byte) {
+	l.Reset(bytesToString(input))
+}
+
+// bytesToString returns b as a string sharing its memory.
+func bytesToString(b []byte) string {
+	return unsafe.String(unsafe.SliceData(b), len(b))
+}
+
+// Err returns the kind of the error reported by the last scanned Error item,
+// ErrInvalidNumber or ErrUnterminatedString, or nil if none was scanned.
+func (l *Lexer) Err() error {
+	return l.err
+}
+
+// Slice returns the input between byte offsets start and end,
+// as reported in the positions of items.
+func (l *Lexer) Slice(start, end int) string {
+	return l.input[start-l.offset : end-l.offset]
+}
+
+// Offset returns the position of the first byte of the input,
+// as set by WithOffset.
+func (l *Lexer) Offset() int {
+	return l.offset
 }
 
+// stateFn represents the state of the scanner
+// as a function that returns the next state.
+type stateFn func(*Lexer) stateFn
+
 // emit passes an Item back to the client.
 func (l *Lexer) emit(t token.Token) {
-	l.items <- Item{
+	l.items = append(l.items, Item{
 		Token: t,
-		Pos:   l.start,
+		Pos:   l.start + l.offset,
 		Val:   l.input[l.start:l.pos],
-	}
+	})
 	l.start = l.pos
 }
 
@@ -84,8 +163,14 @@ func (l *Lexer) emit(t token.Token) {
 func (l *Lexer) next() (r rune) {
 	if l.pos >= len(l.input) {
 		l.width = 0
+		l.atEnd = true
 		return eof
 	}
+	if c := l.input[l.pos]; c < utf8.RuneSelf {
+		l.width = 1
+		l.pos++
+		return rune(c)
+	}
 	r, l.width = utf8.DecodeRuneInString(l.input[l.pos:])
 	l.pos += l.width
 	return r
@@ -129,17 +214,83 @@ func (l *Lexer) acceptRun(valid string) {
 
 // error returns an error Token and terminates the scan
 // by passing back a nil pointer that will be the next
-// state, terminating l.run.
-func (l *Lexer) errorf(format string, args ...interface{}) stateFn {
-	l.items <- Item{token.Error, l.start, fmt.Sprintf(format, args...)}
+// state, terminating l.run. With ContinueOnError, the
+// scan resumes after the offending input instead.
+func (l *Lexer) errorf(kind error, format string, args ...interface{}) stateFn {
+	return l.errorAtf(kind, l.start, format, args...)
+}
+
+// errorAtf is like errorf but reports the error at byte offset pos.
+func (l *Lexer) errorAtf(kind error, pos int, format string, args ...interface{}) stateFn {
+	l.err = kind
+	l.items = append(l.items, Item{token.Error, pos + l.offset, fmt.Sprintf(format, args...)})
+	if l.continueOnError {
+		l.ignore()
+		return lexToken
+	}
 	return nil
 }
 
-// NextItem returns the next Item from the input. The Lexer has to be
-// drained (all items received until itemEOF or itemError) - otherwise
-// the Lexer goroutine will leak.
+// NextItem returns the next Item from the input, running state functions
+// until one is scanned. Once the EOF or Error item has been returned,
+// NextItem keeps returning EOF, unless ContinueOnError is set, in which
+// case scanning goes on after Error items.
 func (l *Lexer) NextItem() Item {
-	return <-l.items
+	for l.head == len(l.items) {
+		if l.state == nil {
+			return Item{Token: token.EOF, Pos: l.pos + l.offset}
+		}
+		l.items = l.items[:0]
+		l.head = 0
+		l.state = l.state(l)
+	}
+	item := l.items[l.head]
+	l.head++
+	return item
+}
+
+// TryNextItem is like NextItem but reports false, without consuming
+// anything, when the input given by Feed may not hold the whole next
+// item yet, e.g. a number or a string continuing in the next chunk.
+// Once End has been called, it always reports true.
+func (l *Lexer) TryNextItem() (Item, bool) {
+	if !l.feeding || l.ended {
+		return l.NextItem(), true
+	}
+	for l.head == len(l.items) {
+		if l.state == nil {
+			return Item{Token: token.EOF, Pos: l.pos + l.offset}, true
+		}
+		start, pos, state, err := l.start, l.pos, l.state, l.err
+		l.items = l.items[:0]
+		l.head = 0
+		l.atEnd = false
+		l.state = l.state(l)
+		if l.atEnd {
+			// The item may go on in the next chunk, scan it again then.
+			l.items = l.items[:0]
+			l.start, l.pos, l.state, l.err = start, pos, state, err
+			return Item{}, false
+		}
+	}
+	item := l.items[l.head]
+	l.head++
+	return item, true
+}
+
+// Items returns an iterator over the remaining items, ending with the
+// EOF or Error item, or only the EOF item with ContinueOnError. The loop
+// may stop early; the remaining items are still returned by the next call
+// of NextItem.
+func (l *Lexer) Items() iter.Seq[Item] {
+	return func(yield func(Item) bool) {
+		for {
+			item := l.NextItem()
+			if !yield(item) || item.Token == token.EOF || item.Token == token.Error && !l.continueOnError {
+				return
+			}
+		}
+	}
 }
 
 // lexToken scans current char and creates a new Token.
@@ -173,15 +324,12 @@ func lexToken(l *Lexer) stateFn {
 			return lexToken
 		case r == '"':
 			return lexQuote
-		case isNumber(r):
+		case isNumber(r) || r == '.' && isDigit(l.peek()):
 			l.backup()
 			return lexNumber
-		case r == 'n':
+		case unicode.IsLetter(r):
 			l.backup()
-			return lexNull
-		case r == 't' || r == 'f':
-			l.backup()
-			return lexBool
+			return lexKeyword
 		default:
 			l.emit(token.Unknown)
 		}
@@ -200,7 +348,7 @@ func lexQuote(l *Lexer) stateFn {
 				break
 			}
 		case eof, '\n':
-			return l.errorf("unterminated quoted string")
+			return l.errorf(ErrUnterminatedString, "unterminated quoted string")
 		case '"':
 			l.emit(token.String)
 			return lexToken
@@ -210,61 +358,112 @@ func lexQuote(l *Lexer) stateFn {
 
 // lexNumber scans a run of number.
 func lexNumber(l *Lexer) stateFn {
-	if !l.scanNumber() {
-		return l.errorf("bad number syntax: %q", l.input[l.start:l.pos])
+	if pos, msg := l.scanNumber(); msg != "" {
+		// Quote the whole malformed number, which is skipped
+		// when continuing on errors.
+		end := l.pos
+		for end < len(l.input) {
+			r, w := utf8.DecodeRuneInString(l.input[end:])
+			if !isAlphaNumeric(r) && !strings.ContainsRune(".+-", r) {
+				break
+			}
+			end += w
+		}
+		text := l.input[l.start:end]
+		if l.continueOnError {
+			l.pos = end
+		}
+		return l.errorAtf(ErrInvalidNumber, pos, "bad number syntax: %s in %q", msg, text)
 	}
 	l.emit(token.Number)
 	return lexToken
 }
 
-func (l *Lexer) scanNumber() bool {
+// scanNumber scans a number following the grammar of RFC 8259,
+// relaxed by the enabled extensions. On failure, it returns the
+// offset and a description of the offending part.
+func (l *Lexer) scanNumber() (int, string) {
 	// Optional leading sign.
-	l.accept("+-")
+	if l.accept("+") {
+		if !l.hasExtension(ExtLeadingPlus) {
+			return l.pos - 1, "leading '+' is not allowed"
+		}
+	} else {
+		l.accept("-")
+	}
 
-	digits := "0123456789_"
-	l.acceptRun(digits)
-	if l.accept(".") {
-		l.acceptRun(digits)
+	// Integer part.
+	intPos := l.pos
+	switch {
+	case l.accept("0"):
+		if isDigit(l.peek()) || l.peek() == '_' && l.hasExtension(ExtNumericSeparators) {
+			return intPos, "leading zero is not allowed"
+		}
+	case isDigit(l.peek()):
+		if pos, msg := l.scanDigits(); msg != "" {
+			return pos, msg
+		}
+	case l.peek() == '.':
+		if !l.hasExtension(ExtLeadingDot) {
+			return intPos, "leading '.' is not allowed"
+		}
+	default:
+		return intPos, "missing digits"
 	}
 
+	// Fraction part.
+	if dotPos := l.pos; l.accept(".") {
+		if isDigit(l.peek()) {
+			if pos, msg := l.scanDigits(); msg != "" {
+				return pos, msg
+			}
+		} else if dotPos == intPos {
+			return dotPos, "missing digits"
+		} else if r := l.peek(); isAlphaNumeric(r) && r != 'e' && r != 'E' {
+			return l.pos, "missing digits after '.'"
+		} else if !l.hasExtension(ExtTrailingDot) {
+			return dotPos, "trailing '.' is not allowed"
+		}
+	}
+
+	// Exponent part.
 	if l.accept("eE") {
 		l.accept("+-")
-		l.acceptRun("0123456789")
+		if !isDigit(l.peek()) {
+			return l.pos, "missing digits in exponent"
+		}
+		if pos, msg := l.scanDigits(); msg != "" {
+			return pos, msg
+		}
 	}
-	// Next thing mustn't be alphanumeric.
-	if isAlphaNumeric(l.peek()) {
+
+	// Next thing mustn't be alphanumeric or a dot.
+	if l.peek() == '_' && !l.hasExtension(ExtNumericSeparators) {
+		pos := l.pos
 		l.next()
-		return false
+		return pos, "'_' digit separators are not allowed"
 	}
-
-	return true
-}
-
-// lexNull scans a run of null.
-func lexNull(l *Lexer) stateFn {
-	if strings.HasPrefix(l.input[l.pos:], nullValue) {
-		for i := 0; i < len(nullValue); i++ {
-			l.next()
-		}
-		l.emit(token.Null)
+	if r := l.peek(); isAlphaNumeric(r) || r == '.' {
+		pos := l.pos
+		l.next()
+		return pos, fmt.Sprintf("unexpected %q", r)
 	}
-	return lexToken
+	return 0, ""
 }
 
-// lexBool scans a run of boolean.
-func lexBool(l *Lexer) stateFn {
-	if strings.HasPrefix(l.input[l.pos:], boolTrueValue) {
-		for i := 0; i < len(boolTrueValue); i++ {
-			l.next()
-		}
-		l.emit(token.True)
-	} else if strings.HasPrefix(l.input[l.pos:], boolFalseValue) {
-		for i := 0; i < len(boolFalseValue); i++ {
-			l.next()
+// scanDigits scans a run of digits, which may contain
+// underscores between digits when ExtNumericSeparators is enabled.
+func (l *Lexer) scanDigits() (int, string) {
+	l.acceptRun("0123456789")
+	for l.hasExtension(ExtNumericSeparators) && l.peek() == '_' {
+		pos := l.pos
+		l.next()
+		if !isDigit(l.peek()) {
+			return pos, "'_' must separate digits"
 		}
-		l.emit(token.False)
+		l.acceptRun("0123456789")
 	}
-	return lexToken
+	return 0, ""
 }
 
 // isSpace reports whether rune is a space character.
@@ -277,6 +476,11 @@ func isNumber(r rune) bool {
 	return r == '+' || r == '-' || ('0' <= r && r <= '9')
 }
 
+// isDigit reports whether rune is a decimal digit.
+func isDigit(r rune) bool {
+	return '0' <= r && r <= '9'
+}
+
 // isAlphaNumeric reports whether rune is an alphabetic, digit, or underscore.
 func isAlphaNumeric(r rune) bool {
 	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
@@ -1,9 +1,11 @@
 package lexer
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/pohedev/gj.git/token"
+	"github.com/stretchr/testify/assert"
 )
 
 type lexTest struct {
@@ -371,3 +373,197 @@ func TestLexToken(t *testing.T) {
 		})
 	}
 }
+
+func TestLexNumber(t *testing.T) {
+	var tests = []struct {
+		name  string
+		input string
+		ext   Extension
+		want  Item
+	}{
+		{"integer", "-0", 0, Item{token.Number, 0, "-0"}},
+		{"fraction and exponent", "-12.5e-3", 0, Item{token.Number, 0, "-12.5e-3"}},
+		{"leading plus", "+5", 0, Item{token.Error, 0, `bad number syntax: leading '+' is not allowed in "+5"`}},
+		{"leading zero", "012", 0, Item{token.Error, 0, `bad number syntax: leading zero is not allowed in "012"`}},
+		{"leading dot", ".5", 0, Item{token.Error, 0, `bad number syntax: leading '.' is not allowed in ".5"`}},
+		{"trailing dot", "5.", 0, Item{token.Error, 1, `bad number syntax: trailing '.' is not allowed in "5."`}},
+		{"separator", "1_000", 0, Item{token.Error, 1, `bad number syntax: '_' digit separators are not allowed in "1_000"`}},
+		{"double minus", "--1", 0, Item{token.Error, 1, `bad number syntax: missing digits in "--1"`}},
+		{"missing exponent", "1e", 0, Item{token.Error, 2, `bad number syntax: missing digits in exponent in "1e"`}},
+		{"extension leading plus", "+5", ExtLeadingPlus, Item{token.Number, 0, "+5"}},
+		{"extension leading dot", ".5", ExtLeadingDot, Item{token.Number, 0, ".5"}},
+		{"extension trailing dot", "5.", ExtTrailingDot, Item{token.Number, 0, "5."}},
+		{"extension trailing dot exponent", "5.e3", ExtTrailingDot, Item{token.Number, 0, "5.e3"}},
+		{"dot before letter", "5.x", ExtTrailingDot, Item{token.Error, 2, `bad number syntax: missing digits after '.' in "5.x"`}},
+		{"extension separators", "1_000.000_1", ExtNumericSeparators, Item{token.Number, 0, "1_000.000_1"}},
+		{"extension exponent separators", "1e1_0", ExtNumericSeparators, Item{token.Number, 0, "1e1_0"}},
+		{"leading zero separator", "0_1", 0, Item{token.Error, 1, `bad number syntax: '_' digit separators are not allowed in "0_1"`}},
+		{"extension misplaced separator", "1__0", ExtNumericSeparators, Item{token.Error, 1, `bad number syntax: '_' must separate digits in "1__0"`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := Lex(tt.input, WithExtensions(tt.ext)).NextItem()
+			assert.Equal(t, tt.want, item)
+		})
+	}
+}
+
+func TestLexKeyword(t *testing.T) {
+	var tests = []struct {
+		input string
+		want  Item
+	}{
+		{"null", Item{token.Null, 0, "null"}},
+		{"true", Item{token.True, 0, "true"}},
+		{"false", Item{token.False, 0, "false"}},
+		{"ture", Item{token.Error, 0, `invalid literal "ture", did you mean true?`}},
+		{"flase", Item{token.Error, 0, `invalid literal "flase", did you mean false?`}},
+		{"nul", Item{token.Error, 0, `truncated literal "nul", expected null`}},
+		{"t", Item{token.Error, 0, `truncated literal "t", expected true`}},
+		{"fals", Item{token.Error, 0, `truncated literal "fals", expected false`}},
+		{"nulll", Item{token.Error, 0, `invalid literal "nulll", did you mean null?`}},
+		{"NULL", Item{token.Error, 0, `invalid literal "NULL", did you mean null?`}},
+		{"True", Item{token.Error, 0, `invalid literal "True", did you mean true?`}},
+		{"nil", Item{token.Error, 0, `invalid literal "nil"`}},
+		{"undefined", Item{token.Unknown, 0, "u"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			l := Lex(tt.input)
+			assert.Equal(t, tt.want, l.NextItem())
+			if tt.want.Token == token.Error {
+				assert.ErrorIs(t, l.Err(), ErrInvalidLiteral)
+			}
+		})
+	}
+}
+
+func TestLexer_Items(t *testing.T) {
+	var items []Item
+	for item := range Lex(`[1, true]`).Items() {
+		items = append(items, item)
+	}
+	assert.True(t, equal(items, []Item{
+		tLeftBracket,
+		mkItem(token.Number, "1"),
+		tComma,
+		tTrue,
+		tRightBracket,
+		tEOF,
+	}, false))
+
+	t.Run("stop early", func(t *testing.T) {
+		l := Lex(`[1, 2, 3]`)
+		for item := range l.Items() {
+			if item.Token == token.Number {
+				break
+			}
+		}
+		assert.Equal(t, token.Comma, l.NextItem().Token)
+	})
+}
+
+func TestLexer_ContinueOnError(t *testing.T) {
+	l := Lex(`[01x, "a`+"\n"+`, 2]`, ContinueOnError())
+	var items []Item
+	for item := range l.Items() {
+		items = append(items, item)
+	}
+	assert.Equal(t, []Item{
+		{token.LeftBracket, 0, "["},
+		{token.Error, 1, `bad number syntax: leading zero is not allowed in "01x"`},
+		{token.Comma, 4, ","},
+		{token.Error, 6, "unterminated quoted string"},
+		{token.Comma, 9, ","},
+		{token.Number, 11, "2"},
+		{token.RightBracket, 12, "]"},
+		{token.EOF, 13, ""},
+	}, items)
+	assert.ErrorIs(t, l.Err(), ErrUnterminatedString)
+}
+
+func TestLexBytes(t *testing.T) {
+	input := []byte(`[1, "a"]`)
+	l := LexBytes(input)
+	var items []Item
+	for item := range l.Items() {
+		items = append(items, item)
+	}
+	assert.Equal(t, []Item{
+		{token.LeftBracket, 0, "["},
+		{token.Number, 1, "1"},
+		{token.Comma, 2, ","},
+		{token.String, 4, `"a"`},
+		{token.RightBracket, 7, "]"},
+		{token.EOF, 8, ""},
+	}, items)
+
+	allocs := testing.AllocsPerRun(10, func() {
+		l.ResetBytes(input)
+	})
+	assert.Zero(t, allocs)
+}
+
+func TestLexer_Feed(t *testing.T) {
+	var tests = []struct {
+		name  string
+		input string
+	}{
+		{"object", `{"name": "g\"j", "n": -12.5e3, "ok": true, "no": false, "nil": null}`},
+		{"number at end", `12345`},
+		{"keyword at end", `[true`},
+		{"error", `[01, "a`},
+	}
+	for _, tt := range tests {
+		var want []Item
+		for item := range Lex(tt.input).Items() {
+			want = append(want, item)
+		}
+		for split := 0; split <= len(tt.input); split++ {
+			t.Run(fmt.Sprintf("%s/%d", tt.name, split), func(t *testing.T) {
+				l := Lex("")
+				var items []Item
+				done := false
+				read := func() {
+					for !done {
+						item, ok := l.TryNextItem()
+						if !ok {
+							return
+						}
+						items = append(items, item)
+						done = item.Token == token.EOF || item.Token == token.Error
+					}
+				}
+				l.Feed([]byte(tt.input[:split]))
+				read()
+				l.Feed([]byte(tt.input[split:]))
+				read()
+				l.End()
+				read()
+				assert.Equal(t, want, items)
+			})
+		}
+	}
+
+	t.Run("reset", func(t *testing.T) {
+		l := Lex("", WithOffset(10))
+		l.Feed([]byte(`[1, `))
+		for _, ok := l.TryNextItem(); ok; _, ok = l.TryNextItem() {
+		}
+		l.Feed([]byte(`2]`))
+		assert.Equal(t, Item{token.Number, 14, "2"}, l.NextItem())
+
+		l.Reset(`3`)
+		assert.Equal(t, Item{token.Number, 10, "3"}, l.NextItem())
+	})
+}
+
+func TestLexer_Reset(t *testing.T) {
+	l := Lex(`[1`, WithExtensions(ExtLeadingPlus))
+	l.NextItem()
+
+	l.Reset(`+2`)
+	assert.Equal(t, Item{token.Number, 0, "+2"}, l.NextItem())
+	assert.Equal(t, Item{token.EOF, 2, ""}, l.NextItem())
+	assert.Equal(t, Item{token.EOF, 2, ""}, l.NextItem())
+}
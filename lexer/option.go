@@ -0,0 +1,43 @@
+package lexer
+
+// Extension identifies a non-standard syntax accepted by the Lexer.
+type Extension uint
+
+const (
+	ExtLeadingPlus       Extension = 1 << iota // +5, normalized to 5 by the parser
+	ExtLeadingDot                              // .5, normalized to 0.5 by the parser
+	ExtTrailingDot                             // 5., normalized to 5.0 by the parser
+	ExtNumericSeparators                       // 1_000, removed by the parser
+)
+
+// Option configures a Lexer.
+type Option func(*Lexer)
+
+// WithExtensions enables the given non-standard syntaxes.
+func WithExtensions(ext Extension) Option {
+	return func(l *Lexer) {
+		l.extensions |= ext
+	}
+}
+
+// WithOffset adds offset to the positions of items, for scanning
+// a fragment of a larger input.
+func WithOffset(offset int) Option {
+	return func(l *Lexer) {
+		l.offset = offset
+	}
+}
+
+// ContinueOnError makes the Lexer resume scanning after the input
+// reported by an Error item, instead of stopping, so that all the
+// errors of the input are reported.
+func ContinueOnError() Option {
+	return func(l *Lexer) {
+		l.continueOnError = true
+	}
+}
+
+// hasExtension reports whether ext is enabled.
+func (l *Lexer) hasExtension(ext Extension) bool {
+	return l.extensions&ext != 0
+}
@@ -0,0 +1,199 @@
+package merge
+
+import (
+	"strconv"
+
+	"github.com/pohedev/gj.git/ast"
+	"github.com/pohedev/gj.git/diff"
+	"github.com/pohedev/gj.git/path"
+)
+
+// Strategy decides how Deep combines a value of dst with the value of src
+// at the same location.
+type Strategy int
+
+const (
+	Recurse Strategy = iota // Objects are merged key by key; other values are replaced.
+	Replace                 // The value of src replaces the value of dst.
+	Append                  // The items of a src array are appended to the dst array.
+	Union                   // The items of a src array missing from the dst array are appended.
+)
+
+// Option configures Deep.
+type Option func(*merger)
+
+// At applies strategy s to the values located at pattern, a JSON Pointer
+// or dot/bracket pattern accepted by path.Parse, e.g. "$.plugins" or
+// "$.services.*.ports". When several patterns match, the last one wins.
+func At(pattern string, s Strategy) Option {
+	return func(m *merger) {
+		m.addRule(pattern, rule{strategy: s})
+	}
+}
+
+// UnionBy applies Union to the arrays of objects located at pattern,
+// identifying items by the value of their key property: a src item with
+// the same key as a dst item is merged into it, other src items are
+// appended.
+func UnionBy(pattern, key string) Option {
+	return func(m *merger) {
+		m.addRule(pattern, rule{strategy: Union, key: key})
+	}
+}
+
+// merger holds the rules of Deep.
+type merger struct {
+	rules []rule
+	err   error // First invalid pattern.
+}
+
+// rule is a Strategy applied at the locations matching pattern.
+type rule struct {
+	pattern  path.Path
+	strategy Strategy
+	key      string // Key property of the items, for Union.
+}
+
+// addRule parses pattern and appends r with it.
+func (m *merger) addRule(pattern string, r rule) {
+	p, err := path.Parse(pattern)
+	if err != nil {
+		if m.err == nil {
+			m.err = err
+		}
+		return
+	}
+	r.pattern = p
+	m.rules = append(m.rules, r)
+}
+
+// rule returns the rule of the value located at tokens.
+func (m *merger) rule(tokens []string) rule {
+	for i := len(m.rules) - 1; i >= 0; i-- {
+		if m.rules[i].pattern.Match(tokens) {
+			return m.rules[i]
+		}
+	}
+	return rule{strategy: Recurse}
+}
+
+// Deep returns dst with src merged into it, for overlaying a fragment onto
+// a base document. By default, objects are merged recursively and any other
+// value of src replaces the value of dst; At and UnionBy pick another
+// Strategy for given locations. dst and src are not modified; the result
+// shares values with them.
+func Deep(dst, src *ast.RootNode, opts ...Option) (*ast.RootNode, error) {
+	m := merger{}
+	for _, opt := range opts {
+		opt(&m)
+	}
+	if m.err != nil {
+		return nil, m.err
+	}
+	return newRoot(m.merge(rootValue(dst), rootValue(src), nil)), nil
+}
+
+// merge returns src merged into dst, located at tokens.
+func (m *merger) merge(dst, src *ast.Value, tokens []string) *ast.Value {
+	if dst == nil {
+		return src
+	}
+	if src == nil {
+		return dst
+	}
+
+	r := m.rule(tokens)
+	switch r.strategy {
+	case Recurse:
+		d, dok := unwrap(dst).(*ast.Object)
+		s, sok := unwrap(src).(*ast.Object)
+		if dok && sok {
+			return &ast.Value{Value: m.mergeObjects(d, s, tokens)}
+		}
+	case Append, Union:
+		d, dok := unwrap(dst).(*ast.Array)
+		s, sok := unwrap(src).(*ast.Array)
+		if dok && sok {
+			merged := ast.Array{Children: append([]ast.ArrayItem(nil), d.Children...)}
+			for _, item := range s.Children {
+				switch {
+				case r.strategy == Append:
+					merged.Children = append(merged.Children, item)
+				case r.key != "":
+					m.unionByKey(&merged, item, r.key, tokens)
+				case !contains(&merged, asValue(item.Value)):
+					merged.Children = append(merged.Children, item)
+				}
+			}
+			return &ast.Value{Value: &merged}
+		}
+	}
+	return src
+}
+
+// mergeObjects merges objects d and s key by key. Keys keep the order
+// of d, followed by the keys only in s.
+func (m *merger) mergeObjects(d, s *ast.Object, tokens []string) *ast.Object {
+	var keys []string
+	seen := map[string]bool{}
+	for _, obj := range []*ast.Object{d, s} {
+		for _, key := range obj.Keys() {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+
+	merged := ast.Object{Children: make([]ast.Property, 0, len(keys))}
+	for _, key := range keys {
+		dv, _ := d.Get(key)
+		sv, _ := s.Get(key)
+		v := m.merge(dv, sv, append(tokens[:len(tokens):len(tokens)], key))
+		merged.Children = append(merged.Children, ast.Property{Identifier: ast.Identifier{Value: key}, Value: v})
+	}
+	return &merged
+}
+
+// unionByKey merges item into the item of array having the same
+// value of property key, or appends it when there is none.
+func (m *merger) unionByKey(array *ast.Array, item ast.ArrayItem, key string, tokens []string) {
+	v := asValue(item.Value)
+	if id, ok := property(v, key); ok {
+		for i := range array.Children {
+			existing := asValue(array.Children[i].Value)
+			if other, ok := property(existing, key); ok && diff.Equal(id, other) {
+				array.Children[i].Value = m.merge(existing, v, append(tokens[:len(tokens):len(tokens)], strconv.Itoa(i)))
+				return
+			}
+		}
+	}
+	array.Children = append(array.Children, item)
+}
+
+// contains reports whether array has an item equal to v.
+func contains(array *ast.Array, v *ast.Value) bool {
+	for other := range array.Values() {
+		if diff.Equal(v, other) {
+			return true
+		}
+	}
+	return false
+}
+
+// property returns the value of property key of object v.
+func property(v *ast.Value, key string) (*ast.Value, bool) {
+	obj, ok := unwrap(v).(*ast.Object)
+	if !ok {
+		return nil, false
+	}
+	return obj.Get(key)
+}
+
+// asValue returns node as a *Value, wrapping bare nodes of array items.
+func asValue(node any) *ast.Value {
+	if v, ok := node.(*ast.Value); ok {
+		return v
+	}
+	return &ast.Value{Value: node}
+}
@@ -0,0 +1,38 @@
+package merge
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/pohedev/gj.git/ast"
+	"github.com/pohedev/gj.git/lexer"
+	"github.com/pohedev/gj.git/parser"
+)
+
+// Files merges the JSON documents in the files at paths left to right
+// with Deep, for layered configuration such as a base file followed by
+// environment and local overrides. Files are read and parsed one at a
+// time, each merged before the next is read, so that only the result and
+// one document are held at once. It returns nil when paths is empty.
+func Files(paths []string, opts ...Option) (*ast.RootNode, error) {
+	m := merger{}
+	for _, opt := range opts {
+		opt(&m)
+	}
+	if m.err != nil {
+		return nil, m.err
+	}
+	var merged *ast.Value
+	for _, p := range paths {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return nil, fmt.Errorf("failed to merge: %w", err)
+		}
+		root, err := parser.New(lexer.Lex(string(data))).Parse()
+		if err != nil {
+			return nil, fmt.Errorf("failed to merge %s: %w", p, err)
+		}
+		merged = m.merge(merged, root.Value, nil)
+	}
+	return newRoot(merged), nil
+}
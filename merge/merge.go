@@ -0,0 +1,57 @@
+// Package merge combines JSON documents.
+package merge
+
+import (
+	"github.com/pohedev/gj.git/ast"
+	"github.com/pohedev/gj.git/diff"
+	"github.com/pohedev/gj.git/path"
+)
+
+// unwrap returns the node held by v.
+func unwrap(v *ast.Value) any {
+	for v != nil {
+		if inner, ok := v.Value.(*ast.Value); ok {
+			v = inner
+			continue
+		}
+		return v.Value
+	}
+	return nil
+}
+
+// rootValue returns the value of root, nil when root is nil.
+func rootValue(root *ast.RootNode) *ast.Value {
+	if root == nil {
+		return nil
+	}
+	return root.Value
+}
+
+// newRoot returns a RootNode holding v, nil when v is nil.
+func newRoot(v *ast.Value) *ast.RootNode {
+	if v == nil {
+		return nil
+	}
+	root := ast.RootNode{RootNodeType: ast.RootNodeTypeLiteral, Value: v}
+	switch unwrap(v).(type) {
+	case *ast.Object:
+		root.RootNodeType = ast.RootNodeTypeObject
+	case *ast.Array:
+		root.RootNodeType = ast.RootNodeTypeArray
+	}
+	return &root
+}
+
+// equal reports whether a and b hold the same value,
+// nil meaning absent.
+func equal(a, b *ast.Value) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return diff.Equal(a, b)
+}
+
+// appendPointer appends token to JSON Pointer ptr.
+func appendPointer(ptr, token string) string {
+	return ptr + "/" + path.Escape(token)
+}
@@ -0,0 +1,236 @@
+package merge_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pohedev/gj.git/ast"
+	"github.com/pohedev/gj.git/gjtest"
+	"github.com/pohedev/gj.git/merge"
+	"github.com/pohedev/gj.git/printer"
+	"github.com/stretchr/testify/assert"
+)
+
+// sprint prints root compactly or fails the test.
+func sprint(t *testing.T, root *ast.RootNode) string {
+	t.Helper()
+	if root == nil {
+		return ""
+	}
+	s, err := printer.Sprint(root.Value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestThreeWay(t *testing.T) {
+	var tests = []struct {
+		name               string
+		base, ours, theirs string
+		want               string
+		conflicts          []string
+	}{
+		{
+			name: "one side changed",
+			base: `{"a": 1, "b": 2}`, ours: `{"a": 1, "b": 2}`, theirs: `{"a": 1, "b": 3}`,
+			want: `{"a":1,"b":3}`,
+		},
+		{
+			name: "both sides changed different keys",
+			base: `{"a": 1, "b": 2, "c": 3}`, ours: `{"a": 10, "b": 2}`, theirs: `{"a": 1, "b": 20, "c": 3, "d": 4}`,
+			want: `{"a":10,"b":20,"d":4}`,
+		},
+		{
+			name: "same change",
+			base: `{"a": 1}`, ours: `{"a": 2}`, theirs: `{"a": 2.0}`,
+			want: `{"a":2}`,
+		},
+		{
+			name: "nested objects",
+			base: `{"db": {"host": "x", "port": 1}}`, ours: `{"db": {"host": "y", "port": 1}}`, theirs: `{"db": {"host": "x", "port": 2}}`,
+			want: `{"db":{"host":"y","port":2}}`,
+		},
+		{
+			name: "arrays by index",
+			base: `[1, 2, 3]`, ours: `[0, 2, 3]`, theirs: `[1, 2, 4]`,
+			want: `[0,2,4]`,
+		},
+		{
+			name: "conflicting values",
+			base: `{"a": 1, "b": [1]}`, ours: `{"a": 2, "b": [1, 2]}`, theirs: `{"a": 3, "b": []}`,
+			want:      `{"a":2,"b":[1,2]}`,
+			conflicts: []string{"/a", "/b"},
+		},
+		{
+			name: "removed and changed",
+			base: `{"a": 1}`, ours: `{}`, theirs: `{"a": 2}`,
+			want:      `{}`,
+			conflicts: []string{"/a"},
+		},
+		{
+			name: "added on both sides",
+			base: `{}`, ours: `{"a": {"x": 1}}`, theirs: `{"a": {"y": 2}}`,
+			want: `{"a":{"x":1,"y":2}}`,
+		},
+		{
+			name: "root conflict",
+			base: `1`, ours: `2`, theirs: `"x"`,
+			want:      `2`,
+			conflicts: []string{""},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged, conflicts := merge.ThreeWay(gjtest.Parse(t, tt.base), gjtest.Parse(t, tt.ours), gjtest.Parse(t, tt.theirs))
+			assert.Equal(t, tt.want, sprint(t, merged))
+			var paths []string
+			for _, c := range conflicts {
+				paths = append(paths, c.Path)
+			}
+			assert.Equal(t, tt.conflicts, paths)
+		})
+	}
+
+	t.Run("conflict values", func(t *testing.T) {
+		_, conflicts := merge.ThreeWay(gjtest.Parse(t, `{"a": 1}`), gjtest.Parse(t, `{}`), gjtest.Parse(t, `{"a": 2}`))
+		if assert.Len(t, conflicts, 1) {
+			assert.Equal(t, int64(1), conflicts[0].Base.ToGo())
+			assert.Nil(t, conflicts[0].Ours)
+			assert.Equal(t, int64(2), conflicts[0].Theirs.ToGo())
+		}
+	})
+
+	t.Run("root type", func(t *testing.T) {
+		merged, _ := merge.ThreeWay(gjtest.Parse(t, `{}`), gjtest.Parse(t, `{}`), gjtest.Parse(t, `[1]`))
+		assert.Equal(t, ast.RootNodeTypeArray, merged.RootNodeType)
+	})
+}
+
+func TestDeep(t *testing.T) {
+	var tests = []struct {
+		name     string
+		dst, src string
+		opts     []merge.Option
+		want     string
+	}{
+		{
+			name: "recurse objects",
+			dst:  `{"db": {"host": "x", "port": 1}, "debug": false}`,
+			src:  `{"db": {"port": 2, "user": "u"}, "debug": true}`,
+			want: `{"db":{"host":"x","port":2,"user":"u"},"debug":true}`,
+		},
+		{
+			name: "arrays replaced by default",
+			dst:  `{"tags": ["a", "b"]}`,
+			src:  `{"tags": ["c"]}`,
+			want: `{"tags":["c"]}`,
+		},
+		{
+			name: "replace object",
+			dst:  `{"db": {"host": "x", "port": 1}}`,
+			src:  `{"db": {"port": 2}}`,
+			opts: []merge.Option{merge.At("/db", merge.Replace)},
+			want: `{"db":{"port":2}}`,
+		},
+		{
+			name: "append",
+			dst:  `{"tags": ["a", "b"]}`,
+			src:  `{"tags": ["b", "c"]}`,
+			opts: []merge.Option{merge.At("$.tags", merge.Append)},
+			want: `{"tags":["a","b","b","c"]}`,
+		},
+		{
+			name: "union",
+			dst:  `{"tags": ["a", "b"]}`,
+			src:  `{"tags": ["b", "c", "c"]}`,
+			opts: []merge.Option{merge.At("$.tags", merge.Union)},
+			want: `{"tags":["a","b","c"]}`,
+		},
+		{
+			name: "union by key",
+			dst:  `{"services": [{"name": "api", "port": 80, "tls": false}, {"name": "db", "port": 5432}]}`,
+			src:  `{"services": [{"name": "api", "tls": true}, {"name": "cache", "port": 6379}]}`,
+			opts: []merge.Option{merge.UnionBy("$.services", "name")},
+			want: `{"services":[{"name":"api","port":80,"tls":true},{"name":"db","port":5432},{"name":"cache","port":6379}]}`,
+		},
+		{
+			name: "wildcard pattern",
+			dst:  `{"a": {"l": [1]}, "b": {"l": [2]}}`,
+			src:  `{"a": {"l": [3]}, "b": {"l": [4]}}`,
+			opts: []merge.Option{merge.At("$.*.l", merge.Append)},
+			want: `{"a":{"l":[1,3]},"b":{"l":[2,4]}}`,
+		},
+		{
+			name: "last pattern wins",
+			dst:  `{"l": [1]}`,
+			src:  `{"l": [2]}`,
+			opts: []merge.Option{merge.At("/l", merge.Append), merge.At("/l", merge.Replace)},
+			want: `{"l":[2]}`,
+		},
+		{
+			name: "mismatched types",
+			dst:  `{"a": {"b": 1}}`,
+			src:  `{"a": [1]}`,
+			opts: []merge.Option{merge.At("/a", merge.Append)},
+			want: `{"a":[1]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst, src := gjtest.Parse(t, tt.dst), gjtest.Parse(t, tt.src)
+			before := sprint(t, dst)
+			merged, err := merge.Deep(dst, src, tt.opts...)
+			if assert.Nil(t, err) {
+				assert.Equal(t, tt.want, sprint(t, merged))
+			}
+			assert.Equal(t, before, sprint(t, dst), "dst was modified")
+		})
+	}
+
+	t.Run("invalid pattern", func(t *testing.T) {
+		_, err := merge.Deep(gjtest.Parse(t, `{}`), gjtest.Parse(t, `{}`), merge.At("$.a[", merge.Replace))
+		assert.Error(t, err)
+	})
+}
+
+func TestFiles(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"base.json":  `{"db": {"host": "localhost", "port": 5432}, "plugins": ["auth"], "debug": false}`,
+		"prod.json":  `{"db": {"host": "db.internal"}, "plugins": ["metrics"]}`,
+		"local.json": `{"debug": true}`,
+	}
+	var paths []string
+	for _, name := range []string{"base.json", "prod.json", "local.json"} {
+		p := filepath.Join(dir, name)
+		if err := os.WriteFile(p, []byte(files[name]), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, p)
+	}
+
+	merged, err := merge.Files(paths, merge.At("$.plugins", merge.Append))
+	if assert.Nil(t, err) {
+		assert.Equal(t, `{"db":{"host":"db.internal","port":5432},"plugins":["auth","metrics"],"debug":true}`, sprint(t, merged))
+	}
+	merged, err = merge.Files(paths[:1])
+	if assert.Nil(t, err) {
+		assert.Equal(t, ast.RootNodeTypeObject, merged.RootNodeType)
+	}
+	merged, err = merge.Files(nil)
+	assert.Nil(t, err)
+	assert.Nil(t, merged)
+
+	invalid := filepath.Join(dir, "invalid.json")
+	if err := os.WriteFile(invalid, []byte(`{"a": }`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	_, err = merge.Files([]string{paths[0], invalid})
+	assert.ErrorContains(t, err, "failed to merge "+invalid+": ")
+	_, err = merge.Files([]string{filepath.Join(dir, "missing.json")})
+	assert.ErrorIs(t, err, os.ErrNotExist)
+	_, err = merge.Files(paths, merge.At("$.a[", merge.Replace))
+	assert.Error(t, err)
+}
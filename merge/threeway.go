@@ -0,0 +1,100 @@
+package merge
+
+import (
+	"strconv"
+
+	"github.com/pohedev/gj.git/ast"
+)
+
+// Conflict is a value changed differently on both sides of a three-way merge.
+type Conflict struct {
+	Path   string     // JSON Pointer of the value.
+	Base   *ast.Value // Value in the common ancestor; nil if absent.
+	Ours   *ast.Value // Value on our side; nil if absent or removed.
+	Theirs *ast.Value // Value on their side; nil if absent or removed.
+}
+
+// ThreeWay merges the changes made from base to ours and from base to
+// theirs, the way git merges files. A value changed on one side only takes
+// the changed value. Objects changed on both sides are merged by key, and
+// arrays by index when they kept the same length; any other value changed
+// differently on both sides is a Conflict, for which the merged tree holds
+// our value. The merged tree shares unchanged values with the inputs; it
+// is nil when the root value was removed.
+func ThreeWay(base, ours, theirs *ast.RootNode) (*ast.RootNode, []Conflict) {
+	var conflicts []Conflict
+	v := threeWay(rootValue(base), rootValue(ours), rootValue(theirs), "", &conflicts)
+	return newRoot(v), conflicts
+}
+
+// threeWay returns the merge of the values at ptr, nil for absent.
+func threeWay(base, ours, theirs *ast.Value, ptr string, conflicts *[]Conflict) *ast.Value {
+	switch {
+	case equal(ours, theirs), equal(base, theirs):
+		return ours
+	case equal(base, ours):
+		return theirs
+	}
+
+	switch o := unwrap(ours).(type) {
+	case *ast.Object:
+		if t, ok := unwrap(theirs).(*ast.Object); ok {
+			b, _ := unwrap(base).(*ast.Object)
+			return &ast.Value{Value: threeWayObjects(b, o, t, ptr, conflicts)}
+		}
+	case *ast.Array:
+		t, ok := unwrap(theirs).(*ast.Array)
+		b, baseOK := unwrap(base).(*ast.Array)
+		if ok && baseOK && b.Len() == o.Len() && b.Len() == t.Len() {
+			return &ast.Value{Value: threeWayArrays(b, o, t, ptr, conflicts)}
+		}
+	}
+
+	*conflicts = append(*conflicts, Conflict{Path: ptr, Base: base, Ours: ours, Theirs: theirs})
+	return ours
+}
+
+// threeWayObjects merges objects o and t by key, b being nil when the
+// object was added on both sides. Keys keep our order, followed by
+// the keys added on their side.
+func threeWayObjects(b, o, t *ast.Object, ptr string, conflicts *[]Conflict) *ast.Object {
+	var keys []string
+	seen := map[string]bool{}
+	for _, obj := range []*ast.Object{o, t, b} {
+		if obj == nil {
+			continue
+		}
+		for _, key := range obj.Keys() {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+
+	merged := ast.Object{}
+	for _, key := range keys {
+		var bv *ast.Value
+		if b != nil {
+			bv, _ = b.Get(key)
+		}
+		ov, _ := o.Get(key)
+		tv, _ := t.Get(key)
+		if v := threeWay(bv, ov, tv, appendPointer(ptr, key), conflicts); v != nil {
+			merged.Children = append(merged.Children, ast.Property{Identifier: ast.Identifier{Value: key}, Value: v})
+		}
+	}
+	return &merged
+}
+
+// threeWayArrays merges arrays b, o and t of the same length by index.
+func threeWayArrays(b, o, t *ast.Array, ptr string, conflicts *[]Conflict) *ast.Array {
+	merged := ast.Array{Children: make([]ast.ArrayItem, b.Len())}
+	for i := range merged.Children {
+		bv, _ := b.At(i)
+		ov, _ := o.At(i)
+		tv, _ := t.At(i)
+		merged.Children[i].Value = threeWay(bv, ov, tv, appendPointer(ptr, strconv.Itoa(i)), conflicts)
+	}
+	return &merged
+}
@@ -0,0 +1,35 @@
+package gj
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Number is the source text of a JSON number, produced by decoding into
+// interface values with UseNumber so that numbers beyond the precision
+// of int64 and float64 survive decoding. Struct fields of type Number
+// always receive the source text.
+type Number string
+
+// String returns the source text of the number.
+func (n Number) String() string {
+	return string(n)
+}
+
+// Int64 returns the number as an int64.
+func (n Number) Int64() (int64, error) {
+	i, err := strconv.ParseInt(string(n), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to convert number %s to int64: %w", n, err.(*strconv.NumError).Err)
+	}
+	return i, nil
+}
+
+// Float64 returns the number as a float64.
+func (n Number) Float64() (float64, error) {
+	f, err := strconv.ParseFloat(string(n), 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to convert number %s to float64: %w", n, err.(*strconv.NumError).Err)
+	}
+	return f, nil
+}
@@ -0,0 +1,119 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/pohedev/gj.git/ast"
+)
+
+// DuplicateKeys is a policy resolving the keys appearing more than once
+// in an object, see OnDuplicateKeys.
+type DuplicateKeys int
+
+const (
+	KeepAll       DuplicateKeys = iota // Every property is kept, the default; lookups find the last one.
+	KeepFirst                          // The first property is kept, later ones are dropped.
+	KeepLast                           // The last value is kept, at the position of the first property.
+	MergeObjects                       // Objects are merged key by key, later values win; other values as with KeepLast.
+	CollectValues                      // The values are collected into an array, at the position of the first property.
+)
+
+// keySet tracks the keys of an object being parsed, to detect and
+// resolve duplicate keys.
+type keySet struct {
+	pos       map[string]int // Position in Children of the property of each key.
+	collected map[int]bool   // Positions of the arrays made by CollectValues.
+}
+
+// resolveDuplicate resolves prop, whose key is held by the property of obj
+// at position i, with the DuplicateKeys policy of the Parser.
+func (p *Parser) resolveDuplicate(obj *ast.Object, keys *keySet, i int, prop *ast.Property) error {
+	first := &obj.Children[i]
+	switch p.duplicateKeys {
+	case KeepLast:
+		first.Value = prop.Value
+	case MergeObjects:
+		merged, err := p.mergeValues(first.Value.(*ast.Value), prop.Value.(*ast.Value))
+		if err != nil {
+			return err
+		}
+		first.Value = merged
+	case CollectValues:
+		if !keys.collected[i] {
+			if keys.collected == nil {
+				keys.collected = map[int]bool{}
+			}
+			keys.collected[i] = true
+			array := p.newArray()
+			array.Children = []ast.ArrayItem{{Value: first.Value.(*ast.Value).Value}}
+			value := p.newValue()
+			value.Value = array
+			first.Value = value
+		}
+		array := first.Value.(*ast.Value).Value.(*ast.Array)
+		array.Children = append(array.Children, ast.ArrayItem{Value: prop.Value.(*ast.Value).Value})
+	}
+	return nil
+}
+
+// mergeValues merges src into dst when both are objects, recursively,
+// and returns dst; otherwise it returns src. The properties of src missing
+// from dst are appended to it. Objects kept as RawValue by lazy parsing
+// are parsed first, so that they are merged as well.
+func (p *Parser) mergeValues(dst, src *ast.Value) (*ast.Value, error) {
+	if !isObject(dst.Value) || !isObject(src.Value) {
+		return src, nil
+	}
+	d, err := p.object(dst.Value)
+	if err != nil {
+		return nil, err
+	}
+	s, err := p.object(src.Value)
+	if err != nil {
+		return nil, err
+	}
+	dst.Value = d
+	index := d.Index()
+	for _, prop := range s.Children {
+		if j, ok := index[prop.Identifier.Value]; ok {
+			merged, err := p.mergeValues(d.Children[j].Value.(*ast.Value), prop.Value.(*ast.Value))
+			if err != nil {
+				return nil, err
+			}
+			d.Children[j].Value = merged
+		} else {
+			d.Children = append(d.Children, prop)
+		}
+	}
+	d.Reindex()
+	return dst, nil
+}
+
+// isObject reports whether node is an object, parsed or kept as RawValue.
+func isObject(node any) bool {
+	switch n := node.(type) {
+	case *ast.Object:
+		return true
+	case *ast.RawValue:
+		return strings.HasPrefix(n.Raw, "{")
+	}
+	return false
+}
+
+// object returns the object node, parsing it with the duplicate keys
+// policy of the Parser when kept as RawValue.
+func (p *Parser) object(node any) (*ast.Object, error) {
+	raw, ok := node.(*ast.RawValue)
+	if !ok {
+		return node.(*ast.Object), nil
+	}
+	opts := []Option{OnDuplicateKeys(p.duplicateKeys)}
+	if p.lossless {
+		opts = append(opts, Lossless())
+	}
+	v, err := ParseRaw(raw, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return v.Value.(*ast.Object), nil
+}
@@ -0,0 +1,96 @@
+package parser
+
+import (
+	"errors"
+	"unicode/utf8"
+
+	"github.com/pohedev/gj.git/ast"
+	"github.com/pohedev/gj.git/internal/jsonstr"
+	"github.com/pohedev/gj.git/lexer"
+)
+
+// Embedded is a document parsed from the value of a string literal, such
+// as the stringified JSON in {"payload": "{\"a\":1}"}, see ParseEmbedded.
+type Embedded struct {
+	// Root is the parsed document. Its positions are byte offsets in the
+	// value of the string, see Outer.
+	Root *ast.RootNode
+
+	raw   string
+	outer []int // Offsets in raw of the bytes of the value, nil if the same.
+}
+
+// ParseEmbedded parses the value of the string literal lit as a document.
+// Positions in the returned tree, and the offsets of syntax and limit
+// errors, are offsets in the value of the string; Outer maps them back to
+// the source of lit.
+func ParseEmbedded(lit *ast.Literal, opts ...Option) (*Embedded, error) {
+	s, ok := lit.Val.(string)
+	if lit.LiteralType != ast.LiteralTypeString || !ok {
+		return nil, errors.New("failed to parse embedded document: literal is not a string")
+	}
+	e := &Embedded{raw: lit.Raw}
+	if e.raw == "" {
+		e.raw = `"` + s + `"`
+	}
+	if e.raw[1:len(e.raw)-1] != s {
+		e.outer = valueOffsets(e.raw, len(s))
+	}
+	root, err := New(lexer.Lex(s), opts...).Parse()
+	if err != nil {
+		return nil, e.mapError(err)
+	}
+	e.Root = root
+	return e, nil
+}
+
+// Outer returns the byte offset in the source of the literal, its opening
+// quote at 0, of the byte at offset in the value of the string. Adding
+// the offset of the literal in its own document, such as the Start of its
+// span in gj.SourceMap, gives the offset in that document.
+func (e *Embedded) Outer(offset int) int {
+	if e.outer == nil {
+		return max(0, min(offset, len(e.raw)-2)) + 1
+	}
+	return e.outer[max(0, min(offset, len(e.outer)-1))]
+}
+
+// mapError returns err with its offsets mapped by Outer.
+func (e *Embedded) mapError(err error) error {
+	switch err := err.(type) {
+	case *SyntaxError:
+		err.Offset, err.End = e.Outer(err.Offset), e.Outer(err.End)
+	case *LimitError:
+		err.Offset = e.Outer(err.Offset)
+	case ErrorList:
+		for _, err := range err {
+			e.mapError(err)
+		}
+	}
+	return err
+}
+
+// valueOffsets returns the offset in raw, a valid string literal, of each
+// of the n bytes of its value, and of its closing quote at index n. The
+// bytes of a character written as an escape sequence map to its start.
+func valueOffsets(raw string, n int) []int {
+	offsets := make([]int, 0, n+1)
+	for i := 1; i < len(raw)-1; {
+		r, size := utf8.DecodeRuneInString(raw[i:])
+		if r == '\\' {
+			r, size = jsonstr.DecodeEscape(raw[i : len(raw)-1])
+		}
+		for range utf8.RuneLen(r) {
+			offsets = append(offsets, i)
+		}
+		i += size
+	}
+	if len(offsets) != n {
+		// The value is not the decoded raw, as kept by AllowInvalidEscapes.
+		offsets = offsets[:0]
+		for i := range n {
+			offsets = append(offsets, min(i+1, len(raw)-1))
+		}
+	}
+	return append(offsets, len(raw)-1)
+}
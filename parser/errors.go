@@ -0,0 +1,128 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/pohedev/gj.git/internal/jsonscan"
+	"github.com/pohedev/gj.git/lexer"
+)
+
+// Kinds of errors returned by the Parser, to be matched with errors.Is.
+var (
+	ErrUnexpectedEOF      = jsonscan.ErrUnexpectedEOF
+	ErrUnexpectedToken    = jsonscan.ErrUnexpectedToken
+	ErrInvalidNumber      = lexer.ErrInvalidNumber
+	ErrUnterminatedString = lexer.ErrUnterminatedString
+	ErrInvalidLiteral     = lexer.ErrInvalidLiteral
+	ErrInvalidString      = jsonscan.ErrInvalidString
+	ErrTrailingComma      = jsonscan.ErrTrailingComma
+	ErrTrailingContent    = jsonscan.ErrTrailingContent
+	ErrDuplicateKey       = errors.New("duplicate key")
+	ErrMaxBytes           = errors.New("input size limit exceeded")
+	ErrMaxTokens          = errors.New("token count limit exceeded")
+	ErrMaxStringLength    = errors.New("string length limit exceeded")
+	ErrMaxChildren        = errors.New("children limit exceeded")
+	ErrMaxDepth           = errors.New("nesting depth limit exceeded")
+	ErrNotFound           = errors.New("value not found")
+)
+
+// SyntaxError represents a syntax error at a byte offset of the input.
+type SyntaxError struct {
+	Msg    string // Description of the error.
+	Offset int    // Byte offset in the input where the error occurred.
+	End    int    // Byte offset just after the offending token, or Offset if unknown.
+	Err    error  // Kind of the error, one of the Err variables.
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("failed to parse: %s at offset %d", e.Msg, e.Offset)
+}
+
+func (e *SyntaxError) Unwrap() error {
+	return e.Err
+}
+
+// Limit identifies a limit configured on the Parser.
+type Limit int
+
+const (
+	LimitBytes        Limit = iota + 1 // MaxBytes
+	LimitTokens                        // MaxTokens
+	LimitStringLength                  // MaxStringLength
+	LimitChildren                      // MaxChildren
+	LimitDepth                         // MaxDepth
+)
+
+var limitErrors = map[Limit]error{
+	LimitBytes:        ErrMaxBytes,
+	LimitTokens:       ErrMaxTokens,
+	LimitStringLength: ErrMaxStringLength,
+	LimitChildren:     ErrMaxChildren,
+	LimitDepth:        ErrMaxDepth,
+}
+
+var limitNames = map[Limit]string{
+	LimitBytes:        "input size",
+	LimitTokens:       "token count",
+	LimitStringLength: "string length",
+	LimitChildren:     "number of children",
+	LimitDepth:        "nesting depth",
+}
+
+func (l Limit) String() string {
+	return limitNames[l]
+}
+
+// LimitError is returned when the input exceeds a limit configured
+// on the Parser.
+type LimitError struct {
+	Limit  Limit // Exceeded limit.
+	Max    int   // Configured maximum.
+	Offset int   // Byte offset in the input where the limit was exceeded.
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("failed to parse: %v exceeds limit of %d at offset %d", e.Limit, e.Max, e.Offset)
+}
+
+// Unwrap returns the Err variable of the exceeded limit, e.g. ErrMaxDepth.
+func (e *LimitError) Unwrap() error {
+	return limitErrors[e.Limit]
+}
+
+// DuplicateKeyError is returned when an object has the same key more than once
+// and duplicate keys are disallowed.
+type DuplicateKeyError struct {
+	Key    string // Duplicated key.
+	Offset int    // Byte offset of the duplicated key.
+}
+
+func (e *DuplicateKeyError) Error() string {
+	return fmt.Sprintf("failed to parse: duplicate key %q at offset %d", e.Key, e.Offset)
+}
+
+func (e *DuplicateKeyError) Unwrap() error {
+	return ErrDuplicateKey
+}
+
+// ErrorList is returned by a Parser with Recover, holding all the errors
+// found in the input in order: SyntaxErrors and DuplicateKeyErrors. It is
+// also used by other packages reporting several errors at once, such as
+// edit.Transaction.Commit.
+type ErrorList []error
+
+func (l ErrorList) Error() string {
+	switch len(l) {
+	case 0:
+		return "no errors"
+	case 1:
+		return l[0].Error()
+	}
+	return fmt.Sprintf("%s (and %d more errors)", l[0], len(l)-1)
+}
+
+// Unwrap returns the errors of the list, for errors.Is and errors.As.
+func (l ErrorList) Unwrap() []error {
+	return l
+}
@@ -0,0 +1,180 @@
+package parser
+
+import "github.com/pohedev/gj.git/ast"
+
+// Option configures a Parser.
+type Option func(*Parser)
+
+// AllowInvalidEscapes makes the Parser keep the raw text between the quotes
+// of strings that contain invalid escape sequences instead of failing.
+func AllowInvalidEscapes() Option {
+	return func(p *Parser) {
+		p.allowInvalidEscapes = true
+	}
+}
+
+// AllowTrailing makes the Parser accept content after the root value,
+// which is left unconsumed for the next call of Parse.
+func AllowTrailing() Option {
+	return func(p *Parser) {
+		p.allowTrailing = true
+	}
+}
+
+// MaxBytes limits the number of input bytes consumed by the Parser.
+// Zero means no limit.
+func MaxBytes(n int) Option {
+	return func(p *Parser) {
+		p.maxBytes = n
+	}
+}
+
+// MaxTokens limits the number of tokens read by the Parser.
+// Zero means no limit.
+func MaxTokens(n int) Option {
+	return func(p *Parser) {
+		p.maxTokens = n
+	}
+}
+
+// MaxStringLength limits the length in bytes of a single string
+// or property key, quotes excluded. Zero means no limit.
+func MaxStringLength(n int) Option {
+	return func(p *Parser) {
+		p.maxStringLength = n
+	}
+}
+
+// MaxChildren limits the number of properties of an object
+// and items of an array. Zero means no limit.
+func MaxChildren(n int) Option {
+	return func(p *Parser) {
+		p.maxChildren = n
+	}
+}
+
+// MaxDepth limits the nesting depth of objects and arrays.
+// Zero means no limit.
+func MaxDepth(n int) Option {
+	return func(p *Parser) {
+		p.maxDepth = n
+	}
+}
+
+// DisallowDuplicateKeys makes the Parser fail on objects
+// having the same key more than once.
+func DisallowDuplicateKeys() Option {
+	return func(p *Parser) {
+		p.disallowDuplicateKeys = true
+	}
+}
+
+// OnDuplicateKeys makes the Parser resolve the keys appearing more than
+// once in an object with policy d, so that each key of the parsed objects
+// holds one value. Systems disagree on the meaning of duplicate keys; the
+// policy picks the one of the system producing the input. With
+// DisallowDuplicateKeys, duplicates are errors and only resolved when
+// recovering from errors.
+func OnDuplicateKeys(d DuplicateKeys) Option {
+	return func(p *Parser) {
+		p.duplicateKeys = d
+	}
+}
+
+// Lazy makes the Parser keep objects and arrays for which fn reports true
+// as *ast.RawValue nodes holding their source text, to be parsed on demand
+// with ParseRaw. fn receives the reference tokens of the value location
+// and must not retain them. Raw values are only checked for balanced
+// brackets and valid tokens.
+func Lazy(fn func(path []string) bool) Option {
+	return func(p *Parser) {
+		p.lazy = fn
+		p.trackPath = true
+	}
+}
+
+// LazyBelow makes the Parser keep objects and arrays nested deeper than
+// depth as *ast.RawValue nodes, the root value being at depth 0.
+func LazyBelow(depth int) Option {
+	return Lazy(func(path []string) bool {
+		return len(path) > depth
+	})
+}
+
+// Recover makes the Parser go on after syntax errors, skipping
+// or completing the offending input, to report all the errors at once.
+// Parse then returns the most complete AST it could build along with
+// an ErrorList. Limits configured on the Parser still stop it.
+func Recover() Option {
+	return func(p *Parser) {
+		p.recover = true
+	}
+}
+
+// Defaults applied by Hardened.
+const (
+	HardenedMaxBytes        = 10 << 20 // 10 MiB
+	HardenedMaxTokens       = 1 << 20
+	HardenedMaxStringLength = 1 << 20 // 1 MiB
+	HardenedMaxChildren     = 1 << 16
+	HardenedMaxDepth        = 64
+)
+
+// Hardened enables all limits with the Hardened* defaults and disallows
+// duplicate keys, for parsing untrusted input. Options given after Hardened
+// override its defaults, e.g. New(lex, Hardened(), MaxBytes(1<<10)).
+func Hardened() Option {
+	return func(p *Parser) {
+		for _, opt := range []Option{
+			MaxBytes(HardenedMaxBytes),
+			MaxTokens(HardenedMaxTokens),
+			MaxStringLength(HardenedMaxStringLength),
+			MaxChildren(HardenedMaxChildren),
+			MaxDepth(HardenedMaxDepth),
+			DisallowDuplicateKeys(),
+		} {
+			opt(p)
+		}
+	}
+}
+
+// Lossless makes the Parser record the trivia of documents, the source
+// text between their tokens, along with the source text of keys, so that
+// printing them with printer.Lossless reproduces the input byte for byte.
+// Edited nodes are printed normally while the trivia of the others is
+// kept, which suits automated edits of configuration files. Strings and
+// numbers keep their source text in any case. Lossless has no effect on
+// Parsers reading from an io.Reader.
+func Lossless() Option {
+	return func(p *Parser) {
+		p.lossless = true
+	}
+}
+
+// WithArena makes the Parser allocate the nodes of the documents it
+// parses from a, which can then release them all at once with Reset.
+// The documents must not be used after a is reset.
+func WithArena(a *ast.Arena) Option {
+	return func(p *Parser) {
+		p.arena = a
+	}
+}
+
+// WithStats makes the Parser call fn after each Parse with the statistics
+// of the parse and its error, if any, so that services can export metrics
+// and spot pathological payloads, such as deeply nested or token-heavy
+// documents, including those rejected by limits.
+func WithStats(fn func(Stats, error)) Option {
+	return func(p *Parser) {
+		p.stats = fn
+	}
+}
+
+// WithRegistry makes the Parser run the recognizers of r on the literals
+// it parses, recording the kind and typed value of recognized ones in
+// their Kind and Typed fields.
+func WithRegistry(r *Registry) Option {
+	return func(p *Parser) {
+		p.registry = r
+	}
+}
@@ -1,11 +1,14 @@
 package parser
 
 import (
-	"errors"
 	"fmt"
+	"io"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/pohedev/gj.git/ast"
+	"github.com/pohedev/gj.git/internal/jsonstr"
 	"github.com/pohedev/gj.git/lexer"
 	"github.com/pohedev/gj.git/token"
 )
@@ -17,14 +20,59 @@ type Parser struct {
 	previous lexer.Item   // Previous Item.
 	current  lexer.Item   // Current Item.
 	peek     lexer.Item   // Peek Item.
+
+	allowInvalidEscapes bool // Keep raw text of strings with invalid escapes.
+	allowTrailing       bool // Allow content after the root value.
+
+	maxBytes        int   // Maximum number of input bytes, 0 for no limit.
+	maxTokens       int   // Maximum number of tokens, 0 for no limit.
+	maxStringLength int   // Maximum length of a string, 0 for no limit.
+	maxChildren     int   // Maximum number of children of a container, 0 for no limit.
+	maxDepth        int   // Maximum nesting depth, 0 for no limit.
+	tokens          int   // Number of tokens read so far.
+	depth           int   // Current nesting depth.
+	err             error // Sticky error set when a limit is exceeded.
+
+	disallowDuplicateKeys bool          // Fail on duplicate keys in objects.
+	duplicateKeys         DuplicateKeys // Resolution of duplicate keys in objects.
+
+	arena *ast.Arena // Allocator of nodes, nil for the heap.
+
+	lossless bool // Record trivia and the source text of keys.
+
+	lazy      func(path []string) bool // Reports values to keep as RawValue.
+	trackPath bool                     // Maintain path.
+	path      []string                 // Reference tokens of the current value.
+	until     []string                 // Reference tokens of the target of ParseUntil, nil if none.
+	found     any                      // Target of ParseUntil once parsed.
+
+	recover    bool      // Keep parsing after syntax errors.
+	errs       ErrorList // Errors recorded so far when recovering.
+	currentErr error     // Kind of the error reported by current, an Error Item.
+	peekErr    error     // Kind of the error reported by peek, an Error Item.
+
+	src io.Reader // Input fed to the Lexer in chunks, nil if given whole.
+	buf []byte    // Buffer for reading src.
+
+	registry *Registry // Recognizers of literals, see WithRegistry.
+
+	stats   func(Stats, error) // Called after each Parse, see WithStats.
+	nodes   int                // Number of nodes built so far.
+	deepest int                // Deepest nesting reached by the current Parse.
 }
 
 // New takes a Lexer and initialize Parser,
 // set current and peek Item,.
-func New(lex *lexer.Lexer) *Parser {
+func New(lex *lexer.Lexer, opts ...Option) *Parser {
 	p := Parser{
 		lex: lex,
 	}
+	for _, opt := range opts {
+		opt(&p)
+	}
+	if p.recover {
+		lexer.ContinueOnError()(p.lex)
+	}
 
 	p.next()
 	p.next()
@@ -32,28 +80,111 @@ func New(lex *lexer.Lexer) *Parser {
 	return &p
 }
 
+// Reset prepares the Parser to parse input, reusing the allocations
+// of the Parser and its Lexer. Options given to New are kept.
+func (p *Parser) Reset(input string) {
+	p.lex.Reset(input)
+	p.reset()
+}
+
+// ResetBytes is like Reset but parses input without copying it.
+// The returned ASTs share the memory of input, which must not be
+// modified while they are in use.
+func (p *Parser) ResetBytes(input []byte) {
+	p.lex.ResetBytes(input)
+	p.reset()
+}
+
+// reset clears the state of the Parser after its Lexer was reset.
+func (p *Parser) reset() {
+	p.previous = lexer.Item{}
+	p.current = lexer.Item{}
+	p.peek = lexer.Item{}
+	p.tokens = 0
+	p.depth = 0
+	p.nodes = 0
+	p.err = nil
+	p.path = p.path[:0]
+	p.errs = nil
+	p.currentErr = nil
+	p.peekErr = nil
+
+	p.next()
+	p.next()
+}
+
 // Parse parses Items and creates an AST.
+// With Recover, it returns the most complete AST it could build
+// along with an ErrorList of all the syntax errors found.
 func (p *Parser) Parse() (*ast.RootNode, error) {
+	if p.stats == nil {
+		return p.parseDocument()
+	}
+	start, offset, tokens, nodes := time.Now(), p.current.Pos, p.tokens-p.pending(), p.nodes
+	root, err := p.parseDocument()
+	p.stats(Stats{
+		Tokens:   p.tokens - p.pending() - tokens,
+		Bytes:    p.current.Pos - offset,
+		Nodes:    p.nodes - nodes,
+		MaxDepth: p.deepest,
+		Duration: time.Since(start),
+	}, err)
+	return root, err
+}
+
+// pending returns the number of tokens read ahead but not parsed yet.
+func (p *Parser) pending() int {
+	n := 0
+	for _, item := range []lexer.Item{p.current, p.peek} {
+		if item.Token != token.EOF {
+			n++
+		}
+	}
+	return n
+}
+
+// parseDocument parses a document for Parse.
+func (p *Parser) parseDocument() (*ast.RootNode, error) {
+	p.errs = nil
+	p.deepest = 0
+	node, err := p.parse()
+	if p.err != nil {
+		return nil, p.err
+	}
+	if err == nil && len(p.errs) > 0 {
+		return node, p.errs
+	}
+	return node, err
+}
+
+// parse parses the root value.
+func (p *Parser) parse() (*ast.RootNode, error) {
 	var node ast.RootNode
 	switch p.current.Token {
 	case token.LeftBrace:
 		node.RootNodeType = ast.RootNodeTypeObject
 	case token.LeftBracket:
 		node.RootNodeType = ast.RootNodeTypeArray
+	case token.String, token.Number, token.True, token.False, token.Null:
+		node.RootNodeType = ast.RootNodeTypeLiteral
 	}
 
 	if err := p.validateStartingSyntax(node); err != nil {
-		return nil, err
+		return nil, p.report(err)
 	}
+	node.Before = p.trivia()
 
 	val, parseErr := p.parseValue()
 	if parseErr != nil {
-		return nil, parseErr
+		return nil, p.report(parseErr)
 	}
 	node.Value = val
+	node.After = p.trivia()
 
 	if err := p.validateClosingSyntax(node); err != nil {
-		return nil, err
+		if err := p.report(err); err != nil {
+			return nil, err
+		}
 	}
 
 	return &node, nil
@@ -70,39 +201,140 @@ func (p *Parser) validateStartingSyntax(n ast.RootNode) error {
 		if p.isCurrentToken(token.LeftBracket) {
 			return nil
 		}
+	case ast.RootNodeTypeLiteral:
+		return nil
 	}
-	return errors.New("failed to parse: missing JSON starting brace or bracket")
+	return p.unexpected("value")
 }
 
-// validateClosingSyntax validate JSON closing syntax.
+// validateClosingSyntax validate JSON closing syntax,
+// nothing but EOF may follow the root value unless trailing content is allowed.
 func (p *Parser) validateClosingSyntax(n ast.RootNode) error {
 	switch n.RootNodeType {
 	case ast.RootNodeTypeObject:
-		if p.isCurrentToken(token.EOF) && p.isPreviousToken(token.RightBrace) {
-			return nil
+		if !p.isPreviousToken(token.RightBrace) {
+			return p.unexpected("'}'")
 		}
 	case ast.RootNodeTypeArray:
-		if p.isCurrentToken(token.EOF) && p.isPreviousToken(token.RightBracket) {
-			return nil
+		if !p.isPreviousToken(token.RightBracket) {
+			return p.unexpected("']'")
 		}
 	}
-	return errors.New("failed to parse: missing JSON closing brace or bracket")
+	if p.isCurrentToken(token.EOF) || p.allowTrailing {
+		return nil
+	}
+	return p.errorAt(p.current, ErrTrailingContent, "unexpected trailing content")
+}
+
+// More reports whether there is more input after the last parsed value.
+// Together with AllowTrailing, it allows Parse to be called repeatedly
+// on a stream of concatenated JSON documents.
+func (p *Parser) More() bool {
+	return !p.isCurrentToken(token.EOF)
 }
 
 // next sets and advance Item which include token.
 // in the process,
 // - set current to previous.
 // - set peek to current.
-// - set returned from p.lex.NextItem() to peek.
+// - set returned from p.readItem() to peek.
 func (p *Parser) next() {
 	p.previous = p.current
 	p.current = p.peek
-	p.peek = p.lex.NextItem()
+	p.currentErr = p.peekErr
+	p.peekErr = nil
+	if p.err != nil || p.isCurrentToken(token.EOF) || p.isCurrentToken(token.Error) && !p.recover {
+		// Lexer has stopped, nothing more to read.
+		p.peek = lexer.Item{Token: token.EOF, Pos: p.current.Pos}
+		return
+	}
+	p.peek = p.readItem()
+	if p.peek.Token == token.Error {
+		// Later Error items overwrite the kind held by the Lexer.
+		p.peekErr = p.lex.Err()
+	}
+	p.checkLimits()
+}
+
+// readItem returns the next Item of the Lexer, feeding it
+// chunks of src when the Parser reads from an io.Reader.
+// A read error is recorded like a LimitError.
+func (p *Parser) readItem() lexer.Item {
+	if p.src == nil {
+		return p.lex.NextItem()
+	}
+	for {
+		if item, ok := p.lex.TryNextItem(); ok {
+			return item
+		}
+		n, err := p.src.Read(p.buf)
+		p.lex.Feed(p.buf[:n])
+		switch {
+		case err == io.EOF:
+			p.lex.End()
+		case err != nil:
+			p.err = fmt.Errorf("failed to read input: %w", err)
+			return lexer.Item{Token: token.EOF, Pos: p.current.Pos}
+		}
+	}
+}
+
+// checkLimits checks the peek Item against the configured limits,
+// and turns it into EOF after recording a LimitError.
+func (p *Parser) checkLimits() {
+	if p.peek.Token != token.EOF {
+		p.tokens++
+	}
+	switch {
+	case p.maxTokens > 0 && p.tokens > p.maxTokens:
+		p.err = &LimitError{Limit: LimitTokens, Max: p.maxTokens, Offset: p.peek.Pos}
+	case p.maxBytes > 0 && p.peek.Pos+len(p.peek.Val) > p.maxBytes:
+		p.err = &LimitError{Limit: LimitBytes, Max: p.maxBytes, Offset: p.maxBytes}
+	case p.maxStringLength > 0 && p.peek.Token == token.String && len(p.peek.Val)-2 > p.maxStringLength:
+		p.err = &LimitError{Limit: LimitStringLength, Max: p.maxStringLength, Offset: p.peek.Pos}
+	default:
+		return
+	}
+	p.peek = lexer.Item{Token: token.EOF, Pos: p.peek.Pos}
+}
+
+// enter increases the nesting depth and returns a LimitError
+// when it exceeds the maximum.
+func (p *Parser) enter() error {
+	p.depth++
+	p.deepest = max(p.deepest, p.depth)
+	if p.maxDepth > 0 && p.depth > p.maxDepth {
+		return &LimitError{Limit: LimitDepth, Max: p.maxDepth, Offset: p.current.Pos}
+	}
+	return nil
+}
+
+// leave decreases the nesting depth.
+func (p *Parser) leave() {
+	p.depth--
+}
+
+// checkChildren returns a LimitError when a container
+// already holds the maximum number of children.
+func (p *Parser) checkChildren(n int) error {
+	if p.maxChildren > 0 && n >= p.maxChildren {
+		return &LimitError{Limit: LimitChildren, Max: p.maxChildren, Offset: p.current.Pos}
+	}
+	return nil
 }
 
 // parseValue is the entry point for parsing JSON values.
 func (p *Parser) parseValue() (*ast.Value, error) {
-	value := ast.Value{}
+	value := p.newValue()
+
+	if p.isLazy() {
+		raw, parseErr := p.parseRaw()
+		if parseErr != nil {
+			return nil, parseErr
+		}
+		value.Value = raw
+		return value, nil
+	}
 
 	switch p.current.Token {
 	case token.LeftBrace:
@@ -127,17 +359,31 @@ func (p *Parser) parseValue() (*ast.Value, error) {
 		value.Value = litValue
 	}
 
-	return &value, nil
+	return value, p.reached(value)
 }
 
 // parseObject parses JSON object.
 func (p *Parser) parseObject() (*ast.Object, error) {
-	obj := ast.Object{}
+	obj := p.newObject()
 	objState := ast.StateObjectStart
 
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer p.leave()
+
+	var keys *keySet
+	if p.disallowDuplicateKeys || p.duplicateKeys != KeepAll {
+		keys = &keySet{pos: map[string]int{}}
+	}
+
 	for {
 		if p.isCurrentToken(token.EOF) {
-			break
+			if err := p.report(p.errorAt(p.current, ErrUnexpectedEOF, "missing closing brace")); err != nil {
+				return nil, err
+			}
+			obj.End = p.current.Pos
+			return obj, nil
 		}
 
 		switch objState {
@@ -147,55 +393,101 @@ func (p *Parser) parseObject() (*ast.Object, error) {
 				objState = ast.StateObjectOpen
 				p.next()
 			} else {
-				return nil, fmt.Errorf(
-					"failed to parse object: expected LeftBrace token but got: %v",
-					p.current.Val,
-				)
+				return nil, p.unexpected("'{'")
 			}
 
 		case ast.StateObjectOpen:
 			if p.isCurrentToken(token.RightBrace) {
+				obj.Trivia = p.trivia()
 				obj.End = p.current.Pos
-				return &obj, nil
+				p.next()
+				return obj, nil
 			}
-			prop, parseErr := p.parseProperty()
-			if parseErr != nil {
-				return nil, parseErr
+			if err := p.addProperty(obj, keys); err != nil {
+				return nil, err
 			}
-			obj.Children = append(obj.Children, *prop)
 			objState = ast.StateObjectProperty
 
 		case ast.StateObjectProperty:
 			if p.isCurrentToken(token.RightBrace) {
+				if n := len(obj.Children); n > 0 {
+					last := &obj.Children[n-1].Trivia
+					last.AfterValue, obj.Trivia = splitTrailing(last.AfterValue)
+				}
 				p.next()
 				obj.End = p.current.Pos
-				return &obj, nil
+				return obj, nil
 			} else if p.isCurrentToken(token.Comma) {
 				objState = ast.StateObjectComma
 				p.next()
 			} else {
-				return nil, fmt.Errorf(
-					"failed to parse property: expected RightBrace or Comma token but got: %v",
-					p.current.Val,
-				)
+				if err := p.report(p.unexpected("',' or '}'")); err != nil {
+					return nil, err
+				}
+				switch p.current.Token {
+				case token.String:
+					// Missing comma, go on with the next property.
+					objState = ast.StateObjectComma
+				case token.RightBracket:
+					// Mismatched closer, left to the enclosing array.
+					obj.End = p.current.Pos
+					return obj, nil
+				default:
+					p.skip()
+				}
 			}
 
 		case ast.StateObjectComma:
 			if p.isCurrentToken(token.RightBrace) {
+				if err := p.report(p.errorAt(p.previous, ErrTrailingComma, "trailing comma in object")); err != nil {
+					return nil, err
+				}
+				p.next()
 				obj.End = p.current.Pos
-				return &obj, nil
+				return obj, nil
 			}
-			prop, parseErr := p.parseProperty()
-			if parseErr != nil {
-				return nil, parseErr
+			if err := p.addProperty(obj, keys); err != nil {
+				return nil, err
 			}
-			obj.Children = append(obj.Children, *prop)
 			objState = ast.StateObjectProperty
 		}
 	}
+}
 
-	obj.End = p.current.Pos
-	return &obj, nil
+// addProperty parses a property and appends it to obj.
+// keys holds the keys seen so far when duplicate keys are
+// disallowed or resolved.
+func (p *Parser) addProperty(obj *ast.Object, keys *keySet) error {
+	if err := p.checkChildren(len(obj.Children)); err != nil {
+		return err
+	}
+	keyPos := p.current.Pos
+	prop, parseErr := p.parseProperty()
+	if parseErr != nil {
+		if err := p.report(parseErr); err != nil {
+			return err
+		}
+		p.skip()
+		return nil
+	}
+	if keys != nil {
+		if i, ok := keys.pos[prop.Identifier.Value]; ok {
+			if p.disallowDuplicateKeys {
+				if err := p.report(&DuplicateKeyError{Key: prop.Identifier.Value, Offset: keyPos}); err != nil {
+					return err
+				}
+			}
+			if p.duplicateKeys != KeepAll {
+				if err := p.resolveDuplicate(obj, keys, i, prop); err != nil {
+					return p.report(err)
+				}
+				return nil
+			}
+		}
+		keys.pos[prop.Identifier.Value] = len(obj.Children)
+	}
+	obj.Children = append(obj.Children, *prop)
+	return nil
 }
 
 // parseProperty parses JSON key value pair property.
@@ -205,57 +497,72 @@ func (p *Parser) parseProperty() (*ast.Property, error) {
 
 	for {
 		if p.isCurrentToken(token.EOF) {
-			break
+			return nil, p.errorAt(p.current, ErrUnexpectedEOF, "unexpected EOF in property")
 		}
 
 		switch propertyState {
 		case ast.StatePropertyStart:
 			if p.isCurrentToken(token.String) {
-				prop.Identifier = ast.Identifier{Value: p.parseString()}
+				key, parseErr := p.parseString()
+				if parseErr != nil {
+					return nil, parseErr
+				}
+				prop.Identifier = ast.Identifier{
+					Value: key,
+					Start: p.current.Pos,
+					End:   p.current.Pos + len(p.current.Val),
+				}
+				if p.lossless {
+					prop.Identifier.Raw = p.current.Val
+					prop.Trivia.BeforeKey = p.trivia()
+				}
 				propertyState = ast.StatePropertyKey
 				p.next()
 			} else {
-				return nil, fmt.Errorf(
-					"failed to parse property start: expected String token but got: %v",
-					p.current.Val,
-				)
+				return nil, p.unexpected("string key")
 			}
 
 		case ast.StatePropertyKey:
 			if p.isCurrentToken(token.Colon) {
+				prop.Trivia.BeforeColon = p.trivia()
 				propertyState = ast.StatePropertyColon
 				p.next()
 			} else {
-				return nil, fmt.Errorf(
-					"failed to parse property key: expected Colon token but got: %v",
-					p.current.Val,
-				)
+				return nil, p.unexpected("':'")
 			}
 
 		case ast.StatePropertyColon:
+			prop.Trivia.BeforeValue = p.trivia()
+			p.pushPath(prop.Identifier.Value)
 			value, parseErr := p.parseValue()
+			p.popPath()
 			if parseErr != nil {
 				return nil, parseErr
 			}
 			prop.Value = value
-			propertyState = ast.StatePropertyValue
-
-		case ast.StatePropertyValue:
+			prop.Trivia.AfterValue = p.trivia()
 			return &prop, nil
 		}
 	}
-
-	return &prop, nil
 }
 
 // parseArray parses JSON array.
 func (p *Parser) parseArray() (*ast.Array, error) {
-	array := ast.Array{}
+	array := p.newArray()
 	arrayState := ast.StateArrayStart
 
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer p.leave()
+
 	for {
 		if p.isCurrentToken(token.EOF) {
-			break
+			if err := p.report(p.errorAt(p.current, ErrUnexpectedEOF, "missing closing bracket")); err != nil {
+				return nil, err
+			}
+			array.End = p.current.Pos
+			return array, nil
 		}
 
 		switch arrayState {
@@ -268,55 +575,94 @@ func (p *Parser) parseArray() (*ast.Array, error) {
 
 		case ast.StateArrayOpen:
 			if p.isCurrentToken(token.RightBracket) {
+				array.Trivia = p.trivia()
 				array.End = p.current.Pos
-				return &array, nil
+				p.next()
+				return array, nil
 			}
-			arrayItem, parseErr := p.parseArrayItem()
-			if parseErr != nil {
-				return nil, parseErr
+			if err := p.addItem(array); err != nil {
+				return nil, err
 			}
-			array.Children = append(array.Children, *arrayItem)
 			arrayState = ast.StateArrayValue
-			if p.isPeekToken(token.RightBracket) {
-				p.next()
-			}
 
 		case ast.StateArrayValue:
 			if p.isCurrentToken(token.RightBracket) {
+				if n := len(array.Children); n > 0 {
+					last := &array.Children[n-1]
+					last.After, array.Trivia = splitTrailing(last.After)
+				}
 				array.End = p.current.Pos
 				p.next()
-				return &array, nil
+				return array, nil
 			} else if p.isCurrentToken(token.Comma) {
 				arrayState = ast.StateArrayComma
 				p.next()
 			} else {
-				return nil, fmt.Errorf(
-					"failed to parse array: expected RightBrace or Comma token but got: %v",
-					p.current.Val,
-				)
+				if err := p.report(p.unexpected("',' or ']'")); err != nil {
+					return nil, err
+				}
+				switch p.current.Token {
+				case token.String, token.Number, token.True, token.False, token.Null, token.LeftBrace, token.LeftBracket:
+					// Missing comma, go on with the next item.
+					arrayState = ast.StateArrayComma
+				case token.RightBrace:
+					// Mismatched closer, left to the enclosing object.
+					array.End = p.current.Pos
+					return array, nil
+				default:
+					p.skip()
+				}
 			}
 
 		case ast.StateArrayComma:
 			if p.isCurrentToken(token.RightBracket) {
+				if err := p.report(p.errorAt(p.previous, ErrTrailingComma, "trailing comma in array")); err != nil {
+					return nil, err
+				}
 				array.End = p.current.Pos
-				return &array, nil
+				p.next()
+				return array, nil
 			}
-			arrayItem, parseErr := p.parseArrayItem()
-			if parseErr != nil {
-				return nil, parseErr
+			if err := p.addItem(array); err != nil {
+				return nil, err
 			}
-			array.Children = append(array.Children, *arrayItem)
 			arrayState = ast.StateArrayValue
 		}
 	}
+}
 
-	array.End = p.current.Pos
-	return &array, nil
+// addItem parses an item and appends it to array.
+func (p *Parser) addItem(array *ast.Array) error {
+	if err := p.checkChildren(len(array.Children)); err != nil {
+		return err
+	}
+	p.pushPath(strconv.Itoa(len(array.Children)))
+	arrayItem, parseErr := p.parseArrayItem()
+	p.popPath()
+	if parseErr != nil {
+		if err := p.report(parseErr); err != nil {
+			return err
+		}
+		p.skip()
+		return nil
+	}
+	array.Children = append(array.Children, *arrayItem)
+	return nil
 }
 
 // parseArrayItem parses item inside JSON array.
 func (p *Parser) parseArrayItem() (*ast.ArrayItem, error) {
-	item := ast.ArrayItem{}
+	item := ast.ArrayItem{Before: p.trivia()}
+
+	if p.isLazy() {
+		raw, parseErr := p.parseRaw()
+		if parseErr != nil {
+			return nil, parseErr
+		}
+		item.Value = raw
+		item.After = p.trivia()
+		return &item, nil
+	}
 
 	switch p.current.Token {
 	case token.LeftBrace:
@@ -341,63 +687,313 @@ func (p *Parser) parseArrayItem() (*ast.ArrayItem, error) {
 		item.Value = litValue
 	}
 
-	return &item, nil
+	item.After = p.trivia()
+	return &item, p.reached(item.Value)
+}
+
+// unexpected returns the error for the current token when expected was
+// expected, reporting the errors of the Lexer as they are.
+func (p *Parser) unexpected(expected string) error {
+	switch p.current.Token {
+	case token.Error:
+		return p.errorAt(p.current, p.currentErr, p.current.Val)
+	case token.EOF:
+		return p.errorAt(p.current, ErrUnexpectedEOF, "unexpected EOF, expected "+expected)
+	}
+	return p.errorAt(p.current, ErrUnexpectedToken, fmt.Sprintf("unexpected %v, expected %s", p.current, expected))
+}
+
+// errorAt returns a SyntaxError of the given kind spanning item.
+func (p *Parser) errorAt(item lexer.Item, kind error, msg string) *SyntaxError {
+	end := item.Pos + len(item.Val)
+	if item.Token == token.Error {
+		// The value of an Error item is the message, not input.
+		end = item.Pos
+	}
+	return &SyntaxError{Msg: msg, Offset: item.Pos, End: end, Err: kind}
+}
+
+// report records err and returns nil when recovering from syntax errors,
+// otherwise it returns err. A syntax error at the offset of the previously
+// recorded one is dropped, as a consequence of it.
+func (p *Parser) report(err error) error {
+	if !p.recover || p.err != nil {
+		return err
+	}
+	switch err := err.(type) {
+	case *SyntaxError:
+		if n := len(p.errs); n > 0 {
+			if last, ok := p.errs[n-1].(*SyntaxError); ok && last.Offset == err.Offset {
+				return nil
+			}
+		}
+	case *DuplicateKeyError:
+	default:
+		// Limits are never recovered from.
+		return err
+	}
+	p.errs = append(p.errs, err)
+	return nil
+}
+
+// skip advances to the next ',', '}' or ']' outside of the objects
+// and arrays it passes over, or to EOF, to resume parsing after an error.
+func (p *Parser) skip() {
+	depth := 0
+	for !p.isCurrentToken(token.EOF) {
+		switch p.current.Token {
+		case token.LeftBrace, token.LeftBracket:
+			depth++
+		case token.RightBrace, token.RightBracket:
+			if depth == 0 {
+				return
+			}
+			depth--
+		case token.Comma:
+			if depth == 0 {
+				return
+			}
+		}
+		p.next()
+	}
+}
+
+// isLazy reports whether the current object or array is kept as RawValue.
+func (p *Parser) isLazy() bool {
+	return p.lazy != nil &&
+		(p.isCurrentToken(token.LeftBrace) || p.isCurrentToken(token.LeftBracket)) &&
+		p.lazy(p.path)
+}
+
+// parseRaw skips the current object or array and returns its source text.
+// Brackets must match and nesting counts towards MaxDepth; the rest of
+// the grammar is checked when the value is parsed, see ParseRaw.
+func (p *Parser) parseRaw() (*ast.RawValue, error) {
+	raw := p.newRawValue()
+	raw.Start = p.current.Pos
+	var closers []token.Token
+	defer func() {
+		for range closers {
+			p.leave()
+		}
+	}()
+	for {
+		switch p.current.Token {
+		case token.LeftBrace, token.LeftBracket:
+			closer := token.RightBrace
+			if p.current.Token == token.LeftBracket {
+				closer = token.RightBracket
+			}
+			closers = append(closers, closer)
+			if err := p.enter(); err != nil {
+				return nil, err
+			}
+		case token.RightBrace, token.RightBracket:
+			if want := closers[len(closers)-1]; p.current.Token != want {
+				if want == token.RightBrace {
+					return nil, p.unexpected("'}'")
+				}
+				return nil, p.unexpected("']'")
+			}
+			closers = closers[:len(closers)-1]
+			p.leave()
+		case token.Error:
+			return nil, p.unexpected("value")
+		case token.EOF:
+			return nil, p.errorAt(p.current, ErrUnexpectedEOF, "missing closing brace or bracket")
+		}
+		if len(closers) == 0 {
+			raw.End = p.current.Pos + 1
+			raw.Raw = p.lex.Slice(raw.Start, raw.End)
+			p.next()
+			return raw, nil
+		}
+		p.next()
+	}
+}
+
+// newValue returns a new Value, allocated from the arena if any.
+func (p *Parser) newValue() *ast.Value {
+	if p.arena != nil {
+		return p.arena.Value()
+	}
+	return &ast.Value{}
+}
+
+// newObject returns a new Object, allocated from the arena if any.
+func (p *Parser) newObject() *ast.Object {
+	p.nodes++
+	if p.arena != nil {
+		return p.arena.Object()
+	}
+	return &ast.Object{}
+}
+
+// newArray returns a new Array, allocated from the arena if any.
+func (p *Parser) newArray() *ast.Array {
+	p.nodes++
+	if p.arena != nil {
+		return p.arena.Array()
+	}
+	return &ast.Array{}
+}
+
+// newLiteral returns a new Literal, allocated from the arena if any.
+func (p *Parser) newLiteral() *ast.Literal {
+	p.nodes++
+	if p.arena != nil {
+		return p.arena.Literal()
+	}
+	return &ast.Literal{}
+}
+
+// newRawValue returns a new RawValue, allocated from the arena if any.
+func (p *Parser) newRawValue() *ast.RawValue {
+	p.nodes++
+	if p.arena != nil {
+		return p.arena.RawValue()
+	}
+	return &ast.RawValue{}
+}
+
+// trivia returns the source text between the previous and the current
+// Item when recording trivia, "" otherwise.
+func (p *Parser) trivia() string {
+	if !p.lossless || p.src != nil {
+		return ""
+	}
+	start := p.lex.Offset()
+	if p.previous.Token != token.Unknown || p.previous.Val != "" {
+		start = p.previous.Pos + len(p.previous.Val)
+	}
+	if p.previous.Token == token.Error || start > p.current.Pos {
+		// The value of an Error item is the message, not input.
+		return ""
+	}
+	return p.lex.Slice(start, p.current.Pos)
+}
+
+// splitTrailing splits the trivia between the last child of a container
+// and its closing bracket into the trivia of the child, up to the end of
+// its line, and the trivia of the container.
+func splitTrailing(trivia string) (string, string) {
+	if i := strings.IndexByte(trivia, '\n'); i >= 0 {
+		return trivia[:i], trivia[i:]
+	}
+	return trivia, ""
+}
+
+// pushPath appends a reference token to the current path.
+func (p *Parser) pushPath(token string) {
+	if p.trackPath {
+		p.path = append(p.path, token)
+	}
+}
+
+// popPath removes the last reference token from the current path.
+func (p *Parser) popPath() {
+	if p.trackPath {
+		p.path = p.path[:len(p.path)-1]
+	}
 }
 
 // parseLiteral parse JSON literal.
 func (p *Parser) parseLiteral() (*ast.Literal, error) {
-	lit := ast.Literal{}
+	lit := p.newLiteral()
 
 	defer p.next()
 
 	switch p.current.Token {
 	case token.String:
-		lit.LiteralType = ast.LiteralTypeString
-		lit.Val = p.parseString()
+		s, parseErr := p.parseString()
+		if parseErr != nil {
+			return nil, parseErr
+		}
+		*lit = *ast.String(s)
+		lit.Raw = p.current.Val
 
 	case token.Number:
-		lit.LiteralType = ast.LiteralTypeNumber
 		ct := p.current.Val
-		i, parseIntErr := strconv.ParseInt(ct, 10, 64)
+		digits := ct
+		if strings.IndexByte(ct, '_') >= 0 {
+			// Separators accepted by lexer.ExtNumericSeparators.
+			digits = strings.ReplaceAll(ct, "_", "")
+		}
+		i, parseIntErr := strconv.ParseInt(digits, 10, 64)
 		if parseIntErr == nil {
-			lit.Val = i
+			*lit = *ast.Number(i)
 		} else {
-			f, parseFloatErr := strconv.ParseFloat(ct, 64)
+			f, parseFloatErr := strconv.ParseFloat(digits, 64)
 			if parseFloatErr != nil {
-				return nil, fmt.Errorf(
-					"failed to parse number: incorrect syntax %v",
-					p.current.Val,
-				)
+				return nil, p.errorAt(p.current, ErrInvalidNumber, "invalid number "+ct)
 			}
-			lit.Val = f
+			*lit = *ast.Number(f)
+		}
+		lit.Raw = ct
+		if !p.lossless {
+			lit.Raw = normalizeNumber(digits)
 		}
 
 	case token.True:
-		lit.LiteralType = ast.LiteralTypeTrue
-		lit.Val = true
+		*lit = *ast.Bool(true)
 
 	case token.False:
-		lit.LiteralType = ast.LiteralTypeFalse
-		lit.Val = false
+		*lit = *ast.Bool(false)
 
 	case token.Null:
-		lit.LiteralType = ast.LiteralTypeNull
-		lit.Val = "null"
+		*lit = *ast.Null()
 
 	default:
-		return nil, fmt.Errorf(
-			"failed to parse literal: incorrect syntax %v",
-			p.current.Val,
-		)
+		return nil, p.unexpected("value")
 	}
 
-	return &lit, nil
+	if p.registry != nil {
+		p.registry.recognize(lit)
+	}
+	return lit, nil
+}
+
+// normalizeNumber rewrites the spellings accepted by lexer.ExtLeadingPlus,
+// ExtLeadingDot and ExtTrailingDot as JSON numbers: +5 as 5, .5 as 0.5
+// and 5. as 5.0, keeping the type of the value. Digit separators accepted
+// by ExtNumericSeparators must already be removed.
+func normalizeNumber(s string) string {
+	dot := strings.IndexByte(s, '.')
+	if s[0] != '+' && dot < 0 {
+		return s
+	}
+	var b strings.Builder
+	switch s[0] {
+	case '+':
+		s, dot = s[1:], dot-1
+	case '-':
+		b.WriteByte('-')
+		s, dot = s[1:], dot-1
+	}
+	if dot == 0 {
+		b.WriteByte('0')
+	}
+	if dot >= 0 && (dot+1 == len(s) || s[dot+1] == 'e' || s[dot+1] == 'E') {
+		b.WriteString(s[:dot+1])
+		b.WriteByte('0')
+		s = s[dot+1:]
+	}
+	b.WriteString(s)
+	return b.String()
 }
 
 // parseString parses JSON string literal.
-func (p *Parser) parseString() string {
-	s, _ := strconv.Unquote(p.current.Val)
-	return s
+func (p *Parser) parseString() (string, error) {
+	s, offset, err := jsonstr.Unquote(p.current.Val)
+	if err != nil {
+		if p.allowInvalidEscapes {
+			return p.current.Val[1 : len(p.current.Val)-1], nil
+		}
+		syntaxErr := p.errorAt(p.current, ErrInvalidString, err.Error())
+		syntaxErr.Offset += offset
+		return "", syntaxErr
+	}
+	return s, nil
 }
 
 // isPreviousToken reports whether t is previous Token.
@@ -1,10 +1,16 @@
 package parser
 
 import (
+	"errors"
+	"math/big"
+	"strings"
 	"testing"
+	"testing/iotest"
+	"time"
 
 	"github.com/pohedev/gj.git/ast"
 	"github.com/pohedev/gj.git/lexer"
+	"github.com/pohedev/gj.git/printer"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -30,8 +36,8 @@ func TestParser_Parse(t *testing.T) {
 					Value: &ast.Object{
 						Children: []ast.Property{
 							{
-								Identifier: ast.Identifier{Value: "color"},
-								Value:      &ast.Value{Value: &ast.Literal{LiteralType: ast.LiteralTypeString, Val: "blue"}},
+								Identifier: ast.Identifier{Value: "color", Start: 1, End: 8},
+								Value:      &ast.Value{Value: &ast.Literal{LiteralType: ast.LiteralTypeString, Val: "blue", Raw: `"blue"`}},
 							},
 						},
 						Start: 0,
@@ -52,13 +58,13 @@ func TestParser_Parse(t *testing.T) {
 					Value: &ast.Object{
 						Children: []ast.Property{
 							{
-								Identifier: ast.Identifier{Value: "boolean_1"},
+								Identifier: ast.Identifier{Value: "boolean_1", Start: 7, End: 18},
 								Value: &ast.Value{
 									Value: &ast.Literal{LiteralType: ast.LiteralTypeTrue, Val: true},
 								},
 							},
 							{
-								Identifier: ast.Identifier{Value: "boolean_2"},
+								Identifier: ast.Identifier{Value: "boolean_2", Start: 31, End: 42},
 								Value: &ast.Value{
 									Value: &ast.Literal{LiteralType: ast.LiteralTypeFalse, Val: false},
 								},
@@ -84,20 +90,20 @@ func TestParser_Parse(t *testing.T) {
 					Value: &ast.Object{
 						Children: []ast.Property{
 							{
-								Identifier: ast.Identifier{Value: "number_1"},
-								Value:      &ast.Value{Value: &ast.Literal{LiteralType: ast.LiteralTypeNumber, Val: int64(210)}},
+								Identifier: ast.Identifier{Value: "number_1", Start: 7, End: 17},
+								Value:      &ast.Value{Value: &ast.Literal{LiteralType: ast.LiteralTypeNumber, Val: int64(210), Raw: "210"}},
 							},
 							{
-								Identifier: ast.Identifier{Value: "number_2"},
-								Value:      &ast.Value{Value: &ast.Literal{LiteralType: ast.LiteralTypeNumber, Val: int64(-210)}},
+								Identifier: ast.Identifier{Value: "number_2", Start: 29, End: 39},
+								Value:      &ast.Value{Value: &ast.Literal{LiteralType: ast.LiteralTypeNumber, Val: int64(-210), Raw: "-210"}},
 							},
 							{
-								Identifier: ast.Identifier{Value: "number_3"},
-								Value:      &ast.Value{Value: &ast.Literal{LiteralType: ast.LiteralTypeNumber, Val: float64(21.05)}},
+								Identifier: ast.Identifier{Value: "number_3", Start: 52, End: 62},
+								Value:      &ast.Value{Value: &ast.Literal{LiteralType: ast.LiteralTypeNumber, Val: float64(21.05), Raw: "21.05"}},
 							},
 							{
-								Identifier: ast.Identifier{Value: "number_4"},
-								Value:      &ast.Value{Value: &ast.Literal{LiteralType: ast.LiteralTypeNumber, Val: float64(100)}},
+								Identifier: ast.Identifier{Value: "number_4", Start: 76, End: 86},
+								Value:      &ast.Value{Value: &ast.Literal{LiteralType: ast.LiteralTypeNumber, Val: float64(100), Raw: "1.0E+2"}},
 							},
 						},
 						Start: 0,
@@ -115,8 +121,8 @@ func TestParser_Parse(t *testing.T) {
 					Value: &ast.Object{
 						Children: []ast.Property{
 							{
-								Identifier: ast.Identifier{Value: "value"},
-								Value:      &ast.Value{Value: &ast.Literal{LiteralType: ast.LiteralTypeString, Val: "abc123"}},
+								Identifier: ast.Identifier{Value: "value", Start: 1, End: 8},
+								Value:      &ast.Value{Value: &ast.Literal{LiteralType: ast.LiteralTypeString, Val: "abc123", Raw: `"abc123"`}},
 							},
 						},
 						Start: 0,
@@ -134,8 +140,8 @@ func TestParser_Parse(t *testing.T) {
 					Value: &ast.Object{
 						Children: []ast.Property{
 							{
-								Identifier: ast.Identifier{Value: "value"},
-								Value:      &ast.Value{Value: &ast.Literal{LiteralType: ast.LiteralTypeNull, Val: "null"}},
+								Identifier: ast.Identifier{Value: "value", Start: 1, End: 8},
+								Value:      &ast.Value{Value: &ast.Literal{LiteralType: ast.LiteralTypeNull, Val: nil}},
 							},
 						},
 						Start: 0,
@@ -153,12 +159,12 @@ func TestParser_Parse(t *testing.T) {
 					Value: &ast.Object{
 						Children: []ast.Property{
 							{
-								Identifier: ast.Identifier{Value: "cars"},
+								Identifier: ast.Identifier{Value: "cars", Start: 1, End: 7},
 								Value: &ast.Value{
 									Value: &ast.Array{
 										Children: []ast.ArrayItem{
-											{Value: &ast.Literal{LiteralType: ast.LiteralTypeString, Val: "Ford"}},
-											{Value: &ast.Literal{LiteralType: ast.LiteralTypeString, Val: "BMW"}},
+											{Value: &ast.Literal{LiteralType: ast.LiteralTypeString, Val: "Ford", Raw: `"Ford"`}},
+											{Value: &ast.Literal{LiteralType: ast.LiteralTypeString, Val: "BMW", Raw: `"BMW"`}},
 										},
 										Start: 8,
 										End:   22,
@@ -181,15 +187,15 @@ func TestParser_Parse(t *testing.T) {
 					Value: &ast.Object{
 						Children: []ast.Property{
 							{
-								Identifier: ast.Identifier{Value: "id"},
+								Identifier: ast.Identifier{Value: "id", Start: 1, End: 5},
 								Value: &ast.Value{
 									Value: &ast.Array{
 										Children: []ast.ArrayItem{
 											{
 												Value: &ast.Array{
 													Children: []ast.ArrayItem{
-														{Value: &ast.Literal{LiteralType: ast.LiteralTypeNumber, Val: int64(12)}},
-														{Value: &ast.Literal{LiteralType: ast.LiteralTypeNumber, Val: int64(23)}},
+														{Value: &ast.Literal{LiteralType: ast.LiteralTypeNumber, Val: int64(12), Raw: "12"}},
+														{Value: &ast.Literal{LiteralType: ast.LiteralTypeNumber, Val: int64(23), Raw: "23"}},
 													},
 													Start: 9,
 													End:   18,
@@ -198,8 +204,8 @@ func TestParser_Parse(t *testing.T) {
 											{
 												Value: &ast.Array{
 													Children: []ast.ArrayItem{
-														{Value: &ast.Literal{LiteralType: ast.LiteralTypeNumber, Val: int64(34)}},
-														{Value: &ast.Literal{LiteralType: ast.LiteralTypeNumber, Val: int64(45)}},
+														{Value: &ast.Literal{LiteralType: ast.LiteralTypeNumber, Val: int64(34), Raw: "34"}},
+														{Value: &ast.Literal{LiteralType: ast.LiteralTypeNumber, Val: int64(45), Raw: "45"}},
 													},
 													Start: 21,
 													End:   30,
@@ -237,27 +243,27 @@ func TestParser_Parse(t *testing.T) {
 					Value: &ast.Object{
 						Children: []ast.Property{
 							{
-								Identifier: ast.Identifier{Value: "id"},
-								Value:      &ast.Value{Value: &ast.Literal{LiteralType: ast.LiteralTypeString, Val: "123"}},
+								Identifier: ast.Identifier{Value: "id", Start: 7, End: 11},
+								Value:      &ast.Value{Value: &ast.Literal{LiteralType: ast.LiteralTypeString, Val: "123", Raw: `"123"`}},
 							},
 							{
-								Identifier: ast.Identifier{Value: "product"},
+								Identifier: ast.Identifier{Value: "product", Start: 25, End: 34},
 								Value: &ast.Value{
 									Value: &ast.Object{
 										Children: []ast.Property{
 											{
-												Identifier: ast.Identifier{Value: "model"},
+												Identifier: ast.Identifier{Value: "model", Start: 44, End: 51},
 												Value: &ast.Value{
 													Value: &ast.Object{
 														Children: []ast.Property{
 															{
-																Identifier: ast.Identifier{Value: "property"},
+																Identifier: ast.Identifier{Value: "property", Start: 61, End: 71},
 																Value: &ast.Value{
 																	Value: &ast.Object{
 																		Children: []ast.Property{
 																			{
-																				Identifier: ast.Identifier{Value: "battery"},
-																				Value:      &ast.Value{Value: &ast.Literal{LiteralType: ast.LiteralTypeString, Val: "li-ion"}},
+																				Identifier: ast.Identifier{Value: "battery", Start: 82, End: 91},
+																				Value:      &ast.Value{Value: &ast.Literal{LiteralType: ast.LiteralTypeString, Val: "li-ion", Raw: `"li-ion"`}},
 																			},
 																		},
 																		Start: 73,
@@ -278,8 +284,8 @@ func TestParser_Parse(t *testing.T) {
 								},
 							},
 							{
-								Identifier: ast.Identifier{Value: "status"},
-								Value:      &ast.Value{Value: &ast.Literal{LiteralType: ast.LiteralTypeString, Val: "In-Stock"}},
+								Identifier: ast.Identifier{Value: "status", Start: 132, End: 140},
+								Value:      &ast.Value{Value: &ast.Literal{LiteralType: ast.LiteralTypeString, Val: "In-Stock", Raw: `"In-Stock"`}},
 							},
 						},
 						Start: 0,
@@ -300,12 +306,12 @@ func TestParser_Parse(t *testing.T) {
 								Value: &ast.Object{
 									Children: []ast.Property{
 										{
-											Identifier: ast.Identifier{Value: "id"},
-											Value:      &ast.Value{Value: &ast.Literal{LiteralType: ast.LiteralTypeNumber, Val: int64(1)}},
+											Identifier: ast.Identifier{Value: "id", Start: 2, End: 6},
+											Value:      &ast.Value{Value: &ast.Literal{LiteralType: ast.LiteralTypeNumber, Val: int64(1), Raw: "1"}},
 										},
 										{
-											Identifier: ast.Identifier{Value: "name"},
-											Value:      &ast.Value{Value: &ast.Literal{LiteralType: ast.LiteralTypeString, Val: "water"}},
+											Identifier: ast.Identifier{Value: "name", Start: 11, End: 17},
+											Value:      &ast.Value{Value: &ast.Literal{LiteralType: ast.LiteralTypeString, Val: "water", Raw: `"water"`}},
 										},
 									},
 									Start: 1,
@@ -316,12 +322,12 @@ func TestParser_Parse(t *testing.T) {
 								Value: &ast.Object{
 									Children: []ast.Property{
 										{
-											Identifier: ast.Identifier{Value: "id"},
-											Value:      &ast.Value{Value: &ast.Literal{LiteralType: ast.LiteralTypeNumber, Val: int64(2)}},
+											Identifier: ast.Identifier{Value: "id", Start: 29, End: 33},
+											Value:      &ast.Value{Value: &ast.Literal{LiteralType: ast.LiteralTypeNumber, Val: int64(2), Raw: "2"}},
 										},
 										{
-											Identifier: ast.Identifier{Value: "name"},
-											Value:      &ast.Value{Value: &ast.Literal{LiteralType: ast.LiteralTypeString, Val: "knife"}},
+											Identifier: ast.Identifier{Value: "name", Start: 37, End: 43},
+											Value:      &ast.Value{Value: &ast.Literal{LiteralType: ast.LiteralTypeString, Val: "knife", Raw: `"knife"`}},
 										},
 									},
 									Start: 28,
@@ -363,57 +369,57 @@ func TestParser_Parse(t *testing.T) {
 					Value: &ast.Object{
 						Children: []ast.Property{
 							{
-								Identifier: ast.Identifier{Value: "glossary"},
+								Identifier: ast.Identifier{Value: "glossary", Start: 7, End: 17},
 								Value: &ast.Value{
 									Value: &ast.Object{
 										Children: []ast.Property{
 											{
-												Identifier: ast.Identifier{Value: "title"},
-												Value:      &ast.Value{Value: &ast.Literal{LiteralType: ast.LiteralTypeString, Val: "example glossary"}},
+												Identifier: ast.Identifier{Value: "title", Start: 27, End: 34},
+												Value:      &ast.Value{Value: &ast.Literal{LiteralType: ast.LiteralTypeString, Val: "example glossary", Raw: `"example glossary"`}},
 											},
 											{
-												Identifier: ast.Identifier{Value: "GlossDiv"},
+												Identifier: ast.Identifier{Value: "GlossDiv", Start: 62, End: 72},
 												Value: &ast.Value{Value: &ast.Object{
 													Children: []ast.Property{
 														{
-															Identifier: ast.Identifier{Value: "title"},
-															Value:      &ast.Value{Value: &ast.Literal{LiteralType: ast.LiteralTypeString, Val: "S"}},
+															Identifier: ast.Identifier{Value: "title", Start: 83, End: 90},
+															Value:      &ast.Value{Value: &ast.Literal{LiteralType: ast.LiteralTypeString, Val: "S", Raw: `"S"`}},
 														},
 														{
-															Identifier: ast.Identifier{Value: "GlossList"},
+															Identifier: ast.Identifier{Value: "GlossList", Start: 104, End: 115},
 															Value: &ast.Value{
 																Value: &ast.Object{
 																	Children: []ast.Property{
 																		{
-																			Identifier: ast.Identifier{Value: "GlossEntry"},
+																			Identifier: ast.Identifier{Value: "GlossEntry", Start: 127, End: 139},
 																			Value: &ast.Value{
 																				Value: &ast.Object{
 																					Children: []ast.Property{
 																						{
-																							Identifier: ast.Identifier{Value: "GlossTerm"},
-																							Value:      &ast.Value{Value: &ast.Literal{LiteralType: ast.LiteralTypeString, Val: "Standard Generalized Markup Language"}},
+																							Identifier: ast.Identifier{Value: "GlossTerm", Start: 152, End: 163},
+																							Value:      &ast.Value{Value: &ast.Literal{LiteralType: ast.LiteralTypeString, Val: "Standard Generalized Markup Language", Raw: `"Standard Generalized Markup Language"`}},
 																						},
 																						{
-																							Identifier: ast.Identifier{Value: "Abbrev"},
-																							Value:      &ast.Value{Value: &ast.Literal{LiteralType: ast.LiteralTypeString, Val: "ISO 8879:1986"}},
+																							Identifier: ast.Identifier{Value: "Abbrev", Start: 214, End: 222},
+																							Value:      &ast.Value{Value: &ast.Literal{LiteralType: ast.LiteralTypeString, Val: "ISO 8879:1986", Raw: `"ISO 8879:1986"`}},
 																						},
 																						{
-																							Identifier: ast.Identifier{Value: "GlossDef"},
+																							Identifier: ast.Identifier{Value: "GlossDef", Start: 250, End: 260},
 																							Value: &ast.Value{Value: &ast.Object{
 																								Children: []ast.Property{
 																									{
-																										Identifier: ast.Identifier{Value: "para"},
-																										Value:      &ast.Value{Value: &ast.Literal{LiteralType: ast.LiteralTypeString, Val: "A meta-markup language, used to create markup languages such as DocBook."}},
+																										Identifier: ast.Identifier{Value: "para", Start: 274, End: 280},
+																										Value:      &ast.Value{Value: &ast.Literal{LiteralType: ast.LiteralTypeString, Val: "A meta-markup language, used to create markup languages such as DocBook.", Raw: `"A meta-markup language, used to create markup languages such as DocBook."`}},
 																									},
 																									{
-																										Identifier: ast.Identifier{Value: "GlossSeeAlso"},
+																										Identifier: ast.Identifier{Value: "GlossSeeAlso", Start: 368, End: 382},
 																										Value: &ast.Value{Value: &ast.Array{
 																											Children: []ast.ArrayItem{
 																												{
-																													Value: &ast.Literal{LiteralType: ast.LiteralTypeString, Val: "GML"},
+																													Value: &ast.Literal{LiteralType: ast.LiteralTypeString, Val: "GML", Raw: `"GML"`},
 																												},
 																												{
-																													Value: &ast.Literal{LiteralType: ast.LiteralTypeString, Val: "XML"},
+																													Value: &ast.Literal{LiteralType: ast.LiteralTypeString, Val: "XML", Raw: `"XML"`},
 																												},
 																											},
 																											Start: 384,
@@ -426,8 +432,8 @@ func TestParser_Parse(t *testing.T) {
 																							}},
 																						},
 																						{
-																							Identifier: ast.Identifier{Value: "GlossSee"},
-																							Value:      &ast.Value{Value: &ast.Literal{LiteralType: ast.LiteralTypeString, Val: "markup"}},
+																							Identifier: ast.Identifier{Value: "GlossSee", Start: 420, End: 430},
+																							Value:      &ast.Value{Value: &ast.Literal{LiteralType: ast.LiteralTypeString, Val: "markup", Raw: `"markup"`}},
 																						},
 																					},
 																					Start: 141,
@@ -442,8 +448,8 @@ func TestParser_Parse(t *testing.T) {
 															},
 														},
 														{
-															Identifier: ast.Identifier{Value: "Nums"},
-															Value:      &ast.Value{Value: &ast.Literal{LiteralType: ast.LiteralTypeNumber, Val: int64(5245243)}},
+															Identifier: ast.Identifier{Value: "Nums", Start: 468, End: 474},
+															Value:      &ast.Value{Value: &ast.Literal{LiteralType: ast.LiteralTypeNumber, Val: int64(5245243), Raw: "5245243"}},
 														},
 													},
 													Start: 74,
@@ -496,6 +502,10 @@ func TestParser_ParseError(t *testing.T) {
 		var tests = []parserErrorTest{
 			{"currency sign is now allowed in numbers", `{"prop": $1.00}`},
 			{"expression is not allowed in numbers", `{"prop": 99.00 * 0.15}`},
+			{"leading plus is not allowed in numbers", `{"prop": +1}`},
+			{"leading zero is not allowed in numbers", `{"prop": 01}`},
+			{"trailing dot is not allowed in numbers", `{"prop": 1.}`},
+			{"numeric separator is not allowed in numbers", `{"prop": 1_000}`},
 		}
 
 		for _, tt := range tests {
@@ -536,6 +546,8 @@ func TestParser_ParseError(t *testing.T) {
 			{"missing property value in object", `{"prop": }`},
 			{"missing colon after property key in object", `{"prop" "val"}`},
 			{"missing property name in object", `{{}}`},
+			{"trailing comma in object", `{"prop": "val",}`},
+			{"missing closing brace in nested object", `{"prop": {"a": 1}`},
 		}
 
 		for _, tt := range tests {
@@ -546,4 +558,980 @@ func TestParser_ParseError(t *testing.T) {
 			})
 		}
 	})
+
+	t.Run("truncated JSON literals", func(t *testing.T) {
+		var tests = []struct {
+			name   string
+			input  string
+			offset int
+		}{
+			{"truncated true in object", `{"a": tru}`, 6},
+			{"truncated false in array", `[1, fal, 2]`, 4},
+			{"truncated null at end", `[nu`, 1},
+			{"truncated root", `t`, 0},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				_, err := New(lexer.Lex(tt.input)).Parse()
+				var syntaxErr *SyntaxError
+				if assert.ErrorAs(t, err, &syntaxErr) {
+					assert.Equal(t, tt.offset, syntaxErr.Offset)
+					assert.Contains(t, syntaxErr.Msg, "truncated literal")
+				}
+			})
+		}
+	})
+}
+
+func TestParser_ParseErrorKind(t *testing.T) {
+	var tests = []struct {
+		name  string
+		input string
+		opts  []Option
+		want  error
+	}{
+		{"empty input", ``, nil, ErrUnexpectedEOF},
+		{"missing closing brace", `{"a": 1`, nil, ErrUnexpectedEOF},
+		{"missing value", `{"a": }`, nil, ErrUnexpectedToken},
+		{"missing colon", `{"a" 1}`, nil, ErrUnexpectedToken},
+		{"unknown character", `[1, $]`, nil, ErrUnexpectedToken},
+		{"invalid number", `[01]`, nil, ErrInvalidNumber},
+		{"unterminated string", `["abc]`, nil, ErrUnterminatedString},
+		{"misspelled literal", `[ture]`, nil, ErrInvalidLiteral},
+		{"truncated literal", `{"a": tru}`, nil, ErrInvalidLiteral},
+		{"invalid escape", `["\x"]`, nil, ErrInvalidString},
+		{"trailing comma", `[1,]`, nil, ErrTrailingComma},
+		{"trailing content", `[1] [2]`, nil, ErrTrailingContent},
+		{"duplicate key", `{"a": 1, "a": 2}`, []Option{DisallowDuplicateKeys()}, ErrDuplicateKey},
+		{"max depth", `[[[1]]]`, []Option{MaxDepth(2)}, ErrMaxDepth},
+		{"max tokens", `[1, 2]`, []Option{MaxTokens(2)}, ErrMaxTokens},
+		{"lazy", `{"a": [1, 01]}`, []Option{LazyBelow(0)}, ErrInvalidNumber},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(lexer.Lex(tt.input), tt.opts...).Parse()
+			assert.ErrorIs(t, err, tt.want)
+		})
+	}
+}
+
+func TestParser_ParseRecover(t *testing.T) {
+	type wantErr struct {
+		offset int
+		kind   error
+	}
+	var tests = []struct {
+		name  string
+		input string
+		want  string
+		errs  []wantErr
+	}{
+		{
+			name:  "valid",
+			input: `{"a": [1, 2]}`,
+			want:  `{"a":[1,2]}`,
+		},
+		{
+			name:  "bad values",
+			input: `{"a": 01, "b": $, "c": 3}`,
+			want:  `{"c":3}`,
+			errs:  []wantErr{{6, ErrInvalidNumber}, {15, ErrUnexpectedToken}},
+		},
+		{
+			name:  "missing commas",
+			input: `{"a": 1 "b": [1 2]}`,
+			want:  `{"a":1,"b":[1,2]}`,
+			errs:  []wantErr{{8, ErrUnexpectedToken}, {16, ErrUnexpectedToken}},
+		},
+		{
+			name:  "missing colon",
+			input: `{"a" [1, 2], "b": true}`,
+			want:  `{"b":true}`,
+			errs:  []wantErr{{5, ErrUnexpectedToken}},
+		},
+		{
+			name:  "trailing commas",
+			input: `[{"a": 1,}, 2,]`,
+			want:  `[{"a":1},2]`,
+			errs:  []wantErr{{8, ErrTrailingComma}, {13, ErrTrailingComma}},
+		},
+		{
+			name:  "unterminated string",
+			input: "[\"abc\n, 2]",
+			want:  `[2]`,
+			errs:  []wantErr{{1, ErrUnterminatedString}},
+		},
+		{
+			name:  "mismatched closer",
+			input: `{"a": [1, 2}`,
+			want:  `{"a":[1,2]}`,
+			errs:  []wantErr{{11, ErrUnexpectedToken}},
+		},
+		{
+			name:  "missing closers",
+			input: `{"a": [1, {"b": 2`,
+			want:  `{"a":[1,{"b":2}]}`,
+			errs:  []wantErr{{17, ErrUnexpectedEOF}},
+		},
+		{
+			name:  "trailing content",
+			input: `[1] 2`,
+			want:  `[1]`,
+			errs:  []wantErr{{4, ErrTrailingContent}},
+		},
+		{
+			name:  "only property missing colon",
+			input: `{"a" 1}`,
+			want:  `{}`,
+			errs:  []wantErr{{5, ErrUnexpectedToken}},
+		},
+		{
+			name:  "only property missing key",
+			input: `{:1}`,
+			want:  `{}`,
+			errs:  []wantErr{{1, ErrUnexpectedToken}},
+		},
+		{
+			name:  "only item missing",
+			input: `[,]`,
+			want:  `[]`,
+			errs:  []wantErr{{1, ErrUnexpectedToken}},
+		},
+		{
+			name:  "only item bad literal",
+			input: `[nul]`,
+			want:  `[]`,
+			errs:  []wantErr{{1, ErrInvalidLiteral}},
+		},
+		{
+			name:  "only item bad number",
+			input: `[-]`,
+			want:  `[]`,
+			errs:  []wantErr{{2, ErrInvalidNumber}},
+		},
+		{
+			name:  "only item mismatched closer",
+			input: `[}]`,
+			want:  `[]`,
+			errs:  []wantErr{{1, ErrUnexpectedToken}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, err := New(lexer.Lex(tt.input), Recover()).Parse()
+			if got, printErr := printer.Sprint(root.Value); assert.Nil(t, printErr) {
+				assert.Equal(t, tt.want, got)
+			}
+			if tt.errs == nil {
+				assert.Nil(t, err)
+				return
+			}
+			var list ErrorList
+			if !assert.ErrorAs(t, err, &list) || !assert.Len(t, list, len(tt.errs)) {
+				return
+			}
+			for i, want := range tt.errs {
+				var syntaxErr *SyntaxError
+				if assert.ErrorAs(t, list[i], &syntaxErr) {
+					assert.Equal(t, want.offset, syntaxErr.Offset)
+				}
+				assert.ErrorIs(t, list[i], want.kind)
+			}
+		})
+	}
+
+	t.Run("no value", func(t *testing.T) {
+		root, err := New(lexer.Lex(`]`), Recover()).Parse()
+		assert.Nil(t, root)
+		assert.ErrorIs(t, err, ErrUnexpectedToken)
+	})
+
+	t.Run("duplicate keys", func(t *testing.T) {
+		root, err := New(lexer.Lex(`{"a": 1, "a": 2}`), Recover(), DisallowDuplicateKeys()).Parse()
+		assert.Len(t, root.Value.Value.(*ast.Object).Children, 2)
+		assert.ErrorIs(t, err, ErrDuplicateKey)
+	})
+
+	t.Run("limits", func(t *testing.T) {
+		root, err := New(lexer.Lex(`[[[1, ]]]`), Recover(), MaxDepth(2)).Parse()
+		assert.Nil(t, root)
+		assert.ErrorIs(t, err, ErrMaxDepth)
+	})
+
+	t.Run("span", func(t *testing.T) {
+		_, err := New(lexer.Lex(`[1 "abc"]`), Recover()).Parse()
+		var syntaxErr *SyntaxError
+		if assert.ErrorAs(t, err, &syntaxErr) {
+			assert.Equal(t, 3, syntaxErr.Offset)
+			assert.Equal(t, 8, syntaxErr.End)
+		}
+	})
+}
+
+func TestParser_ParseRawString(t *testing.T) {
+	p := New(lexer.Lex(`{"name": "café \"x\""}`))
+	result, err := p.Parse()
+	assert.Nil(t, err)
+
+	lit := result.Value.Value.(*ast.Object).Children[0].Value.(*ast.Value).Value.(*ast.Literal)
+	assert.Equal(t, `café "x"`, lit.Val)
+	assert.Equal(t, `"café \"x\""`, lit.Raw)
+}
+
+func TestParser_ParseInvalidEscape(t *testing.T) {
+	var tests = []struct {
+		name       string
+		input      string
+		wantOffset int
+	}{
+		{"unknown escape in value", `{"a": "x\qy"}`, 8},
+		{"short unicode escape in value", `{"a": "\u12"}`, 7},
+		{"unknown escape in key", `{"\a": 1}`, 2},
+		{"control character", "{\"a\": \"x\ty\"}", 8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(lexer.Lex(tt.input)).Parse()
+			var syntaxErr *SyntaxError
+			if assert.ErrorAs(t, err, &syntaxErr) {
+				assert.Equal(t, tt.wantOffset, syntaxErr.Offset)
+			}
+		})
+	}
+
+	t.Run("allow invalid escapes", func(t *testing.T) {
+		result, err := New(lexer.Lex(`{"a": "x\qy"}`), AllowInvalidEscapes()).Parse()
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]any{"a": `x\qy`}, result.ToGo())
+	})
+}
+
+func TestParser_ParseTrailingContent(t *testing.T) {
+	_, err := New(lexer.Lex(`{"a": 1} garbage`)).Parse()
+	var syntaxErr *SyntaxError
+	if assert.ErrorAs(t, err, &syntaxErr) {
+		assert.Equal(t, 9, syntaxErr.Offset)
+		assert.EqualError(t, err, "failed to parse: unexpected trailing content at offset 9")
+	}
+
+	t.Run("allow trailing", func(t *testing.T) {
+		p := New(lexer.Lex(`{"a": 1} [2] {}`), AllowTrailing())
+
+		var docs []any
+		for p.More() {
+			result, err := p.Parse()
+			assert.Nil(t, err)
+			docs = append(docs, result.ToGo())
+		}
+		assert.Equal(t, []any{
+			map[string]any{"a": int64(1)},
+			[]any{int64(2)},
+			map[string]any{},
+		}, docs)
+	})
+}
+
+func TestParser_ParseNestedContainers(t *testing.T) {
+	var tests = []struct {
+		name  string
+		input string
+		want  any
+	}{
+		{"empty object", `{}`, map[string]any{}},
+		{"empty array", `[]`, []any{}},
+		{"nested empty containers", `{"a": {}, "b": [], "c": [{}, []]}`, map[string]any{
+			"a": map[string]any{},
+			"b": []any{},
+			"c": []any{map[string]any{}, []any{}},
+		}},
+		{"nested arrays followed by property", `{"a": [[1]], "b": 2}`, map[string]any{
+			"a": []any{[]any{int64(1)}},
+			"b": int64(2),
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := New(lexer.Lex(tt.input)).Parse()
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, result.ToGo())
+		})
+	}
+}
+
+func TestParser_ParseLiteralRoot(t *testing.T) {
+	var tests = []struct {
+		name  string
+		input string
+		want  *ast.Literal
+	}{
+		{"string", ` "hello" `, &ast.Literal{LiteralType: ast.LiteralTypeString, Val: "hello", Raw: `"hello"`}},
+		{"number", `42`, &ast.Literal{LiteralType: ast.LiteralTypeNumber, Val: int64(42), Raw: "42"}},
+		{"true", `true`, ast.Bool(true)},
+		{"null", `null`, ast.Null()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := New(lexer.Lex(tt.input)).Parse()
+			assert.Nil(t, err)
+			assert.Equal(t, &ast.RootNode{
+				RootNodeType: ast.RootNodeTypeLiteral,
+				Value:        &ast.Value{Value: tt.want},
+			}, result)
+		})
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, input := range []string{`42 43`, `01`, `"a`, ``} {
+			_, err := New(lexer.Lex(input)).Parse()
+			assert.Error(t, err, input)
+		}
+	})
+}
+
+func TestParser_ParseNumericSeparators(t *testing.T) {
+	var tests = []struct {
+		input string
+		want  *ast.Literal
+	}{
+		{"1_000", &ast.Literal{LiteralType: ast.LiteralTypeNumber, Val: int64(1000), Raw: "1000"}},
+		{"-9_223_372_036_854_775_807", &ast.Literal{LiteralType: ast.LiteralTypeNumber, Val: int64(-9223372036854775807), Raw: "-9223372036854775807"}},
+		{"1_000.000_5e1_0", &ast.Literal{LiteralType: ast.LiteralTypeNumber, Val: 1000.0005e10, Raw: "1000.0005e10"}},
+		{"1_0.2_5", &ast.Literal{LiteralType: ast.LiteralTypeNumber, Val: 10.25, Raw: "10.25"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			lex := lexer.Lex(tt.input, lexer.WithExtensions(lexer.ExtNumericSeparators))
+			got, err := New(lex).Parse()
+			if assert.Nil(t, err) {
+				assert.Equal(t, tt.want, got.Value.Value)
+			}
+
+			_, err = New(lexer.Lex(tt.input)).Parse()
+			assert.ErrorIs(t, err, lexer.ErrInvalidNumber)
+			assert.ErrorContains(t, err, "'_' digit separators are not allowed")
+		})
+	}
+}
+
+func TestParser_ParseLossless(t *testing.T) {
+	input := " {\"a\" :\t[ 1 ,[ ] ],\n\"\\u0062\": { }\n} "
+	got, err := New(lexer.Lex(input), Lossless()).Parse()
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, " ", got.Before)
+	assert.Equal(t, " ", got.After)
+
+	obj := got.Value.Value.(*ast.Object)
+	assert.Equal(t, ast.PropertyTrivia{BeforeColon: " ", BeforeValue: "\t"}, obj.Children[0].Trivia)
+	assert.Equal(t, `"a"`, obj.Children[0].Raw)
+	assert.Equal(t, ast.PropertyTrivia{BeforeKey: "\n", BeforeValue: " "}, obj.Children[1].Trivia)
+	assert.Equal(t, "\n", obj.Trivia, "trivia after the line of the last property")
+	assert.Equal(t, `"\u0062"`, obj.Children[1].Raw)
+	assert.Equal(t, " ", obj.Children[1].Value.(*ast.Value).Value.(*ast.Object).Trivia)
+
+	array := obj.Children[0].Value.(*ast.Value).Value.(*ast.Array)
+	assert.Equal(t, []ast.ArrayItem{
+		{Value: array.Children[0].Value, Before: " ", After: " "},
+		{Value: array.Children[1].Value, After: " "},
+	}, array.Children)
+	assert.Equal(t, " ", array.Children[1].Value.(*ast.Array).Trivia)
+
+	got, err = New(lexer.Lex(input)).Parse()
+	if assert.Nil(t, err) {
+		assert.Empty(t, got.Before)
+		assert.Empty(t, got.Value.Value.(*ast.Object).Children[0].Raw)
+	}
+}
+
+func TestParser_ParseDuplicateKeys(t *testing.T) {
+	input := `{"a": 1, "b": {"x": 1, "y": [1]}, "c": true, "a": 2, "b": {"y": [2], "z": {"k": 3}}, "a": [3]}`
+	var tests = []struct {
+		policy DuplicateKeys
+		keys   []string
+		want   any
+	}{
+		{KeepAll, []string{"a", "b", "c", "a", "b", "a"}, nil},
+		{KeepFirst, []string{"a", "b", "c"}, map[string]any{
+			"a": int64(1), "b": map[string]any{"x": int64(1), "y": []any{int64(1)}}, "c": true,
+		}},
+		{KeepLast, []string{"a", "b", "c"}, map[string]any{
+			"a": []any{int64(3)}, "b": map[string]any{"y": []any{int64(2)}, "z": map[string]any{"k": int64(3)}}, "c": true,
+		}},
+		{MergeObjects, []string{"a", "b", "c"}, map[string]any{
+			"a": []any{int64(3)}, "b": map[string]any{"x": int64(1), "y": []any{int64(2)}, "z": map[string]any{"k": int64(3)}}, "c": true,
+		}},
+		{CollectValues, []string{"a", "b", "c"}, map[string]any{
+			"a": []any{int64(1), int64(2), []any{int64(3)}},
+			"b": []any{
+				map[string]any{"x": int64(1), "y": []any{int64(1)}},
+				map[string]any{"y": []any{int64(2)}, "z": map[string]any{"k": int64(3)}},
+			},
+			"c": true,
+		}},
+	}
+	for _, tt := range tests {
+		got, err := New(lexer.Lex(input), OnDuplicateKeys(tt.policy)).Parse()
+		if !assert.Nil(t, err) {
+			continue
+		}
+		obj := got.Value.Value.(*ast.Object)
+		assert.Equal(t, tt.keys, obj.Keys())
+		if tt.want != nil {
+			assert.Equal(t, tt.want, got.ToGo())
+		}
+	}
+
+	_, err := New(lexer.Lex(input), OnDuplicateKeys(KeepFirst), DisallowDuplicateKeys()).Parse()
+	assert.ErrorIs(t, err, ErrDuplicateKey)
+
+	got, err := New(lexer.Lex(input), OnDuplicateKeys(KeepFirst), DisallowDuplicateKeys(), Recover()).Parse()
+	assert.ErrorIs(t, err, ErrDuplicateKey)
+	assert.Equal(t, []string{"a", "b", "c"}, got.Value.Value.(*ast.Object).Keys())
+
+	t.Run("merge lazy objects", func(t *testing.T) {
+		for _, depth := range []int{0, 1} {
+			got, err := New(lexer.Lex(`{"b": {"y": {"p": 1}, "x": [1]}, "b": {"y": {"q": 2}, "x": 2}}`), OnDuplicateKeys(MergeObjects), LazyBelow(depth)).Parse()
+			if assert.Nil(t, err) {
+				assert.Equal(t, map[string]any{
+					"b": map[string]any{"y": map[string]any{"p": int64(1), "q": int64(2)}, "x": int64(2)},
+				}, got.ToGo(), depth)
+			}
+		}
+
+		_, err := New(lexer.Lex(`{"b": {"y": 1}, "b": {"y" 2}}`), OnDuplicateKeys(MergeObjects), LazyBelow(0)).Parse()
+		var syntaxErr *SyntaxError
+		if assert.ErrorAs(t, err, &syntaxErr) {
+			assert.Equal(t, 26, syntaxErr.Offset)
+		}
+	})
+}
+
+func TestParser_ParseNumberExtensions(t *testing.T) {
+	ext := lexer.WithExtensions(lexer.ExtLeadingPlus | lexer.ExtLeadingDot | lexer.ExtTrailingDot)
+	var tests = []struct {
+		input string
+		want  *ast.Literal
+		err   string
+	}{
+		{"+5", &ast.Literal{LiteralType: ast.LiteralTypeNumber, Val: int64(5), Raw: "5"}, "leading '+' is not allowed"},
+		{".5", &ast.Literal{LiteralType: ast.LiteralTypeNumber, Val: 0.5, Raw: "0.5"}, "leading '.' is not allowed"},
+		{"-.5e1", &ast.Literal{LiteralType: ast.LiteralTypeNumber, Val: -5.0, Raw: "-0.5e1"}, "leading '.' is not allowed"},
+		{"5.", &ast.Literal{LiteralType: ast.LiteralTypeNumber, Val: 5.0, Raw: "5.0"}, "trailing '.' is not allowed"},
+		{"+5.E2", &ast.Literal{LiteralType: ast.LiteralTypeNumber, Val: 500.0, Raw: "5.0E2"}, "leading '+' is not allowed"},
+		{"+.5", &ast.Literal{LiteralType: ast.LiteralTypeNumber, Val: 0.5, Raw: "0.5"}, "leading '+' is not allowed"},
+		{"-1.5", &ast.Literal{LiteralType: ast.LiteralTypeNumber, Val: -1.5, Raw: "-1.5"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := New(lexer.Lex(tt.input, ext)).Parse()
+			if assert.Nil(t, err) {
+				assert.Equal(t, tt.want, got.Value.Value)
+			}
+
+			got, err = New(lexer.Lex(tt.input, ext), Lossless()).Parse()
+			if assert.Nil(t, err) {
+				assert.Equal(t, tt.input, got.Value.Value.(*ast.Literal).Raw, "source kept when lossless")
+			}
+
+			_, err = New(lexer.Lex(tt.input)).Parse()
+			if tt.err != "" {
+				assert.ErrorIs(t, err, lexer.ErrInvalidNumber)
+				assert.ErrorContains(t, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestParser_ParseLimits(t *testing.T) {
+	var tests = []struct {
+		name       string
+		input      string
+		opt        Option
+		wantLimit  Limit
+		wantOffset int
+	}{
+		{"max bytes", `{"a": [1, 2, 3]}`, MaxBytes(10), LimitBytes, 10},
+		{"max tokens", `[1, 2, 3]`, MaxTokens(4), LimitTokens, 5},
+		{"max string length", `{"a": "abcdef"}`, MaxStringLength(5), LimitStringLength, 6},
+		{"max string length of key", `{"abcdef": 1}`, MaxStringLength(5), LimitStringLength, 1},
+		{"max children of array", `[1, 2, 3]`, MaxChildren(2), LimitChildren, 7},
+		{"max children of object", `{"a": 1, "b": 2}`, MaxChildren(1), LimitChildren, 9},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(lexer.Lex(tt.input), tt.opt).Parse()
+			var limitErr *LimitError
+			if assert.ErrorAs(t, err, &limitErr) {
+				assert.Equal(t, tt.wantLimit, limitErr.Limit)
+				assert.Equal(t, tt.wantOffset, limitErr.Offset)
+			}
+		})
+	}
+
+	t.Run("within limits", func(t *testing.T) {
+		_, err := New(lexer.Lex(`{"a": [1, 2]}`), MaxBytes(13), MaxTokens(9), MaxStringLength(1), MaxChildren(2)).Parse()
+		assert.Nil(t, err)
+	})
+}
+
+func TestParser_ParseStats(t *testing.T) {
+	var (
+		stats []Stats
+		errs  []error
+	)
+	record := WithStats(func(s Stats, err error) {
+		assert.GreaterOrEqual(t, s.Duration, time.Duration(0))
+		s.Duration = 0
+		stats = append(stats, s)
+		errs = append(errs, err)
+	})
+
+	input := ` {"a": [1, {"b": null}]}`
+	_, err := New(lexer.Lex(input), record).Parse()
+	assert.Nil(t, err)
+	assert.Equal(t, []Stats{{Tokens: 13, Bytes: len(input) - 1, Nodes: 5, MaxDepth: 3}}, stats)
+
+	stats, errs = nil, nil
+	p := New(lexer.Lex(`[1] [2, [3]] `), record, AllowTrailing())
+	for p.More() {
+		_, err := p.Parse()
+		assert.Nil(t, err)
+	}
+	assert.Equal(t, []Stats{
+		{Tokens: 3, Bytes: 4, Nodes: 2, MaxDepth: 1},
+		{Tokens: 7, Bytes: 9, Nodes: 4, MaxDepth: 2},
+	}, stats)
+
+	stats, errs = nil, nil
+	_, err = New(lexer.Lex(`[[[1]]]`), record, MaxDepth(2)).Parse()
+	assert.ErrorIs(t, err, ErrMaxDepth)
+	if assert.Len(t, stats, 1) {
+		assert.Equal(t, 3, stats[0].MaxDepth)
+		assert.Equal(t, err, errs[0])
+	}
+}
+
+func TestParser_ParseHardened(t *testing.T) {
+	t.Run("max depth", func(t *testing.T) {
+		_, err := New(lexer.Lex(`{"a": [[{"b": 1}]]}`), MaxDepth(3)).Parse()
+		var limitErr *LimitError
+		if assert.ErrorAs(t, err, &limitErr) {
+			assert.Equal(t, LimitDepth, limitErr.Limit)
+			assert.Equal(t, 8, limitErr.Offset)
+		}
+	})
+
+	t.Run("duplicate keys", func(t *testing.T) {
+		_, err := New(lexer.Lex(`{"a": 1, "b": {"a": 2}, "a": 3}`), DisallowDuplicateKeys()).Parse()
+		var dupErr *DuplicateKeyError
+		if assert.ErrorAs(t, err, &dupErr) {
+			assert.Equal(t, "a", dupErr.Key)
+			assert.Equal(t, 24, dupErr.Offset)
+		}
+	})
+
+	t.Run("hardened", func(t *testing.T) {
+		_, err := New(lexer.Lex(`{"a": 1}`), Hardened()).Parse()
+		assert.Nil(t, err)
+
+		deep := strings.Repeat("[", HardenedMaxDepth+1) + strings.Repeat("]", HardenedMaxDepth+1)
+		_, err = New(lexer.Lex(deep), Hardened()).Parse()
+		assert.Error(t, err)
+
+		_, err = New(lexer.Lex(`{"a": 1, "a": 2}`), Hardened()).Parse()
+		assert.Error(t, err)
+
+		_, err = New(lexer.Lex(`{"a": "long"}`), Hardened(), MaxStringLength(2)).Parse()
+		assert.Error(t, err)
+	})
+}
+
+func TestParser_ParseLazy(t *testing.T) {
+	input := `{"a": {"b": [1, 2]}, "c": [{"d": true}], "e": 3}`
+
+	t.Run("below depth", func(t *testing.T) {
+		root, err := New(lexer.Lex(input), LazyBelow(1)).Parse()
+		if !assert.Nil(t, err) {
+			return
+		}
+		obj := root.Value.Value.(*ast.Object)
+		a, _ := obj.Get("a")
+		assert.IsType(t, &ast.Object{}, a.Value)
+		b, _ := a.Value.(*ast.Object).Get("b")
+		assert.Equal(t, &ast.RawValue{Raw: `[1, 2]`, Start: 12, End: 18}, b.Value)
+		c, _ := obj.Get("c")
+		item, _ := c.Value.(*ast.Array).At(0)
+		assert.Equal(t, &ast.RawValue{Raw: `{"d": true}`, Start: 27, End: 38}, item.Value)
+		e, _ := obj.Get("e")
+		assert.Equal(t, int64(3), e.ToGo())
+
+		v, err := ParseRaw(item.Value.(*ast.RawValue))
+		if assert.Nil(t, err) {
+			d := v.Value.(*ast.Object)
+			assert.Equal(t, 28, d.Children[0].Identifier.Start)
+			assert.Equal(t, map[string]any{"d": true}, d.ToGo())
+		}
+	})
+
+	t.Run("predicate", func(t *testing.T) {
+		root, err := New(lexer.Lex(input), Lazy(func(path []string) bool {
+			return len(path) == 1 && path[0] == "c"
+		})).Parse()
+		if !assert.Nil(t, err) {
+			return
+		}
+		obj := root.Value.Value.(*ast.Object)
+		a, _ := obj.Get("a")
+		assert.Equal(t, map[string]any{"b": []any{int64(1), int64(2)}}, a.ToGo())
+		c, _ := obj.Get("c")
+		assert.Equal(t, &ast.RawValue{Raw: `[{"d": true}]`, Start: 26, End: 39}, c.Value)
+	})
+
+	t.Run("unbalanced", func(t *testing.T) {
+		_, err := New(lexer.Lex(`{"a": [1, {"b": 2]`), LazyBelow(0)).Parse()
+		var syntaxErr *SyntaxError
+		assert.ErrorAs(t, err, &syntaxErr)
+	})
+
+	t.Run("mismatched closers", func(t *testing.T) {
+		for input, offset := range map[string]int{
+			`{"a":[1}}`:     7,
+			`{"a":{"b":1]}`: 11,
+			`[[1}]`:         3,
+		} {
+			_, err := New(lexer.Lex(input), LazyBelow(0)).Parse()
+			var syntaxErr *SyntaxError
+			if assert.ErrorAs(t, err, &syntaxErr, input) {
+				assert.Equal(t, offset, syntaxErr.Offset, input)
+				assert.ErrorIs(t, err, ErrUnexpectedToken, input)
+			}
+		}
+	})
+
+	t.Run("max depth", func(t *testing.T) {
+		_, err := New(lexer.Lex(`{"a": [[1]]}`), LazyBelow(0), MaxDepth(2)).Parse()
+		var limitErr *LimitError
+		if assert.ErrorAs(t, err, &limitErr) {
+			assert.Equal(t, LimitDepth, limitErr.Limit)
+		}
+
+		root, err := New(lexer.Lex(`{"a": [[1]], "b": [2]}`), LazyBelow(0), MaxDepth(3)).Parse()
+		if assert.Nil(t, err) {
+			b, _ := root.Value.Value.(*ast.Object).Get("b")
+			assert.Equal(t, &ast.RawValue{Raw: `[2]`, Start: 18, End: 21}, b.Value)
+		}
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		_, err := New(lexer.Lex(`{"a": [01]}`), LazyBelow(0)).Parse()
+		var syntaxErr *SyntaxError
+		if assert.ErrorAs(t, err, &syntaxErr) {
+			assert.Equal(t, 7, syntaxErr.Offset)
+		}
+	})
+}
+
+func TestParser_Reset(t *testing.T) {
+	p := New(lexer.Lex(`{"a": 1}`), MaxTokens(5))
+	_, err := p.Parse()
+	assert.Nil(t, err)
+
+	for _, input := range []string{`[1, 2]`, `{"b": true}`} {
+		p.Reset(input)
+		_, err := p.Parse()
+		assert.Nil(t, err)
+	}
+
+	p.Reset(`[1, 2, 3]`)
+	_, err = p.Parse()
+	assert.Error(t, err)
+}
+
+func BenchmarkParser_Reset(b *testing.B) {
+	input := `{"id": 1, "name": "water", "tags": ["a", "b"], "price": 1.5}`
+	p := New(lexer.Lex(input))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		p.Reset(input)
+		if _, err := p.Parse(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParser_New(b *testing.B) {
+	input := `{"id": 1, "name": "water", "tags": ["a", "b"], "price": 1.5}`
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := New(lexer.Lex(input)).Parse(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestWithArena(t *testing.T) {
+	inputs := []string{
+		`{"id": 1, "name": "water", "tags": ["a", {"b": null}], "price": 1.5}`,
+		`[true, false, [], {}]`,
+		`"s"`,
+	}
+	arena := &ast.Arena{}
+	for _, input := range inputs {
+		want, err := New(lexer.Lex(input)).Parse()
+		assert.Nil(t, err)
+		got, err := New(lexer.Lex(input), WithArena(arena)).Parse()
+		assert.Nil(t, err)
+		assert.Equal(t, want, got)
+	}
+
+	got, err := New(lexer.Lex(`{"a": [1]}`), WithArena(arena), LazyBelow(0)).Parse()
+	assert.Nil(t, err)
+	raw := got.Value.Value.(*ast.Object).Children[0].Value.(*ast.Value).Value
+	assert.Equal(t, &ast.RawValue{Raw: "[1]", Start: 6, End: 9}, raw)
+
+	arena.Reset()
+	assert.Equal(t, &ast.RawValue{}, raw)
+}
+
+func BenchmarkParser_Arena(b *testing.B) {
+	input := `{"id": 1, "name": "water", "tags": ["a", "b"], "price": 1.5}`
+	arena := &ast.Arena{}
+	p := New(lexer.Lex(input), WithArena(arena))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		arena.Reset()
+		p.Reset(input)
+		if _, err := p.Parse(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestParseArrayStream(t *testing.T) {
+	var tests = []struct {
+		name  string
+		input string
+		want  []any
+		err   error
+	}{
+		{"empty", ` [ ] `, nil, nil},
+		{"items", `[1, "two", {"three": [3]}, null]`, []any{int64(1), "two", map[string]any{"three": []any{int64(3)}}, nil}, nil},
+		{"not an array", `{"a": 1}`, nil, ErrUnexpectedToken},
+		{"bad item", `[1, $]`, []any{int64(1)}, ErrUnexpectedToken},
+		{"missing bracket", `[1, 2`, []any{int64(1), int64(2)}, ErrUnexpectedEOF},
+		{"trailing comma", `[1,]`, []any{int64(1)}, ErrTrailingComma},
+		{"trailing content", `[1] 2`, []any{int64(1)}, ErrTrailingContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []any
+			err := ParseArrayStream(iotest.OneByteReader(strings.NewReader(tt.input)), func(i int, item *ast.Value) error {
+				assert.Equal(t, len(got), i)
+				got = append(got, item.ToGo())
+				return nil
+			})
+			assert.Equal(t, tt.want, got)
+			if tt.err == nil {
+				assert.Nil(t, err)
+			} else {
+				assert.ErrorIs(t, err, tt.err)
+			}
+		})
+	}
+
+	t.Run("callback error", func(t *testing.T) {
+		stop := errors.New("stop")
+		n := 0
+		err := ParseArrayStream(strings.NewReader(`[1, 2, 3]`), func(i int, item *ast.Value) error {
+			n++
+			return stop
+		})
+		assert.Equal(t, stop, err)
+		assert.Equal(t, 1, n)
+	})
+
+	t.Run("read error", func(t *testing.T) {
+		r := iotest.TimeoutReader(iotest.OneByteReader(strings.NewReader(`[1, 2]`)))
+		err := ParseArrayStream(r, func(int, *ast.Value) error { return nil })
+		assert.ErrorIs(t, err, iotest.ErrTimeout)
+	})
+
+	t.Run("limits", func(t *testing.T) {
+		err := ParseArrayStream(strings.NewReader(`[1, 2, 3]`), func(int, *ast.Value) error { return nil }, MaxChildren(2))
+		assert.ErrorIs(t, err, ErrMaxChildren)
+	})
+}
+
+func TestParseUntil(t *testing.T) {
+	const input = `{"type": "event", "meta": {"id": [1, {"k": true}]}, "body": [1, 2, {"x": "y"}], "type": "dup"}`
+	var tests = []struct {
+		name    string
+		input   string
+		pointer string
+		want    any
+	}{
+		{"first key", input, "/type", "event"},
+		{"nested", input, "/meta/id/1", map[string]any{"k": true}},
+		{"array item literal", input, "/body/1", int64(2)},
+		{"container", input, "/body", []any{int64(1), int64(2), map[string]any{"x": "y"}}},
+		{"root", `[1]`, "", []any{int64(1)}},
+		{"escaped key", `{"a/b": {"~": 1}}`, "/a~1b/~0", int64(1)},
+		{"garbage after target", `{"type": "event", "body": [1, }`, "/type", "event"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseUntil(tt.input, tt.pointer)
+			if assert.Nil(t, err) {
+				assert.Equal(t, tt.want, got.ToGo())
+			}
+		})
+	}
+
+	_, err := ParseUntil(input, "/meta/missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.EqualError(t, err, `failed to parse: no value at "/meta/missing": value not found`)
+	_, err = ParseUntil(`{"a": 1 "b": 2}`, "/b")
+	assert.ErrorIs(t, err, ErrUnexpectedToken)
+	_, err = ParseUntil(input, "type")
+	assert.EqualError(t, err, `failed to parse: invalid JSON Pointer "type"`)
+	_, err = ParseUntil(`{"a": [[[1]]]}`, "/a/0", MaxDepth(2))
+	assert.ErrorIs(t, err, ErrMaxDepth)
+}
+
+func TestGet(t *testing.T) {
+	p := Get(`[1, 2, 3]`, MaxChildren(2))
+	_, err := p.Parse()
+	assert.Error(t, err)
+	Put(p)
+
+	p = Get(`[1, 2, 3]`)
+	result, err := p.Parse()
+	assert.Nil(t, err)
+	assert.Equal(t, []any{int64(1), int64(2), int64(3)}, result.ToGo())
+	Put(p)
+}
+
+func TestGetBytes(t *testing.T) {
+	input := []byte(`{"a": [1, "x"]}`)
+	p := GetBytes(input)
+	result, err := p.Parse()
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]any{"a": []any{int64(1), "x"}}, result.ToGo())
+
+	p.ResetBytes([]byte(`[true]`))
+	result, err = p.Parse()
+	assert.Nil(t, err)
+	assert.Equal(t, []any{true}, result.ToGo())
+	Put(p)
+}
+
+func BenchmarkGet(b *testing.B) {
+	input := `{"id": 1, "name": "water", "tags": ["a", "b"], "price": 1.5}`
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			p := Get(input)
+			if _, err := p.Parse(); err != nil {
+				b.Fatal(err)
+			}
+			Put(p)
+		}
+	})
+}
+
+func TestParseEmbedded(t *testing.T) {
+	root, err := New(lexer.Lex(`{"payload": "{\"a\": [1, \"\u00e9\"], \"b\":\ttrue}"}`)).Parse()
+	if !assert.Nil(t, err) {
+		return
+	}
+	lit := root.Value.Value.(*ast.Object).Children[0].Value.(*ast.Value).Value.(*ast.Literal)
+	e, err := ParseEmbedded(lit)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, map[string]any{"a": []any{int64(1), "é"}, "b": true}, e.Root.ToGo())
+	obj := e.Root.Value.Value.(*ast.Object)
+	array := obj.Children[0].Value.(*ast.Value).Value.(*ast.Array)
+	assert.Equal(t, 6, array.Start)
+	assert.Equal(t, 9, e.Outer(array.Start))
+	assert.Equal(t, 15, e.Outer(12), "second byte of an escaped character")
+	assert.Equal(t, 26, e.Outer(obj.Children[1].Identifier.Start))
+	assert.Equal(t, len(lit.Raw)-1, e.Outer(obj.End))
+
+	var tests = []struct {
+		name   string
+		input  string
+		offset int
+		err    error
+	}{
+		{"unescaped", `"[1,]"`, 3, ErrTrailingComma},
+		{"escaped", `"{\"a\" 1}"`, 8, ErrUnexpectedToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, err := New(lexer.Lex(tt.input)).Parse()
+			if !assert.Nil(t, err) {
+				return
+			}
+			_, err = ParseEmbedded(root.Value.Value.(*ast.Literal))
+			var syntaxErr *SyntaxError
+			if assert.ErrorAs(t, err, &syntaxErr) {
+				assert.ErrorIs(t, err, tt.err)
+				assert.Equal(t, tt.offset, syntaxErr.Offset)
+			}
+		})
+	}
+
+	_, err = ParseEmbedded(ast.Number(1))
+	assert.EqualError(t, err, "failed to parse embedded document: literal is not a string")
+	e, err = ParseEmbedded(ast.String(`[1]`))
+	if assert.Nil(t, err) {
+		assert.Equal(t, []any{int64(1)}, e.Root.ToGo())
+		assert.Equal(t, 2, e.Outer(1))
+	}
+}
+
+func TestWithRegistry(t *testing.T) {
+	var r Registry
+	r.Register("date", RecognizeTime("2006-01-02"))
+	r.Register("decimal", RecognizeDecimal())
+	r.Register("even", func(lit *ast.Literal) (any, bool) {
+		i, ok := lit.AsInt()
+		return i / 2, ok && i%2 == 0
+	})
+
+	input := `{"day": "2024-02-29", "price": "-12.30", "n": 4, "m": 3, "s": "12.", "t": "1e3", "when": "2024-02-30"}`
+	root, err := New(lexer.Lex(input), WithRegistry(&r)).Parse()
+	if !assert.Nil(t, err) {
+		return
+	}
+	obj := root.Value.Value.(*ast.Object)
+	literal := func(key string) *ast.Literal {
+		v, _ := obj.Get(key)
+		return v.Value.(*ast.Literal)
+	}
+
+	day := literal("day")
+	assert.Equal(t, "date", day.Kind)
+	assert.Equal(t, time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC), day.Typed)
+	assert.Equal(t, "2024-02-29", day.Val, "the JSON value is kept")
+
+	price := literal("price")
+	assert.Equal(t, "decimal", price.Kind)
+	if rat, ok := price.Typed.(*big.Rat); assert.True(t, ok) {
+		assert.Equal(t, "-123/10", rat.String())
+	}
+	assert.Equal(t, "even", literal("n").Kind)
+	assert.Equal(t, int64(2), literal("n").Typed)
+	for _, key := range []string{"m", "s", "t", "when"} {
+		assert.Empty(t, literal(key).Kind, key)
+		assert.Nil(t, literal(key).Typed, key)
+	}
+
+	root, err = New(lexer.Lex(input)).Parse()
+	if assert.Nil(t, err) {
+		assert.Empty(t, root.Value.Value.(*ast.Object).Children[0].Value.(*ast.Value).Value.(*ast.Literal).Kind)
+	}
 }
@@ -0,0 +1,51 @@
+package parser
+
+import (
+	"sync"
+
+	"github.com/pohedev/gj.git/lexer"
+)
+
+// pool holds Parsers, with their Lexers, for reuse by Get.
+var pool = sync.Pool{
+	New: func() any {
+		return New(lexer.Lex(""))
+	},
+}
+
+// Get returns a Parser from an internal pool, ready to parse input with opts.
+// The Parser and its Lexer buffers are reused across calls, which avoids
+// most allocations besides the AST itself. Return it with Put once done.
+func Get(input string, opts ...Option) *Parser {
+	p := get(opts)
+	p.Reset(input)
+	return p
+}
+
+// GetBytes is like Get but parses input without copying it,
+// with the same restrictions as Parser.ResetBytes.
+func GetBytes(input []byte, opts ...Option) *Parser {
+	p := get(opts)
+	p.ResetBytes(input)
+	return p
+}
+
+// get returns a Parser from the pool configured with opts.
+func get(opts []Option) *Parser {
+	p := pool.Get().(*Parser)
+	*p = Parser{lex: p.lex}
+	for _, opt := range opts {
+		opt(p)
+	}
+	if p.recover {
+		lexer.ContinueOnError()(p.lex)
+	}
+	return p
+}
+
+// Put returns a Parser obtained from Get to the pool. The Parser must not
+// be used afterwards; ASTs it returned remain valid.
+func Put(p *Parser) {
+	p.lex.Reset("")
+	pool.Put(p)
+}
@@ -0,0 +1,16 @@
+package parser
+
+import (
+	"github.com/pohedev/gj.git/ast"
+	"github.com/pohedev/gj.git/lexer"
+)
+
+// ParseRaw parses a RawValue kept by lazy parsing. Positions in the
+// returned tree are offsets in the original input.
+func ParseRaw(raw *ast.RawValue, opts ...Option) (*ast.Value, error) {
+	root, err := New(lexer.Lex(raw.Raw, lexer.WithOffset(raw.Start)), opts...).Parse()
+	if err != nil {
+		return nil, err
+	}
+	return root.Value, nil
+}
@@ -0,0 +1,89 @@
+package parser
+
+import (
+	"math/big"
+
+	"github.com/pohedev/gj.git/ast"
+)
+
+// Recognizer returns the domain-specific value of a literal, such as the
+// time held by a string, and whether the literal holds one.
+type Recognizer func(lit *ast.Literal) (any, bool)
+
+// Registry holds recognizers of literals of domain-specific types, run by
+// Parsers configured with WithRegistry, so that tools can work with typed
+// literals without changing the parser. Recognized literals keep their
+// JSON type and value, and are printed and compared as before. The zero
+// Registry is empty and ready to use; it must not be modified while used.
+type Registry struct {
+	kinds       []string
+	recognizers []Recognizer
+}
+
+// Register adds fn as the recognizer of literals of the given kind, such
+// as "date". Recognizers are tried in order of registration; the first
+// one recognizing a literal sets its kind.
+func (r *Registry) Register(kind string, fn Recognizer) {
+	r.kinds = append(r.kinds, kind)
+	r.recognizers = append(r.recognizers, fn)
+}
+
+// recognize sets the Kind and Typed fields of lit from the first
+// recognizer accepting it.
+func (r *Registry) recognize(lit *ast.Literal) {
+	for i, fn := range r.recognizers {
+		if v, ok := fn(lit); ok {
+			lit.Kind, lit.Typed = r.kinds[i], v
+			return
+		}
+	}
+}
+
+// RecognizeTime returns a Recognizer of strings holding times in one of
+// layouts, by default time.RFC3339, as a time.Time, see ast.Literal.AsTime.
+// Numbers are not recognized.
+func RecognizeTime(layouts ...string) Recognizer {
+	return func(lit *ast.Literal) (any, bool) {
+		if lit.LiteralType != ast.LiteralTypeString {
+			return nil, false
+		}
+		t, ok := lit.AsTime(layouts...)
+		if !ok {
+			return nil, false
+		}
+		return t, true
+	}
+}
+
+// RecognizeDecimal returns a Recognizer of strings holding decimal
+// numbers, such as "-12.30", as an exact *big.Rat, for amounts that must
+// not be rounded to a float64.
+func RecognizeDecimal() Recognizer {
+	return func(lit *ast.Literal) (any, bool) {
+		s, ok := lit.AsString()
+		if !ok || !isDecimal(s) {
+			return nil, false
+		}
+		return new(big.Rat).SetString(s)
+	}
+}
+
+// isDecimal reports whether s is an optional minus sign followed by
+// digits with an optional fraction, such as "-12.30".
+func isDecimal(s string) bool {
+	if len(s) > 0 && s[0] == '-' {
+		s = s[1:]
+	}
+	digits, dot := 0, false
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c >= '0' && c <= '9':
+			digits++
+		case c == '.' && !dot && digits > 0 && i < len(s)-1:
+			dot = true
+		default:
+			return false
+		}
+	}
+	return digits > 0
+}
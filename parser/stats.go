@@ -0,0 +1,12 @@
+package parser
+
+import "time"
+
+// Stats describes a call to Parse, see WithStats.
+type Stats struct {
+	Tokens   int           // Tokens of the document.
+	Bytes    int           // Bytes of input from the first token up to the next token after the document or EOF.
+	Nodes    int           // Objects, arrays, literals and raw values built.
+	MaxDepth int           // Deepest nesting of objects and arrays reached.
+	Duration time.Duration // Time spent in Parse.
+}
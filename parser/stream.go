@@ -0,0 +1,80 @@
+package parser
+
+import (
+	"io"
+
+	"github.com/pohedev/gj.git/ast"
+	"github.com/pohedev/gj.git/lexer"
+	"github.com/pohedev/gj.git/token"
+)
+
+// streamChunkSize is the size of the chunks read by ParseArrayStream.
+const streamChunkSize = 64 << 10
+
+// ParseArrayStream parses a document made of a top-level array read from r,
+// calling fn with each item as soon as it is parsed, so that only one item
+// is held in memory at a time. Parsing stops at the first error, including
+// one returned by fn, which is returned as is. Lazy, Recover and Lossless
+// options are ignored.
+func ParseArrayStream(r io.Reader, fn func(i int, item *ast.Value) error, opts ...Option) error {
+	p := &Parser{lex: lexer.Lex(""), src: r, buf: make([]byte, streamChunkSize)}
+	for _, opt := range opts {
+		opt(p)
+	}
+	p.lazy = nil
+	p.trackPath = false
+	p.recover = false
+	p.lex.Feed(nil)
+	p.reset()
+
+	if !p.isCurrentToken(token.LeftBracket) {
+		return p.fail(p.unexpected("'['"))
+	}
+	if err := p.enter(); err != nil {
+		return err
+	}
+	p.next()
+
+	for i := 0; ; i++ {
+		if i == 0 && p.isCurrentToken(token.RightBracket) {
+			p.next()
+			break
+		}
+		if err := p.checkChildren(i); err != nil {
+			return err
+		}
+		item, err := p.parseValue()
+		if err != nil {
+			return p.fail(err)
+		}
+		if err := fn(i, item); err != nil {
+			return err
+		}
+
+		if p.isCurrentToken(token.RightBracket) {
+			p.next()
+			break
+		}
+		if !p.isCurrentToken(token.Comma) {
+			return p.fail(p.unexpected("',' or ']'"))
+		}
+		p.next()
+		if p.isCurrentToken(token.RightBracket) {
+			return p.fail(p.errorAt(p.previous, ErrTrailingComma, "trailing comma in array"))
+		}
+	}
+
+	if !p.isCurrentToken(token.EOF) && !p.allowTrailing {
+		return p.fail(p.errorAt(p.current, ErrTrailingContent, "unexpected trailing content"))
+	}
+	return p.err
+}
+
+// fail returns the sticky error of the Parser if any, which caused err,
+// or err otherwise.
+func (p *Parser) fail(err error) error {
+	if p.err != nil {
+		return p.err
+	}
+	return err
+}
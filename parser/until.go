@@ -0,0 +1,71 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/pohedev/gj.git/ast"
+	"github.com/pohedev/gj.git/lexer"
+	"github.com/pohedev/gj.git/path"
+)
+
+// errReached stops parsing once the target of ParseUntil is parsed.
+var errReached = errors.New("target reached")
+
+// ParseUntil parses input up to the value at JSON Pointer pointer and
+// returns that value, without reading the rest of the input: for
+// sniffing a field such as "/type" of a large payload, most of the work
+// is saved. Objects and arrays off the way to the target are skipped
+// like lazy values, and syntax errors after the target go unnoticed.
+// When a key appears more than once, the first value is returned.
+// A missing value is reported as ErrNotFound. Lazy and Recover options
+// are ignored.
+func ParseUntil(input string, pointer string, opts ...Option) (*ast.Value, error) {
+	if pointer != "" && pointer[0] != '/' {
+		return nil, fmt.Errorf("failed to parse: invalid JSON Pointer %q", pointer)
+	}
+	segs, err := path.Parse(pointer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse: %w", err)
+	}
+	target := make([]string, len(segs))
+	for i, seg := range segs {
+		target[i] = seg.Key
+	}
+
+	p := &Parser{lex: lexer.Lex(input)}
+	for _, opt := range opts {
+		opt(p)
+	}
+	p.recover = false
+	p.trackPath = true
+	p.until = target
+	p.lazy = func(path []string) bool {
+		n := min(len(path), len(target))
+		return !slices.Equal(path[:n], target[:n])
+	}
+	p.reset()
+
+	_, err = p.Parse()
+	if errors.Is(err, errReached) {
+		if v, ok := p.found.(*ast.Value); ok {
+			return v, nil
+		}
+		return &ast.Value{Value: p.found}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("failed to parse: no value at %q: %w", pointer, ErrNotFound)
+}
+
+// reached returns errReached if node, just parsed at the current path,
+// is the target of ParseUntil.
+func (p *Parser) reached(node any) error {
+	if p.until == nil || !slices.Equal(p.path, p.until) {
+		return nil
+	}
+	p.found = node
+	return errReached
+}
@@ -0,0 +1,185 @@
+package path
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Segment represents a single step of a Path. Array indices are
+// stored in Key as decimal strings.
+type Segment struct {
+	Key      string // Object key or array index.
+	Wildcard bool   // Matches any single key or index.
+}
+
+// Path represents a location, or a pattern of locations, in a JSON document.
+type Path []Segment
+
+// Parse parses s into a Path. Two syntaxes are accepted:
+//   - JSON Pointer (RFC 6901), e.g. "/a/b/0". The empty string is the root.
+//   - dot/bracket paths, e.g. "$.a.b[0]", "a.b[0]", "$.*.password",
+//     "a['key.with.dots']". "*" and "[*]" match any single key or index.
+func Parse(s string) (Path, error) {
+	if s == "" {
+		return Path{}, nil
+	}
+	if s[0] == '/' {
+		return parsePointer(s)
+	}
+	return parseDotted(s)
+}
+
+// MustParse is like Parse but panics if s cannot be parsed.
+func MustParse(s string) Path {
+	p, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// parsePointer parses JSON Pointer s.
+func parsePointer(s string) (Path, error) {
+	var p Path
+	for _, token := range strings.Split(s[1:], "/") {
+		p = append(p, Segment{Key: Unescape(token)})
+	}
+	return p, nil
+}
+
+// parseDotted parses dot/bracket path s.
+func parseDotted(s string) (Path, error) {
+	p := Path{}
+	rest := strings.TrimPrefix(s, "$")
+	first := len(rest) == len(s)
+
+	for rest != "" {
+		switch {
+		case rest[0] == '.' || first && rest[0] != '[':
+			if rest[0] == '.' {
+				rest = rest[1:]
+			}
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			name := rest[:end]
+			if name == "" {
+				return nil, fmt.Errorf("failed to parse path %q: empty key", s)
+			}
+			p = append(p, Segment{Key: name, Wildcard: name == "*"})
+			rest = rest[end:]
+
+		case rest[0] == '[':
+			seg, n, err := parseBracket(rest)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse path %q: %w", s, err)
+			}
+			p = append(p, seg)
+			rest = rest[n:]
+
+		default:
+			return nil, fmt.Errorf("failed to parse path %q: unexpected %q", s, rest[0])
+		}
+		first = false
+	}
+	return p, nil
+}
+
+// parseBracket parses a bracket segment at the start of s and returns it
+// together with the number of bytes consumed.
+func parseBracket(s string) (Segment, int, error) {
+	if len(s) > 1 && (s[1] == '\'' || s[1] == '"') {
+		quote := s[1]
+		end := strings.IndexByte(s[2:], quote)
+		if end < 0 || len(s) < end+4 || s[end+3] != ']' {
+			return Segment{}, 0, fmt.Errorf("unterminated quoted key")
+		}
+		return Segment{Key: s[2 : end+2]}, end + 4, nil
+	}
+
+	end := strings.IndexByte(s, ']')
+	if end < 0 {
+		return Segment{}, 0, fmt.Errorf("unterminated bracket")
+	}
+	inner := s[1:end]
+	if inner == "*" {
+		return Segment{Key: inner, Wildcard: true}, end + 1, nil
+	}
+	if i, err := strconv.Atoi(inner); err != nil || i < 0 {
+		return Segment{}, 0, fmt.Errorf("invalid array index %q", inner)
+	}
+	return Segment{Key: inner}, end + 1, nil
+}
+
+// Match reports whether the concrete location given as reference tokens
+// (object keys and decimal array indices) matches p.
+func (p Path) Match(tokens []string) bool {
+	if len(p) != len(tokens) {
+		return false
+	}
+	for i, seg := range p {
+		if !seg.Wildcard && seg.Key != tokens[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Pointer returns p formatted as JSON Pointer.
+func (p Path) Pointer() string {
+	var b strings.Builder
+	for _, seg := range p {
+		b.WriteByte('/')
+		b.WriteString(Escape(seg.Key))
+	}
+	return b.String()
+}
+
+// String returns p in dot/bracket syntax rooted at "$".
+func (p Path) String() string {
+	var b strings.Builder
+	b.WriteByte('$')
+	for _, seg := range p {
+		switch {
+		case seg.Wildcard:
+			b.WriteString(".*")
+		case isIndex(seg.Key):
+			b.WriteString("[" + seg.Key + "]")
+		case strings.ContainsAny(seg.Key, ".[]'*") || seg.Key == "":
+			b.WriteString(`["` + seg.Key + `"]`)
+		default:
+			b.WriteString("." + seg.Key)
+		}
+	}
+	return b.String()
+}
+
+// isIndex reports whether key is a decimal array index.
+func isIndex(key string) bool {
+	if key == "" {
+		return false
+	}
+	for _, r := range key {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+var (
+	escaper   = strings.NewReplacer("~", "~0", "/", "~1")
+	unescaper = strings.NewReplacer("~1", "/", "~0", "~")
+)
+
+// Escape escapes a JSON Pointer reference token.
+func Escape(token string) string {
+	return escaper.Replace(token)
+}
+
+// Unescape unescapes a JSON Pointer reference token.
+func Unescape(token string) string {
+	return unescaper.Replace(token)
+}
@@ -0,0 +1,56 @@
+package path
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	var tests = []struct {
+		name  string
+		input string
+		want  Path
+	}{
+		{"root pointer", "", Path{}},
+		{"root dotted", "$", Path{}},
+		{"pointer", "/a/b~1c/0/~0d", Path{{Key: "a"}, {Key: "b/c"}, {Key: "0"}, {Key: "~d"}}},
+		{"dotted", "$.a.b[0].c", Path{{Key: "a"}, {Key: "b"}, {Key: "0"}, {Key: "c"}}},
+		{"dotted without root", "a.b[0]", Path{{Key: "a"}, {Key: "b"}, {Key: "0"}}},
+		{"index without root", "[1].a", Path{{Key: "1"}, {Key: "a"}}},
+		{"wildcards", "$.*.password[*]", Path{{Key: "*", Wildcard: true}, {Key: "password"}, {Key: "*", Wildcard: true}}},
+		{"quoted key", `$['a.b']["c"]`, Path{{Key: "a.b"}, {Key: "c"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := Parse(tt.input)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, p)
+		})
+	}
+}
+
+func TestParse_Error(t *testing.T) {
+	for _, input := range []string{"$..a", "$.a[", "$.a[x]", "$['a]", "$a"} {
+		t.Run(input, func(t *testing.T) {
+			_, err := Parse(input)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestPath_Match(t *testing.T) {
+	p := MustParse("$.*.password")
+
+	assert.True(t, p.Match([]string{"user", "password"}))
+	assert.True(t, p.Match([]string{"0", "password"}))
+	assert.False(t, p.Match([]string{"password"}))
+	assert.False(t, p.Match([]string{"user", "name"}))
+}
+
+func TestPath_String(t *testing.T) {
+	p := Path{{Key: "a"}, {Key: "0"}, {Key: "b.c"}, {Key: "*", Wildcard: true}}
+
+	assert.Equal(t, `$.a[0]["b.c"].*`, p.String())
+	assert.Equal(t, "/a/0/b.c/*", p.Pointer())
+}
@@ -0,0 +1,84 @@
+// Package position converts the byte offsets stored in the AST, such as
+// the Start and End of nodes, to lines and columns and back.
+package position
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Position is a location in a source: a byte offset and the matching
+// 1-based line and column. Columns count runes, as editors do.
+type Position struct {
+	Offset int
+	Line   int
+	Column int
+}
+
+// String returns the position as "line:column".
+func (p Position) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
+// File converts the offsets of a source. It is built once from the
+// source, in a single scan, and is safe for concurrent use.
+type File struct {
+	src   string
+	lines []int // Offsets of the starts of lines.
+}
+
+// NewFile returns a File for src. Lines end with "\n"; a "\r" before it
+// counts as the last column of the line.
+func NewFile(src string) *File {
+	f := &File{src: src, lines: []int{0}}
+	for i := 0; ; {
+		j := strings.IndexByte(src[i:], '\n')
+		if j < 0 {
+			break
+		}
+		i += j + 1
+		f.lines = append(f.lines, i)
+	}
+	return f
+}
+
+// LineCount returns the number of lines of the source, at least 1.
+func (f *File) LineCount() int {
+	return len(f.lines)
+}
+
+// Position returns the position of offset, clamped to the source.
+func (f *File) Position(offset int) Position {
+	offset = max(0, min(offset, len(f.src)))
+	// The line is the last one starting at or before offset.
+	line := sort.SearchInts(f.lines, offset+1)
+	start := f.lines[line-1]
+	return Position{
+		Offset: offset,
+		Line:   line,
+		Column: utf8.RuneCountInString(f.src[start:offset]) + 1,
+	}
+}
+
+// Offset returns the byte offset of the 1-based line and column, and
+// false when there is no such position. The column one past the end of a
+// line, that of its newline or of the end of the source, is accepted.
+func (f *File) Offset(line, column int) (int, bool) {
+	if line < 1 || line > len(f.lines) || column < 1 {
+		return 0, false
+	}
+	offset, end := f.lines[line-1], len(f.src)
+	if line < len(f.lines) {
+		end = f.lines[line] - 1
+	}
+	for ; column > 1; column-- {
+		if offset >= end {
+			return 0, false
+		}
+		_, size := utf8.DecodeRuneInString(f.src[offset:end])
+		offset += size
+	}
+	return offset, true
+}
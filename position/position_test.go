@@ -0,0 +1,66 @@
+package position
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFile_Position(t *testing.T) {
+	src := "{\n  \"é\": 1,\r\n\t\"b\": 2\n}"
+	f := NewFile(src)
+	assert.Equal(t, 4, f.LineCount())
+
+	tests := []struct {
+		offset int
+		want   Position
+	}{
+		{0, Position{0, 1, 1}},
+		{1, Position{1, 1, 2}},
+		{2, Position{2, 2, 1}},
+		{4, Position{4, 2, 3}},
+		{8, Position{8, 2, 6}},
+		{13, Position{13, 2, 11}},
+		{14, Position{14, 3, 1}},
+		{15, Position{15, 3, 2}},
+		{len(src), Position{len(src), 4, 2}},
+		{len(src) + 10, Position{len(src), 4, 2}},
+		{-1, Position{0, 1, 1}},
+	}
+	for _, tt := range tests {
+		got := f.Position(tt.offset)
+		assert.Equal(t, tt.want, got, tt.offset)
+
+		offset, ok := f.Offset(got.Line, got.Column)
+		assert.True(t, ok, tt.offset)
+		assert.Equal(t, got.Offset, offset)
+	}
+	assert.Equal(t, "2:6", f.Position(8).String())
+}
+
+func TestFile_Offset(t *testing.T) {
+	f := NewFile("ab\nc")
+	tests := []struct {
+		line, column int
+		want         int
+		ok           bool
+	}{
+		{1, 1, 0, true},
+		{1, 3, 2, true},
+		{1, 4, 0, false},
+		{2, 2, 4, true},
+		{2, 3, 0, false},
+		{3, 1, 0, false},
+		{0, 1, 0, false},
+		{1, 0, 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := f.Offset(tt.line, tt.column)
+		assert.Equal(t, tt.ok, ok, "%d:%d", tt.line, tt.column)
+		assert.Equal(t, tt.want, got, "%d:%d", tt.line, tt.column)
+	}
+
+	empty := NewFile("")
+	assert.Equal(t, 1, empty.LineCount())
+	assert.Equal(t, Position{0, 1, 1}, empty.Position(0))
+}
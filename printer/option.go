@@ -0,0 +1,85 @@
+package printer
+
+// Option configures a Printer.
+type Option func(*Printer)
+
+// Indent makes the Printer write one property or item per line,
+// indented by indent for each level of nesting.
+func Indent(indent string) Option {
+	return func(p *Printer) {
+		p.indent = indent
+	}
+}
+
+// EscapeHTML makes the Printer escape '<', '>' and '&' in strings as
+// \u003c, \u003e and \u0026, and U+2028 and U+2029 as \u2028 and \u2029,
+// like encoding/json, so that output can be embedded in HTML and
+// JavaScript.
+func EscapeHTML() Option {
+	return func(p *Printer) {
+		p.escapeHTML = true
+	}
+}
+
+// ASCIIOnly makes the Printer escape every non-ASCII character in strings
+// as \uXXXX, characters beyond U+FFFF as surrogate pairs, so that output is
+// pure ASCII for systems that do not handle UTF-8.
+func ASCIIOnly() Option {
+	return func(p *Printer) {
+		p.asciiOnly = true
+	}
+}
+
+// WithColors makes the Printer wrap tokens in the ANSI escape sequences
+// of c, for display in terminals.
+func WithColors(c Colors) Option {
+	return func(p *Printer) {
+		p.colors = c
+	}
+}
+
+// SortKeys makes the Printer write the properties of objects sorted by key
+// in byte order. When a key appears more than once, only the last property
+// is written.
+func SortKeys() Option {
+	return func(p *Printer) {
+		p.sortKeys = true
+	}
+}
+
+// CanonicalNumbers makes the Printer write numbers in canonical form instead
+// of their source text, so that equal numbers are written the same way,
+// e.g. 1.0E+2 as 100 and -0 as 0. See transform.NormalizeNumbers.
+func CanonicalNumbers() Option {
+	return func(p *Printer) {
+		p.canonicalNumbers = true
+	}
+}
+
+// Lossless makes the Printer write the trivia recorded by parser.Lossless
+// and the source text of keys and strings, so that a document parsed with
+// it is printed back byte for byte. Nodes without trivia, such as nodes
+// added after parsing, are written compactly, and keys and strings whose
+// value was changed are escaped again. Indent is ignored.
+func Lossless() Option {
+	return func(p *Printer) {
+		p.lossless = true
+	}
+}
+
+// Deterministic makes the Printer write byte-identical output for
+// structurally equal documents, for golden files and reproducible builds:
+// it implies SortKeys and CanonicalNumbers, disables EscapeHTML, ASCIIOnly,
+// Lossless and colors, so that strings are always escaped the same way,
+// and writes RawValue nodes parsed. Indent may be given after Deterministic.
+func Deterministic() Option {
+	return func(p *Printer) {
+		p.sortKeys = true
+		p.canonicalNumbers = true
+		p.parseRaw = true
+		p.escapeHTML = false
+		p.asciiOnly = false
+		p.lossless = false
+		p.colors = Colors{}
+	}
+}
@@ -0,0 +1,358 @@
+// Package printer serializes AST nodes back into JSON text.
+package printer
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pohedev/gj.git/ast"
+	"github.com/pohedev/gj.git/internal/jsonnum"
+	"github.com/pohedev/gj.git/internal/jsonstr"
+)
+
+// Colors holds the ANSI escape sequences starting each kind of token.
+// An empty sequence leaves the kind uncolored.
+type Colors struct {
+	Key         string // Object keys.
+	String      string // String values.
+	Number      string // Numbers.
+	Bool        string // true and false.
+	Null        string // null.
+	Punctuation string // Braces, brackets, colons and commas.
+}
+
+// DefaultColors is a color scheme close to the one of jq.
+var DefaultColors = Colors{
+	Key:         "\x1b[34;1m",
+	String:      "\x1b[32m",
+	Number:      "\x1b[36m",
+	Bool:        "\x1b[33m",
+	Null:        "\x1b[90m",
+	Punctuation: "\x1b[1m",
+}
+
+// colorReset ends a colored token.
+const colorReset = "\x1b[0m"
+
+// Printer serializes AST nodes. The zero Printer writes compact output.
+type Printer struct {
+	indent           string // Indentation per level; compact output when empty.
+	escapeHTML       bool   // Escape HTML-sensitive characters in strings.
+	asciiOnly        bool   // Escape non-ASCII characters in strings.
+	colors           Colors // ANSI colors of tokens.
+	sortKeys         bool   // Sort properties by key, dropping duplicates.
+	canonicalNumbers bool   // Write numbers in canonical form.
+	parseRaw         bool   // Parse RawValue nodes instead of copying them.
+	lossless         bool   // Write trivia and the source text of strings.
+
+	buf   []byte
+	depth int
+}
+
+// New creates a new Printer.
+func New(opts ...Option) *Printer {
+	p := &Printer{}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// Fprint writes node to w as JSON. node is an *ast.RootNode, *ast.Value,
+// *ast.Object, *ast.Array, *ast.Literal or *ast.RawValue.
+func Fprint(w io.Writer, node any, opts ...Option) error {
+	return New(opts...).Fprint(w, node)
+}
+
+// Sprint returns node as JSON, see Fprint.
+func Sprint(node any, opts ...Option) (string, error) {
+	return New(opts...).Sprint(node)
+}
+
+// Fprint writes node to w as JSON, see the Fprint function.
+func (p *Printer) Fprint(w io.Writer, node any) error {
+	p.buf = p.buf[:0]
+	p.depth = 0
+	if err := p.print(node); err != nil {
+		return err
+	}
+	_, err := w.Write(p.buf)
+	return err
+}
+
+// Sprint returns node as JSON, see the Fprint function.
+func (p *Printer) Sprint(node any) (string, error) {
+	var b strings.Builder
+	if err := p.Fprint(&b, node); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// print appends node to the buffer.
+func (p *Printer) print(node any) error {
+	switch n := node.(type) {
+	case *ast.RootNode:
+		if n == nil {
+			return p.print(nil)
+		}
+		if !p.lossless {
+			return p.print(n.Value)
+		}
+		p.buf = append(p.buf, n.Before...)
+		if err := p.print(n.Value); err != nil {
+			return err
+		}
+		p.buf = append(p.buf, n.After...)
+		return nil
+	case *ast.Value:
+		if n == nil {
+			return p.print(nil)
+		}
+		return p.print(n.Value)
+	case *ast.Object:
+		return p.printObject(n)
+	case *ast.Array:
+		return p.printArray(n)
+	case *ast.Literal:
+		return p.printLiteral(n)
+	case *ast.RawValue:
+		if p.parseRaw {
+			var v ast.RootNode
+			if err := v.UnmarshalJSON([]byte(n.Raw)); err != nil {
+				return fmt.Errorf("failed to print: %w", err)
+			}
+			return p.print(v.Value)
+		}
+		p.buf = append(p.buf, n.Raw...)
+		return nil
+	case nil:
+		p.token(p.colors.Null, "null")
+		return nil
+	}
+	return fmt.Errorf("failed to print: unsupported node %T", node)
+}
+
+// printObject appends obj to the buffer.
+func (p *Printer) printObject(obj *ast.Object) error {
+	if p.lossless {
+		return p.printObjectLossless(obj)
+	}
+	if len(obj.Children) == 0 {
+		p.token(p.colors.Punctuation, "{}")
+		return nil
+	}
+	children := obj.Children
+	if p.sortKeys {
+		children = sortedProperties(obj)
+	}
+	p.token(p.colors.Punctuation, "{")
+	p.depth++
+	for i, prop := range children {
+		if i > 0 {
+			p.token(p.colors.Punctuation, ",")
+		}
+		p.newline()
+		p.start(p.colors.Key)
+		p.buf = jsonstr.AppendQuote(p.buf, prop.Identifier.Value, p.escapeHTML, p.asciiOnly)
+		p.end(p.colors.Key)
+		p.token(p.colors.Punctuation, ":")
+		if p.indent != "" {
+			p.buf = append(p.buf, ' ')
+		}
+		if err := p.print(prop.Value); err != nil {
+			return err
+		}
+	}
+	p.depth--
+	p.newline()
+	p.token(p.colors.Punctuation, "}")
+	return nil
+}
+
+// printObjectLossless appends obj to the buffer with its trivia.
+func (p *Printer) printObjectLossless(obj *ast.Object) error {
+	children := obj.Children
+	if p.sortKeys {
+		children = sortedProperties(obj)
+	}
+	p.token(p.colors.Punctuation, "{")
+	for i, prop := range children {
+		if i > 0 {
+			p.token(p.colors.Punctuation, ",")
+		}
+		p.buf = append(p.buf, prop.Trivia.BeforeKey...)
+		p.start(p.colors.Key)
+		p.buf = p.appendSource(p.buf, prop.Identifier.Value, prop.Identifier.Raw)
+		p.end(p.colors.Key)
+		p.buf = append(p.buf, prop.Trivia.BeforeColon...)
+		p.token(p.colors.Punctuation, ":")
+		p.buf = append(p.buf, prop.Trivia.BeforeValue...)
+		if err := p.print(prop.Value); err != nil {
+			return err
+		}
+		p.buf = append(p.buf, prop.Trivia.AfterValue...)
+	}
+	p.buf = append(p.buf, obj.Trivia...)
+	p.token(p.colors.Punctuation, "}")
+	return nil
+}
+
+// sortedProperties returns the properties of obj sorted by key,
+// keeping the last one of duplicate keys.
+func sortedProperties(obj *ast.Object) []ast.Property {
+	index := obj.Index()
+	props := make([]ast.Property, 0, len(index))
+	for _, i := range index {
+		props = append(props, obj.Children[i])
+	}
+	slices.SortFunc(props, func(a, b ast.Property) int {
+		return strings.Compare(a.Identifier.Value, b.Identifier.Value)
+	})
+	return props
+}
+
+// printArray appends array to the buffer.
+func (p *Printer) printArray(array *ast.Array) error {
+	if p.lossless {
+		return p.printArrayLossless(array)
+	}
+	if len(array.Children) == 0 {
+		p.token(p.colors.Punctuation, "[]")
+		return nil
+	}
+	p.token(p.colors.Punctuation, "[")
+	p.depth++
+	for i, item := range array.Children {
+		if i > 0 {
+			p.token(p.colors.Punctuation, ",")
+		}
+		p.newline()
+		if err := p.print(item.Value); err != nil {
+			return err
+		}
+	}
+	p.depth--
+	p.newline()
+	p.token(p.colors.Punctuation, "]")
+	return nil
+}
+
+// printArrayLossless appends array to the buffer with its trivia.
+func (p *Printer) printArrayLossless(array *ast.Array) error {
+	p.token(p.colors.Punctuation, "[")
+	for i, item := range array.Children {
+		if i > 0 {
+			p.token(p.colors.Punctuation, ",")
+		}
+		p.buf = append(p.buf, item.Before...)
+		if err := p.print(item.Value); err != nil {
+			return err
+		}
+		p.buf = append(p.buf, item.After...)
+	}
+	p.buf = append(p.buf, array.Trivia...)
+	p.token(p.colors.Punctuation, "]")
+	return nil
+}
+
+// printLiteral appends lit to the buffer. Numbers keep their source text
+// when parsed from source unless printing canonical numbers; strings are
+// escaped again unless printing lossless.
+func (p *Printer) printLiteral(lit *ast.Literal) error {
+	if p.canonicalNumbers && lit.LiteralType == ast.LiteralTypeNumber {
+		if s, ok := jsonnum.CanonicalValue(lit.Raw, lit.Val); ok {
+			p.token(p.colors.Number, s)
+			return nil
+		}
+	}
+	switch v := lit.Val.(type) {
+	case nil:
+		p.token(p.colors.Null, "null")
+	case bool:
+		p.token(p.colors.Bool, strconv.FormatBool(v))
+	case string:
+		p.start(p.colors.String)
+		p.buf = p.appendSource(p.buf, v, lit.Raw)
+		p.end(p.colors.String)
+	case int64:
+		p.start(p.colors.Number)
+		if lit.Raw != "" {
+			p.buf = append(p.buf, lit.Raw...)
+		} else {
+			p.buf = strconv.AppendInt(p.buf, v, 10)
+		}
+		p.end(p.colors.Number)
+	case float64:
+		if lit.Raw == "" && (math.IsInf(v, 0) || math.IsNaN(v)) {
+			return fmt.Errorf("failed to print: unsupported number %v", v)
+		}
+		p.start(p.colors.Number)
+		if lit.Raw != "" {
+			p.buf = append(p.buf, lit.Raw...)
+		} else {
+			p.buf = strconv.AppendFloat(p.buf, v, 'g', -1, 64)
+		}
+		p.end(p.colors.Number)
+	default:
+		return fmt.Errorf("failed to print: unsupported literal value %T", lit.Val)
+	}
+	return nil
+}
+
+// token appends s in color.
+func (p *Printer) token(color, s string) {
+	p.start(color)
+	p.buf = append(p.buf, s...)
+	p.end(color)
+}
+
+// start begins a token in color.
+func (p *Printer) start(color string) {
+	p.buf = append(p.buf, color...)
+}
+
+// end ends a token started in color.
+func (p *Printer) end(color string) {
+	if color != "" {
+		p.buf = append(p.buf, colorReset...)
+	}
+}
+
+// newline starts a new indented line when printing indented output.
+func (p *Printer) newline() {
+	if p.indent == "" {
+		return
+	}
+	p.buf = append(p.buf, '\n')
+	for i := 0; i < p.depth; i++ {
+		p.buf = append(p.buf, p.indent...)
+	}
+}
+
+// appendSource appends s to b as a quoted JSON string, copying raw, its
+// source text, when printing lossless and raw still holds s, in ASCII
+// when printing ASCII only.
+func (p *Printer) appendSource(b []byte, s, raw string) []byte {
+	if p.lossless && raw != "" && !(p.asciiOnly && !isASCII(raw)) {
+		if v, _, err := jsonstr.Unquote(raw); err == nil && v == s {
+			return append(b, raw...)
+		}
+	}
+	return jsonstr.AppendQuote(b, s, p.escapeHTML, p.asciiOnly)
+}
+
+// isASCII reports whether s holds only ASCII characters.
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
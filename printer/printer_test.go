@@ -0,0 +1,190 @@
+package printer_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/pohedev/gj.git/ast"
+	"github.com/pohedev/gj.git/gjtest"
+	"github.com/pohedev/gj.git/lexer"
+	"github.com/pohedev/gj.git/parser"
+	"github.com/pohedev/gj.git/printer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSprint(t *testing.T) {
+	var tests = []struct {
+		name  string
+		input string
+		opts  []printer.Option
+		want  string
+	}{
+		{
+			name:  "compact",
+			input: `{ "a" : [1, 2.50, 1.0E+2], "b": {"c": null, "d": true}, "e": [], "f": {} }`,
+			want:  `{"a":[1,2.50,1.0E+2],"b":{"c":null,"d":true},"e":[],"f":{}}`,
+		},
+		{
+			name:  "indent",
+			input: `{"a": [1, {"b": false}], "c": []}`,
+			opts:  []printer.Option{printer.Indent("  ")},
+			want: `{
+  "a": [
+    1,
+    {
+      "b": false
+    }
+  ],
+  "c": []
+}`,
+		},
+		{
+			name:  "literal",
+			input: `"x"`,
+			want:  `"x"`,
+		},
+		{
+			name:  "escapes",
+			input: `"q\" b\\ \/ \n\t\u0001 é"`,
+			want:  `"q\" b\\ / \n\t\u0001 é"`,
+		},
+		{
+			name:  "html",
+			input: `{"<a>": "x & y\u2028\u2029"}`,
+			want:  `{"<a>":"x & y` + "\u2028\u2029" + `"}`,
+		},
+		{
+			name:  "escape html",
+			input: `{"<a>": "x & y\u2028\u2029"}`,
+			opts:  []printer.Option{printer.EscapeHTML()},
+			want:  `{"\u003ca\u003e":"x \u0026 y\u2028\u2029"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := printer.Sprint(gjtest.Parse(t, tt.input), tt.opts...)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSprint_Constructed(t *testing.T) {
+	obj := &ast.Object{}
+	obj.Set("n", &ast.Value{Value: ast.Number(1.5)})
+	obj.Set("i", &ast.Value{Value: ast.Number(-2)})
+	obj.Set("s", &ast.Value{Value: ast.String("bad \xff")})
+	got, err := printer.Sprint(obj)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"n":1.5,"i":-2,"s":"bad \ufffd"}`, got)
+
+	_, err = printer.Sprint(&ast.Value{Value: 42})
+	assert.Error(t, err)
+}
+
+func TestPrinter_Fprint(t *testing.T) {
+	p := printer.New(printer.Indent("\t"))
+	var b bytes.Buffer
+	assert.Nil(t, p.Fprint(&b, gjtest.Parse(t, `[1]`)))
+	assert.Nil(t, p.Fprint(&b, gjtest.Parse(t, `{"a": "b"}`)))
+	assert.Equal(t, "[\n\t1\n]{\n\t\"a\": \"b\"\n}", b.String())
+}
+
+func TestSprint_Colors(t *testing.T) {
+	colors := printer.Colors{Key: "<k>", String: "<s>", Number: "<n>", Bool: "<b>", Punctuation: "<p>"}
+	got, err := printer.Sprint(gjtest.Parse(t, `{"a": ["x", 1, true, null]}`), printer.WithColors(colors))
+	assert.Nil(t, err)
+	r := "\x1b[0m"
+	assert.Equal(t, "<p>{"+r+"<k>\"a\""+r+"<p>:"+r+"<p>["+r+"<s>\"x\""+r+"<p>,"+r+"<n>1"+r+"<p>,"+r+"<b>true"+r+"<p>,"+r+"null<p>]"+r+"<p>}"+r, got)
+}
+
+func TestSprint_Deterministic(t *testing.T) {
+	inputs := []string{
+		`{"b": [1, 2.50, 1.0E+2], "a": {"y": "<é>", "x": -0}, "c": 1}`,
+		`{"c": 1, "a": {"x": 0, "y": "<é>"}, "b": [1.0, 25e-1, 100]}`,
+		`{"a": {"x": 0.0, "y": "<é>"}, "c": 2, "b": [1, 2.5, 1e2], "c": 1}`,
+	}
+	want := `{"a":{"x":0,"y":"<é>"},"b":[1,2.5,100],"c":1}`
+	opts := []printer.Option{printer.WithColors(printer.DefaultColors), printer.EscapeHTML(), printer.ASCIIOnly(), printer.Lossless(), printer.Deterministic()}
+	for _, input := range inputs {
+		got, err := printer.Sprint(gjtest.Parse(t, input), opts...)
+		assert.Nil(t, err)
+		assert.Equal(t, want, got, input)
+
+		lazy, err := parser.New(lexer.Lex(input), parser.LazyBelow(1)).Parse()
+		assert.Nil(t, err)
+		got, err = printer.Sprint(lazy, opts...)
+		assert.Nil(t, err)
+		assert.Equal(t, want, got, input)
+	}
+
+	got, err := printer.Sprint(gjtest.Parse(t, inputs[0]), printer.Deterministic(), printer.Indent(" "))
+	assert.Nil(t, err)
+	assert.Equal(t, "{\n \"a\": {\n  \"x\": 0,\n  \"y\": \"<é>\"\n },\n \"b\": [\n  1,\n  2.5,\n  100\n ],\n \"c\": 1\n}", got)
+}
+
+func TestSprint_ASCIIOnly(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`"abc"`, `"abc"`},
+		{`"é"`, `"\u00e9"`},
+		{`{"ключ": "日本"}`, `{"\u043a\u043b\u044e\u0447":"\u65e5\u672c"}`},
+		{`"a😀b"`, `"a\ud83d\ude00b"`},
+		{"\"\u2028\"", `"\u2028"`},
+		{`"\u00e9\n"`, `"\u00e9\n"`},
+	}
+	for _, tt := range tests {
+		got, err := printer.Sprint(gjtest.Parse(t, tt.input), printer.ASCIIOnly())
+		assert.Nil(t, err)
+		assert.Equal(t, tt.want, got, tt.input)
+	}
+
+	root, err := parser.New(lexer.Lex(`{"é": "😀", "\u00e8": "a"}`), parser.Lossless()).Parse()
+	assert.Nil(t, err)
+	got, err := printer.Sprint(root, printer.ASCIIOnly(), printer.Lossless())
+	assert.Nil(t, err)
+	assert.Equal(t, `{"\u00e9": "\ud83d\ude00", "\u00e8": "a"}`, got)
+}
+
+func TestSprint_Lossless(t *testing.T) {
+	inputs := []string{
+		"  {\n\t\"a\" : [ 1 , 2.50e+1,\"\\u0041\\/\" ],\r\n  \"b\\n\":{ } ,\"c\":[\n] , \"d\": {\"e\":null}\n}\n",
+		`"\u00e9"`,
+		" -0.0 ",
+		"[]",
+		`[{"a":true}   ,false]`,
+	}
+	for _, input := range inputs {
+		root, err := parser.New(lexer.Lex(input), parser.Lossless()).Parse()
+		if !assert.Nil(t, err) {
+			continue
+		}
+		got, err := printer.Sprint(root, printer.Lossless(), printer.Indent("  "))
+		assert.Nil(t, err)
+		assert.Equal(t, input, got)
+
+		want, _ := printer.Sprint(gjtest.Parse(t, input))
+		got, err = printer.Sprint(root)
+		assert.Nil(t, err)
+		assert.Equal(t, want, got, "trivia is ignored by default")
+	}
+}
+
+func TestSprint_LosslessEdited(t *testing.T) {
+	input := "{\n  \"a\": \"\\u0078\",\n  \"b\": [1,  2]\n}"
+	root, err := parser.New(lexer.Lex(input), parser.Lossless()).Parse()
+	if !assert.Nil(t, err) {
+		return
+	}
+	obj := root.Value.Value.(*ast.Object)
+	obj.Children[0].Value.(*ast.Value).Value.(*ast.Literal).Val = "y"
+	obj.Children[1].Identifier.Value = "c"
+	array := obj.Children[1].Value.(*ast.Value).Value.(*ast.Array)
+	array.Children = append(array.Children, ast.ArrayItem{Value: ast.Number(3)})
+
+	got, err := printer.Sprint(root, printer.Lossless())
+	assert.Nil(t, err)
+	assert.Equal(t, "{\n  \"a\": \"y\",\n  \"c\": [1,  2,3]\n}", got)
+}
@@ -0,0 +1,131 @@
+package gj
+
+import (
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pohedev/gj.git/ast"
+	"github.com/pohedev/gj.git/lexer"
+	"github.com/pohedev/gj.git/parser"
+)
+
+// ProtoJSON makes decoding accept the quirks of the protobuf JSON mapping,
+// as written by protojson and gRPC-gateway:
+//
+//   - numbers may be quoted, as int64 and uint64 values always are, and
+//     floating point values may be "NaN", "Infinity" or "-Infinity";
+//   - keys match fields ignoring underscores, so that the lowerCamelCase
+//     key "userId" fills the field tagged user_id;
+//   - time.Duration decodes from the google.protobuf.Duration form,
+//     such as "1.5s".
+//
+// Enums decode from their name once registered with ProtoEnum. The other
+// well-known types decode into Go types without the option:
+// google.protobuf.Timestamp into time.Time, Struct into map[string]any,
+// Value into any and ListValue into []any.
+func ProtoJSON() DecodeOption {
+	return func(d *decoder) {
+		d.protoJSON = true
+	}
+}
+
+// ProtoEnum makes decoding accept the names of the values of enum type T
+// in addition to their numbers. values maps names to numbers, such as the
+// <Enum>_value maps of generated code. Unknown names fail with *TypeError.
+func ProtoEnum[T ~int32](values map[string]int32) DecodeOption {
+	return func(d *decoder) {
+		if d.enums == nil {
+			d.enums = map[reflect.Type]map[string]int32{}
+		}
+		d.enums[reflect.TypeFor[T]()] = values
+	}
+}
+
+var durationType = reflect.TypeFor[time.Duration]()
+
+// decodeProtoString stores string literal lit into rv following
+// the protobuf JSON mapping and reports whether it applied.
+func (d *decoder) decodeProtoString(lit *ast.Literal, rv reflect.Value, ptr string) (bool, error) {
+	s := lit.Val.(string)
+	if values, ok := d.enums[rv.Type()]; ok {
+		n, ok := values[s]
+		if !ok {
+			return true, d.typeError(lit, rv.Type(), ptr, "unknown enum value "+strconv.Quote(s))
+		}
+		rv.SetInt(int64(n))
+		return true, nil
+	}
+	if !d.protoJSON {
+		return false, nil
+	}
+	if rv.Type() == durationType {
+		dur, ok := parseProtoDuration(s)
+		if !ok {
+			return true, d.typeError(lit, rv.Type(), ptr, "invalid duration "+strconv.Quote(s))
+		}
+		rv.SetInt(int64(dur))
+		return true, nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if i, err := strconv.ParseInt(s, 10, 64); err == nil && !rv.OverflowInt(i) {
+			rv.SetInt(i)
+			return true, nil
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if u, err := strconv.ParseUint(s, 10, 64); err == nil && !rv.OverflowUint(u) {
+			rv.SetUint(u)
+			return true, nil
+		}
+	case reflect.Float32, reflect.Float64:
+		switch s {
+		case "NaN":
+			rv.SetFloat(math.NaN())
+			return true, nil
+		case "Infinity":
+			rv.SetFloat(math.Inf(1))
+			return true, nil
+		case "-Infinity":
+			rv.SetFloat(math.Inf(-1))
+			return true, nil
+		}
+	default:
+		return false, nil
+	}
+	// Other forms of numbers, such as "1e3", convert like unquoted ones.
+	if num, ok := quotedNumber(s); ok {
+		return true, d.decodeLiteral(num, rv, ptr)
+	}
+	return true, d.typeError(lit, rv.Type(), ptr, "")
+}
+
+// quotedNumber returns the number literal held by s.
+func quotedNumber(s string) (*ast.Literal, bool) {
+	if s == "" || s != strings.TrimSpace(s) {
+		return nil, false
+	}
+	root, err := parser.New(lexer.Lex(s)).Parse()
+	if err != nil {
+		return nil, false
+	}
+	lit, ok := root.Value.Value.(*ast.Literal)
+	return lit, ok && lit.LiteralType == ast.LiteralTypeNumber
+}
+
+// protoDuration matches the seconds of a google.protobuf.Duration.
+var protoDuration = regexp.MustCompile(`^-?[0-9]+(\.[0-9]{1,9})?s$`)
+
+// parseProtoDuration parses s in the form of google.protobuf.Duration,
+// seconds with up to nine fractional digits and the suffix "s".
+func parseProtoDuration(s string) (time.Duration, bool) {
+	if !protoDuration.MatchString(s) {
+		return 0, false
+	}
+	d, err := time.ParseDuration(s)
+	return d, err == nil
+}
@@ -0,0 +1,135 @@
+package gj
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+
+	"github.com/pohedev/gj.git/lexer"
+	"github.com/pohedev/gj.git/token"
+)
+
+// reformatChunkSize is the size of the chunks read by Reformat.
+const reformatChunkSize = 64 << 10
+
+// ReformatOption configures Reformat.
+type ReformatOption func(*reformatter)
+
+// ReformatIndent makes Reformat write indented output, with indent per
+// level and a space after colons. Output is compact by default.
+func ReformatIndent(indent string) ReformatOption {
+	return func(r *reformatter) {
+		r.indent = indent
+	}
+}
+
+// Reformat copies the JSON document read from src to dst, minified or
+// re-indented. It works on the token stream without building the AST, so
+// that documents of any size are reformatted in constant memory, apart from
+// the longest token and the nesting depth. Strings and numbers are copied
+// as written.
+//
+// The document is checked as it is read, and syntax errors are returned as
+// *parser.SyntaxError like Valid does; dst may then hold partial output.
+func Reformat(dst io.Writer, src io.Reader, opts ...ReformatOption) error {
+	r := &reformatter{w: bufio.NewWriter(dst)}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	lex := lexer.Lex("")
+	lex.Feed(nil)
+	buf := make([]byte, reformatChunkSize)
+	var (
+		v     validator
+		stack []token.Token
+	)
+	for {
+		item, ok := lex.TryNextItem()
+		if !ok {
+			n, err := src.Read(buf)
+			lex.Feed(buf[:n])
+			switch {
+			case err == io.EOF:
+				lex.End()
+			case err != nil:
+				return fmt.Errorf("failed to read input: %w", err)
+			}
+			continue
+		}
+
+		var (
+			done bool
+			err  error
+		)
+		if stack, done, err = v.step(item, lex, stack); err != nil {
+			r.w.Flush()
+			return err
+		}
+		if done {
+			break
+		}
+		if err := r.write(item); err != nil {
+			return fmt.Errorf("failed to write output: %w", err)
+		}
+	}
+	if err := r.w.Flush(); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	return nil
+}
+
+// reformatter writes the items of a document.
+type reformatter struct {
+	indent string // Indentation per level; compact output when empty.
+
+	w     *bufio.Writer
+	depth int
+	open  bool // The last item opened a container.
+}
+
+// write writes the valid item. Empty containers are written on one line.
+func (r *reformatter) write(item lexer.Item) error {
+	open := r.open
+	r.open = false
+	switch item.Token {
+	case token.LeftBrace, token.LeftBracket:
+		if open {
+			r.newline()
+		}
+		r.depth++
+		r.open = true
+	case token.RightBrace, token.RightBracket:
+		r.depth--
+		if !open {
+			r.newline()
+		}
+	case token.Comma:
+		r.w.WriteString(item.Val)
+		r.newline()
+		return nil
+	case token.Colon:
+		r.w.WriteString(item.Val)
+		if r.indent != "" {
+			r.w.WriteByte(' ')
+		}
+		return nil
+	default:
+		if open {
+			r.newline()
+		}
+	}
+	_, err := r.w.WriteString(item.Val)
+	return err
+}
+
+// newline starts a new indented line when writing indented output.
+func (r *reformatter) newline() {
+	if r.indent == "" {
+		return
+	}
+	r.w.WriteByte('\n')
+	for i := 0; i < r.depth; i++ {
+		r.w.WriteString(r.indent)
+	}
+}
@@ -0,0 +1,57 @@
+package gj_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/pohedev/gj.git"
+	"github.com/pohedev/gj.git/lexer"
+	"github.com/pohedev/gj.git/parser"
+	"github.com/pohedev/gj.git/printer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReformat(t *testing.T) {
+	var tests = []string{
+		`{ "a" : [1, 2.50, 1.0E+2], "b": {"c": null, "d": true}, "e": [], "f": {} }`,
+		` [ [ [ ] ] , { "x\n" : "é" } ] `,
+		`"x"`,
+		`-1e3`,
+	}
+	for _, input := range tests {
+		root, err := parser.New(lexer.Lex(input)).Parse()
+		if !assert.Nil(t, err) {
+			continue
+		}
+		for _, indent := range []string{"", "  "} {
+			var opts []printer.Option
+			if indent != "" {
+				opts = append(opts, printer.Indent(indent))
+			}
+			want, err := printer.Sprint(root, opts...)
+			assert.Nil(t, err)
+
+			var b strings.Builder
+			err = gj.Reformat(&b, iotest.OneByteReader(strings.NewReader(input)), gj.ReformatIndent(indent))
+			assert.Nil(t, err)
+			assert.Equal(t, want, b.String(), input)
+		}
+	}
+}
+
+func TestReformat_Error(t *testing.T) {
+	for _, input := range []string{`{"a": [1,]}`, `[1} `, `[1] 2`, `{"a" 1}`, `[tru]`, ``} {
+		var b strings.Builder
+		err := gj.Reformat(&b, strings.NewReader(input))
+		want := gj.Valid(input)
+		if assert.Error(t, err, input) {
+			assert.Equal(t, want, err, input)
+		}
+	}
+
+	readErr := errors.New("boom")
+	err := gj.Reformat(&strings.Builder{}, iotest.ErrReader(readErr))
+	assert.ErrorIs(t, err, readErr)
+}
@@ -0,0 +1,142 @@
+package gj
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/pohedev/gj.git/ast"
+	"github.com/pohedev/gj.git/lexer"
+	"github.com/pohedev/gj.git/parser"
+	"github.com/pohedev/gj.git/position"
+)
+
+// RequestError reports a request body rejected by DecodeRequest, with the
+// status of the response to send.
+type RequestError struct {
+	Status int    // HTTP status: 415, 413 or 400.
+	Line   int    // 1-based line of a syntax error, 0 otherwise.
+	Column int    // 1-based column of a syntax error in runes, 0 otherwise.
+	Msg    string // Reason of the rejection, suitable for the response.
+	Err    error  // Underlying error, such as a *parser.SyntaxError.
+}
+
+func (e *RequestError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("failed to decode request: %d:%d: %s", e.Line, e.Column, e.Msg)
+	}
+	return "failed to decode request: " + e.Msg
+}
+
+func (e *RequestError) Unwrap() error {
+	return e.Err
+}
+
+// DecodeRequest parses the JSON body of r, checking that its Content-Type
+// is application/json or another JSON media type such as
+// application/merge-patch+json, and decompressing it when its
+// Content-Encoding is gzip. Bodies over maxBytes, before or after
+// decompression, are rejected; maxBytes <= 0 means no limit. The body is
+// parsed with opts, e.g. parser.Hardened() for untrusted clients. Rejected
+// requests are reported as *RequestError, with line and column for syntax
+// errors; other errors are those reading the body.
+func DecodeRequest(r *http.Request, maxBytes int64, opts ...parser.Option) (*ast.RootNode, error) {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != "application/json" && !strings.HasSuffix(mediaType, "+json") {
+		return nil, &RequestError{Status: http.StatusUnsupportedMediaType, Msg: fmt.Sprintf("unsupported Content-Type %q", r.Header.Get("Content-Type"))}
+	}
+	if r.Body == nil {
+		return nil, &RequestError{Status: http.StatusBadRequest, Msg: "empty body"}
+	}
+
+	body := limitBody(r.Body, maxBytes)
+	compressed := false
+	switch encoding := r.Header.Get("Content-Encoding"); strings.ToLower(encoding) {
+	case "", "identity":
+	case "gzip":
+		gz, err := gzip.NewReader(body)
+		if err != nil {
+			return nil, requestReadError(err, true)
+		}
+		defer gz.Close()
+		body = limitBody(gz, maxBytes)
+		compressed = true
+	default:
+		return nil, &RequestError{Status: http.StatusUnsupportedMediaType, Msg: fmt.Sprintf("unsupported Content-Encoding %q", encoding)}
+	}
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, requestReadError(err, compressed)
+	}
+	input := string(data)
+	root, err := parser.New(lexer.Lex(input), opts...).Parse()
+	if err != nil {
+		e := &RequestError{Status: http.StatusBadRequest, Msg: err.Error(), Err: err}
+		file := position.NewFile(input)
+		var (
+			syntaxErr *parser.SyntaxError
+			limitErr  *parser.LimitError
+		)
+		switch {
+		case errors.As(err, &syntaxErr):
+			pos := file.Position(syntaxErr.Offset)
+			e.Line, e.Column = pos.Line, pos.Column
+			e.Msg = syntaxErr.Msg
+		case errors.As(err, &limitErr):
+			pos := file.Position(limitErr.Offset)
+			e.Line, e.Column = pos.Line, pos.Column
+			e.Msg = fmt.Sprintf("%v exceeds limit of %d", limitErr.Limit, limitErr.Max)
+			if limitErr.Limit == parser.LimitBytes {
+				e.Status = http.StatusRequestEntityTooLarge
+			}
+		}
+		return nil, e
+	}
+	return root, nil
+}
+
+// errBodyTooLarge reports a request body over the limit of DecodeRequest.
+var errBodyTooLarge = errors.New("body too large")
+
+// limitBody returns r failing with errBodyTooLarge after maxBytes bytes,
+// or r when maxBytes <= 0.
+func limitBody(r io.Reader, maxBytes int64) io.Reader {
+	if maxBytes <= 0 {
+		return r
+	}
+	return &bodyLimiter{r: io.LimitReader(r, maxBytes+1), left: maxBytes}
+}
+
+// bodyLimiter reads up to left bytes, failing if there are more.
+type bodyLimiter struct {
+	r    io.Reader
+	left int64
+}
+
+func (l *bodyLimiter) Read(p []byte) (int, error) {
+	n, err := l.r.Read(p)
+	if int64(n) > l.left {
+		return int(l.left), errBodyTooLarge
+	}
+	l.left -= int64(n)
+	return n, err
+}
+
+// requestReadError returns the error reading a request body, compressed
+// or not, as *RequestError when it is caused by the client.
+func requestReadError(err error, compressed bool) error {
+	switch {
+	case errors.Is(err, errBodyTooLarge):
+		return &RequestError{Status: http.StatusRequestEntityTooLarge, Msg: "body too large", Err: err}
+	case !compressed:
+		return err
+	case errors.Is(err, gzip.ErrHeader), errors.Is(err, gzip.ErrChecksum), errors.Is(err, io.ErrUnexpectedEOF), errors.Is(err, io.EOF):
+		return &RequestError{Status: http.StatusBadRequest, Msg: "invalid gzip body", Err: err}
+	}
+	return err
+}
@@ -0,0 +1,153 @@
+package gj_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/pohedev/gj.git"
+	"github.com/pohedev/gj.git/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+// gzipped returns s compressed with gzip.
+func gzipped(s string) string {
+	var b bytes.Buffer
+	w := gzip.NewWriter(&b)
+	w.Write([]byte(s))
+	w.Close()
+	return b.String()
+}
+
+func TestDecodeRequest(t *testing.T) {
+	var tests = []struct {
+		name        string
+		contentType string
+		encoding    string
+		body        string
+		maxBytes    int64
+		opts        []parser.Option
+		want        any
+		status      int
+		err         string
+	}{
+		{name: "json", contentType: "application/json; charset=utf-8", body: `{"a": [1]}`, maxBytes: 100, want: map[string]any{"a": []any{int64(1)}}},
+		{name: "json suffix", contentType: "application/merge-patch+json", body: `null`, want: nil},
+		{name: "gzip", contentType: "application/json", encoding: "gzip", body: gzipped(`[true]`), maxBytes: 100, want: []any{true}},
+		{name: "exact size", contentType: "application/json", body: `[1]`, maxBytes: 3, want: []any{int64(1)}},
+		{
+			name:   "missing content type",
+			body:   `{}`,
+			status: http.StatusUnsupportedMediaType,
+			err:    `failed to decode request: unsupported Content-Type ""`,
+		},
+		{
+			name:        "other content type",
+			contentType: "text/plain",
+			body:        `{}`,
+			status:      http.StatusUnsupportedMediaType,
+			err:         `failed to decode request: unsupported Content-Type "text/plain"`,
+		},
+		{
+			name:        "other encoding",
+			contentType: "application/json",
+			encoding:    "br",
+			body:        `{}`,
+			status:      http.StatusUnsupportedMediaType,
+			err:         `failed to decode request: unsupported Content-Encoding "br"`,
+		},
+		{
+			name:        "too large",
+			contentType: "application/json",
+			body:        `[1, 2]`,
+			maxBytes:    5,
+			status:      http.StatusRequestEntityTooLarge,
+			err:         "failed to decode request: body too large",
+		},
+		{
+			name:        "too large once decompressed",
+			contentType: "application/json",
+			encoding:    "gzip",
+			body:        gzipped("[" + strings.Repeat(" ", 1000) + "]"),
+			maxBytes:    500,
+			status:      http.StatusRequestEntityTooLarge,
+			err:         "failed to decode request: body too large",
+		},
+		{
+			name:        "invalid gzip",
+			contentType: "application/json",
+			encoding:    "gzip",
+			body:        `{}`,
+			status:      http.StatusBadRequest,
+			err:         "failed to decode request: invalid gzip body",
+		},
+		{
+			name:        "syntax error",
+			contentType: "application/json",
+			body:        "{\n  \"a\": 1,\n  \"é\": }",
+			status:      http.StatusBadRequest,
+			err:         `failed to decode request: 3:8: unexpected "}", expected value`,
+		},
+		{
+			name:        "parser limit",
+			contentType: "application/json",
+			body:        `[1, 2]`,
+			opts:        []parser.Option{parser.MaxBytes(4)},
+			status:      http.StatusRequestEntityTooLarge,
+			err:         "failed to decode request: 1:5: input size exceeds limit of 4",
+		},
+		{
+			name:        "depth limit",
+			contentType: "application/json",
+			body:        `[[1]]`,
+			opts:        []parser.Option{parser.MaxDepth(1)},
+			status:      http.StatusBadRequest,
+			err:         "failed to decode request: 1:2: nesting depth exceeds limit of 1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				r.Header.Set("Content-Type", tt.contentType)
+			}
+			if tt.encoding != "" {
+				r.Header.Set("Content-Encoding", tt.encoding)
+			}
+			root, err := gj.DecodeRequest(r, tt.maxBytes, tt.opts...)
+			if tt.status == 0 {
+				if assert.Nil(t, err) {
+					assert.Equal(t, tt.want, root.Value.ToGo())
+				}
+				return
+			}
+			var reqErr *gj.RequestError
+			if assert.ErrorAs(t, err, &reqErr) {
+				assert.Equal(t, tt.status, reqErr.Status)
+				if tt.err != "" {
+					assert.Equal(t, tt.err, err.Error())
+				}
+			}
+		})
+	}
+
+	r := httptest.NewRequest(http.MethodPost, "/", errReader{})
+	r.Header.Set("Content-Type", "application/json")
+	_, err := gj.DecodeRequest(r, 0)
+	assert.ErrorIs(t, err, errBroken)
+	var reqErr *gj.RequestError
+	assert.False(t, errors.As(err, &reqErr), "read errors are not the client's")
+}
+
+var errBroken = errors.New("broken connection")
+
+// errReader fails every read.
+type errReader struct{}
+
+func (errReader) Read([]byte) (int, error) {
+	return 0, errBroken
+}
@@ -0,0 +1,214 @@
+// Package selector selects nodes of a document with lightweight glob
+// patterns such as "items.*.id" or "**.password", a common subset of what
+// JSONPath offers that is simpler to write and faster to match.
+package selector
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pohedev/gj.git/ast"
+	"github.com/pohedev/gj.git/path"
+)
+
+// segment kinds.
+const (
+	segmentKey      = iota // Matches one key or index equal to key.
+	segmentWildcard        // Matches any single key or index.
+	segmentRecurse         // Matches zero or more keys or indices.
+)
+
+// segment is one dot-separated part of a Selector.
+type segment struct {
+	kind int
+	key  string
+}
+
+// Selector is a compiled pattern of locations in a document. Patterns are
+// dot-separated segments, each being
+//   - a key of an object or an index of an array, e.g. "items" or "0";
+//   - "*", matching any single key or index;
+//   - "**", matching any number of keys or indices, including none.
+//
+// A backslash escapes the next character of a key, e.g. `a\.b` for the
+// key "a.b" or `\*` for the key "*". The empty pattern selects the root.
+type Selector struct {
+	segments []segment
+}
+
+// Match is a node selected by a Selector.
+type Match struct {
+	Path  string // JSON Pointer of the node.
+	Node  any    // The *ast.Object, *ast.Array, *ast.Literal or *ast.RawValue.
+	Start int    // Byte offset of the node in the source, -1 when unknown.
+	End   int    // Byte offset just after the node, -1 when unknown.
+}
+
+// Compile parses pattern into a Selector.
+func Compile(pattern string) (*Selector, error) {
+	s := &Selector{}
+	if pattern == "" {
+		return s, nil
+	}
+	var key strings.Builder
+	escaped := false
+	flush := func() error {
+		raw := key.String()
+		switch {
+		case raw == "" && !escaped:
+			return fmt.Errorf("failed to compile selector %q: empty segment", pattern)
+		case raw == "*" && !escaped:
+			s.segments = append(s.segments, segment{kind: segmentWildcard})
+		case raw == "**" && !escaped:
+			s.segments = append(s.segments, segment{kind: segmentRecurse})
+		default:
+			s.segments = append(s.segments, segment{kind: segmentKey, key: raw})
+		}
+		key.Reset()
+		escaped = false
+		return nil
+	}
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '\\':
+			if i+1 == len(pattern) {
+				return nil, fmt.Errorf("failed to compile selector %q: trailing backslash", pattern)
+			}
+			i++
+			key.WriteByte(pattern[i])
+			escaped = true
+		case '.':
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		default:
+			key.WriteByte(c)
+		}
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// MustCompile is like Compile but panics if pattern cannot be compiled.
+func MustCompile(pattern string) *Selector {
+	s, err := Compile(pattern)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// Select returns the nodes of root matching pattern, see Selector.Select.
+func Select(root *ast.RootNode, pattern string) ([]Match, error) {
+	s, err := Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return s.Select(root), nil
+}
+
+// Select returns the nodes of root matching s in document order, parents
+// before their children. Each node is returned once, even when several
+// "**" segments match it. The content of RawValue nodes kept by lazy
+// parsing is not searched.
+func (s *Selector) Select(root *ast.RootNode) []Match {
+	if root == nil || root.Value == nil {
+		return nil
+	}
+	var matches []Match
+	s.match(root.Value, "", s.closure([]int{0}), &matches)
+	return matches
+}
+
+// match appends node to matches when states, the positions reached in
+// the segments, include the end, and searches its children.
+func (s *Selector) match(node any, ptr string, states []int, matches *[]Match) {
+	node = unwrap(node)
+	if slices.Contains(states, len(s.segments)) {
+		m := Match{Path: ptr, Node: node}
+		m.Start, m.End = span(node)
+		*matches = append(*matches, m)
+	}
+
+	switch n := node.(type) {
+	case *ast.Object:
+		for _, prop := range n.Children {
+			if next := s.step(states, prop.Identifier.Value); len(next) > 0 {
+				s.match(prop.Value, ptr+"/"+path.Escape(prop.Identifier.Value), next, matches)
+			}
+		}
+	case *ast.Array:
+		for i, item := range n.Children {
+			key := strconv.Itoa(i)
+			if next := s.step(states, key); len(next) > 0 {
+				s.match(item.Value, ptr+"/"+key, next, matches)
+			}
+		}
+	}
+}
+
+// step returns the states reached from states by descending into key.
+func (s *Selector) step(states []int, key string) []int {
+	var next []int
+	for _, i := range states {
+		if i == len(s.segments) {
+			continue
+		}
+		switch seg := s.segments[i]; {
+		case seg.kind == segmentRecurse:
+			next = append(next, i)
+		case seg.kind == segmentWildcard, seg.key == key:
+			next = append(next, i+1)
+		}
+	}
+	return s.closure(next)
+}
+
+// closure adds to states the states following "**" segments, which may
+// match no key, and removes duplicates.
+func (s *Selector) closure(states []int) []int {
+	for j := 0; j < len(states); j++ {
+		i := states[j]
+		if i < len(s.segments) && s.segments[i].kind == segmentRecurse {
+			states = append(states, i+1)
+		}
+	}
+	slices.Sort(states)
+	return slices.Compact(states)
+}
+
+// span returns the byte offsets of node in the source, or -1, -1 for
+// literals, which keep no position, and constructed nodes. Objects and
+// arrays hold the offset of their closing bracket as End.
+func span(node any) (int, int) {
+	switch n := node.(type) {
+	case *ast.Object:
+		if n.End > n.Start {
+			return n.Start, n.End + 1
+		}
+	case *ast.Array:
+		if n.End > n.Start {
+			return n.Start, n.End + 1
+		}
+	case *ast.RawValue:
+		if n.End > n.Start {
+			return n.Start, n.End
+		}
+	}
+	return -1, -1
+}
+
+// unwrap strips Value wrappers.
+func unwrap(node any) any {
+	for {
+		v, ok := node.(*ast.Value)
+		if !ok || v == nil {
+			return node
+		}
+		node = v.Value
+	}
+}
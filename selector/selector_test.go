@@ -0,0 +1,67 @@
+package selector_test
+
+import (
+	"testing"
+
+	"github.com/pohedev/gj.git/ast"
+	"github.com/pohedev/gj.git/gjtest"
+	"github.com/pohedev/gj.git/selector"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelect(t *testing.T) {
+	root := gjtest.Parse(t, `{
+		"items": [{"id": 1, "password": "a"}, {"id": 2, "meta": {"password": "b"}}],
+		"password": "c",
+		"a.b": {"*": true}
+	}`)
+	var tests = []struct {
+		pattern string
+		want    []string
+	}{
+		{"", []string{""}},
+		{"items.*.id", []string{"/items/0/id", "/items/1/id"}},
+		{"items.1.id", []string{"/items/1/id"}},
+		{"**.password", []string{"/items/0/password", "/items/1/meta/password", "/password"}},
+		{"items.**.password", []string{"/items/0/password", "/items/1/meta/password"}},
+		{"**.**.password", []string{"/items/0/password", "/items/1/meta/password", "/password"}},
+		{"**.meta.**", []string{"/items/1/meta", "/items/1/meta/password"}},
+		{`a\.b.\*`, []string{"/a.b/*"}},
+		{"items.id", nil},
+		{"*.*.*.*.*", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			matches, err := selector.Select(root, tt.pattern)
+			assert.Nil(t, err)
+			var got []string
+			for _, m := range matches {
+				got = append(got, m.Path)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSelect_Span(t *testing.T) {
+	input := `{"a": {"b": [1]}}`
+	matches := selector.MustCompile("a.*").Select(gjtest.Parse(t, input))
+	if assert.Len(t, matches, 1) {
+		m := matches[0]
+		assert.IsType(t, &ast.Array{}, m.Node)
+		assert.Equal(t, "[1]", input[m.Start:m.End])
+	}
+
+	matches = selector.MustCompile("a.b.0").Select(gjtest.Parse(t, input))
+	if assert.Len(t, matches, 1) {
+		assert.Equal(t, -1, matches[0].Start)
+		assert.Equal(t, int64(1), matches[0].Node.(*ast.Literal).Val)
+	}
+}
+
+func TestCompile_Error(t *testing.T) {
+	for _, pattern := range []string{".", "a..b", "a.", `a\`} {
+		_, err := selector.Compile(pattern)
+		assert.Error(t, err, pattern)
+	}
+}
@@ -0,0 +1,196 @@
+package gj
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pohedev/gj.git/ast"
+	"github.com/pohedev/gj.git/internal/jsonstr"
+	"github.com/pohedev/gj.git/lexer"
+	"github.com/pohedev/gj.git/path"
+	"github.com/pohedev/gj.git/printer"
+	"github.com/pohedev/gj.git/token"
+)
+
+// Set returns input with the value at path replaced by value, splicing
+// its JSON text into the original text so that every other byte, layout
+// and comments aside, is kept. path is as accepted by GetAs. The document
+// is only tokenized, and not past the place of the change: syntax errors
+// after it go unnoticed. When a key appears more than once, the first
+// value is replaced.
+//
+// A missing key is added at the end of its object, and the index one past
+// the end of an array, or "-" in a JSON Pointer, appends to it; the new
+// property or item copies the spacing of the previous one. Missing objects
+// on the way are created. value is an AST node, such as *ast.Value, or
+// any value marshaled by encoding/json.
+func Set(input string, path string, value any) (string, error) {
+	segs, err := concretePath(path)
+	if err != nil {
+		return "", err
+	}
+	text, err := marshalSetValue(value)
+	if err != nil {
+		return "", err
+	}
+
+	lex := lexers.Get().(*lexer.Lexer)
+	lex.Reset(input)
+	defer func() {
+		lex.Reset("")
+		lexers.Put(lex)
+	}()
+
+	var (
+		v      validator
+		stack  []token.Token
+		frames []setFrame
+		start  = -1 // Offset of the target when it is a container.
+	)
+	for {
+		item := lex.NextItem()
+		state := v.state
+		var done bool
+		stack, done, err = v.step(item, lex, stack)
+		if err != nil {
+			return "", err
+		}
+		if done {
+			return "", &PathError{Path: path, Msg: "no value"}
+		}
+
+		var f *setFrame
+		if len(frames) > 0 {
+			f = &frames[len(frames)-1]
+		}
+		switch item.Token {
+		case token.Comma:
+			f.sepEnd = item.Pos + 1
+			continue
+		case token.Colon:
+			continue
+		case token.RightBrace, token.RightBracket:
+			closed := frames[len(frames)-1]
+			frames = frames[:len(frames)-1]
+			end := item.Pos + 1
+			if start >= 0 && len(frames) == len(segs) {
+				return input[:start] + text + input[end:], nil
+			}
+			if closed.onPath {
+				return closed.insert(input, item.Pos, segs[len(frames):], text, path)
+			}
+			if len(frames) > 0 {
+				frames[len(frames)-1].valueEnd(end)
+			}
+			continue
+		case token.String:
+			if state == validKeyOrEnd || state == validKey {
+				f.key, _, _ = jsonstr.Unquote(item.Val)
+				f.keyStart, f.keyEnd = item.Pos, item.Pos+len(item.Val)
+				continue
+			}
+		}
+
+		// item starts a value.
+		d := len(frames)
+		onPath := d == 0
+		if f != nil {
+			f.valueStart = item.Pos
+			onPath = f.onPath && f.child() == segs[d-1].Key
+		}
+		container := item.Token == token.LeftBrace || item.Token == token.LeftBracket
+		switch {
+		case onPath && d == len(segs) && container:
+			start = item.Pos
+		case onPath && d == len(segs):
+			return input[:item.Pos] + text + input[item.Pos+len(item.Val):], nil
+		case onPath && !container:
+			return "", &PathError{Path: path, Msg: fmt.Sprintf("no key %q in %s at %q", segs[d].Key, strings.ToLower(item.Token.String()), segs[:d].Pointer())}
+		}
+		if container {
+			frames = append(frames, setFrame{
+				object: item.Token == token.LeftBrace,
+				onPath: onPath && d < len(segs),
+				sepEnd: item.Pos + 1,
+			})
+		} else if f != nil {
+			f.valueEnd(item.Pos + len(item.Val))
+		}
+	}
+}
+
+// setFrame is an open object or array read by Set.
+type setFrame struct {
+	object bool
+	onPath bool // The container is on the way to the target.
+	n      int  // Number of values read so far.
+
+	key              string // Key of the current property of an object.
+	sepEnd           int    // Offset after the last opening bracket or comma.
+	keyStart, keyEnd int    // Span of the key of the last property.
+	valueStart       int    // Offset of the last value.
+	end              int    // Offset after the last value.
+}
+
+// child returns the key or index of the current value of f.
+func (f *setFrame) child() string {
+	if f.object {
+		return f.key
+	}
+	return strconv.Itoa(f.n)
+}
+
+// valueEnd records the end of a value of f.
+func (f *setFrame) valueEnd(end int) {
+	f.n++
+	f.end = end
+}
+
+// insert returns input with the value text added to the container f,
+// closed at offset closer, at segs, the remaining path to the target.
+func (f *setFrame) insert(input string, closer int, segs path.Path, text, p string) (string, error) {
+	for i := len(segs) - 1; i > 0; i-- {
+		text = "{" + quote(segs[i].Key) + ":" + text + "}"
+	}
+	if f.object {
+		gap := ":"
+		if f.n > 0 {
+			gap = input[f.keyEnd:f.valueStart]
+		}
+		text = quote(segs[0].Key) + gap + text
+	} else if key := segs[0].Key; key != "-" && key != strconv.Itoa(f.n) {
+		return "", &PathError{Path: p, Msg: fmt.Sprintf("no index %s in array of length %d", key, f.n)}
+	}
+	if f.n == 0 {
+		return input[:closer] + text + input[closer:], nil
+	}
+	before := input[f.sepEnd:f.valueStart]
+	if f.object {
+		before = input[f.sepEnd:f.keyStart]
+	}
+	return input[:f.end] + "," + before + text + input[f.end:], nil
+}
+
+// marshalSetValue returns v as compact JSON text.
+func marshalSetValue(v any) (string, error) {
+	switch v.(type) {
+	case *ast.RootNode, *ast.Value, *ast.Object, *ast.Array, *ast.Literal, *ast.RawValue:
+		return printer.Sprint(v)
+	}
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return "", fmt.Errorf("failed to set value: %w", err)
+	}
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
+
+// quote returns s as a JSON string.
+func quote(s string) string {
+	q, _ := printer.Sprint(ast.String(s))
+	return q
+}
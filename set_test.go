@@ -0,0 +1,138 @@
+package gj_test
+
+import (
+	"testing"
+
+	"github.com/pohedev/gj.git"
+	"github.com/pohedev/gj.git/ast"
+	"github.com/pohedev/gj.git/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSet(t *testing.T) {
+	const doc = `{
+  "name": "app",
+  "server": {"port": 80, "tags": ["a", "b"]},
+  "empty": {},
+  "list": [ ]
+}`
+	var tests = []struct {
+		name  string
+		input string
+		path  string
+		value any
+		want  string
+	}{
+		{
+			name:  "literal",
+			input: doc,
+			path:  "server.port",
+			value: 8080,
+			want:  `{` + "\n" + `  "name": "app",` + "\n" + `  "server": {"port": 8080, "tags": ["a", "b"]},` + "\n" + `  "empty": {},` + "\n" + `  "list": [ ]` + "\n" + `}`,
+		},
+		{
+			name:  "container",
+			input: `{"a": {"b": [1, {}]}, "c": 1}`,
+			path:  "/a",
+			value: map[string]any{"x": "<y>"},
+			want:  `{"a": {"x":"<y>"}, "c": 1}`,
+		},
+		{
+			name:  "array item",
+			input: doc,
+			path:  "server.tags[1]",
+			value: ast.Null(),
+			want:  `{` + "\n" + `  "name": "app",` + "\n" + `  "server": {"port": 80, "tags": ["a", null]},` + "\n" + `  "empty": {},` + "\n" + `  "list": [ ]` + "\n" + `}`,
+		},
+		{
+			name:  "new key copies layout",
+			input: doc,
+			path:  "version",
+			value: "1.0",
+			want:  `{` + "\n" + `  "name": "app",` + "\n" + `  "server": {"port": 80, "tags": ["a", "b"]},` + "\n" + `  "empty": {},` + "\n" + `  "list": [ ],` + "\n" + `  "version": "1.0"` + "\n" + `}`,
+		},
+		{
+			name:  "new key in empty object",
+			input: doc,
+			path:  "empty.a.b",
+			value: true,
+			want:  `{` + "\n" + `  "name": "app",` + "\n" + `  "server": {"port": 80, "tags": ["a", "b"]},` + "\n" + `  "empty": {"a":{"b":true}},` + "\n" + `  "list": [ ]` + "\n" + `}`,
+		},
+		{
+			name:  "append",
+			input: `[1, 2]`,
+			path:  "/-",
+			value: 3,
+			want:  `[1, 2, 3]`,
+		},
+		{
+			name:  "append by index",
+			input: `{"list": [ ]}`,
+			path:  "list[0]",
+			value: "x",
+			want:  `{"list": [ "x"]}`,
+		},
+		{
+			name:  "first duplicate",
+			input: `{"a": 1, "a": 2}`,
+			path:  "a",
+			value: 3,
+			want:  `{"a": 3, "a": 2}`,
+		},
+		{
+			name:  "root",
+			input: " [1] \n",
+			path:  "",
+			value: map[string]int{"a": 1},
+			want:  ` {"a":1} ` + "\n",
+		},
+		{
+			name:  "escaped key",
+			input: `{"a\/b": 1}`,
+			path:  "/a~1b",
+			value: 2,
+			want:  `{"a\/b": 2}`,
+		},
+		{
+			name:  "input after the change is not read",
+			input: `{"a": 1, "b": [`,
+			path:  "a",
+			value: 2,
+			want:  `{"a": 2, "b": [`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := gj.Set(tt.input, tt.path, tt.value)
+			if assert.Nil(t, err) {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestSet_Error(t *testing.T) {
+	var tests = []struct {
+		name  string
+		input string
+		path  string
+		want  string
+	}{
+		{"key in literal", `{"a": [1]}`, "a[0].b", `failed to resolve path "a[0].b": no key "b" in number at "/a/0"`},
+		{"index out of range", `{"a": [1]}`, "a[2]", `failed to resolve path "a[2]": no index 2 in array of length 1`},
+		{"key in array", `{"a": []}`, "a.b", `failed to resolve path "a.b": no index b in array of length 0`},
+		{"wildcard", `{}`, "a.*", `failed to resolve path "a.*": wildcards are not supported`},
+		{"syntax", `{"a" 1}`, "a", `failed to parse: unexpected "1", expected ':' at offset 5`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := gj.Set(tt.input, tt.path, 1)
+			assert.EqualError(t, err, tt.want)
+		})
+	}
+
+	_, err := gj.Set(`{}`, "a", func() {})
+	assert.Error(t, err)
+	_, err = gj.Set(`[1,]`, "/-", 1)
+	assert.ErrorIs(t, err, parser.ErrTrailingComma)
+}
@@ -0,0 +1,116 @@
+package gj
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pohedev/gj.git/internal/jsonstr"
+	"github.com/pohedev/gj.git/lexer"
+	"github.com/pohedev/gj.git/path"
+	"github.com/pohedev/gj.git/token"
+)
+
+// Span is the byte range of a value in a document, End excluded.
+type Span struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// SourceMap returns the span in input of every leaf of the document, that
+// is every literal and empty object or array, keyed by JSON Pointer. It
+// lets tools without access to gj, such as a web page overlaying
+// validation results on the raw text, highlight the source of a value.
+// The map marshals to a JSON object with encoding/json. When a key
+// appears more than once, the last value wins. Errors are those of Valid.
+func SourceMap(input string) (map[string]Span, error) {
+	lex := lexers.Get().(*lexer.Lexer)
+	lex.Reset(input)
+	defer func() {
+		lex.Reset("")
+		lexers.Put(lex)
+	}()
+
+	var (
+		v      validator
+		stack  []token.Token
+		frames []spanFrame
+		done   bool
+		err    error
+		spans  = map[string]Span{}
+	)
+	for !done {
+		item := lex.NextItem()
+		state := v.state
+		stack, done, err = v.step(item, lex, stack)
+		if err != nil {
+			return nil, err
+		}
+
+		switch item.Token {
+		case token.LeftBrace, token.LeftBracket:
+			frames = append(frames, spanFrame{ptr: valuePointer(frames), start: item.Pos, object: item.Token == token.LeftBrace})
+			continue
+		case token.RightBrace, token.RightBracket:
+			f := frames[len(frames)-1]
+			frames = frames[:len(frames)-1]
+			if f.n == 0 {
+				spans[f.ptr] = Span{f.start, item.Pos + len(item.Val)}
+			}
+		case token.String:
+			if state == validKeyOrEnd || state == validKey {
+				f := &frames[len(frames)-1]
+				f.key, _, _ = jsonstr.Unquote(item.Val)
+				if _, ok := f.keys[f.key]; ok {
+					dropSpans(spans, valuePointer(frames))
+				} else if f.keys == nil {
+					f.keys = map[string]struct{}{f.key: {}}
+				} else {
+					f.keys[f.key] = struct{}{}
+				}
+				continue
+			}
+			fallthrough
+		case token.Number, token.True, token.False, token.Null:
+			spans[valuePointer(frames)] = Span{item.Pos, item.Pos + len(item.Val)}
+		default:
+			continue
+		}
+		if len(frames) > 0 {
+			frames[len(frames)-1].n++
+		}
+	}
+	return spans, nil
+}
+
+// spanFrame is an open object or array while building a source map.
+type spanFrame struct {
+	ptr    string // Pointer of the container.
+	start  int    // Offset of the opening bracket.
+	object bool
+	key    string              // Key of the current property of an object.
+	keys   map[string]struct{} // Keys of the object read so far.
+	n      int                 // Number of values read so far.
+}
+
+// valuePointer returns the pointer of the next value of the innermost
+// container of frames.
+func valuePointer(frames []spanFrame) string {
+	if len(frames) == 0 {
+		return ""
+	}
+	f := &frames[len(frames)-1]
+	if f.object {
+		return f.ptr + "/" + path.Escape(f.key)
+	}
+	return f.ptr + "/" + strconv.Itoa(f.n)
+}
+
+// dropSpans removes the spans of the value at ptr and its descendants,
+// replaced by a later duplicate key.
+func dropSpans(spans map[string]Span, ptr string) {
+	for p := range spans {
+		if p == ptr || strings.HasPrefix(p, ptr+"/") {
+			delete(spans, p)
+		}
+	}
+}
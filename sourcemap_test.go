@@ -0,0 +1,56 @@
+package gj_test
+
+import (
+	"testing"
+
+	"github.com/pohedev/gj.git"
+	"github.com/pohedev/gj.git/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSourceMap(t *testing.T) {
+	var tests = []struct {
+		name  string
+		input string
+		want  map[string]gj.Span
+	}{
+		{"literal", ` "x" `, map[string]gj.Span{"": {1, 4}}},
+		{"empty object", `{ }`, map[string]gj.Span{"": {0, 3}}},
+		{
+			name:  "nested",
+			input: `{"a": [1, true, {}], "b/c": {"d~": null, "e": []}, "f": -2.5e1}`,
+			want: map[string]gj.Span{
+				"/a/0":      {7, 8},
+				"/a/1":      {10, 14},
+				"/a/2":      {16, 18},
+				"/b~1c/d~0": {35, 39},
+				"/b~1c/e":   {46, 48},
+				"/f":        {56, 62},
+			},
+		},
+		{"escaped key", `{"a": "\"x\""}`, map[string]gj.Span{"/a": {6, 13}}},
+		{"duplicate key", `{"a": [1, 2], "ab": 3, "a": {"b": 4}}`, map[string]gj.Span{"/ab": {20, 21}, "/a/b": {34, 35}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spans, err := gj.SourceMap(tt.input)
+			if assert.Nil(t, err) {
+				assert.Equal(t, tt.want, spans)
+				for ptr, span := range spans {
+					assert.NotEmpty(t, tt.input[span.Start:span.End], ptr)
+				}
+			}
+		})
+	}
+}
+
+func TestSourceMap_Error(t *testing.T) {
+	for _, input := range []string{``, `{"a": 1,}`, `[1] 2`, `["\x"]`} {
+		t.Run(input, func(t *testing.T) {
+			_, err := gj.SourceMap(input)
+			assert.Equal(t, gj.Valid(input), err)
+			var syntaxErr *parser.SyntaxError
+			assert.ErrorAs(t, err, &syntaxErr)
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package tape
+
+// Cursor walks the values of a Tape without recursion. A Cursor is
+// positioned on a value; in objects, the key of the value is available
+// through Key.
+type Cursor struct {
+	tape    *Tape
+	pos     int   // index of the current value.
+	parents []int // indexes of the enclosing containers.
+}
+
+// Cursor returns a Cursor positioned on the root value.
+func (t *Tape) Cursor() *Cursor {
+	return &Cursor{tape: t}
+}
+
+// Pos returns the index of the current value in the tape.
+func (c *Cursor) Pos() int {
+	return c.pos
+}
+
+// Kind returns the kind of the current value.
+func (c *Cursor) Kind() Kind {
+	return c.tape.Entries[c.pos].Kind
+}
+
+// Key returns the key of the current value when its parent is an object.
+func (c *Cursor) Key() (string, bool) {
+	if c.pos == 0 || c.tape.Entries[c.pos-1].Kind != Key {
+		return "", false
+	}
+	return c.tape.Text(c.pos - 1), true
+}
+
+// Len returns the number of properties or items of the current
+// object or array, 0 for other values.
+func (c *Cursor) Len() int {
+	return int(c.tape.Entries[c.pos].Len)
+}
+
+// Enter moves to the first child of the current object or array
+// and reports whether there was one.
+func (c *Cursor) Enter() bool {
+	e := c.tape.Entries[c.pos]
+	if e.Kind != Object && e.Kind != Array || e.Len == 0 {
+		return false
+	}
+	c.parents = append(c.parents, c.pos)
+	c.pos++
+	if e.Kind == Object {
+		c.pos++ // skip the key.
+	}
+	return true
+}
+
+// Next moves to the next sibling of the current value
+// and reports whether there was one.
+func (c *Cursor) Next() bool {
+	next := c.tape.End(c.pos)
+	if next >= len(c.tape.Entries) {
+		return false
+	}
+	switch c.tape.Entries[next].Kind {
+	case ObjectEnd, ArrayEnd:
+		return false
+	case Key:
+		next++
+	}
+	c.pos = next
+	return true
+}
+
+// Up moves to the parent of the current value
+// and reports whether there was one.
+func (c *Cursor) Up() bool {
+	if len(c.parents) == 0 {
+		return false
+	}
+	c.pos = c.parents[len(c.parents)-1]
+	c.parents = c.parents[:len(c.parents)-1]
+	return true
+}
+
+// AsString returns the text of the current value if it is a string.
+func (c *Cursor) AsString() (string, bool) {
+	if c.Kind() != String {
+		return "", false
+	}
+	return c.tape.Text(c.pos), true
+}
+
+// AsBool returns the current value if it is a boolean.
+func (c *Cursor) AsBool() (bool, bool) {
+	switch c.Kind() {
+	case True:
+		return true, true
+	case False:
+		return false, true
+	}
+	return false, false
+}
+
+// AsInt returns the current value if it is an integer.
+func (c *Cursor) AsInt() (int64, bool) {
+	if c.Kind() != Int {
+		return 0, false
+	}
+	return c.tape.Int(c.pos), true
+}
+
+// AsFloat returns the current value as a float64 if it is a number.
+func (c *Cursor) AsFloat() (float64, bool) {
+	switch c.Kind() {
+	case Int:
+		return float64(c.tape.Int(c.pos)), true
+	case Float:
+		return c.tape.Float(c.pos), true
+	}
+	return 0, false
+}
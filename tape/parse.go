@@ -0,0 +1,111 @@
+package tape
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/pohedev/gj.git/internal/jsonscan"
+	"github.com/pohedev/gj.git/lexer"
+	"github.com/pohedev/gj.git/parser"
+)
+
+// Parse parses input into a new Tape.
+// Errors are reported as *parser.SyntaxError.
+func Parse(input string) (*Tape, error) {
+	t := &Tape{}
+	if err := t.Parse(input); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Parse replaces the content of the Tape with the document in input,
+// reusing its allocations.
+func (t *Tape) Parse(input string) error {
+	t.Reset()
+	if t.lex == nil {
+		t.lex = lexer.Lex(input)
+	} else {
+		t.lex.Reset(input)
+	}
+	if err := jsonscan.Scan(t.lex, (*builder)(t)); err != nil {
+		return (*parser.SyntaxError)(err)
+	}
+	return nil
+}
+
+// builder appends the entries of the values read by jsonscan.Scan to
+// a Tape.
+type builder Tape
+
+// add appends an entry and returns its index.
+func (b *builder) add(e Entry) int {
+	b.Entries = append(b.Entries, e)
+	return len(b.Entries) - 1
+}
+
+// addText appends a String or Key entry holding s.
+func (b *builder) addText(kind Kind, s string) {
+	b.add(Entry{Kind: kind, Len: uint32(len(s)), Val: uint64(len(b.Strings))})
+	b.Strings = append(b.Strings, s...)
+}
+
+// begin appends the start entry of a container.
+func (b *builder) begin(kind Kind) {
+	b.stack = append(b.stack, b.add(Entry{Kind: kind}))
+}
+
+// close appends the end entry of the innermost container, holding n
+// children.
+func (b *builder) close(kind Kind, n int) {
+	start := b.stack[len(b.stack)-1]
+	b.stack = b.stack[:len(b.stack)-1]
+	end := b.add(Entry{Kind: kind, Val: uint64(start)})
+	b.Entries[start].Val = uint64(end)
+	b.Entries[start].Len = uint32(n)
+}
+
+func (b *builder) Null(item lexer.Item) {
+	b.add(Entry{Kind: Null})
+}
+
+func (b *builder) Bool(item lexer.Item, v bool) {
+	if v {
+		b.add(Entry{Kind: True})
+	} else {
+		b.add(Entry{Kind: False})
+	}
+}
+
+func (b *builder) Number(item lexer.Item) {
+	if i, err := strconv.ParseInt(item.Val, 10, 64); err == nil {
+		b.add(Entry{Kind: Int, Val: uint64(i)})
+		return
+	}
+	f, _ := strconv.ParseFloat(item.Val, 64)
+	b.add(Entry{Kind: Float, Val: math.Float64bits(f)})
+}
+
+func (b *builder) String(item lexer.Item, s string) {
+	b.addText(String, s)
+}
+
+func (b *builder) BeginObject(item lexer.Item) {
+	b.begin(Object)
+}
+
+func (b *builder) Key(item lexer.Item, s string) {
+	b.addText(Key, s)
+}
+
+func (b *builder) EndObject(item lexer.Item, n int) {
+	b.close(ObjectEnd, n)
+}
+
+func (b *builder) BeginArray(item lexer.Item) {
+	b.begin(Array)
+}
+
+func (b *builder) EndArray(item lexer.Item, n int) {
+	b.close(ArrayEnd, n)
+}
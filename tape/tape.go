@@ -0,0 +1,110 @@
+// Package tape provides a flat representation of JSON documents.
+//
+// A Tape stores a document as a sequence of typed entries in document
+// order plus a buffer holding the decoded strings, in the manner of
+// simdjson. It avoids the pointer-heavy nodes of package ast and suits
+// analytical workloads scanning large documents.
+package tape
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/pohedev/gj.git/lexer"
+)
+
+// Kind identifies the type of an Entry.
+type Kind uint8
+
+const (
+	Null Kind = iota
+	True
+	False
+	Int
+	Float
+	String
+	Key
+	Object
+	ObjectEnd
+	Array
+	ArrayEnd
+)
+
+var kindNames = [...]string{
+	Null:      "null",
+	True:      "true",
+	False:     "false",
+	Int:       "int",
+	Float:     "float",
+	String:    "string",
+	Key:       "key",
+	Object:    "object",
+	ObjectEnd: "object end",
+	Array:     "array",
+	ArrayEnd:  "array end",
+}
+
+func (k Kind) String() string {
+	if int(k) < len(kindNames) {
+		return kindNames[k]
+	}
+	return fmt.Sprintf("Kind(%d)", uint8(k))
+}
+
+// Entry is an element of a Tape. The meaning of Val and Len depends on Kind:
+//
+//   - Object, Array: Val is the index of the matching end entry,
+//     Len the number of properties or items.
+//   - ObjectEnd, ArrayEnd: Val is the index of the matching start entry.
+//   - String, Key: Val is the offset of the decoded text in Strings,
+//     Len its length in bytes.
+//   - Int: Val holds the int64 bits.
+//   - Float: Val holds the float64 bits.
+type Entry struct {
+	Kind Kind
+	Len  uint32
+	Val  uint64
+}
+
+// Tape is a flat representation of a JSON document. An object is stored
+// as its Object entry, a Key entry followed by the value for each property,
+// and its ObjectEnd entry; an array likewise without Key entries.
+type Tape struct {
+	Entries []Entry // Entries in document order, the root value first.
+	Strings []byte  // Decoded strings and keys.
+
+	lex   *lexer.Lexer // Reused by Parse.
+	stack []int        // Start entries of the open containers while parsing.
+}
+
+// End returns the index of the entry following the value at i.
+func (t *Tape) End(i int) int {
+	switch t.Entries[i].Kind {
+	case Object, Array:
+		return int(t.Entries[i].Val) + 1
+	}
+	return i + 1
+}
+
+// Text returns the text of the String or Key entry at i.
+func (t *Tape) Text(i int) string {
+	e := t.Entries[i]
+	return string(t.Strings[e.Val : e.Val+uint64(e.Len)])
+}
+
+// Int returns the value of the Int entry at i.
+func (t *Tape) Int(i int) int64 {
+	return int64(t.Entries[i].Val)
+}
+
+// Float returns the value of the Float entry at i.
+func (t *Tape) Float(i int) float64 {
+	return math.Float64frombits(t.Entries[i].Val)
+}
+
+// Reset empties the Tape, keeping its allocations.
+func (t *Tape) Reset() {
+	t.Entries = t.Entries[:0]
+	t.Strings = t.Strings[:0]
+	t.stack = t.stack[:0]
+}
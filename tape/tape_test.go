@@ -0,0 +1,152 @@
+package tape
+
+import (
+	"math"
+	"testing"
+
+	"github.com/pohedev/gj.git/lexer"
+	"github.com/pohedev/gj.git/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	var tests = []struct {
+		name    string
+		input   string
+		entries []Entry
+		strings string
+	}{
+		{
+			name:    "literal",
+			input:   `"a\nb"`,
+			entries: []Entry{{Kind: String, Len: 3}},
+			strings: "a\nb",
+		},
+		{
+			name:  "empty containers",
+			input: `[{}, []]`,
+			entries: []Entry{
+				{Kind: Array, Len: 2, Val: 5},
+				{Kind: Object, Val: 2},
+				{Kind: ObjectEnd, Val: 1},
+				{Kind: Array, Val: 4},
+				{Kind: ArrayEnd, Val: 3},
+				{Kind: ArrayEnd, Val: 0},
+			},
+		},
+		{
+			name:  "object",
+			input: `{"a": 1, "bc": [1.5, true, null], "d": false}`,
+			entries: []Entry{
+				{Kind: Object, Len: 3, Val: 11},
+				{Kind: Key, Len: 1, Val: 0},
+				{Kind: Int, Val: 1},
+				{Kind: Key, Len: 2, Val: 1},
+				{Kind: Array, Len: 3, Val: 8},
+				{Kind: Float, Val: math.Float64bits(1.5)},
+				{Kind: True},
+				{Kind: Null},
+				{Kind: ArrayEnd, Val: 4},
+				{Kind: Key, Len: 1, Val: 3},
+				{Kind: False},
+				{Kind: ObjectEnd, Val: 0},
+			},
+			strings: "abcd",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tp, err := Parse(tt.input)
+			if assert.Nil(t, err) {
+				assert.Equal(t, tt.entries, tp.Entries)
+				assert.Equal(t, tt.strings, string(tp.Strings))
+			}
+		})
+	}
+}
+
+func TestParse_Error(t *testing.T) {
+	var tests = []struct {
+		name   string
+		input  string
+		offset int
+		kind   error
+	}{
+		{"empty", ``, 0, parser.ErrUnexpectedEOF},
+		{"missing colon", `{"a" 1}`, 5, parser.ErrUnexpectedToken},
+		{"missing brace", `{"a": 1`, 7, parser.ErrUnexpectedEOF},
+		{"trailing comma", `[1, ]`, 4, parser.ErrTrailingComma},
+		{"trailing content", `[1] 2`, 4, parser.ErrTrailingContent},
+		{"bad number", `[01]`, 1, parser.ErrInvalidNumber},
+		{"bad escape", `["\x"]`, 2, parser.ErrInvalidString},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.input)
+			var syntaxErr *parser.SyntaxError
+			if assert.ErrorAs(t, err, &syntaxErr) {
+				assert.Equal(t, tt.offset, syntaxErr.Offset)
+			}
+			assert.ErrorIs(t, err, tt.kind)
+		})
+	}
+}
+
+func TestCursor(t *testing.T) {
+	tp, err := Parse(`{"a": {"b": [1, "x"]}, "c": 2.5}`)
+	if !assert.Nil(t, err) {
+		return
+	}
+	c := tp.Cursor()
+	assert.Equal(t, Object, c.Kind())
+	assert.Equal(t, 2, c.Len())
+	_, ok := c.Key()
+	assert.False(t, ok)
+
+	assert.True(t, c.Enter())
+	key, _ := c.Key()
+	assert.Equal(t, "a", key)
+	assert.True(t, c.Enter())
+	assert.True(t, c.Enter())
+	i, _ := c.AsInt()
+	assert.Equal(t, int64(1), i)
+	assert.True(t, c.Next())
+	s, _ := c.AsString()
+	assert.Equal(t, "x", s)
+	assert.False(t, c.Next())
+	assert.True(t, c.Up())
+	assert.Equal(t, Array, c.Kind())
+	assert.True(t, c.Up())
+	assert.True(t, c.Next())
+	key, _ = c.Key()
+	assert.Equal(t, "c", key)
+	f, _ := c.AsFloat()
+	assert.Equal(t, 2.5, f)
+	assert.False(t, c.Enter())
+	assert.False(t, c.Next())
+	assert.True(t, c.Up())
+	assert.False(t, c.Up())
+}
+
+const benchInput = `{"id": 1, "name": "gj", "tags": ["a", "b", "c"], "nested": {"x": 1.5, "y": [true, false, null]}}`
+
+func BenchmarkParse(b *testing.B) {
+	b.ReportAllocs()
+	tp := &Tape{}
+	for i := 0; i < b.N; i++ {
+		if err := tp.Parse(benchInput); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParser_Parse(b *testing.B) {
+	b.ReportAllocs()
+	p := parser.New(lexer.Lex(benchInput))
+	for i := 0; i < b.N; i++ {
+		p.Reset(benchInput)
+		if _, err := p.Parse(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -1,5 +1,7 @@
 package token
 
+import "strconv"
+
 // Token identifies the type of Lex items.
 type Token int
 
@@ -19,3 +21,39 @@ const (
 	EOF                       // eof
 	Error                     // error
 )
+
+// names maps tokens to their names.
+var names = [...]string{
+	Unknown:      "Unknown",
+	LeftBrace:    "LeftBrace",
+	RightBrace:   "RightBrace",
+	LeftBracket:  "LeftBracket",
+	RightBracket: "RightBracket",
+	String:       "String",
+	Number:       "Number",
+	True:         "True",
+	False:        "False",
+	Null:         "Null",
+	Comma:        "Comma",
+	Colon:        "Colon",
+	EOF:          "EOF",
+	Error:        "Error",
+}
+
+// String returns the name of the token, e.g. "RightBrace".
+func (t Token) String() string {
+	if t >= 0 && int(t) < len(names) {
+		return names[t]
+	}
+	return "Token(" + strconv.Itoa(int(t)) + ")"
+}
+
+// Lookup returns the token with the given name, as returned by String.
+func Lookup(name string) (Token, bool) {
+	for t, n := range names {
+		if n == name {
+			return Token(t), true
+		}
+	}
+	return Unknown, false
+}
@@ -0,0 +1,25 @@
+package token
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToken_String(t *testing.T) {
+	assert.Equal(t, "RightBrace", RightBrace.String())
+	assert.Equal(t, "EOF", fmt.Sprint(EOF))
+	assert.Equal(t, "Token(42)", Token(42).String())
+	assert.Equal(t, "Token(-1)", Token(-1).String())
+}
+
+func TestLookup(t *testing.T) {
+	for tok := Unknown; tok <= Error; tok++ {
+		got, ok := Lookup(tok.String())
+		assert.True(t, ok)
+		assert.Equal(t, tok, got)
+	}
+	_, ok := Lookup("rightbrace")
+	assert.False(t, ok)
+}
@@ -0,0 +1,34 @@
+package transform
+
+import (
+	"os"
+	"strings"
+
+	"github.com/pohedev/gj.git/ast"
+)
+
+// EnvResolver returns a Resolver looking up environment variables with
+// lookup, os.LookupEnv when nil. Besides "${VAR}", it resolves
+// "${VAR:-default}" to default when VAR is unset or empty; default cannot
+// contain "}".
+func EnvResolver(lookup func(name string) (string, bool)) Resolver {
+	if lookup == nil {
+		lookup = os.LookupEnv
+	}
+	return func(name string) (string, bool) {
+		name, def, hasDefault := strings.Cut(name, ":-")
+		v, ok := lookup(name)
+		if hasDefault && v == "" {
+			return def, true
+		}
+		return v, ok
+	}
+}
+
+// ExpandEnv replaces "${VAR}" and "${VAR:-default}" placeholders in every
+// string value with environment variables looked up with lookup, see
+// EnvResolver, for loading configuration files. Unset variables without
+// default are reported like Substitute does.
+func ExpandEnv(root *ast.RootNode, lookup func(name string) (string, bool)) error {
+	return Substitute(root, EnvResolver(lookup))
+}
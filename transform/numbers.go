@@ -0,0 +1,58 @@
+package transform
+
+import (
+	"strconv"
+
+	"github.com/pohedev/gj.git/ast"
+	"github.com/pohedev/gj.git/internal/jsonnum"
+)
+
+// NormalizeNumbers rewrites every number literal of root in canonical form,
+// modifying root in place, so that equal numbers are written the same way
+// before hashing or diffing: exponents are expanded and superfluous signs
+// and zeros dropped, e.g. 1.0E+2 becomes 100, -0 becomes 0 and 2.50
+// becomes 2.5. Very large or small numbers keep an exponent, e.g. 1e+21.
+// Decimal values are kept exactly, beyond the precision of float64.
+// It returns ast.ErrFrozen when root is frozen.
+func NormalizeNumbers(root *ast.RootNode) error {
+	if err := prepare(root); err != nil {
+		return err
+	}
+	if root != nil && root.Value != nil {
+		normalizeNumbers(root.Value)
+	}
+	return nil
+}
+
+// normalizeNumbers walks node and rewrites number literals.
+func normalizeNumbers(node any) {
+	switch n := node.(type) {
+	case *ast.Value:
+		normalizeNumbers(n.Value)
+
+	case *ast.Object:
+		for i := range n.Children {
+			normalizeNumbers(n.Children[i].Value)
+		}
+
+	case *ast.Array:
+		for i := range n.Children {
+			normalizeNumbers(n.Children[i].Value)
+		}
+
+	case *ast.Literal:
+		if n.LiteralType != ast.LiteralTypeNumber {
+			return
+		}
+		canonical, ok := jsonnum.CanonicalValue(n.Raw, n.Val)
+		if !ok || canonical == n.Raw {
+			return
+		}
+		n.Raw = canonical
+		if i, err := strconv.ParseInt(canonical, 10, 64); err == nil {
+			n.Val = i
+		} else if f, err := strconv.ParseFloat(canonical, 64); err == nil {
+			n.Val = f
+		}
+	}
+}
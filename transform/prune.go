@@ -0,0 +1,65 @@
+package transform
+
+import "github.com/pohedev/gj.git/ast"
+
+// PruneFlag selects the values removed by Prune.
+type PruneFlag uint
+
+const (
+	PruneNulls        PruneFlag = 1 << iota // null values
+	PruneEmptyObjects                       // objects without properties
+	PruneEmptyArrays                        // arrays without items
+)
+
+// Prune removes the properties and array items holding the values selected
+// by flags, modifying root in place. Containers are pruned
+// after their children, so that an object left empty by PruneNulls is removed
+// with PruneEmptyObjects. The root value itself is never removed.
+// It returns ast.ErrFrozen when root is frozen.
+func Prune(root *ast.RootNode, flags PruneFlag) error {
+	if err := prepare(root); err != nil {
+		return err
+	}
+	if root != nil && root.Value != nil {
+		prune(root.Value, flags)
+	}
+	return nil
+}
+
+// prune removes the selected children of node
+// and reports whether node itself is selected.
+func prune(node any, flags PruneFlag) bool {
+	switch n := node.(type) {
+	case *ast.Value:
+		return prune(n.Value, flags)
+
+	case *ast.Object:
+		children := n.Children[:0]
+		for _, prop := range n.Children {
+			if !prune(prop.Value, flags) {
+				children = append(children, prop)
+			}
+		}
+		if len(children) != len(n.Children) {
+			clear(n.Children[len(children):])
+			n.Children = children
+			n.Reindex()
+		}
+		return flags&PruneEmptyObjects != 0 && len(n.Children) == 0
+
+	case *ast.Array:
+		children := n.Children[:0]
+		for _, item := range n.Children {
+			if !prune(item.Value, flags) {
+				children = append(children, item)
+			}
+		}
+		clear(n.Children[len(children):])
+		n.Children = children
+		return flags&PruneEmptyArrays != 0 && len(n.Children) == 0
+
+	case *ast.Literal:
+		return flags&PruneNulls != 0 && n.IsNull()
+	}
+	return false
+}
@@ -0,0 +1,67 @@
+package transform
+
+import (
+	"fmt"
+
+	"github.com/pohedev/gj.git/ast"
+)
+
+// prepare readies root for an in-place transform: it returns ast.ErrFrozen
+// when root is frozen and replaces the RawValue nodes kept by lazy parsing
+// with their parsed value, so that their content is transformed as well.
+func prepare(root *ast.RootNode) error {
+	if root.Frozen() {
+		return ast.ErrFrozen
+	}
+	if root == nil || root.Value == nil {
+		return nil
+	}
+	return expandRaw(root.Value)
+}
+
+// expandRaw replaces the RawValue nodes below node with their parsed value.
+func expandRaw(node any) error {
+	switch n := node.(type) {
+	case *ast.Value:
+		if raw, ok := n.Value.(*ast.RawValue); ok {
+			v, err := parseRaw(raw)
+			if err != nil {
+				return err
+			}
+			n.Value = v
+			return nil
+		}
+		return expandRaw(n.Value)
+
+	case *ast.Object:
+		for _, prop := range n.Children {
+			if err := expandRaw(prop.Value); err != nil {
+				return err
+			}
+		}
+
+	case *ast.Array:
+		for i := range n.Children {
+			item := &n.Children[i]
+			if raw, ok := item.Value.(*ast.RawValue); ok {
+				v, err := parseRaw(raw)
+				if err != nil {
+					return err
+				}
+				item.Value = v
+			} else if err := expandRaw(item.Value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// parseRaw parses raw like the printer does, see ast.RootNode.UnmarshalJSON.
+func parseRaw(raw *ast.RawValue) (any, error) {
+	var v ast.RootNode
+	if err := v.UnmarshalJSON([]byte(raw.Raw)); err != nil {
+		return nil, fmt.Errorf("failed to parse raw value at offset %d: %w", raw.Start, err)
+	}
+	return v.Value.Value, nil
+}
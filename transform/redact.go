@@ -0,0 +1,73 @@
+package transform
+
+import (
+	"strconv"
+
+	"github.com/pohedev/gj.git/ast"
+	"github.com/pohedev/gj.git/path"
+)
+
+// Redact replaces every value located at one of paths with a string literal
+// holding replacement, keeping the rest of the document intact.
+// paths are JSON Pointers or dot/bracket patterns accepted by path.Parse,
+// e.g. "/user/password" or "$.*.password". The root itself is never replaced.
+// It returns ast.ErrFrozen when root is frozen.
+func Redact(root *ast.RootNode, paths []string, replacement string) error {
+	if err := prepare(root); err != nil {
+		return err
+	}
+	patterns := make([]path.Path, 0, len(paths))
+	for _, s := range paths {
+		p, err := path.Parse(s)
+		if err != nil {
+			return err
+		}
+		patterns = append(patterns, p)
+	}
+
+	if root == nil || root.Value == nil {
+		return nil
+	}
+	redact(root.Value.Value, nil, patterns, replacement)
+	return nil
+}
+
+// redact walks node and replaces matched children.
+func redact(node any, tokens []string, patterns []path.Path, replacement string) {
+	switch n := node.(type) {
+	case *ast.Value:
+		redact(n.Value, tokens, patterns, replacement)
+
+	case *ast.Object:
+		for i := range n.Children {
+			prop := &n.Children[i]
+			childTokens := append(tokens[:len(tokens):len(tokens)], prop.Identifier.Value)
+			if matchAny(patterns, childTokens) {
+				prop.Value = &ast.Value{Value: ast.String(replacement)}
+				continue
+			}
+			redact(prop.Value, childTokens, patterns, replacement)
+		}
+
+	case *ast.Array:
+		for i := range n.Children {
+			item := &n.Children[i]
+			childTokens := append(tokens[:len(tokens):len(tokens)], strconv.Itoa(i))
+			if matchAny(patterns, childTokens) {
+				item.Value = ast.String(replacement)
+				continue
+			}
+			redact(item.Value, childTokens, patterns, replacement)
+		}
+	}
+}
+
+// matchAny reports whether tokens match any of patterns.
+func matchAny(patterns []path.Path, tokens []string) bool {
+	for _, p := range patterns {
+		if p.Match(tokens) {
+			return true
+		}
+	}
+	return false
+}
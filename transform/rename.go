@@ -0,0 +1,113 @@
+package transform
+
+import (
+	"strconv"
+
+	"github.com/pohedev/gj.git/ast"
+	"github.com/pohedev/gj.git/path"
+)
+
+// Rename records a property renamed by RenameKeys or RenamePaths.
+type Rename struct {
+	From string // JSON Pointer of the property before renaming.
+	To   string // JSON Pointer of the property after renaming.
+}
+
+// RenameRule renames the properties located at Path to To.
+type RenameRule struct {
+	Path string // JSON Pointer or dot/bracket pattern accepted by path.Parse.
+	To   string // New key.
+}
+
+// RenameKeys renames every property whose key is in mapping to the
+// mapped key, at any depth, and returns the renamed properties in
+// document order. A renamed key equal to an existing key of the same
+// object makes a duplicate key, the last property winning for lookups.
+// It returns ast.ErrFrozen when root is frozen.
+func RenameKeys(root *ast.RootNode, mapping map[string]string) ([]Rename, error) {
+	return renameRoot(root, func(tokens []string) (string, bool) {
+		to, ok := mapping[tokens[len(tokens)-1]]
+		return to, ok
+	})
+}
+
+// RenamePaths renames the properties matched by the paths of rules,
+// the last matching rule winning, and returns the renamed properties in
+// document order. Paths are matched against the keys of the document
+// before renaming, e.g. "$.users[*].mail" or "/server/addr".
+// It returns ast.ErrFrozen when root is frozen.
+func RenamePaths(root *ast.RootNode, rules []RenameRule) ([]Rename, error) {
+	patterns := make([]path.Path, 0, len(rules))
+	for _, rule := range rules {
+		p, err := path.Parse(rule.Path)
+		if err != nil {
+			return nil, err
+		}
+		patterns = append(patterns, p)
+	}
+	return renameRoot(root, func(tokens []string) (string, bool) {
+		for i := len(patterns) - 1; i >= 0; i-- {
+			if patterns[i].Match(tokens) {
+				return rules[i].To, true
+			}
+		}
+		return "", false
+	})
+}
+
+// renameRoot renames the properties of root for which rename
+// reports true, given their location before renaming.
+func renameRoot(root *ast.RootNode, rename func(tokens []string) (string, bool)) ([]Rename, error) {
+	if err := prepare(root); err != nil {
+		return nil, err
+	}
+	if root == nil || root.Value == nil {
+		return nil, nil
+	}
+	var renames []Rename
+	renameKeys(root.Value, nil, nil, rename, &renames)
+	return renames, nil
+}
+
+// renameKeys walks node, located at from before renaming and at to after.
+func renameKeys(node any, from, to []string, rename func([]string) (string, bool), renames *[]Rename) {
+	switch n := node.(type) {
+	case *ast.Value:
+		renameKeys(n.Value, from, to, rename, renames)
+
+	case *ast.Object:
+		renamed := false
+		for i := range n.Children {
+			prop := &n.Children[i]
+			childFrom := append(from[:len(from):len(from)], prop.Identifier.Value)
+			childTo := append(to[:len(to):len(to)], prop.Identifier.Value)
+			if key, ok := rename(childFrom); ok && key != prop.Identifier.Value {
+				prop.Identifier.Value = key
+				childTo[len(childTo)-1] = key
+				*renames = append(*renames, Rename{From: pointer(childFrom), To: pointer(childTo)})
+				renamed = true
+			}
+			renameKeys(prop.Value, childFrom, childTo, rename, renames)
+		}
+		if renamed {
+			n.Reindex()
+		}
+
+	case *ast.Array:
+		for i := range n.Children {
+			index := strconv.Itoa(i)
+			childFrom := append(from[:len(from):len(from)], index)
+			childTo := append(to[:len(to):len(to)], index)
+			renameKeys(n.Children[i].Value, childFrom, childTo, rename, renames)
+		}
+	}
+}
+
+// pointer returns the JSON Pointer made of tokens.
+func pointer(tokens []string) string {
+	p := make(path.Path, 0, len(tokens))
+	for _, token := range tokens {
+		p = append(p, path.Segment{Key: token})
+	}
+	return p.Pointer()
+}
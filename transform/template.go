@@ -0,0 +1,125 @@
+package transform
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pohedev/gj.git/ast"
+)
+
+// Resolver returns the value of placeholder name
+// and reports whether it was found.
+type Resolver func(name string) (string, bool)
+
+// MapResolver returns a Resolver looking up placeholders in m.
+func MapResolver(m map[string]string) Resolver {
+	return func(name string) (string, bool) {
+		v, ok := m[name]
+		return v, ok
+	}
+}
+
+// Placeholder represents a placeholder found in a string value.
+type Placeholder struct {
+	Name   string // Name between "${" and "}".
+	Path   string // JSON Pointer of the string value.
+	Offset int    // Byte offset of "${" in the decoded string value.
+}
+
+// UnresolvedError is returned by Substitute when some placeholders
+// could not be resolved.
+type UnresolvedError struct {
+	Placeholders []Placeholder
+}
+
+func (e *UnresolvedError) Error() string {
+	var b strings.Builder
+	b.WriteString("failed to substitute: unresolved placeholders:")
+	for _, p := range e.Placeholders {
+		fmt.Fprintf(&b, " ${%s} at %s offset %d;", p.Name, p.Path, p.Offset)
+	}
+	return strings.TrimSuffix(b.String(), ";")
+}
+
+// Substitute replaces "${NAME}" placeholders in every string value with
+// the result of resolve. "$${" is written as a literal "${".
+// Placeholders that cannot be resolved are left untouched and reported
+// together in an *UnresolvedError once the whole document is processed.
+// It returns ast.ErrFrozen when root is frozen.
+func Substitute(root *ast.RootNode, resolve Resolver) error {
+	if err := prepare(root); err != nil {
+		return err
+	}
+	if root == nil || root.Value == nil {
+		return nil
+	}
+	var unresolved []Placeholder
+	substitute(root.Value, nil, resolve, &unresolved)
+	if len(unresolved) > 0 {
+		return &UnresolvedError{Placeholders: unresolved}
+	}
+	return nil
+}
+
+// substitute walks node and replaces placeholders in string literals.
+func substitute(node any, tokens []string, resolve Resolver, unresolved *[]Placeholder) {
+	switch n := node.(type) {
+	case *ast.Value:
+		substitute(n.Value, tokens, resolve, unresolved)
+
+	case *ast.Object:
+		for i := range n.Children {
+			prop := &n.Children[i]
+			childTokens := append(tokens[:len(tokens):len(tokens)], prop.Identifier.Value)
+			substitute(prop.Value, childTokens, resolve, unresolved)
+		}
+
+	case *ast.Array:
+		for i := range n.Children {
+			childTokens := append(tokens[:len(tokens):len(tokens)], strconv.Itoa(i))
+			substitute(n.Children[i].Value, childTokens, resolve, unresolved)
+		}
+
+	case *ast.Literal:
+		s, ok := n.Val.(string)
+		if n.LiteralType != ast.LiteralTypeString || !ok {
+			return
+		}
+		if expanded := expand(s, pointer(tokens), resolve, unresolved); expanded != s {
+			n.Val = expanded
+			n.Raw = ""
+		}
+	}
+}
+
+// expand replaces placeholders in s.
+func expand(s, pointer string, resolve Resolver, unresolved *[]Placeholder) string {
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		if strings.HasPrefix(s[i:], "$${") {
+			b.WriteString("${")
+			i += 3
+			continue
+		}
+		if !strings.HasPrefix(s[i:], "${") {
+			b.WriteByte(s[i])
+			i++
+			continue
+		}
+		end := strings.IndexByte(s[i:], '}')
+		if end < 0 {
+			b.WriteString(s[i:])
+			break
+		}
+		name := s[i+2 : i+end]
+		if v, ok := resolve(name); ok {
+			b.WriteString(v)
+		} else {
+			*unresolved = append(*unresolved, Placeholder{Name: name, Path: pointer, Offset: i})
+			b.WriteString(s[i : i+end+1])
+		}
+		i += end + 1
+	}
+	return b.String()
+}
@@ -0,0 +1,264 @@
+package transform
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/pohedev/gj.git/ast"
+	"github.com/pohedev/gj.git/flatten"
+	"github.com/pohedev/gj.git/lexer"
+	"github.com/pohedev/gj.git/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+func parse(t *testing.T, input string) *ast.RootNode {
+	t.Helper()
+	root, err := parser.New(lexer.Lex(input)).Parse()
+	if err != nil {
+		t.Fatalf("failed to parse %q: %v", input, err)
+	}
+	return root
+}
+
+func TestRedact(t *testing.T) {
+	root := parse(t, `{
+		"admin": {"name": "root", "password": "hunter2"},
+		"users": [{"name": "joe", "password": "secret"}],
+		"token": "abc"
+	}`)
+
+	err := Redact(root, []string{"$.*.password", "$.users[*].password", "/token"}, "***")
+
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]any{
+		"admin.name":        "root",
+		"admin.password":    "***",
+		"users[0].name":     "joe",
+		"users[0].password": "***",
+		"token":             "***",
+	}, flatten.Flatten(root))
+}
+
+func TestRedact_InvalidPath(t *testing.T) {
+	root := parse(t, `{"a": 1}`)
+
+	assert.Error(t, Redact(root, []string{"$.a["}, "***"))
+}
+
+func TestSubstitute(t *testing.T) {
+	root := parse(t, `{"url": "https://${HOST}:${PORT}/", "tags": ["${ENV}", "$${ENV}"], "n": 1}`)
+
+	err := Substitute(root, MapResolver(map[string]string{
+		"HOST": "example.com",
+		"PORT": "8080",
+		"ENV":  "prod",
+	}))
+
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]any{
+		"url":     "https://example.com:8080/",
+		"tags[0]": "prod",
+		"tags[1]": "${ENV}",
+		"n":       int64(1),
+	}, flatten.Flatten(root))
+}
+
+func TestSubstitute_Unresolved(t *testing.T) {
+	root := parse(t, `{"a": "${X}", "b": ["ok", "x ${Y}"]}`)
+
+	err := Substitute(root, MapResolver(nil))
+
+	var unresolvedErr *UnresolvedError
+	assert.ErrorAs(t, err, &unresolvedErr)
+	assert.Equal(t, []Placeholder{
+		{Name: "X", Path: "/a", Offset: 0},
+		{Name: "Y", Path: "/b/1", Offset: 2},
+	}, unresolvedErr.Placeholders)
+	assert.Equal(t, "x ${Y}", flatten.Flatten(root)["b[1]"])
+}
+
+func TestExpandEnv(t *testing.T) {
+	root := parse(t, `{"db": "${DB_HOST:-localhost}:${DB_PORT:-5432}", "user": "${USER:-nobody}", "empty": "${EMPTY:-x}", "token": "${TOKEN}"}`)
+	env := map[string]string{"DB_PORT": "6543", "USER": "ann", "EMPTY": "", "TOKEN": "s3cr3t"}
+
+	err := ExpandEnv(root, func(name string) (string, bool) {
+		v, ok := env[name]
+		return v, ok
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]any{
+		"db":    "localhost:6543",
+		"user":  "ann",
+		"empty": "x",
+		"token": "s3cr3t",
+	}, flatten.Flatten(root))
+
+	var unresolved *UnresolvedError
+	err = ExpandEnv(parse(t, `["${MISSING}"]`), func(string) (string, bool) { return "", false })
+	if assert.ErrorAs(t, err, &unresolved) {
+		assert.Equal(t, "MISSING", unresolved.Placeholders[0].Name)
+	}
+}
+
+func TestRenameKeys(t *testing.T) {
+	root := parse(t, `{"usr": {"nm": "joe", "tags": [{"nm": "a"}]}, "id": 1}`)
+	obj := root.Value.Value.(*ast.Object)
+	obj.Index()
+
+	renames, err := RenameKeys(root, map[string]string{"usr": "user", "nm": "name", "id": "id"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []Rename{
+		{From: "/usr", To: "/user"},
+		{From: "/usr/nm", To: "/user/name"},
+		{From: "/usr/tags/0/nm", To: "/user/tags/0/name"},
+	}, renames)
+	assert.Equal(t, map[string]any{
+		"user.name":         "joe",
+		"user.tags[0].name": "a",
+		"id":                int64(1),
+	}, flatten.Flatten(root))
+	_, ok := obj.Get("user")
+	assert.True(t, ok)
+}
+
+func TestRenamePaths(t *testing.T) {
+	root := parse(t, `{"users": [{"mail": "a@x"}, {"mail": "b@x"}], "mail": "root@x", "server": {"addr": "h"}}`)
+
+	renames, err := RenamePaths(root, []RenameRule{
+		{Path: "$.users[*].mail", To: "email"},
+		{Path: "/server/addr", To: "host"},
+		{Path: "/server", To: "backend"},
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []Rename{
+		{From: "/users/0/mail", To: "/users/0/email"},
+		{From: "/users/1/mail", To: "/users/1/email"},
+		{From: "/server", To: "/backend"},
+		{From: "/server/addr", To: "/backend/host"},
+	}, renames)
+	assert.Equal(t, map[string]any{
+		"users[0].email": "a@x",
+		"users[1].email": "b@x",
+		"mail":           "root@x",
+		"backend.host":   "h",
+	}, flatten.Flatten(root))
+
+	_, err = RenamePaths(root, []RenameRule{{Path: "$.a[", To: "b"}})
+	assert.Error(t, err)
+}
+
+func TestPrune(t *testing.T) {
+	const input = `{"a": null, "b": {}, "c": [], "d": {"e": null}, "f": [null, 1, [], {}], "g": 0}`
+	var tests = []struct {
+		name  string
+		flags PruneFlag
+		want  any
+	}{
+		{"nulls", PruneNulls, map[string]any{
+			"b": map[string]any{}, "c": []any{}, "d": map[string]any{},
+			"f": []any{int64(1), []any{}, map[string]any{}}, "g": int64(0),
+		}},
+		{"empty containers", PruneEmptyObjects | PruneEmptyArrays, map[string]any{
+			"a": nil, "d": map[string]any{"e": nil}, "f": []any{nil, int64(1)}, "g": int64(0),
+		}},
+		{"all", PruneNulls | PruneEmptyObjects | PruneEmptyArrays, map[string]any{
+			"f": []any{int64(1)}, "g": int64(0),
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := parse(t, input)
+			assert.Nil(t, Prune(root, tt.flags))
+			assert.Equal(t, tt.want, root.ToGo())
+		})
+	}
+
+	t.Run("root", func(t *testing.T) {
+		root := parse(t, `{"a": null}`)
+		assert.Nil(t, Prune(root, PruneNulls|PruneEmptyObjects))
+		assert.Equal(t, map[string]any{}, root.ToGo())
+	})
+
+	t.Run("lazy", func(t *testing.T) {
+		root, err := parser.New(lexer.Lex(input), parser.LazyBelow(0)).Parse()
+		assert.Nil(t, err)
+		assert.Nil(t, Prune(root, PruneNulls|PruneEmptyObjects|PruneEmptyArrays))
+		assert.Equal(t, map[string]any{"f": []any{int64(1)}, "g": int64(0)}, root.ToGo())
+		_, ok := find(t, root, "/f/0").Val.(int64)
+		assert.True(t, ok, "raw values are replaced by their parsed value")
+	})
+
+	t.Run("invalid raw value", func(t *testing.T) {
+		root, err := parser.New(lexer.Lex(`{"a": [null], "b": {"c" 1}}`), parser.LazyBelow(0)).Parse()
+		assert.Nil(t, err)
+		assert.EqualError(t, Prune(root, PruneNulls), `failed to parse raw value at offset 19: failed to unmarshal document: unexpected "1", expected ':' at offset 5`)
+	})
+}
+
+func TestFrozen(t *testing.T) {
+	root := parse(t, `{"a": null, "b": 1, "c": "${X}", "d": 1.0}`)
+	root.Freeze()
+
+	assert.ErrorIs(t, Prune(root, PruneNulls), ast.ErrFrozen)
+	_, err := RenameKeys(root, map[string]string{"b": "x"})
+	assert.ErrorIs(t, err, ast.ErrFrozen)
+	_, err = RenamePaths(root, []RenameRule{{Path: "/b", To: "x"}})
+	assert.ErrorIs(t, err, ast.ErrFrozen)
+	assert.ErrorIs(t, NormalizeNumbers(root), ast.ErrFrozen)
+	assert.ErrorIs(t, Redact(root, []string{"/b"}, "***"), ast.ErrFrozen)
+	assert.ErrorIs(t, Substitute(root, MapResolver(map[string]string{"X": "x"})), ast.ErrFrozen)
+
+	obj := root.Value.Value.(*ast.Object)
+	b, _ := obj.Get("b")
+	c, _ := obj.Get("c")
+	assert.Equal(t, int64(1), b.Value.(*ast.Literal).Val)
+	assert.Equal(t, "${X}", c.Value.(*ast.Literal).Val)
+	assert.Equal(t, map[string]any{"a": nil, "b": int64(1), "c": "${X}", "d": 1.0}, root.ToGo())
+}
+
+func TestNormalizeNumbers(t *testing.T) {
+	root := parse(t, `{"a": 1.0E+2, "b": -0, "c": [2.50, 1e21, 0.0000001, 12], "d": "1.0"}`)
+	root.Value.Value.(*ast.Object).Set("e", &ast.Value{Value: ast.Number(3.0)})
+
+	assert.Nil(t, NormalizeNumbers(root))
+
+	var raws []string
+	var vals []any
+	for _, v := range []string{"/a", "/b", "/c/0", "/c/1", "/c/2", "/c/3", "/e"} {
+		lit := find(t, root, v)
+		raws = append(raws, lit.Raw)
+		vals = append(vals, lit.Val)
+	}
+	assert.Equal(t, []string{"100", "0", "2.5", "1e+21", "1e-7", "12", "3"}, raws)
+	assert.Equal(t, []any{int64(100), int64(0), 2.5, 1e21, 1e-7, int64(12), int64(3)}, vals)
+	assert.Equal(t, "1.0", find(t, root, "/d").Val)
+}
+
+// find returns the literal located at JSON Pointer ptr.
+func find(t *testing.T, root *ast.RootNode, ptr string) *ast.Literal {
+	t.Helper()
+	c := ast.NewCursor(root.Value)
+	for _, token := range strings.Split(ptr, "/")[1:] {
+		if !c.Enter() {
+			t.Fatalf("no value at %s", ptr)
+		}
+		for {
+			key, ok := c.Key()
+			if ok && key == token || !ok && strconv.Itoa(c.Index()) == token {
+				break
+			}
+			if !c.Next() {
+				t.Fatalf("no value at %s", ptr)
+			}
+		}
+	}
+	lit, ok := c.Value().Value.(*ast.Literal)
+	if !ok {
+		t.Fatalf("no literal at %s", ptr)
+	}
+	return lit
+}
@@ -0,0 +1,105 @@
+// Package typegen infers the types of documents and writes them as type
+// declarations of other languages, so that the shapes of payloads can be
+// shared with code written in them.
+package typegen
+
+import "github.com/pohedev/gj.git/ast"
+
+// kind is a set of JSON types.
+type kind uint8
+
+const (
+	kindString kind = 1 << iota
+	kindNumber
+	kindBool
+	kindObject
+	kindArray
+	kindNull
+)
+
+// shape is the type inferred from the values found at one location
+// of the documents.
+type shape struct {
+	kinds   kind
+	fields  []*field // Properties of the objects, in order of first appearance.
+	objects int      // Number of objects merged, to find optional properties.
+	items   *shape   // Items of the arrays, nil when they were all empty.
+}
+
+// field is a property of the objects of a shape.
+type field struct {
+	key   string
+	shape *shape
+	count int // Number of objects holding the property.
+}
+
+// infer returns the shape of the values of roots.
+func infer(roots []*ast.RootNode) *shape {
+	s := &shape{}
+	for _, root := range roots {
+		if root != nil {
+			s.add(root.Value)
+		}
+	}
+	return s
+}
+
+// add merges node into s.
+func (s *shape) add(node any) {
+	switch n := node.(type) {
+	case *ast.Value:
+		if n == nil {
+			s.kinds |= kindNull
+			return
+		}
+		s.add(n.Value)
+	case *ast.Object:
+		s.kinds |= kindObject
+		s.objects++
+		seen := map[string]bool{}
+		for _, prop := range n.Children {
+			key := prop.Identifier.Value
+			f := s.field(key)
+			if !seen[key] {
+				seen[key] = true
+				f.count++
+			}
+			f.shape.add(prop.Value)
+		}
+	case *ast.Array:
+		s.kinds |= kindArray
+		for _, item := range n.Children {
+			if s.items == nil {
+				s.items = &shape{}
+			}
+			s.items.add(item.Value)
+		}
+	case *ast.Literal:
+		switch n.LiteralType {
+		case ast.LiteralTypeString:
+			s.kinds |= kindString
+		case ast.LiteralTypeNumber:
+			s.kinds |= kindNumber
+		case ast.LiteralTypeTrue, ast.LiteralTypeFalse:
+			s.kinds |= kindBool
+		default:
+			s.kinds |= kindNull
+		}
+	case *ast.RawValue:
+		// Lazily parsed values are not inspected.
+	default:
+		s.kinds |= kindNull
+	}
+}
+
+// field returns the field of s with the given key, adding it if needed.
+func (s *shape) field(key string) *field {
+	for _, f := range s.fields {
+		if f.key == key {
+			return f
+		}
+	}
+	f := &field{key: key, shape: &shape{}}
+	s.fields = append(s.fields, f)
+	return f
+}
@@ -0,0 +1,114 @@
+package typegen
+
+import (
+	"testing"
+
+	"github.com/pohedev/gj.git/ast"
+	"github.com/pohedev/gj.git/lexer"
+	"github.com/pohedev/gj.git/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTypeScript(t *testing.T) {
+	var tests = []struct {
+		name   string
+		inputs []string
+		want   string
+	}{
+		{"literal", []string{`"x"`}, "export type Root = string;\n"},
+		{"union", []string{`1`, `null`, `true`}, "export type Root = number | boolean | null;\n"},
+		{"empty array", []string{`[]`}, "export type Root = unknown[];\n"},
+		{
+			name:   "object",
+			inputs: []string{`{"id": 1, "user-name": "x", "tags": ["a", 2]}`},
+			want: `export interface Root {
+  id: number;
+  "user-name": string;
+  tags: (string | number)[];
+}
+`,
+		},
+		{
+			name:   "optional properties",
+			inputs: []string{`{"a": 1, "b": "x"}`, `{"a": 2, "c": null}`},
+			want: `export interface Root {
+  a: number;
+  b?: string;
+  c?: null;
+}
+`,
+		},
+		{
+			name: "nested objects",
+			inputs: []string{
+				`{"owner": {"name": "x"}, "line_items": [{"sku": "a"}, {"sku": "b", "qty": 2}]}`,
+				`{"owner": null, "line_items": []}`,
+			},
+			want: `export interface Root {
+  owner: Owner | null;
+  line_items: LineItemsItem[];
+}
+
+export interface Owner {
+  name: string;
+}
+
+export interface LineItemsItem {
+  sku: string;
+  qty?: number;
+}
+`,
+		},
+		{
+			name:   "name collisions",
+			inputs: []string{`{"root": {"a": 1}, "x": {"root": {"b": true}}}`},
+			want: `export interface Root {
+  root: Root2;
+  x: X;
+}
+
+export interface Root2 {
+  a: number;
+}
+
+export interface X {
+  root: Root3;
+}
+
+export interface Root3 {
+  b: boolean;
+}
+`,
+		},
+		{
+			name:   "array of objects at root",
+			inputs: []string{`[{"a": 1}]`},
+			want: `export type Root = RootItem[];
+
+export interface RootItem {
+  a: number;
+}
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var roots []*ast.RootNode
+			for _, input := range tt.inputs {
+				root, err := parser.New(lexer.Lex(input)).Parse()
+				require.NoError(t, err)
+				roots = append(roots, root)
+			}
+			assert.Equal(t, tt.want, TypeScript("root", roots...))
+		})
+	}
+}
+
+func TestIdentifier(t *testing.T) {
+	assert.Equal(t, "UserId", identifier("user_id"))
+	assert.Equal(t, "ContentType", identifier("content-type"))
+	assert.Equal(t, "_2fa", identifier("2fa"))
+	assert.Equal(t, "Value", identifier("€"))
+}
@@ -0,0 +1,145 @@
+package typegen
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/pohedev/gj.git/ast"
+)
+
+// TypeScript returns TypeScript declarations of the type of the documents
+// roots, named name. Objects are declared as interfaces named after their
+// key, with properties missing from some of the objects at a location made
+// optional; values of several types are unions, and arrays of values of no
+// known type, such as arrays always empty, are unknown[]. The root type is
+// declared first, then the interfaces it uses in order of first use.
+func TypeScript(name string, roots ...*ast.RootNode) string {
+	g := &tsGenerator{names: map[string]bool{}}
+	s := infer(roots)
+	if s.kinds == kindObject {
+		g.declare(identifier(name), s)
+	} else {
+		g.names[identifier(name)] = true
+		g.decls = append(g.decls, "")
+		g.decls[0] = "export type " + identifier(name) + " = " + g.expr(s, name) + ";\n"
+	}
+	for i := 0; i < len(g.pending); i++ {
+		g.interface_(g.pending[i])
+	}
+	return strings.Join(g.decls, "\n")
+}
+
+// tsGenerator writes TypeScript declarations.
+type tsGenerator struct {
+	names   map[string]bool // Declared names.
+	pending []pendingDecl   // Interfaces to write.
+	decls   []string
+}
+
+// pendingDecl is an interface to write.
+type pendingDecl struct {
+	name  string
+	shape *shape
+	index int // Position of the declaration in decls.
+}
+
+// declare reserves a declaration for the interface of object shape s,
+// named after name, and returns its final name.
+func (g *tsGenerator) declare(name string, s *shape) string {
+	unique := name
+	for i := 2; g.names[unique]; i++ {
+		unique = name + strconv.Itoa(i)
+	}
+	g.names[unique] = true
+	g.pending = append(g.pending, pendingDecl{name: unique, shape: s, index: len(g.decls)})
+	g.decls = append(g.decls, "")
+	return unique
+}
+
+// interface_ writes the interface of d.
+func (g *tsGenerator) interface_(d pendingDecl) {
+	var b strings.Builder
+	b.WriteString("export interface " + d.name + " {\n")
+	for _, f := range d.shape.fields {
+		b.WriteString("  " + propertyName(f.key))
+		if f.count < d.shape.objects {
+			b.WriteByte('?')
+		}
+		b.WriteString(": " + g.expr(f.shape, f.key) + ";\n")
+	}
+	b.WriteString("}\n")
+	g.decls[d.index] = b.String()
+}
+
+// expr returns the type expression of s, found at key.
+func (g *tsGenerator) expr(s *shape, key string) string {
+	var types []string
+	if s.kinds&kindString != 0 {
+		types = append(types, "string")
+	}
+	if s.kinds&kindNumber != 0 {
+		types = append(types, "number")
+	}
+	if s.kinds&kindBool != 0 {
+		types = append(types, "boolean")
+	}
+	if s.kinds&kindObject != 0 {
+		types = append(types, g.declare(identifier(key), s))
+	}
+	if s.kinds&kindArray != 0 {
+		item := "unknown"
+		if s.items != nil && s.items.kinds != 0 {
+			item = g.expr(s.items, key+"Item")
+		}
+		if strings.Contains(item, " ") {
+			item = "(" + item + ")"
+		}
+		types = append(types, item+"[]")
+	}
+	if s.kinds&kindNull != 0 {
+		types = append(types, "null")
+	}
+	if len(types) == 0 {
+		return "unknown"
+	}
+	return strings.Join(types, " | ")
+}
+
+// plainName matches the property names written without quotes.
+var plainName = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// propertyName returns key as a TypeScript property name.
+func propertyName(key string) string {
+	if plainName.MatchString(key) {
+		return key
+	}
+	return strconv.Quote(key)
+}
+
+// identifier returns s as a type name in PascalCase, e.g. "user_id" as
+// UserId, dropping the characters not allowed in identifiers.
+func identifier(s string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range s {
+		switch {
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			if b.Len() == 0 && unicode.IsDigit(r) {
+				b.WriteByte('_')
+			}
+			if upper {
+				r = unicode.ToUpper(r)
+			}
+			b.WriteRune(r)
+			upper = false
+		default:
+			upper = true
+		}
+	}
+	if b.Len() == 0 {
+		return "Value"
+	}
+	return b.String()
+}
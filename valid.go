@@ -0,0 +1,184 @@
+package gj
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/pohedev/gj.git/internal/jsonstr"
+	"github.com/pohedev/gj.git/lexer"
+	"github.com/pohedev/gj.git/parser"
+	"github.com/pohedev/gj.git/token"
+)
+
+// validState is the state of the validator between two items.
+type validState int
+
+const (
+	validValue           validState = iota // A value is expected.
+	validValueOrEnd                        // A value or ']' is expected.
+	validValueAfterComma                   // A value is expected, ']' is a trailing comma.
+	validKeyOrEnd                          // A key or '}' is expected.
+	validKey                               // A key is expected, '}' is a trailing comma.
+	validColon                             // ':' is expected.
+	validAfterValue                        // ',', the closer of the container or EOF is expected.
+)
+
+// lexers holds the Lexers used by Valid.
+var lexers = sync.Pool{
+	New: func() any {
+		return lexer.Lex("")
+	},
+}
+
+// Valid reports whether input is a well-formed JSON document, returning
+// the same errors as the parser without building the AST: a
+// *parser.SyntaxError matched with the parser.Err variables. Duplicate
+// keys are not checked.
+func Valid(input string) error {
+	lex := lexers.Get().(*lexer.Lexer)
+	lex.Reset(input)
+	defer func() {
+		lex.Reset("")
+		lexers.Put(lex)
+	}()
+
+	var (
+		v     validator
+		depth [32]token.Token
+		stack = depth[:0]
+		done  bool
+		err   error
+	)
+	for !done && err == nil {
+		stack, done, err = v.step(lex.NextItem(), lex, stack)
+	}
+	return err
+}
+
+// validator checks the items of a document one at a time.
+type validator struct {
+	state    validState
+	previous lexer.Item
+}
+
+// step checks the next item of the document read by lex given the open
+// brackets in stack, innermost last. It returns the updated stack and
+// whether the document is complete. The stack is passed along rather than
+// held by the validator so that it can stay off the heap.
+func (v *validator) step(item lexer.Item, lex *lexer.Lexer, stack []token.Token) ([]token.Token, bool, error) {
+	if item.Token == token.Error {
+		return stack, false, &parser.SyntaxError{Msg: item.Val, Offset: item.Pos, End: item.Pos, Err: lex.Err()}
+	}
+
+	switch v.state {
+	case validValueOrEnd, validValueAfterComma:
+		if item.Token == token.RightBracket {
+			if v.state == validValueAfterComma {
+				return stack, false, syntaxError(v.previous, parser.ErrTrailingComma, "trailing comma in array")
+			}
+			stack = stack[:len(stack)-1]
+			v.state = validAfterValue
+			break
+		}
+		fallthrough
+
+	case validValue:
+		switch item.Token {
+		case token.String:
+			if err := validString(item); err != nil {
+				return stack, false, err
+			}
+			v.state = validAfterValue
+		case token.Number:
+			if _, err := strconv.ParseFloat(item.Val, 64); err != nil {
+				return stack, false, syntaxError(item, parser.ErrInvalidNumber, "invalid number "+item.Val)
+			}
+			v.state = validAfterValue
+		case token.True, token.False, token.Null:
+			v.state = validAfterValue
+		case token.LeftBrace:
+			stack = append(stack, item.Token)
+			v.state = validKeyOrEnd
+		case token.LeftBracket:
+			stack = append(stack, item.Token)
+			v.state = validValueOrEnd
+		default:
+			return stack, false, unexpected(item, "value")
+		}
+
+	case validKeyOrEnd, validKey:
+		switch item.Token {
+		case token.String:
+			if err := validString(item); err != nil {
+				return stack, false, err
+			}
+			v.state = validColon
+		case token.RightBrace:
+			if v.state == validKey {
+				return stack, false, syntaxError(v.previous, parser.ErrTrailingComma, "trailing comma in object")
+			}
+			stack = stack[:len(stack)-1]
+			v.state = validAfterValue
+		default:
+			return stack, false, unexpected(item, "string key")
+		}
+
+	case validColon:
+		if item.Token != token.Colon {
+			return stack, false, unexpected(item, "':'")
+		}
+		v.state = validValue
+
+	case validAfterValue:
+		if len(stack) == 0 {
+			if item.Token != token.EOF {
+				return stack, false, syntaxError(item, parser.ErrTrailingContent, "unexpected trailing content")
+			}
+			return stack, true, nil
+		}
+		open := stack[len(stack)-1]
+		switch {
+		case item.Token == token.Comma && open == token.LeftBrace:
+			v.state = validKey
+		case item.Token == token.Comma:
+			v.state = validValueAfterComma
+		case item.Token == token.RightBrace && open == token.LeftBrace,
+			item.Token == token.RightBracket && open == token.LeftBracket:
+			stack = stack[:len(stack)-1]
+		case item.Token == token.EOF && open == token.LeftBrace:
+			return stack, false, syntaxError(item, parser.ErrUnexpectedEOF, "missing closing brace")
+		case item.Token == token.EOF:
+			return stack, false, syntaxError(item, parser.ErrUnexpectedEOF, "missing closing bracket")
+		case open == token.LeftBrace:
+			return stack, false, unexpected(item, "',' or '}'")
+		default:
+			return stack, false, unexpected(item, "',' or ']'")
+		}
+	}
+	v.previous = item
+	return stack, false, nil
+}
+
+// validString checks the escapes of string item.
+func validString(item lexer.Item) error {
+	if offset, err := jsonstr.Check(item.Val); err != nil {
+		syntaxErr := syntaxError(item, parser.ErrInvalidString, err.Error())
+		syntaxErr.Offset += offset
+		return syntaxErr
+	}
+	return nil
+}
+
+// unexpected returns the error for item when expected was expected.
+func unexpected(item lexer.Item, expected string) error {
+	if item.Token == token.EOF {
+		return syntaxError(item, parser.ErrUnexpectedEOF, "unexpected EOF, expected "+expected)
+	}
+	return syntaxError(item, parser.ErrUnexpectedToken, fmt.Sprintf("unexpected %v, expected %s", item, expected))
+}
+
+// syntaxError returns a *parser.SyntaxError of the given kind spanning item.
+func syntaxError(item lexer.Item, kind error, msg string) *parser.SyntaxError {
+	return &parser.SyntaxError{Msg: msg, Offset: item.Pos, End: item.Pos + len(item.Val), Err: kind}
+}
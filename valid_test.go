@@ -0,0 +1,74 @@
+package gj_test
+
+import (
+	"testing"
+
+	"github.com/pohedev/gj.git"
+	"github.com/pohedev/gj.git/lexer"
+	"github.com/pohedev/gj.git/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValid(t *testing.T) {
+	var tests = []string{
+		`{"a": [1, -2.5e3, "x\n", true, false, null, {}, []]}`,
+		`"x"`,
+		` 1 `,
+		``,
+		`{`,
+		`[1, 2`,
+		`{"a": 1,}`,
+		`[1,]`,
+		`{"a" 1}`,
+		`{1: 2}`,
+		`{"a": 1 "b": 2}`,
+		`[1 2]`,
+		`[1}`,
+		`{"a": 1]`,
+		`[1] 2`,
+		`[01]`,
+		`[1e999]`,
+		`["abc]`,
+		`["\x"]`,
+		`{"\x": 1}`,
+		`[$]`,
+		`{"a": tru}`,
+		`[fals`,
+		`:`,
+	}
+	for _, input := range tests {
+		t.Run(input, func(t *testing.T) {
+			_, want := parser.New(lexer.Lex(input)).Parse()
+			err := gj.Valid(input)
+			if want == nil {
+				assert.Nil(t, err)
+				return
+			}
+			var wantErr, syntaxErr *parser.SyntaxError
+			if assert.ErrorAs(t, want, &wantErr) && assert.ErrorAs(t, err, &syntaxErr) {
+				assert.Equal(t, wantErr.Offset, syntaxErr.Offset)
+				assert.Equal(t, wantErr.Err, syntaxErr.Err)
+			}
+		})
+	}
+}
+
+const benchDocument = `{"id": 1, "name": "gj", "tags": ["a", "b", "c"], "nested": {"x": 1.5, "y": [true, false, null]}, "text": "line\nbreak"}`
+
+func BenchmarkValid(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := gj.Valid(benchDocument); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParse(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := parser.New(lexer.Lex(benchDocument)).Parse(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package gj
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"runtime"
+	"slices"
+	"sync"
+
+	"github.com/pohedev/gj.git/lexer"
+	"github.com/pohedev/gj.git/parser"
+	"github.com/pohedev/gj.git/position"
+)
+
+// FileResult is the outcome of validating one file with ValidateFS.
+type FileResult struct {
+	Path   string      // Path of the file in the file system.
+	OK     bool        // The file is well-formed JSON.
+	Errors []FileError // Syntax errors in order, or the error reading the file.
+}
+
+// FileError is an error found in a file by ValidateFS.
+type FileError struct {
+	Line   int   // 1-based line of the error, 0 when unknown.
+	Column int   // 1-based column of the error in runes, 0 when unknown.
+	Err    error // The *parser.SyntaxError, or the error reading the file.
+}
+
+func (e FileError) Error() string {
+	if e.Line == 0 {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("%d:%d: %v", e.Line, e.Column, e.Err)
+}
+
+func (e FileError) Unwrap() error {
+	return e.Err
+}
+
+// ValidateFS validates the files of fsys matching glob, as accepted by
+// fs.Glob, returning one result per file in path order. Files are validated
+// in parallel, and all the syntax errors of a file are reported, see
+// parser.Recover. The error is only non-nil for a malformed pattern.
+func ValidateFS(fsys fs.FS, glob string) ([]FileResult, error) {
+	paths, err := fs.Glob(fsys, glob)
+	if err != nil {
+		return nil, fmt.Errorf("failed to validate files: %w", err)
+	}
+	slices.Sort(paths)
+
+	results := make([]FileResult, len(paths))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for range min(runtime.GOMAXPROCS(0), len(paths)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				results[i] = validateFile(fsys, paths[i])
+			}
+		}()
+	}
+	for i := range paths {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	return results, nil
+}
+
+// validateFile validates the file at path in fsys.
+func validateFile(fsys fs.FS, path string) FileResult {
+	result := FileResult{Path: path}
+	data, err := fs.ReadFile(fsys, path)
+	if err != nil {
+		result.Errors = []FileError{{Err: err}}
+		return result
+	}
+	input := string(data)
+	_, err = parser.New(lexer.Lex(input), parser.Recover()).Parse()
+	if err == nil {
+		result.OK = true
+		return result
+	}
+
+	errs := []error{err}
+	var list parser.ErrorList
+	if errors.As(err, &list) {
+		errs = list
+	}
+	file := position.NewFile(input)
+	for _, err := range errs {
+		fileErr := FileError{Err: err}
+		var syntaxErr *parser.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			pos := file.Position(syntaxErr.Offset)
+			fileErr.Line, fileErr.Column = pos.Line, pos.Column
+		}
+		result.Errors = append(result.Errors, fileErr)
+	}
+	return result
+}
@@ -0,0 +1,50 @@
+package gj_test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/pohedev/gj.git"
+	"github.com/pohedev/gj.git/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a.json":       {Data: []byte(`{"a": [1, 2]}`)},
+		"b.json":       {Data: []byte("{\n  \"é\": 1 \"b\": 2,\n  \"c\": [1,]\n}")},
+		"c.txt":        {Data: []byte(`not json`)},
+		"dir/d.json":   {Data: []byte(`[`)},
+		"dir/e.json":   {Data: []byte(`null`)},
+		"other/f.json": {Data: []byte(`1`)},
+	}
+	results, err := gj.ValidateFS(fsys, "*/*.json")
+	assert.Nil(t, err)
+	if assert.Len(t, results, 3) {
+		assert.Equal(t, "dir/d.json", results[0].Path)
+		assert.False(t, results[0].OK)
+		assert.Equal(t, "other/f.json", results[2].Path)
+		assert.True(t, results[2].OK)
+	}
+
+	results, err = gj.ValidateFS(fsys, "*.json")
+	assert.Nil(t, err)
+	if !assert.Len(t, results, 2) {
+		return
+	}
+	assert.Equal(t, gj.FileResult{Path: "a.json", OK: true}, results[0])
+	b := results[1]
+	assert.False(t, b.OK)
+	if assert.Len(t, b.Errors, 2) {
+		assert.Equal(t, 2, b.Errors[0].Line)
+		assert.Equal(t, 10, b.Errors[0].Column)
+		assert.ErrorIs(t, b.Errors[0], parser.ErrUnexpectedToken)
+		assert.Equal(t, 3, b.Errors[1].Line)
+		assert.Equal(t, 10, b.Errors[1].Column)
+		assert.ErrorIs(t, b.Errors[1], parser.ErrTrailingComma)
+		assert.Regexp(t, `^2:10: `, b.Errors[0].Error())
+	}
+
+	_, err = gj.ValidateFS(fsys, "[")
+	assert.Error(t, err)
+}